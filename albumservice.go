@@ -1,13 +1,9 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"net/url"
-	"time"
 )
 
 // AlbumService 专辑服务
@@ -89,10 +85,63 @@ type PlaylistSongsResponse struct {
 	Data      []AlbumSongData `json:"data"`
 }
 
-// readCookieFromFile 从全局Cookie管理器读取cookie
-func (a *AlbumService) readCookieFromFile() (string, error) {
-	cookie := GlobalCookieManager.GetCookie()
-	return cookie, nil
+// albumDetailItem /album/detail 接口 data 数组的元素
+type albumDetailItem struct {
+	AlbumID        apiString `json:"album_id"`
+	AlbumName      apiString `json:"album_name"`
+	AuthorName     apiString `json:"author_name"`
+	PublishDate    apiString `json:"publish_date"`
+	Intro          apiString `json:"intro"`
+	PublishCompany apiString `json:"publish_company"`
+	Language       apiString `json:"language"`
+	Category       apiString `json:"category"`
+	SizableCover   apiString `json:"sizable_cover"`
+}
+
+// albumSongItem /album/songs 接口 data.songs 数组的元素
+type albumSongItem struct {
+	AudioInfo struct {
+		Hash     apiString `json:"hash"`
+		Duration apiInt    `json:"duration"`
+	} `json:"audio_info"`
+	Base struct {
+		AudioName  apiString `json:"audio_name"`
+		AuthorName apiString `json:"author_name"`
+		AlbumID    apiString `json:"album_id"`
+	} `json:"base"`
+	AlbumInfo struct {
+		AlbumName apiString `json:"album_name"`
+		Cover     apiString `json:"cover"`
+	} `json:"album_info"`
+}
+
+// playlistDetailItem /playlist/detail 接口 data 数组的元素
+type playlistDetailItem struct {
+	GlobalCollectionID apiString `json:"global_collection_id"`
+	Name               apiString `json:"name"`
+	ListCreateUsername apiString `json:"list_create_username"`
+	Intro              apiString `json:"intro"`
+	PublishDate        apiString `json:"publish_date"`
+	Pic                apiString `json:"pic"`
+	Count              apiInt    `json:"count"`
+}
+
+// playlistTrackItem /playlist/track/all 接口 data.songs 数组的元素
+type playlistTrackItem struct {
+	Hash apiString `json:"hash"`
+	Base struct {
+		AudioName apiString `json:"audio_name"`
+	} `json:"base"`
+	Name       apiString    `json:"name"`
+	Timelen    apiInt       `json:"timelen"`
+	AlbumInfo  apiAlbumInfo `json:"albuminfo"`
+	SingerInfo apiSingers   `json:"singerinfo"`
+	Cover      apiString    `json:"cover"`
+}
+
+// albumSongsData 专辑/歌单歌曲列表接口 data 字段的结构
+type albumSongsData[T any] struct {
+	Songs apiList[T] `json:"songs"`
 }
 
 // GetAlbumDetail 获取专辑详情
@@ -106,168 +155,43 @@ func (a *AlbumService) GetAlbumDetail(albumID string) AlbumDetailResponse {
 		}
 	}
 
-	// 读取cookie
-	cookie, err := a.readCookieFromFile()
-	if err != nil {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/album/detail", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("id", albumID)
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用专辑详情API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		log.Printf("专辑详情API请求失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "API请求失败",
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
+	items, err := apiGetData[apiList[albumDetailItem]](apiRequest{
+		Path:   "/album/detail",
+		Params: url.Values{"id": {albumID}},
+		Cookie: true,
+	}, "API请求失败")
 	if err != nil {
-		log.Printf("读取专辑详情响应失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "读取响应失败",
-		}
-	}
-
-	log.Printf("专辑详情API响应状态码: %d", resp.StatusCode)
-
-	// 检查响应状态码
-	if resp.StatusCode != 200 {
-		log.Printf("专辑详情API返回非200状态码: %d", resp.StatusCode)
+		log.Printf("获取专辑详情失败: %v", err)
+		apiErr := asApiError(err)
 		return AlbumDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("API返回状态码: %d", resp.StatusCode),
+			Success:   false,
+			Message:   apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Status:    apiErr.Status,
 		}
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("解析专辑详情JSON失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "解析响应失败",
-		}
-	}
-
-	// 打印完整的API响应用于调试
-	log.Printf("专辑详情API完整响应: %+v", apiResponse)
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AlbumDetailResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 获取data数组
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
-		}
-	}
-
-	log.Printf("专辑详情data字段类型: %T, 内容: %+v", dataInterface, dataInterface)
-
-	dataArray, ok := dataInterface.([]interface{})
-	if !ok {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "data字段格式错误，期望数组",
-		}
-	}
-
-	if len(dataArray) == 0 {
+	if len(items) == 0 {
 		return AlbumDetailResponse{
 			Success: false,
 			Message: "专辑详情数据为空",
 		}
 	}
 
-	// 获取第一个专辑详情对象
-	dataMap, ok := dataArray[0].(map[string]interface{})
-	if !ok {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "专辑详情数据格式错误",
-		}
-	}
-
 	// 解析专辑详情数据
-	albumDetail := AlbumDetailData{}
-
-	if id, ok := dataMap["album_id"].(string); ok {
-		albumDetail.ID = id
-	} else if idFloat, ok := dataMap["album_id"].(float64); ok {
-		albumDetail.ID = fmt.Sprintf("%.0f", idFloat)
-	}
-
-	if albumName, ok := dataMap["album_name"].(string); ok {
-		albumDetail.AlbumName = albumName
-	}
-
-	if authorName, ok := dataMap["author_name"].(string); ok {
-		albumDetail.AuthorName = authorName
-	}
-
-	if publishDate, ok := dataMap["publish_date"].(string); ok {
-		albumDetail.PublishDate = publishDate
-	}
-
-	// 注意：API响应中没有song_count字段，我们暂时设为0
-	albumDetail.SongCount = 0
-
-	if description, ok := dataMap["intro"].(string); ok {
-		albumDetail.Description = description
-	}
-
-	if publishCompany, ok := dataMap["publish_company"].(string); ok {
-		albumDetail.PublishCompany = publishCompany
-	}
-
-	if language, ok := dataMap["language"].(string); ok {
-		albumDetail.Language = language
-	}
-
-	if category, ok := dataMap["category"].(string); ok {
-		albumDetail.Category = category
-	}
-
-	// 处理封面图片URL
-	if sizableCover, ok := dataMap["sizable_cover"].(string); ok {
-		albumDetail.UnionCover = sizableCover
+	item := items[0]
+	albumDetail := AlbumDetailData{
+		ID:          item.AlbumID.String(),
+		AlbumName:   item.AlbumName.String(),
+		AuthorName:  item.AuthorName.String(),
+		PublishDate: item.PublishDate.String(),
+		// 注意：API响应中没有song_count字段，我们暂时设为0
+		SongCount:      0,
+		Description:    item.Intro.String(),
+		PublishCompany: item.PublishCompany.String(),
+		Language:       item.Language.String(),
+		Category:       item.Category.String(),
+		UnionCover:     item.SizableCover.String(),
 	}
 
 	log.Printf("成功获取专辑详情: %s", albumDetail.AlbumName)
@@ -292,192 +216,42 @@ func (a *AlbumService) GetAlbumSongs(albumID string, page int, pageSize int) Alb
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
+	page, pageSize = normalizePaging(page, pageSize)
 
-	// 读取cookie
-	cookie, err := a.readCookieFromFile()
+	data, err := apiGetData[albumSongsData[albumSongItem]](apiRequest{
+		Path: "/album/songs",
+		Params: url.Values{
+			"id":       {albumID},
+			"page":     {fmt.Sprintf("%d", page)},
+			"pagesize": {fmt.Sprintf("%d", pageSize)},
+		},
+		Cookie: true,
+	}, "API请求失败")
 	if err != nil {
+		log.Printf("获取专辑歌曲列表失败: %v", err)
+		apiErr := asApiError(err)
 		return AlbumSongsResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/album/songs", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("id", albumID)
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用专辑歌曲列表API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		log.Printf("专辑歌曲列表API请求失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "API请求失败",
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("读取专辑歌曲列表响应失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "读取响应失败",
-		}
-	}
-
-	log.Printf("专辑歌曲列表API响应状态码: %d", resp.StatusCode)
-
-	// 检查响应状态码
-	if resp.StatusCode != 200 {
-		log.Printf("专辑歌曲列表API返回非200状态码: %d", resp.StatusCode)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: fmt.Sprintf("API返回状态码: %d", resp.StatusCode),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("解析专辑歌曲列表JSON失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "解析响应失败",
-		}
-	}
-
-	// 打印完整的API响应用于调试
-	log.Printf("专辑歌曲列表API完整响应: %+v", apiResponse)
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AlbumSongsResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 获取data对象
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
-		}
-	}
-
-	log.Printf("专辑歌曲列表data字段类型: %T, 内容: %+v", dataInterface, dataInterface)
-
-	dataMap, ok := dataInterface.(map[string]interface{})
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "data字段格式错误，期望对象",
-		}
-	}
-
-	// 获取songs数组
-	songsInterface, ok := dataMap["songs"]
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "响应中缺少songs字段",
-		}
-	}
-
-	dataArray, ok := songsInterface.([]interface{})
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "songs字段格式错误",
+			Success:   false,
+			Message:   apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Status:    apiErr.Status,
 		}
 	}
 
 	// 转换为前端需要的格式
 	var albumSongsList []AlbumSongData
-	for _, item := range dataArray {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
+	for _, item := range data.Songs {
+		song := AlbumSongData{
+			Hash:       item.AudioInfo.Hash.String(),
+			SongName:   item.Base.AudioName.String(),
+			TimeLength: item.AudioInfo.Duration.Int() / 1000, // 毫秒转秒
+			AlbumName:  item.AlbumInfo.AlbumName.String(),
+			AlbumID:    item.Base.AlbumID.String(),
+			AuthorName: item.Base.AuthorName.String(),
+			UnionCover: item.AlbumInfo.Cover.String(),
 		}
-
-		song := AlbumSongData{}
-
-		// 根据API文档提取字段
-		// hash: $.data.songs[0].audio_info.hash
-		if audioInfo, ok := itemMap["audio_info"].(map[string]interface{}); ok {
-			if hash, ok := audioInfo["hash"].(string); ok {
-				song.Hash = hash
-			}
-			// timelength: $.data.songs[0].audio_info.duration (毫秒转秒)
-			if duration, ok := audioInfo["duration"].(float64); ok {
-				song.TimeLength = int(duration / 1000) // 毫秒转秒
-			} else if durationInt, ok := audioInfo["duration"].(int); ok {
-				song.TimeLength = durationInt / 1000 // 毫秒转秒
-			}
-		}
-
-		// songname: $.data.songs[0].base.audio_name
-		// author_name: $.data.songs[0].base.author_name
-		// album_id: $.data.songs[0].base.album_id
-		if base, ok := itemMap["base"].(map[string]interface{}); ok {
-			if audioName, ok := base["audio_name"].(string); ok {
-				song.SongName = audioName
-			}
-			if authorName, ok := base["author_name"].(string); ok {
-				song.AuthorName = authorName
-			}
-			if albumID, ok := base["album_id"].(string); ok {
-				song.AlbumID = albumID
-			} else if albumIDFloat, ok := base["album_id"].(float64); ok {
-				song.AlbumID = fmt.Sprintf("%.0f", albumIDFloat)
-			}
-
-			// filename: $.data.songs[0].base.author_name + " - " + $.data.songs[0].base.audio_name
-			if authorName, ok := base["author_name"].(string); ok {
-				if audioName, ok := base["audio_name"].(string); ok {
-					song.FileName = authorName + " - " + audioName
-				}
-			}
-		}
-
-		// albumname: $.data.songs[0].album_info.album_name
-		// union_cover: $.data.songs[0].album_info.cover
-		if albumInfo, ok := itemMap["album_info"].(map[string]interface{}); ok {
-			if albumName, ok := albumInfo["album_name"].(string); ok {
-				song.AlbumName = albumName
-			}
-			if cover, ok := albumInfo["cover"].(string); ok {
-				song.UnionCover = cover
-			}
+		if song.AuthorName != "" && song.SongName != "" {
+			song.FileName = song.AuthorName + " - " + song.SongName
 		}
 
 		albumSongsList = append(albumSongsList, song)
@@ -505,148 +279,39 @@ func (a *AlbumService) GetPlaylistDetail(playlistID string) AlbumDetailResponse
 		}
 	}
 
-	// 读取cookie
-	cookie, err := a.readCookieFromFile()
-	if err != nil {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/playlist/detail", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("ids", playlistID)
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用歌单详情API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	items, err := apiGetData[apiList[playlistDetailItem]](apiRequest{
+		Path:   "/playlist/detail",
+		Params: url.Values{"ids": {playlistID}},
+		Cookie: true,
+	}, "API请求失败")
 	if err != nil {
-		log.Printf("歌单详情API请求失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "API请求失败",
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("读取歌单详情响应失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "读取响应失败",
-		}
-	}
-
-	log.Printf("歌单详情API响应状态码: %d", resp.StatusCode)
-
-	// 检查响应状态码
-	if resp.StatusCode != 200 {
-		log.Printf("歌单详情API返回非200状态码: %d", resp.StatusCode)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("API返回状态码: %d", resp.StatusCode),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("解析歌单详情JSON失败: %v", err)
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "解析响应失败",
-		}
-	}
-
-	// 打印完整的API响应用于调试
-	log.Printf("歌单详情API完整响应: %+v", apiResponse)
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AlbumDetailResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 获取data数组
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
+		log.Printf("获取歌单详情失败: %v", err)
+		apiErr := asApiError(err)
 		return AlbumDetailResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
+			Success:   false,
+			Message:   apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Status:    apiErr.Status,
 		}
 	}
 
-	log.Printf("歌单详情data字段类型: %T, 内容: %+v", dataInterface, dataInterface)
-
-	dataArray, ok := dataInterface.([]interface{})
-	if !ok {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "data字段格式错误，期望数组",
-		}
-	}
-
-	if len(dataArray) == 0 {
+	if len(items) == 0 {
 		return AlbumDetailResponse{
 			Success: false,
 			Message: "歌单详情数据为空",
 		}
 	}
 
-	// 获取第一个歌单详情对象
-	dataMap, ok := dataArray[0].(map[string]interface{})
-	if !ok {
-		return AlbumDetailResponse{
-			Success: false,
-			Message: "歌单详情数据格式错误",
-		}
-	}
-
 	// 解析歌单详情数据
-	playlistDetail := AlbumDetailData{}
-
-	if globalCollectionID, ok := dataMap["global_collection_id"].(string); ok {
-		playlistDetail.ID = globalCollectionID
-	}
-	if name, ok := dataMap["name"].(string); ok {
-		playlistDetail.AlbumName = name
-	}
-	if authorName, ok := dataMap["list_create_username"].(string); ok {
-		playlistDetail.AuthorName = authorName
-	}
-	if intro, ok := dataMap["intro"].(string); ok {
-		playlistDetail.Description = intro
-	}
-	if publishDate, ok := dataMap["publish_date"].(string); ok {
-		playlistDetail.PublishDate = publishDate
-	}
-	if pic, ok := dataMap["pic"].(string); ok {
-		playlistDetail.UnionCover = pic
-	}
-	if count, ok := dataMap["count"].(float64); ok {
-		playlistDetail.SongCount = int(count)
+	item := items[0]
+	playlistDetail := AlbumDetailData{
+		ID:          item.GlobalCollectionID.String(),
+		AlbumName:   item.Name.String(),
+		AuthorName:  item.ListCreateUsername.String(),
+		Description: item.Intro.String(),
+		PublishDate: item.PublishDate.String(),
+		UnionCover:  item.Pic.String(),
+		SongCount:   item.Count.Int(),
 	}
 
 	log.Printf("成功获取歌单详情: %s", playlistDetail.AlbumName)
@@ -671,198 +336,41 @@ func (a *AlbumService) GetPlaylistSongs(playlistID string, page int, pageSize in
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := a.readCookieFromFile()
-	if err != nil {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/playlist/track/all", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("id", playlistID)
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用歌单歌曲列表API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		log.Printf("歌单歌曲列表API请求失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "API请求失败",
-		}
-	}
-	defer resp.Body.Close()
+	page, pageSize = normalizePaging(page, pageSize)
 
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
+	data, err := apiGetData[albumSongsData[playlistTrackItem]](apiRequest{
+		Path: "/playlist/track/all",
+		Params: url.Values{
+			"id":       {playlistID},
+			"page":     {fmt.Sprintf("%d", page)},
+			"pagesize": {fmt.Sprintf("%d", pageSize)},
+		},
+		Cookie: true,
+	}, "API请求失败")
 	if err != nil {
-		log.Printf("读取歌单歌曲列表响应失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "读取响应失败",
-		}
-	}
-
-	log.Printf("歌单歌曲列表API响应状态码: %d", resp.StatusCode)
-
-	// 检查响应状态码
-	if resp.StatusCode != 200 {
-		log.Printf("歌单歌曲列表API返回非200状态码: %d", resp.StatusCode)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: fmt.Sprintf("API返回状态码: %d", resp.StatusCode),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("解析歌单歌曲列表JSON失败: %v", err)
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "解析响应失败",
-		}
-	}
-
-	// 打印完整的API响应用于调试
-	log.Printf("歌单歌曲列表API完整响应: %+v", apiResponse)
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AlbumSongsResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 获取data对象
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
-		}
-	}
-
-	log.Printf("歌单歌曲列表data字段类型: %T, 内容: %+v", dataInterface, dataInterface)
-
-	dataMap, ok := dataInterface.(map[string]interface{})
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "data字段格式错误，期望对象",
-		}
-	}
-
-	// 获取songs数组
-	songsInterface, ok := dataMap["songs"]
-	if !ok {
+		log.Printf("获取歌单歌曲列表失败: %v", err)
+		apiErr := asApiError(err)
 		return AlbumSongsResponse{
-			Success: false,
-			Message: "响应中缺少songs字段",
-		}
-	}
-
-	dataArray, ok := songsInterface.([]interface{})
-	if !ok {
-		return AlbumSongsResponse{
-			Success: false,
-			Message: "songs字段格式错误，期望数组",
+			Success:   false,
+			Message:   apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Status:    apiErr.Status,
 		}
 	}
 
 	// 转换为前端需要的格式
 	var playlistSongsList []AlbumSongData
-	for _, item := range dataArray {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		song := AlbumSongData{}
-
-		// 根据API文档提取字段
-		// hash: $.data.songs[0].hash
-		if hash, ok := itemMap["hash"].(string); ok {
-			song.Hash = hash
-		}
-
-		// songname: $.data.songs[0].base.audio_name
-		if base, ok := itemMap["base"].(map[string]interface{}); ok {
-			if audioName, ok := base["audio_name"].(string); ok {
-				song.SongName = audioName
-			}
-		}
-
-		// filename: $.data.songs[0].name
-		if name, ok := itemMap["name"].(string); ok {
-			song.FileName = name
-		}
-
-		// timelength: $.data.songs[0].timelen/1000
-		if timelen, ok := itemMap["timelen"].(float64); ok {
-			song.TimeLength = int(timelen / 1000) // 毫秒转秒
-		} else if timelenInt, ok := itemMap["timelen"].(int); ok {
-			song.TimeLength = timelenInt / 1000 // 毫秒转秒
-		}
-
-		// albumname: $.data.songs[0].albuminfo.name
-		// album_id: $.data.songs[0].albuminfo.id
-		if albuminfo, ok := itemMap["albuminfo"].(map[string]interface{}); ok {
-			if albumName, ok := albuminfo["name"].(string); ok {
-				song.AlbumName = albumName
-			}
-			if albumID, ok := albuminfo["id"].(string); ok {
-				song.AlbumID = albumID
-			} else if albumIDFloat, ok := albuminfo["id"].(float64); ok {
-				song.AlbumID = fmt.Sprintf("%.0f", albumIDFloat)
-			}
-		}
-
-		// author_name: $.data.songs[0].singerinfo[0].name
-		if singerinfo, ok := itemMap["singerinfo"].([]interface{}); ok && len(singerinfo) > 0 {
-			if singer, ok := singerinfo[0].(map[string]interface{}); ok {
-				if singerName, ok := singer["name"].(string); ok {
-					song.AuthorName = singerName
-				}
-			}
-		}
-
-		// union_cover: $.data.songs[0].cover
-		if cover, ok := itemMap["cover"].(string); ok {
-			song.UnionCover = cover
-		}
-
-		playlistSongsList = append(playlistSongsList, song)
+	for _, item := range data.Songs {
+		playlistSongsList = append(playlistSongsList, AlbumSongData{
+			Hash:       item.Hash.String(),
+			SongName:   item.Base.AudioName.String(),
+			FileName:   item.Name.String(),
+			TimeLength: item.Timelen.Int() / 1000, // 毫秒转秒
+			AlbumName:  item.AlbumInfo.Name.String(),
+			AlbumID:    item.AlbumInfo.ID.String(),
+			AuthorName: item.SingerInfo.FirstName(),
+			UnionCover: item.Cover.String(),
+		})
 	}
 
 	log.Printf("成功获取歌单歌曲列表，共%d首歌曲", len(playlistSongsList))
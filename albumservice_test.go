@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestGetAlbumDetail(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&AlbumService{}).GetAlbumDetail("960399")
+	if !resp.Success {
+		t.Fatalf("GetAlbumDetail failed: %s", resp.Message)
+	}
+	data := resp.Data
+	if data.ID != "960399" || data.AlbumName != "叶惠美" || data.PublishCompany != "杰威尔音乐" || data.Language != "国语" {
+		t.Errorf("data = %+v", data)
+	}
+}
+
+func TestGetAlbumSongs(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := (&AlbumService{}).GetAlbumSongs("960399", 1, 30)
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	song := resp.Data[0]
+	if song.FileName != "周杰伦 - 晴天" || song.TimeLength != 269 || song.AlbumID != "960399" {
+		t.Errorf("song = %+v", song)
+	}
+
+	req, _ := srv.LastRequest("/album/songs")
+	if req.Query.Get("id") != "960399" {
+		t.Errorf("query = %v", req.Query)
+	}
+}
+
+func TestGetPlaylistDetail(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&AlbumService{}).GetPlaylistDetail("collection_3_1234567_8_0")
+	if !resp.Success {
+		t.Fatalf("GetPlaylistDetail failed: %s", resp.Message)
+	}
+	if resp.Data.AlbumName != "周杰伦精选" || resp.Data.SongCount != 50 {
+		t.Errorf("data = %+v", resp.Data)
+	}
+}
+
+func TestAlbumServiceGetPlaylistSongs(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&AlbumService{}).GetPlaylistSongs("collection_3_1234567_8_0", 1, 30)
+	if !resp.Success || len(resp.Data) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	first, second := resp.Data[0], resp.Data[1]
+	if first.SongName != "晴天" || first.AuthorName != "周杰伦" || first.AlbumID != "960399" || first.TimeLength != 269 {
+		t.Errorf("first = %+v", first)
+	}
+	// timelen 为字符串、singerinfo 为空数组时也能解析
+	if second.TimeLength != 299 || second.AlbumID != "979856" || second.AuthorName != "" {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestGetAlbumDetailFailure(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/album/detail", `{"status":0,"error_code":404}`)
+
+	resp := (&AlbumService{}).GetAlbumDetail("0")
+	if resp.Success || resp.ErrorCode != 404 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 后端接口调用的错误码，写入 ApiResponse.ErrorCode
+// 负数为客户端错误，其余为后端原样返回的 error_code
+const (
+	ApiErrUpstream           = -1    // 后端返回业务错误但没有给出 error_code
+	ApiErrNetwork            = -1001 // 网络请求失败
+	ApiErrReadBody           = -1002 // 读取响应失败
+	ApiErrDecode             = -1003 // 解析响应失败
+	ApiErrHTTPStatus         = -1004 // 服务器返回非200状态且响应无法解析
+	ApiErrBackendUnreachable = -1005 // 所有后端地址均无法连接
+	ApiErrOffline            = -1006 // 已开启离线模式，不访问后端
+)
+
+// defaultApiTimeout 后端请求默认超时时间
+const defaultApiTimeout = 15 * time.Second
+
+// ApiError 后端接口调用错误
+type ApiError struct {
+	Code    int    // 错误码，见 ApiErr* 常量
+	Status  int    // 后端返回的 status 字段
+	Message string // 面向用户的错误信息
+}
+
+// Error 实现 error 接口
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+// asApiError 将任意错误转换为 ApiError
+func asApiError(err error) *ApiError {
+	if apiErr, ok := err.(*ApiError); ok {
+		return apiErr
+	}
+	return &ApiError{Code: ApiErrUpstream, Message: err.Error()}
+}
+
+// isOfflineError 错误是否由离线模式或后端不可达引起
+func isOfflineError(err error) bool {
+	code := asApiError(err).Code
+	return code == ApiErrOffline || code == ApiErrBackendUnreachable
+}
+
+// apiFailure 根据错误生成失败的 ApiResponse
+func apiFailure[T any](err error) ApiResponse[T] {
+	apiErr := asApiError(err)
+	return ApiResponse[T]{
+		Success:   false,
+		Message:   apiErr.Message,
+		ErrorCode: apiErr.Code,
+		Status:    apiErr.Status,
+	}
+}
+
+// apiRequest 描述一次后端接口调用
+type apiRequest struct {
+	Path    string        // 接口路径，例如 /search
+	Params  url.Values    // 查询参数
+	Cookie  bool          // 是否附带登录cookie
+	Timeout time.Duration // 超时时间，为0时使用默认值
+}
+
+// apiClient 后端接口客户端，所有服务共用同一个连接池
+type apiClient struct {
+	httpClient *http.Client
+}
+
+// defaultApiClient 全局共享的后端接口客户端
+var defaultApiClient = newApiClient()
+
+// newApiClient 创建后端接口客户端
+func newApiClient() *apiClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 64
+	transport.MaxIdleConnsPerHost = 16
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &apiClient{
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+// buildURL 基于指定的后端地址拼接完整的请求地址，需要时注入cookie
+func (c *apiClient) buildURL(base string, req apiRequest) string {
+	params := url.Values{}
+	for key, values := range req.Params {
+		params[key] = values
+	}
+	if req.Cookie {
+		params.Set("cookie", GlobalCookieManager.GetCookie())
+	}
+
+	requestURL := base + req.Path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+	return requestURL
+}
+
+// Get 发送GET请求，返回响应体和HTTP状态码
+// 当前后端地址无法连接时按顺序尝试备用地址，全部失败时返回 ApiErrBackendUnreachable
+// 开启离线模式时直接返回 ApiErrOffline
+func (c *apiClient) Get(req apiRequest) ([]byte, int, error) {
+	if GlobalBackendManager.OfflineMode() {
+		return nil, 0, &ApiError{Code: ApiErrOffline, Message: "离线模式下无法使用在线功能"}
+	}
+
+	var lastErr error
+	for _, base := range GlobalBackendManager.candidates() {
+		body, statusCode, err := c.getFrom(base, req)
+		if err == nil {
+			GlobalBackendManager.markOnline(base)
+			return body, statusCode, nil
+		}
+		if apiErr, ok := err.(*ApiError); ok && apiErr.Code != ApiErrNetwork {
+			// 已收到响应，说明地址可用，不再切换
+			GlobalBackendManager.markOnline(base)
+			return nil, statusCode, err
+		}
+		lastErr = err
+	}
+
+	GlobalBackendManager.markUnreachable(lastErr)
+	return nil, 0, &ApiError{
+		Code:    ApiErrBackendUnreachable,
+		Message: fmt.Sprintf("后端服务不可达: %v", lastErr),
+	}
+}
+
+// getFrom 向指定的后端地址发送GET请求
+func (c *apiClient) getFrom(base string, req apiRequest) ([]byte, int, error) {
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultApiTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.buildURL(base, req), nil)
+	if err != nil {
+		return nil, 0, &ApiError{Code: ApiErrNetwork, Message: fmt.Sprintf("创建请求失败: %v", err)}
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, 0, &ApiError{Code: ApiErrNetwork, Message: fmt.Sprintf("网络请求失败: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, &ApiError{Code: ApiErrReadBody, Message: fmt.Sprintf("读取响应失败: %v", err)}
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// GetJSON 发送GET请求并将响应体解码到 v
+// 非200状态但响应体仍是合法JSON时照常解码，由调用方根据业务字段判断成败
+func (c *apiClient) GetJSON(req apiRequest, v any) error {
+	body, statusCode, err := c.Get(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		if statusCode != http.StatusOK {
+			return &ApiError{
+				Code:    ApiErrHTTPStatus,
+				Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", statusCode, truncateBody(body, 200)),
+			}
+		}
+		return &ApiError{Code: ApiErrDecode, Message: fmt.Sprintf("解析响应失败: %v", err)}
+	}
+
+	return nil
+}
+
+// apiGet 使用共享客户端请求接口并解码为类型 T
+func apiGet[T any](req apiRequest) (*T, error) {
+	var result T
+	if err := defaultApiClient.GetJSON(req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// truncateBody 截断响应体，避免错误信息过长
+func truncateBody(body []byte, max int) string {
+	if len(body) <= max {
+		return string(body)
+	}
+	return string(body[:max]) + "..."
+}
+
+// ==================== 响应外壳 ====================
+
+// apiEnvelope 后端接口的通用响应外壳
+// 不同接口用不同的字段表示成败（status / error_code / code），这里统一收集
+type apiEnvelope struct {
+	Status    *apiInt         `json:"status"`
+	ErrorCode *apiInt         `json:"error_code"`
+	Code      *apiInt         `json:"code"`
+	Message   apiString       `json:"message"`
+	ErrorMsg  apiString       `json:"error_msg"`
+	Msg       apiString       `json:"msg"`
+	Error     apiString       `json:"error"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// statusIs 判断 status 字段是否存在且等于指定值
+func (e *apiEnvelope) statusIs(value int) bool {
+	return e.Status != nil && e.Status.Int() == value
+}
+
+// errorCodeIs 判断 error_code 字段是否存在且等于指定值
+func (e *apiEnvelope) errorCodeIs(value int) bool {
+	return e.ErrorCode != nil && e.ErrorCode.Int() == value
+}
+
+// codeIs 判断 code 字段是否存在且等于指定值
+func (e *apiEnvelope) codeIs(value int) bool {
+	return e.Code != nil && e.Code.Int() == value
+}
+
+// failure 根据响应外壳生成业务错误，fallback 为后端未给出说明时的默认信息
+func (e *apiEnvelope) failure(fallback string) *ApiError {
+	apiErr := &ApiError{Code: ApiErrUpstream, Message: fallback}
+
+	if e.ErrorCode != nil {
+		apiErr.Code = e.ErrorCode.Int()
+	}
+	if e.Status != nil {
+		apiErr.Status = e.Status.Int()
+	}
+
+	for _, msg := range []apiString{e.Message, e.ErrorMsg, e.Msg, e.Error} {
+		if msg != "" {
+			apiErr.Message = msg.String()
+			return apiErr
+		}
+	}
+
+	if e.Status != nil {
+		apiErr.Message = fmt.Sprintf("%s，状态码: %d", fallback, e.Status.Int())
+	}
+	return apiErr
+}
+
+// decodeApiData 将响应外壳中的 data 字段解码为类型 T
+func decodeApiData[T any](e *apiEnvelope) (T, error) {
+	var data T
+	if len(e.Data) == 0 || string(e.Data) == "null" {
+		return data, &ApiError{Code: ApiErrDecode, Message: "响应中缺少data字段"}
+	}
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return data, &ApiError{Code: ApiErrDecode, Message: fmt.Sprintf("data字段格式错误: %v", err)}
+	}
+	return data, nil
+}
+
+// apiGetData 请求以 status==1 表示成功的接口，并将 data 字段解码为类型 T
+// fallback 为后端未给出错误说明时的默认信息
+func apiGetData[T any](req apiRequest, fallback string) (T, error) {
+	var zero T
+
+	envelope, err := apiGet[apiEnvelope](req)
+	if err != nil {
+		return zero, err
+	}
+
+	if !envelope.statusIs(1) {
+		return zero, envelope.failure(fallback)
+	}
+
+	return decodeApiData[T](envelope)
+}
+
+// ==================== 宽松的JSON类型 ====================
+// 后端同一字段有时是字符串有时是数字，有时干脆缺失或为null。
+// 以下类型在类型不符时保持零值而不是让整个响应解析失败。
+
+// apiString 兼容字符串与数字的字段
+type apiString string
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (s *apiString) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	*s = ""
+	if len(b) == 0 {
+		return nil
+	}
+
+	switch {
+	case b[0] == '"':
+		var str string
+		if err := json.Unmarshal(b, &str); err == nil {
+			*s = apiString(str)
+		}
+	case b[0] == '-' || (b[0] >= '0' && b[0] <= '9'):
+		if _, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+			*s = apiString(b)
+		} else if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+			*s = apiString(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	}
+	return nil
+}
+
+// String 返回字符串值
+func (s apiString) String() string {
+	return string(s)
+}
+
+// apiInt 兼容数字、数字字符串与布尔值的整数字段
+type apiInt int64
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (n *apiInt) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	*n = 0
+	if len(b) == 0 {
+		return nil
+	}
+
+	raw := string(b)
+	switch {
+	case raw == "true":
+		*n = 1
+	case b[0] == '"':
+		var str string
+		if err := json.Unmarshal(b, &str); err == nil {
+			raw = str
+		}
+		fallthrough
+	default:
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			*n = apiInt(i)
+		} else if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			*n = apiInt(f)
+		}
+	}
+	return nil
+}
+
+// Int 返回 int 值
+func (n apiInt) Int() int {
+	return int(n)
+}
+
+// apiList 宽松的数组字段，非数组时为nil，无法解析的元素会被跳过
+type apiList[T any] []T
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (l *apiList[T]) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	*l = nil
+	if len(b) == 0 || b[0] != '[' {
+		return nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil
+	}
+
+	list := make(apiList[T], 0, len(items))
+	for _, item := range items {
+		var value T
+		if err := json.Unmarshal(item, &value); err == nil {
+			list = append(list, value)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// decodeObject 仅当 b 为JSON对象时解码到 v，否则保持零值
+func decodeObject(b []byte, v any) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || b[0] != '{' {
+		return nil
+	}
+	return json.Unmarshal(b, v)
+}
+
+// ==================== 公共的歌曲字段 ====================
+
+// apiTransParam 歌曲条目中的 trans_param 字段
+type apiTransParam struct {
+	UnionCover apiString `json:"union_cover"`
+	Filename   apiString `json:"filename"`
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (t *apiTransParam) UnmarshalJSON(b []byte) error {
+	type plain apiTransParam
+	return decodeObject(b, (*plain)(t))
+}
+
+// apiRelateGood 歌曲条目中 relate_goods 数组的元素
+type apiRelateGood struct {
+	AlbumName      apiString `json:"albumname"`
+	AlbumNameAlias apiString `json:"album_name"`
+	AlbumID        apiString `json:"album_id"`
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (g *apiRelateGood) UnmarshalJSON(b []byte) error {
+	type plain apiRelateGood
+	return decodeObject(b, (*plain)(g))
+}
+
+// apiRelateGoods 歌曲条目中的 relate_goods 字段
+// 专辑名取第一个元素，专辑ID优先取第二个元素
+type apiRelateGoods apiList[apiRelateGood]
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (r *apiRelateGoods) UnmarshalJSON(b []byte) error {
+	return (*apiList[apiRelateGood])(r).UnmarshalJSON(b)
+}
+
+// AlbumName 返回专辑名称
+func (r apiRelateGoods) AlbumName() string {
+	for _, good := range r {
+		if good.AlbumName != "" {
+			return good.AlbumName.String()
+		}
+		if good.AlbumNameAlias != "" {
+			return good.AlbumNameAlias.String()
+		}
+	}
+	return ""
+}
+
+// AlbumID 返回专辑ID
+func (r apiRelateGoods) AlbumID() string {
+	if len(r) > 1 && r[1].AlbumID != "" {
+		return r[1].AlbumID.String()
+	}
+	for _, good := range r {
+		if good.AlbumID != "" {
+			return good.AlbumID.String()
+		}
+	}
+	return ""
+}
+
+// apiSingerInfo 歌曲条目中 singerinfo 数组的元素
+type apiSingerInfo struct {
+	Name apiString `json:"name"`
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (s *apiSingerInfo) UnmarshalJSON(b []byte) error {
+	type plain apiSingerInfo
+	return decodeObject(b, (*plain)(s))
+}
+
+// apiSingers 歌曲条目中的 singerinfo 字段
+type apiSingers apiList[apiSingerInfo]
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (s *apiSingers) UnmarshalJSON(b []byte) error {
+	return (*apiList[apiSingerInfo])(s).UnmarshalJSON(b)
+}
+
+// FirstName 返回第一位歌手的名字
+func (s apiSingers) FirstName() string {
+	if len(s) > 0 {
+		return s[0].Name.String()
+	}
+	return ""
+}
+
+// apiAlbumInfo 歌曲条目中的 albuminfo 字段
+type apiAlbumInfo struct {
+	ID   apiString `json:"id"`
+	Name apiString `json:"name"`
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (a *apiAlbumInfo) UnmarshalJSON(b []byte) error {
+	type plain apiAlbumInfo
+	return decodeObject(b, (*plain)(a))
+}
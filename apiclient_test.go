@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"wmplayer/internal/mockapi"
+)
+
+// testCookie 测试中使用的登录cookie
+const testCookie = "token=mock-token;userid=123456789"
+
+// newMockAPI 启动模拟API并让所有服务请求指向它，测试结束时恢复原配置
+func newMockAPI(t *testing.T) *mockapi.Server {
+	t.Helper()
+
+	srv := mockapi.New()
+	previous := GlobalBackendManager.Status()
+	previousCookie := GlobalCookieManager.GetCookie()
+
+	GlobalBackendManager.Configure([]string{srv.URL}, "test")
+	GlobalCookieManager.SetCookie(testCookie)
+	// 登录成功时会写入配置目录，避免污染真实的用户目录
+	t.Setenv("HOME", t.TempDir())
+
+	t.Cleanup(func() {
+		srv.Close()
+		GlobalBackendManager.Configure(previous.Endpoints, previous.Source)
+		GlobalCookieManager.SetCookie(previousCookie)
+	})
+	return srv
+}
+
+// enableOfflineMode 开启离线模式，测试结束时关闭
+func enableOfflineMode(t *testing.T) {
+	t.Helper()
+	GlobalBackendManager.SetOffline(true)
+	t.Cleanup(func() { GlobalBackendManager.SetOffline(false) })
+}
+
+// assertCookieSent 检查请求是否附带了登录cookie
+func assertCookieSent(t *testing.T, srv *mockapi.Server, path string, want bool) {
+	t.Helper()
+
+	req, ok := srv.LastRequest(path)
+	if !ok {
+		t.Fatalf("%s was not requested", path)
+	}
+	if got := req.Cookie == testCookie; got != want {
+		t.Errorf("%s cookie = %q, want sent=%v", path, req.Cookie, want)
+	}
+}
+
+func TestApiClientFailover(t *testing.T) {
+	srv := newMockAPI(t)
+
+	// 第一个地址无法连接，应切换到模拟API
+	dead := mockapi.New()
+	dead.Close()
+	GlobalBackendManager.Configure([]string{dead.URL, srv.URL}, "test")
+
+	if _, _, err := defaultApiClient.Get(apiRequest{Path: "/search/hot"}); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	status := GlobalBackendManager.Status()
+	if status.ActiveURL != srv.URL || status.State != BackendStateOnline {
+		t.Errorf("status = %+v, want active %s online", status, srv.URL)
+	}
+}
+
+func TestApiClientUnreachable(t *testing.T) {
+	newMockAPI(t)
+
+	dead := mockapi.New()
+	dead.Close()
+	GlobalBackendManager.Configure([]string{dead.URL}, "test")
+
+	_, _, err := defaultApiClient.Get(apiRequest{Path: "/search/hot"})
+	if err == nil || asApiError(err).Code != ApiErrBackendUnreachable {
+		t.Fatalf("err = %v, want code %d", err, ApiErrBackendUnreachable)
+	}
+	if state := GlobalBackendManager.Status().State; state != BackendStateUnreachable {
+		t.Errorf("state = %s, want %s", state, BackendStateUnreachable)
+	}
+}
+
+func TestApiClientOfflineMode(t *testing.T) {
+	srv := newMockAPI(t)
+	enableOfflineMode(t)
+
+	_, _, err := defaultApiClient.Get(apiRequest{Path: "/search/hot"})
+	if err == nil || asApiError(err).Code != ApiErrOffline {
+		t.Fatalf("err = %v, want code %d", err, ApiErrOffline)
+	}
+	if _, ok := srv.LastRequest("/search/hot"); ok {
+		t.Error("backend should not be requested in offline mode")
+	}
+	if status := GlobalBackendManager.Status(); status.State != BackendStateOffline || !status.Offline {
+		t.Errorf("status = %+v, want offline", status)
+	}
+}
+
+func TestApiClientHTTPStatus(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.Handle("/search/hot", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	})
+
+	err := defaultApiClient.GetJSON(apiRequest{Path: "/search/hot"}, &apiEnvelope{})
+	if err == nil || asApiError(err).Code != ApiErrHTTPStatus {
+		t.Fatalf("err = %v, want code %d", err, ApiErrHTTPStatus)
+	}
+}
+
+func TestApiEnvelopeFailure(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/top/song", `{"status":0,"error_code":20018,"error_msg":"请先登录"}`)
+
+	_, err := apiGetData[apiList[newSongItem]](apiRequest{Path: "/top/song"}, "API请求失败")
+	if err == nil {
+		t.Fatal("expected upstream error")
+	}
+	if apiErr := asApiError(err); apiErr.Code != 20018 || apiErr.Message != "请先登录" {
+		t.Errorf("err = %+v, want code 20018 with upstream message", apiErr)
+	}
+}
+
+func TestLenientTypes(t *testing.T) {
+	var item struct {
+		ID    apiString       `json:"id"`
+		Count apiInt          `json:"count"`
+		Tags  apiList[string] `json:"tags"`
+		Album apiAlbumInfo    `json:"album"`
+	}
+	if err := json.Unmarshal([]byte(`{"id":960399,"count":"12","tags":"","album":[]}`), &item); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if item.ID.String() != "960399" || item.Count.Int() != 12 || len(item.Tags) != 0 || item.Album.Name != "" {
+		t.Errorf("unexpected decode result: %+v", item)
+	}
+}
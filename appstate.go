@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"path/filepath"
+)
+
+// errAppStateUnavailable 应用数据尚未创建
+var errAppStateUnavailable = errors.New("应用数据未初始化")
+
+// AppState 应用级的本地数据：播放队列、本地歌单、智能歌单、歌词和歌曲元数据
+// 由 main 创建并设为全局实例，CacheService 只负责音频缓存、本地音乐映射和本地HTTP服务
+type AppState struct {
+	metadata       *SongMetadataStore  // 缓存歌曲的元数据
+	lyricsCache    *LyricsCache        // 按歌曲hash缓存的歌词
+	lyricsClock    *LyricsClock        // 按播放进度推送OSD歌词
+	lyricsOffsets  *LyricsOffsetStore  // 用户调整的歌词偏移
+	lyricsChoices  *LyricsChoiceStore  // 用户选择的候选歌词
+	queue          *PlayQueue          // 当前播放列表
+	playlists      *LocalPlaylistStore // 本地歌单
+	smartPlaylists *SmartPlaylistStore // 智能歌单的条件
+}
+
+// NewAppState 加载 dataDir（~/.cache/gomusic）中保存的应用数据
+// emit 把歌词时钟的消息推送给OSD歌词客户端
+func NewAppState(dataDir string, emit func(LyricsMessage)) *AppState {
+	cacheDir := filepath.Join(dataDir, "cache")
+	queue := NewPlayQueue(filepath.Join(dataDir, "playlist.json"))
+	return &AppState{
+		metadata:       NewSongMetadataStore(filepath.Join(cacheDir, "song_metadata.json")),
+		lyricsCache:    NewLyricsCache(filepath.Join(cacheDir, "lyrics")),
+		lyricsClock:    NewLyricsClock(emit),
+		lyricsOffsets:  NewLyricsOffsetStore(filepath.Join(cacheDir, "lyrics_offsets.json")),
+		lyricsChoices:  NewLyricsChoiceStore(filepath.Join(cacheDir, "lyrics_choices.json")),
+		queue:          queue,
+		playlists:      NewLocalPlaylistStore(filepath.Join(dataDir, "local_playlists.json"), queue),
+		smartPlaylists: NewSmartPlaylistStore(filepath.Join(dataDir, "smart_playlists.json")),
+	}
+}
+
+// currentAppState 获取全局应用数据，尚未创建时返回错误
+func currentAppState() (*AppState, error) {
+	if state := GetAppState(); state != nil {
+		return state, nil
+	}
+	return nil, errAppStateUnavailable
+}
+
+// ApplyInterfaceSettings 应用界面设置中桌面歌词显示的翻译和音译层
+func (s *AppState) ApplyInterfaceSettings(settings InterfaceSettings) {
+	s.lyricsClock.SetLayers(settings.OSDLyricsTranslation, settings.OSDLyricsRomanization)
+}
+
+// flush 保存所有延迟写盘的数据，退出前调用
+func (s *AppState) flush() {
+	if err := s.metadata.Flush(); err != nil {
+		log.Printf("⚠️ 保存歌曲元数据失败: %v", err)
+	}
+	if err := s.queue.Flush(); err != nil {
+		log.Printf("⚠️ 保存播放列表失败: %v", err)
+	}
+	if err := s.playlists.Flush(); err != nil {
+		log.Printf("⚠️ 保存本地歌单失败: %v", err)
+	}
+}
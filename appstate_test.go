@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestAppStateFlush(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	state := NewAppState(c.cacheDir, c.broadcastLyricsMessage)
+
+	// 退出前保存延迟写盘的播放列表、本地歌单和歌曲元数据，与HTTP服务器是否启动无关
+	state.queue.Replace("我喜欢", testQueueSongs(3), 1)
+	state.playlists.Create("歌单", testQueueSongs(2))
+	state.metadata.Update(SongMetadata{Hash: "s0", SongName: "晴天"})
+	state.flush()
+
+	loaded := NewAppState(c.cacheDir, c.broadcastLyricsMessage)
+	if data := loaded.queue.Snapshot(); data.Name != "我喜欢" || len(data.Songs) != 3 || data.CurrentIndex != 1 {
+		t.Errorf("queue = %+v", data)
+	}
+	if playlists := loaded.playlists.List(); len(playlists) != 1 || playlists[0].Name != "歌单" {
+		t.Errorf("playlists = %+v", playlists)
+	}
+	if meta, ok := loaded.metadata.Get("s0"); !ok || meta.SongName != "晴天" {
+		t.Errorf("metadata = %+v, %v", meta, ok)
+	}
+}
+
+func TestAppStateUnavailable(t *testing.T) {
+	withAppState(t, nil)
+
+	// 应用数据尚未创建时，依赖它的服务返回错误而不是崩溃
+	if resp := (&PlaylistService{}).GetPlaylist(); resp.Success || resp.Message != errAppStateUnavailable.Error() {
+		t.Errorf("playlist = %+v", resp)
+	}
+	if resp := (&LocalPlaylistService{}).GetLocalPlaylists(); resp.Success {
+		t.Errorf("local playlists = %+v", resp)
+	}
+	if resp := (&SmartPlaylistService{}).GetSmartPlaylists(); resp.Success {
+		t.Errorf("smart playlists = %+v", resp)
+	}
+}
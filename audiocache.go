@@ -0,0 +1,553 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 缓存固定原因
+const (
+	CachePinDownload = "download" // 已下载的歌曲
+	CachePinFavorite = "favorite" // 我喜欢的歌曲
+)
+
+// defaultCacheMaxSizeMB 默认的音频缓存上限（MB）
+const defaultCacheMaxSizeMB = 2048
+
+// audioCacheSaveDelay 索引变更后延迟写盘的时间，避免每次访问都写文件
+const audioCacheSaveDelay = 5 * time.Second
+
+// audioCacheEntry 缓存索引中的单个文件
+type audioCacheEntry struct {
+	File       string `json:"file"`        // 缓存目录中的文件名
+	Hash       string `json:"hash"`        // 歌曲hash（本地音乐为 local- 前缀的hash）
+	Quality    string `json:"quality"`     // 音质等级，旧版本缓存和本地音乐为空
+	Size       int64  `json:"size"`        // 文件大小（字节）
+	Checksum   string `json:"checksum"`    // 文件内容的md5，旧版本缓存在首次校验时补充
+	ModTime    int64  `json:"mod_time"`    // 计算校验和时文件的修改时间（纳秒）
+	CreatedAt  int64  `json:"created_at"`  // 写入缓存的时间
+	LastAccess int64  `json:"last_access"` // 最近一次被播放的时间
+}
+
+// audioCacheIndex 缓存索引文件的结构
+type audioCacheIndex struct {
+	Entries []*audioCacheEntry  `json:"entries"`
+	Pins    map[string][]string `json:"pins"` // 歌曲hash -> 固定原因
+}
+
+// CacheStats 缓存统计信息
+type CacheStats struct {
+	CacheDir    string `json:"cache_dir"`
+	TotalSize   int64  `json:"total_size"`
+	MaxSize     int64  `json:"max_size"` // 0表示不限制
+	FileCount   int    `json:"file_count"`
+	PinnedCount int    `json:"pinned_count"`
+	PinnedSize  int64  `json:"pinned_size"`
+}
+
+// CachePruneResult 清理缓存的结果
+type CachePruneResult struct {
+	RemovedCount int   `json:"removed_count"`
+	FreedSize    int64 `json:"freed_size"`
+}
+
+// AudioCache 管理音频缓存目录的索引、容量上限与LRU淘汰
+type AudioCache struct {
+	dir           string
+	indexFile     string
+	quarantineDir string // 隔离损坏文件的目录
+	maxSize       int64
+	entries       map[string]*audioCacheEntry // 文件名 -> 条目
+	pins          map[string][]string
+	saveTimer     *time.Timer
+	saveMutex     sync.Mutex // 串行写盘，先取得的快照先写入
+	mutex         sync.Mutex
+}
+
+// NewAudioCache 创建音频缓存管理器，并与缓存目录中的实际文件同步
+func NewAudioCache(dir string, indexFile string) *AudioCache {
+	cache := &AudioCache{
+		dir:           dir,
+		indexFile:     indexFile,
+		quarantineDir: filepath.Join(filepath.Dir(dir), "quarantine"),
+		maxSize:       defaultCacheMaxSizeMB << 20,
+		entries:       make(map[string]*audioCacheEntry),
+		pins:          make(map[string][]string),
+	}
+	cache.load()
+	return cache
+}
+
+// load 读取索引文件，补充索引中缺失的文件并移除已不存在的条目
+func (a *AudioCache) load() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if data, err := os.ReadFile(a.indexFile); err == nil {
+		var index audioCacheIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			fmt.Printf("⚠️ 解析缓存索引失败，将重新扫描: %v\n", err)
+		} else {
+			for _, entry := range index.Entries {
+				if entry != nil && entry.File != "" {
+					a.entries[entry.File] = entry
+				}
+			}
+			for hash, reasons := range index.Pins {
+				if len(reasons) > 0 {
+					a.pins[hash] = reasons
+				}
+			}
+		}
+	}
+
+	files, err := os.ReadDir(a.dir)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️ 读取缓存目录失败: %v\n", err)
+	}
+
+	present := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), ".tmp") {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		present[file.Name()] = true
+
+		if entry, ok := a.entries[file.Name()]; ok {
+			// 长度与记录不一致说明文件被截断或改写
+			if entry.Checksum != "" && entry.Size != info.Size() {
+				a.quarantineLocked(entry, fmt.Errorf("文件长度不一致: %d / %d", info.Size(), entry.Size))
+				delete(present, file.Name())
+				continue
+			}
+			entry.Size = info.Size()
+			continue
+		}
+		// 索引之外的文件（旧版本缓存）以修改时间作为最近访问时间
+		modTime := info.ModTime().Unix()
+		a.entries[file.Name()] = &audioCacheEntry{
+			File:       file.Name(),
+			Size:       info.Size(),
+			CreatedAt:  modTime,
+			LastAccess: modTime,
+		}
+	}
+
+	for name := range a.entries {
+		if !present[name] {
+			delete(a.entries, name)
+		}
+	}
+
+	fmt.Printf("✅ 音频缓存索引已加载: %d 个文件\n", len(a.entries))
+}
+
+// SetMaxSize 设置缓存上限（字节），0表示不限制，超出时立即淘汰
+func (a *AudioCache) SetMaxSize(maxSize int64) {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+
+	a.mutex.Lock()
+	a.maxSize = maxSize
+	a.mutex.Unlock()
+
+	a.Prune()
+}
+
+// Record 记录新写入缓存的文件及其校验和，并在超出上限时淘汰最久未播放的文件
+// 同一首歌缓存了更高音质后，较低音质的文件会被删除
+func (a *AudioCache) Record(file string, hash string, quality string) {
+	info, err := os.Stat(filepath.Join(a.dir, file))
+	if err != nil {
+		return
+	}
+	checksum, err := fileChecksum(filepath.Join(a.dir, file))
+	if err != nil {
+		fmt.Printf("⚠️ 计算缓存文件校验和失败: %s, %v\n", file, err)
+	}
+
+	now := time.Now().Unix()
+	a.mutex.Lock()
+	entry, ok := a.entries[file]
+	if !ok {
+		entry = &audioCacheEntry{File: file, CreatedAt: now}
+		a.entries[file] = entry
+	}
+	if hash != "" {
+		entry.Hash = hash
+	}
+	if quality != "" {
+		entry.Quality = quality
+	}
+	entry.Size = info.Size()
+	entry.Checksum = checksum
+	entry.ModTime = info.ModTime().UnixNano()
+	entry.LastAccess = now
+
+	if rank := audioQualityRank(entry.Quality); rank > 0 && entry.Hash != "" {
+		for _, other := range a.entries {
+			if other != entry && other.Hash == entry.Hash && audioQualityRank(other.Quality) < rank {
+				if err := a.removeLocked(other); err != nil {
+					fmt.Printf("⚠️ 删除低音质缓存失败: %s, %v\n", other.File, err)
+				}
+			}
+		}
+	}
+	a.scheduleSaveLocked()
+	a.mutex.Unlock()
+
+	a.Prune()
+}
+
+// AssignHash 为旧版本缓存补充歌曲hash和音质，之后按音质查找时可以直接使用
+func (a *AudioCache) AssignHash(file string, hash string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	entry, ok := a.entries[file]
+	if !ok || (entry.Hash != "" && entry.Quality != "") {
+		return
+	}
+	if entry.Hash == "" {
+		entry.Hash = hash
+	}
+	if entry.Quality == "" {
+		entry.Quality = legacyAudioQuality(filepath.Join(a.dir, file))
+	}
+	a.scheduleSaveLocked()
+}
+
+// legacyAudioQuality 推断旧版本缓存文件的音质
+// 旧版本获取播放地址时不指定音质，缓存的都是mp3，按第一帧的码率区分标准和高品质
+func legacyAudioQuality(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return AudioQualityLow
+	}
+	defer file.Close()
+
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(file, head)
+	// 跳过开头的ID3v2标签，带封面的标签可能很长
+	if skip := id3v2Size(head[:n]); skip > 0 {
+		if _, err := file.Seek(int64(skip), io.SeekStart); err != nil {
+			return AudioQualityLow
+		}
+		n, _ = io.ReadFull(file, head)
+	}
+	if mp3FrameBitrate(head[:n]) >= 256 {
+		return AudioQualityMedium
+	}
+	return AudioQualityLow
+}
+
+// Lookup 查找歌曲满足音质要求的缓存文件，优先使用最接近要求的音质
+// allowLower 为 true 时，没有满足要求的文件则返回已缓存的最高音质
+func (a *AudioCache) Lookup(hash string, quality string, allowLower bool) (string, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	want := audioQualityRank(quality)
+	var best, lower *audioCacheEntry
+	for _, entry := range a.entries {
+		if entry.Hash != hash {
+			continue
+		}
+		rank := audioQualityRank(entry.Quality)
+		if rank >= want {
+			if best == nil || rank < audioQualityRank(best.Quality) {
+				best = entry
+			}
+		} else if lower == nil || rank > audioQualityRank(lower.Quality) {
+			lower = entry
+		}
+	}
+
+	if best == nil && allowLower {
+		best = lower
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.File, true
+}
+
+// Touch 更新文件的最近访问时间，由HTTP文件服务调用
+func (a *AudioCache) Touch(file string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if entry, ok := a.entries[file]; ok {
+		entry.LastAccess = time.Now().Unix()
+		a.scheduleSaveLocked()
+	}
+}
+
+// Pin 固定歌曲，使其不会被自动淘汰
+func (a *AudioCache) Pin(hash string, reason string) {
+	if hash == "" {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, existing := range a.pins[hash] {
+		if existing == reason {
+			return
+		}
+	}
+	a.pins[hash] = append(a.pins[hash], reason)
+	a.scheduleSaveLocked()
+}
+
+// Unpin 取消指定原因的固定，所有原因都取消后歌曲可以被淘汰
+func (a *AudioCache) Unpin(hash string, reason string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	reasons := a.pins[hash]
+	for i, existing := range reasons {
+		if existing == reason {
+			reasons = append(reasons[:i:i], reasons[i+1:]...)
+			break
+		}
+	}
+	if len(reasons) == 0 {
+		delete(a.pins, hash)
+	} else {
+		a.pins[hash] = reasons
+	}
+	a.scheduleSaveLocked()
+}
+
+// UnpinAll 取消所有歌曲指定原因的固定
+func (a *AudioCache) UnpinAll(reason string) {
+	a.mutex.Lock()
+	hashes := make([]string, 0, len(a.pins))
+	for hash := range a.pins {
+		hashes = append(hashes, hash)
+	}
+	a.mutex.Unlock()
+
+	for _, hash := range hashes {
+		a.Unpin(hash, reason)
+	}
+}
+
+// IsPinned 判断歌曲是否被固定
+func (a *AudioCache) IsPinned(hash string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return len(a.pins[hash]) > 0
+}
+
+// Stats 返回缓存统计信息
+func (a *AudioCache) Stats() CacheStats {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	stats := CacheStats{
+		CacheDir:  a.dir,
+		MaxSize:   a.maxSize,
+		FileCount: len(a.entries),
+	}
+	for _, entry := range a.entries {
+		stats.TotalSize += entry.Size
+		if len(a.pins[entry.Hash]) > 0 {
+			stats.PinnedCount++
+			stats.PinnedSize += entry.Size
+		}
+	}
+	return stats
+}
+
+// Entries 返回缓存索引中所有条目的副本
+func (a *AudioCache) Entries() []audioCacheEntry {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	entries := make([]audioCacheEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+// Prune 按最近访问时间从旧到新淘汰未固定的文件，直到总大小不超过上限
+func (a *AudioCache) Prune() CachePruneResult {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var result CachePruneResult
+	if a.maxSize <= 0 {
+		return result
+	}
+
+	var total int64
+	candidates := make([]*audioCacheEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		total += entry.Size
+		if len(a.pins[entry.Hash]) == 0 {
+			candidates = append(candidates, entry)
+		}
+	}
+	if total <= a.maxSize {
+		return result
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].LastAccess < candidates[j].LastAccess
+	})
+
+	for _, entry := range candidates {
+		if total <= a.maxSize {
+			break
+		}
+		if err := a.removeLocked(entry); err != nil {
+			fmt.Printf("⚠️ 淘汰缓存文件失败: %s, %v\n", entry.File, err)
+			continue
+		}
+		total -= entry.Size
+		result.RemovedCount++
+		result.FreedSize += entry.Size
+	}
+
+	if result.RemovedCount > 0 {
+		fmt.Printf("🗑️ 缓存超出上限，已淘汰 %d 个文件，释放 %.1f MB\n", result.RemovedCount, float64(result.FreedSize)/(1<<20))
+	}
+	if total > a.maxSize {
+		fmt.Printf("⚠️ 固定的缓存文件超出上限: %.1f MB / %.1f MB\n", float64(total)/(1<<20), float64(a.maxSize)/(1<<20))
+	}
+	return result
+}
+
+// Clear 删除缓存文件，keepPinned 为 true 时保留固定的歌曲
+// activeTemps 为正在下载的临时文件名，不会被删除
+func (a *AudioCache) Clear(keepPinned bool, activeTemps map[string]bool) CachePruneResult {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var result CachePruneResult
+	for _, entry := range a.entries {
+		if keepPinned && len(a.pins[entry.Hash]) > 0 {
+			continue
+		}
+		if err := a.removeLocked(entry); err != nil {
+			fmt.Printf("⚠️ 删除缓存文件失败: %s, %v\n", entry.File, err)
+			continue
+		}
+		result.RemovedCount++
+		result.FreedSize += entry.Size
+	}
+
+	// 清理下载中断留下的临时文件和隔离的损坏文件
+	if files, err := os.ReadDir(a.dir); err == nil {
+		for _, file := range files {
+			if strings.HasSuffix(file.Name(), ".tmp") && !activeTemps[file.Name()] {
+				os.Remove(filepath.Join(a.dir, file.Name()))
+			}
+		}
+	}
+	os.RemoveAll(a.quarantineDir)
+
+	return result
+}
+
+// removeLocked 删除缓存文件及其索引条目，调用方需持有锁
+func (a *AudioCache) removeLocked(entry *audioCacheEntry) error {
+	if err := os.Remove(filepath.Join(a.dir, entry.File)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(a.entries, entry.File)
+	a.scheduleSaveLocked()
+	return nil
+}
+
+// scheduleSaveLocked 延迟保存索引，调用方需持有锁
+func (a *AudioCache) scheduleSaveLocked() {
+	if a.saveTimer != nil {
+		return
+	}
+	a.saveTimer = time.AfterFunc(audioCacheSaveDelay, func() {
+		if err := a.Flush(); err != nil {
+			fmt.Printf("⚠️ 保存缓存索引失败: %v\n", err)
+		}
+	})
+}
+
+// Flush 立即将索引写入文件
+func (a *AudioCache) Flush() error {
+	// 定时保存和退出时的保存不会交错写入临时文件，旧的索引也不会覆盖新的
+	a.saveMutex.Lock()
+	defer a.saveMutex.Unlock()
+
+	a.mutex.Lock()
+	if a.saveTimer != nil {
+		a.saveTimer.Stop()
+		a.saveTimer = nil
+	}
+	index := audioCacheIndex{
+		Entries: make([]*audioCacheEntry, 0, len(a.entries)),
+		Pins:    make(map[string][]string, len(a.pins)),
+	}
+	for _, entry := range a.entries {
+		copied := *entry
+		index.Entries = append(index.Entries, &copied)
+	}
+	for hash, reasons := range a.pins {
+		index.Pins[hash] = append([]string(nil), reasons...)
+	}
+	a.mutex.Unlock()
+
+	sort.Slice(index.Entries, func(i, j int) bool {
+		return index.Entries[i].File < index.Entries[j].File
+	})
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化缓存索引失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.indexFile), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致索引损坏
+	tempFile := a.indexFile + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入缓存索引失败: %v", err)
+	}
+	return os.Rename(tempFile, a.indexFile)
+}
+
+// pinCachedSong 固定歌曲缓存，缓存服务尚未创建时忽略
+func pinCachedSong(hash string, reason string) {
+	if cacheService := GetCacheService(); cacheService != nil {
+		cacheService.audioCache.Pin(hash, reason)
+	}
+}
+
+// unpinCachedSong 取消歌曲缓存的固定，hash 为空时取消该原因下的所有固定
+func unpinCachedSong(hash string, reason string) {
+	cacheService := GetCacheService()
+	if cacheService == nil {
+		return
+	}
+	if hash == "" {
+		cacheService.audioCache.UnpinAll(reason)
+		return
+	}
+	cacheService.audioCache.Unpin(hash, reason)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCacheFile 在缓存目录中写入指定大小的文件
+func writeCacheFile(t *testing.T, dir string, name string, size int) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cacheFileExists(dir string, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+func newTestAudioCache(t *testing.T) (*AudioCache, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cache := NewAudioCache(filepath.Join(dir, "mp3"), filepath.Join(dir, "index.json"))
+	if err := os.MkdirAll(cache.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return cache, cache.dir
+}
+
+func TestAudioCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+	cache.SetMaxSize(250)
+
+	for i, name := range []string{"a.mp3", "b.mp3", "c.mp3"} {
+		writeCacheFile(t, dir, name, 100)
+		cache.Record(name, name, "")
+		// 同一秒内写入的文件访问时间相同，显式区分先后
+		cache.mutex.Lock()
+		if entry, ok := cache.entries[name]; ok {
+			entry.LastAccess = time.Now().Add(time.Duration(i-3) * time.Hour).Unix()
+		}
+		cache.mutex.Unlock()
+	}
+	// c 写入后超出上限，最早写入的 a 被淘汰
+	if cacheFileExists(dir, "a.mp3") || !cacheFileExists(dir, "b.mp3") || !cacheFileExists(dir, "c.mp3") {
+		t.Fatalf("unexpected files after eviction: a=%v b=%v c=%v",
+			cacheFileExists(dir, "a.mp3"), cacheFileExists(dir, "b.mp3"), cacheFileExists(dir, "c.mp3"))
+	}
+
+	// 播放 b 后，下一次淘汰的是 c
+	cache.mutex.Lock()
+	cache.entries["c.mp3"].LastAccess = time.Now().Add(-time.Hour).Unix()
+	cache.mutex.Unlock()
+	cache.Touch("b.mp3")
+
+	writeCacheFile(t, dir, "d.mp3", 100)
+	cache.Record("d.mp3", "d", "")
+	if cacheFileExists(dir, "c.mp3") || !cacheFileExists(dir, "b.mp3") {
+		t.Errorf("touched file should survive: b=%v c=%v", cacheFileExists(dir, "b.mp3"), cacheFileExists(dir, "c.mp3"))
+	}
+
+	if stats := cache.Stats(); stats.TotalSize != 200 || stats.FileCount != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAudioCachePinnedSongsAreKept(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+	cache.SetMaxSize(0)
+
+	writeCacheFile(t, dir, "old.mp3", 100)
+	cache.Record("old.mp3", "old-hash", "")
+	writeCacheFile(t, dir, "new.mp3", 100)
+	cache.Record("new.mp3", "new-hash", "")
+
+	cache.mutex.Lock()
+	cache.entries["old.mp3"].LastAccess = time.Now().Add(-time.Hour).Unix()
+	cache.mutex.Unlock()
+
+	cache.Pin("old-hash", CachePinDownload)
+	cache.Pin("old-hash", CachePinFavorite)
+	cache.SetMaxSize(150)
+
+	if !cacheFileExists(dir, "old.mp3") || cacheFileExists(dir, "new.mp3") {
+		t.Fatalf("pinned file should be kept instead of newer one")
+	}
+
+	// 只取消一个原因时仍然保持固定
+	cache.Unpin("old-hash", CachePinDownload)
+	if !cache.IsPinned("old-hash") {
+		t.Error("song should stay pinned while another reason remains")
+	}
+	cache.UnpinAll(CachePinFavorite)
+	if cache.IsPinned("old-hash") {
+		t.Error("song should be unpinned")
+	}
+}
+
+func TestAudioCacheClear(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+
+	writeCacheFile(t, dir, "keep.mp3", 10)
+	cache.Record("keep.mp3", "keep", "")
+	writeCacheFile(t, dir, "drop.mp3", 20)
+	cache.Record("drop.mp3", "drop", "")
+	writeCacheFile(t, dir, "partial.mp3.tmp", 5)
+	cache.Pin("keep", CachePinFavorite)
+
+	result := cache.Clear(true, nil)
+	if result.RemovedCount != 1 || result.FreedSize != 20 {
+		t.Errorf("result = %+v", result)
+	}
+	if !cacheFileExists(dir, "keep.mp3") || cacheFileExists(dir, "drop.mp3") || cacheFileExists(dir, "partial.mp3.tmp") {
+		t.Error("Clear(true) should keep only pinned files")
+	}
+
+	if result := cache.Clear(false, nil); result.RemovedCount != 1 || cacheFileExists(dir, "keep.mp3") {
+		t.Errorf("Clear(false) = %+v", result)
+	}
+}
+
+func TestAudioCacheIndexPersistence(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+
+	writeCacheFile(t, dir, "a.mp3", 10)
+	cache.Record("a.mp3", "hash-a", "")
+	cache.Pin("hash-a", CachePinDownload)
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// 索引之外的文件在重新加载时补充，已删除的文件被移除
+	writeCacheFile(t, dir, "legacy.mp3", 30)
+	reloaded := NewAudioCache(dir, cache.indexFile)
+	if stats := reloaded.Stats(); stats.FileCount != 2 || stats.PinnedCount != 1 || stats.TotalSize != 40 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	os.Remove(filepath.Join(dir, "a.mp3"))
+	reloaded = NewAudioCache(dir, cache.indexFile)
+	if stats := reloaded.Stats(); stats.FileCount != 1 || !reloaded.IsPinned("hash-a") {
+		t.Errorf("stats = %+v, pinned = %v", stats, reloaded.IsPinned("hash-a"))
+	}
+}
+
+func TestAudioCacheConcurrentFlush(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+	writeCacheFile(t, dir, "a.mp3", 10)
+	cache.Record("a.mp3", "hash-a", "")
+
+	// 多个保存同时进行时不会互相删除临时文件
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- cache.Flush()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	}
+	if stats := NewAudioCache(dir, cache.indexFile).Stats(); stats.FileCount != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestLegacyCacheQuality(t *testing.T) {
+	c, _ := newTestCacheService(t)
+
+	// 旧版本的缓存文件名为 md5(hash).mp3，一个带ID3标签的 128kbps 文件和一个 320kbps 文件
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20}, make([]byte, 20)...)
+	low := append(id3, testMP3Data(20)...)
+	frame := make([]byte, 1044)
+	copy(frame, []byte{0xFF, 0xFB, 0xE0, 0x64})
+	medium := bytes.Repeat(frame, 10)
+	os.WriteFile(filepath.Join(c.mp3Dir, c.generateFileHash("hash-low")+".mp3"), low, 0644)
+	os.WriteFile(filepath.Join(c.mp3Dir, c.generateFileHash("hash-medium")+".mp3"), medium, 0644)
+	c.audioCache = NewAudioCache(c.mp3Dir, c.audioCache.indexFile)
+
+	tests := []struct {
+		hash    string
+		quality string
+		want    bool
+	}{
+		{"hash-low", AudioQualityLow, true},
+		{"hash-low", AudioQualityMedium, false},
+		{"hash-medium", AudioQualityMedium, true},
+		{"hash-medium", AudioQualityLow, true},
+		{"hash-medium", AudioQualityHigh, false},
+	}
+	for _, tt := range tests {
+		if _, ok := c.lookupCachedFile(tt.hash, tt.quality, false); ok != tt.want {
+			t.Errorf("lookup %s %s = %v, want %v", tt.hash, tt.quality, ok, tt.want)
+		}
+	}
+
+	// 补充的音质保存在索引中
+	if err := c.audioCache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewAudioCache(c.mp3Dir, c.audioCache.indexFile)
+	if _, ok := reloaded.Lookup("hash-medium", AudioQualityMedium, false); !ok {
+		t.Error("legacy quality should be kept in the index")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// 音质等级，与设置中的 streamingQuality / downloadQuality 对应
+const (
+	AudioQualityLow      = "low"      // 标准 128kbps
+	AudioQualityMedium   = "medium"   // 高品质 320kbps
+	AudioQualityHigh     = "high"     // 无损 FLAC
+	AudioQualityLossless = "lossless" // 下载设置中的无损，等同于 high
+)
+
+// defaultStreamingQuality 默认的在线播放音质
+const defaultStreamingQuality = AudioQualityHigh
+
+// normalizeAudioQuality 统一音质名称，未知的值使用默认音质
+func normalizeAudioQuality(quality string) string {
+	switch quality {
+	case AudioQualityLow, AudioQualityMedium, AudioQualityHigh:
+		return quality
+	case AudioQualityLossless:
+		return AudioQualityHigh
+	default:
+		return defaultStreamingQuality
+	}
+}
+
+// audioQualityRank 音质高低，旧版本缓存等未知音质为0
+func audioQualityRank(quality string) int {
+	switch quality {
+	case AudioQualityLow:
+		return 1
+	case AudioQualityMedium:
+		return 2
+	case AudioQualityHigh, AudioQualityLossless:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// mp3Bitrates MPEG-1 Layer III 帧头中码率索引对应的码率（kbps）
+var mp3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+
+// mp3FrameBitrate 查找第一个 MPEG-1 Layer III 帧并返回码率（kbps），找不到时返回0
+// MPEG-2 等低采样率的帧码率不超过 160kbps，不做区分
+func mp3FrameBitrate(data []byte) int {
+	for i := 0; i+2 < len(data); i++ {
+		// 帧同步、MPEG-1、Layer III
+		if data[i] != 0xFF || data[i+1]&0xFE != 0xFA {
+			continue
+		}
+		if bitrate := mp3Bitrates[data[i+2]>>4]; bitrate != 0 && (data[i+2]>>2)&3 != 3 {
+			return bitrate
+		}
+	}
+	return 0
+}
+
+// id3v2Size 文件开头ID3v2标签的总长度，没有标签时返回0
+func id3v2Size(head []byte) int {
+	if len(head) < 10 || !bytes.HasPrefix(head, []byte("ID3")) {
+		return 0
+	}
+	size := int(head[6]&0x7f)<<21 | int(head[7]&0x7f)<<14 | int(head[8]&0x7f)<<7 | int(head[9]&0x7f)
+	if head[5]&0x10 != 0 {
+		// 带有标签尾
+		size += 10
+	}
+	return 10 + size
+}
+
+// audioQualityParam 音质对应的 /song/url quality 参数
+func audioQualityParam(quality string) string {
+	switch normalizeAudioQuality(quality) {
+	case AudioQualityLow:
+		return "128"
+	case AudioQualityMedium:
+		return "320"
+	default:
+		return "flac"
+	}
+}
+
+// audioMimeTypes 支持的音频格式扩展名与MIME类型
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+}
+
+// audioContentTypes 远程服务器返回的Content-Type对应的扩展名
+var audioContentTypes = map[string]string{
+	"audio/mpeg":   ".mp3",
+	"audio/mp3":    ".mp3",
+	"audio/flac":   ".flac",
+	"audio/x-flac": ".flac",
+	"audio/mp4":    ".m4a",
+	"audio/x-m4a":  ".m4a",
+	"audio/aac":    ".aac",
+	"audio/ogg":    ".ogg",
+	"audio/wav":    ".wav",
+	"audio/x-wav":  ".wav",
+}
+
+// audioMimeType 获取扩展名对应的MIME类型，未知格式返回空字符串
+func audioMimeType(ext string) string {
+	return audioMimeTypes[strings.ToLower(ext)]
+}
+
+// detectAudioFormat 根据文件头判断音频格式，无法识别时依次参考Content-Type和地址扩展名，默认为mp3
+func detectAudioFormat(head []byte, contentType string, rawURL string) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return ".flac"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return ".ogg"
+	case len(head) >= 12 && bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return ".wav"
+	case len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")):
+		return ".m4a"
+	case bytes.HasPrefix(head, []byte("ID3")):
+		return ".mp3"
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xF6 == 0xF0:
+		// ADTS帧头的layer固定为0，需要在mp3帧同步之前判断
+		return ".aac"
+	case len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return ".mp3"
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if ext, ok := audioContentTypes[mediaType]; ok {
+			return ext
+		}
+	}
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if ext := strings.ToLower(path.Ext(parsed.Path)); audioMimeType(ext) != "" {
+			return ext
+		}
+	}
+	return ".mp3"
+}
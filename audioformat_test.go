@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectAudioFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		head        []byte
+		contentType string
+		url         string
+		want        string
+	}{
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), "", "", ".flac"},
+		{"id3", []byte("ID3\x04\x00"), "", "", ".mp3"},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64}, "", "", ".mp3"},
+		{"adts", []byte{0xFF, 0xF1, 0x50, 0x80}, "", "", ".aac"},
+		{"m4a", []byte("\x00\x00\x00\x20ftypM4A "), "", "", ".m4a"},
+		{"ogg", []byte("OggS\x00\x02"), "", "", ".ogg"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVE"), "", "", ".wav"},
+		{"content type", []byte("unknown"), "audio/x-flac; charset=binary", "", ".flac"},
+		{"url extension", []byte("unknown"), "application/octet-stream", "http://cdn/a/song.m4a?sign=1", ".m4a"},
+		{"default", nil, "", "http://cdn/a/song", ".mp3"},
+	}
+	for _, tt := range tests {
+		if got := detectAudioFormat(tt.head, tt.contentType, tt.url); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAudioCacheQualityLookup(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+	cache.SetMaxSize(0)
+
+	writeCacheFile(t, dir, "song_medium.mp3", 100)
+	cache.Record("song_medium.mp3", "song", AudioQualityMedium)
+
+	if file, ok := cache.Lookup("song", AudioQualityLow, false); !ok || file != "song_medium.mp3" {
+		t.Errorf("low lookup = %q, %v, want medium file", file, ok)
+	}
+	if _, ok := cache.Lookup("song", AudioQualityHigh, false); ok {
+		t.Error("medium file should not satisfy high request")
+	}
+	if file, ok := cache.Lookup("song", AudioQualityHigh, true); !ok || file != "song_medium.mp3" {
+		t.Errorf("downgrade lookup = %q, %v", file, ok)
+	}
+
+	// 缓存更高音质后删除低音质文件
+	writeCacheFile(t, dir, "song_high.flac", 300)
+	cache.Record("song_high.flac", "song", AudioQualityHigh)
+	if cacheFileExists(dir, "song_medium.mp3") {
+		t.Error("lower quality file should be removed after upgrade")
+	}
+	if file, ok := cache.Lookup("song", AudioQualityMedium, false); !ok || file != "song_high.flac" {
+		t.Errorf("medium lookup = %q, %v, want lossless file", file, ok)
+	}
+}
+
+func TestStreamAudioDetectsFormat(t *testing.T) {
+	c, local := newTestCacheService(t)
+
+	payload := append([]byte("fLaC\x00\x00\x00\x22"), testStreamPayload...)
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(payload)
+	}))
+	defer remote.Close()
+
+	resp := c.StreamAudioFile(testStreamHash, AudioQualityHigh, []string{remote.URL})
+	if !resp.Success || !strings.HasSuffix(localURLFile(resp.Data), "_high.flac") {
+		t.Fatalf("StreamAudioFile = %+v, want flac url", resp)
+	}
+
+	httpResp, err := http.Get(localTestURL(t, local, resp.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpResp.Body.Close()
+	if ct := httpResp.Header.Get("Content-Type"); ct != "audio/flac" {
+		t.Errorf("Content-Type = %q, want audio/flac", ct)
+	}
+
+	// 切换到较低音质时复用已缓存的无损文件
+	c.ApplyQualitySettings(QualitySettings{StreamingQuality: AudioQualityLow})
+	if resp := c.CacheAudioFile(testStreamHash, []string{remote.URL}); !resp.Success || !strings.HasSuffix(localURLFile(resp.Data), "_high.flac") {
+		t.Errorf("CacheAudioFile = %+v, want cached lossless file", resp)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// audioDecodeCheckBytes 校验mp3时解码的PCM数据量，约为开头的十几帧
+const audioDecodeCheckBytes = 64 * 1024
+
+// CacheVerifyReport 缓存校验的进度与结果
+type CacheVerifyReport struct {
+	Running    bool     `json:"running"`
+	StartedAt  int64    `json:"started_at"`
+	FinishedAt int64    `json:"finished_at"`
+	Checked    int      `json:"checked"`
+	Total      int      `json:"total"`
+	Corrupt    []string `json:"corrupt"` // 已隔离的损坏文件
+}
+
+// fileChecksum 计算文件内容的md5
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// checkAudioDecodable 检查文件开头是否为有效的音频数据，避免缓存错误页面等内容
+// mp3 解码开头的若干帧，其他格式检查文件头与扩展名是否一致
+func checkAudioDecodable(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".mp3" {
+		if audioMimeType(ext) == "" {
+			return nil
+		}
+		head := make([]byte, audioSniffSize)
+		n, _ := io.ReadFull(file, head)
+		// 部分FLAC等文件开头带有ID3标签，无法仅凭文件头判断
+		if bytes.HasPrefix(head[:n], []byte("ID3")) {
+			return nil
+		}
+		if detected := detectAudioFormat(head[:n], "", ""); detected != ext {
+			return fmt.Errorf("文件头与格式不符: %s", ext)
+		}
+		return nil
+	}
+
+	// 只提供 io.Reader，避免解码器为计算时长扫描整个文件
+	decoder, err := mp3.NewDecoder(struct{ io.Reader }{file})
+	if err != nil {
+		return fmt.Errorf("解码失败: %v", err)
+	}
+	if _, err := io.CopyN(io.Discard, decoder, audioDecodeCheckBytes); err != nil && err != io.EOF {
+		return fmt.Errorf("解码失败: %v", err)
+	}
+	return nil
+}
+
+// inspect 校验缓存文件，返回更新了校验信息的条目
+// 长度和修改时间与记录一致时跳过校验和计算，full 为 true 时总是完整校验
+func (a *AudioCache) inspect(entry audioCacheEntry, full bool) (audioCacheEntry, error) {
+	path := filepath.Join(a.dir, entry.File)
+	info, err := os.Stat(path)
+	if err != nil {
+		return entry, err
+	}
+	if entry.Checksum != "" && info.Size() != entry.Size {
+		return entry, fmt.Errorf("文件长度不一致: %d / %d", info.Size(), entry.Size)
+	}
+	if !full && entry.Checksum != "" && info.ModTime().UnixNano() == entry.ModTime {
+		return entry, nil
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return entry, err
+	}
+	if entry.Checksum != "" && checksum != entry.Checksum {
+		return entry, errors.New("校验和不一致")
+	}
+	// 没有校验和的旧版本缓存无法比对内容，至少确认能够解码
+	if full || entry.Checksum == "" {
+		if err := checkAudioDecodable(path); err != nil {
+			return entry, err
+		}
+	}
+
+	entry.Size = info.Size()
+	entry.Checksum = checksum
+	entry.ModTime = info.ModTime().UnixNano()
+	return entry, nil
+}
+
+// Validate 校验缓存文件是否完整，损坏的文件会被隔离
+func (a *AudioCache) Validate(file string) bool {
+	a.mutex.Lock()
+	entry, ok := a.entries[file]
+	if !ok {
+		a.mutex.Unlock()
+		return false
+	}
+	snapshot := *entry
+	a.mutex.Unlock()
+
+	updated, err := a.inspect(snapshot, false)
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.applyInspectionLocked(entry, snapshot, updated, err)
+}
+
+// Verify 完整校验所有缓存文件，返回被隔离的文件名
+func (a *AudioCache) Verify(progress func(checked int, total int)) []string {
+	a.mutex.Lock()
+	entries := make([]*audioCacheEntry, 0, len(a.entries))
+	for _, entry := range a.entries {
+		entries = append(entries, entry)
+	}
+	a.mutex.Unlock()
+
+	corrupt := []string{}
+	for i, entry := range entries {
+		a.mutex.Lock()
+		snapshot := *entry
+		a.mutex.Unlock()
+
+		updated, err := a.inspect(snapshot, true)
+
+		a.mutex.Lock()
+		if !a.applyInspectionLocked(entry, snapshot, updated, err) && err != nil && !os.IsNotExist(err) {
+			corrupt = append(corrupt, entry.File)
+		}
+		a.mutex.Unlock()
+
+		if progress != nil {
+			progress(i+1, len(entries))
+		}
+	}
+	return corrupt
+}
+
+// applyInspectionLocked 根据校验结果更新或隔离条目，调用方需持有锁
+// 校验期间条目被重新写入时放弃本次结果
+func (a *AudioCache) applyInspectionLocked(entry *audioCacheEntry, snapshot audioCacheEntry, updated audioCacheEntry, err error) bool {
+	if a.entries[entry.File] != entry || entry.Checksum != snapshot.Checksum || entry.ModTime != snapshot.ModTime {
+		return a.entries[entry.File] != nil
+	}
+
+	switch {
+	case os.IsNotExist(err):
+		delete(a.entries, entry.File)
+		a.scheduleSaveLocked()
+		return false
+	case err != nil:
+		a.quarantineLocked(entry, err)
+		return false
+	}
+
+	if updated.Checksum != entry.Checksum || updated.ModTime != entry.ModTime || updated.Size != entry.Size {
+		entry.Size = updated.Size
+		entry.Checksum = updated.Checksum
+		entry.ModTime = updated.ModTime
+		a.scheduleSaveLocked()
+	}
+	return true
+}
+
+// quarantineLocked 将损坏的文件移入隔离目录并移除索引条目，调用方需持有锁
+func (a *AudioCache) quarantineLocked(entry *audioCacheEntry, reason error) {
+	src := filepath.Join(a.dir, entry.File)
+	os.MkdirAll(a.quarantineDir, 0755)
+	if err := os.Rename(src, filepath.Join(a.quarantineDir, entry.File)); err != nil {
+		// 无法移动时直接删除，避免再次播放损坏的文件
+		os.Remove(src)
+	}
+
+	delete(a.entries, entry.File)
+	a.scheduleSaveLocked()
+	fmt.Printf("🚫 缓存文件已损坏，已隔离: %s (%v)\n", entry.File, reason)
+}
+
+// VerifyCache 在后台完整校验所有缓存文件，损坏的文件会被隔离，进度通过 GetCacheVerifyReport 查询
+func (c *CacheService) VerifyCache() CacheResponse {
+	c.verifyMutex.Lock()
+	defer c.verifyMutex.Unlock()
+
+	if c.verifyReport.Running {
+		return CacheResponse{
+			Success: false,
+			Message: "缓存校验正在进行",
+		}
+	}
+	c.verifyReport = CacheVerifyReport{
+		Running:   true,
+		StartedAt: time.Now().Unix(),
+		Corrupt:   []string{},
+	}
+
+	go func() {
+		fmt.Printf("🔍 开始校验音频缓存\n")
+		corrupt := c.audioCache.Verify(func(checked int, total int) {
+			c.verifyMutex.Lock()
+			c.verifyReport.Checked = checked
+			c.verifyReport.Total = total
+			c.verifyMutex.Unlock()
+		})
+
+		c.verifyMutex.Lock()
+		c.verifyReport.Running = false
+		c.verifyReport.FinishedAt = time.Now().Unix()
+		c.verifyReport.Corrupt = corrupt
+		c.verifyMutex.Unlock()
+		fmt.Printf("✅ 音频缓存校验完成，隔离 %d 个损坏文件\n", len(corrupt))
+	}()
+
+	return CacheResponse{
+		Success: true,
+		Message: "已开始校验缓存",
+	}
+}
+
+// GetCacheVerifyReport 获取缓存校验的进度与结果
+func (c *CacheService) GetCacheVerifyReport() ApiResponse[CacheVerifyReport] {
+	c.verifyMutex.Lock()
+	defer c.verifyMutex.Unlock()
+
+	report := c.verifyReport
+	report.Corrupt = append([]string{}, c.verifyReport.Corrupt...)
+	return ApiResponse[CacheVerifyReport]{
+		Success: true,
+		Message: "获取缓存校验结果成功",
+		Data:    report,
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testMP3Data 生成由静音帧组成的mp3数据（MPEG1 Layer III，128kbps，44.1kHz）
+func testMP3Data(frames int) []byte {
+	frame := make([]byte, 417)
+	copy(frame, []byte{0xFF, 0xFB, 0x90, 0x64})
+	return bytes.Repeat(frame, frames)
+}
+
+func TestAudioCacheQuarantinesTruncatedFile(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+
+	if err := os.WriteFile(filepath.Join(dir, "a_high.mp3"), testMP3Data(20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache.Record("a_high.mp3", "a", AudioQualityHigh)
+	if !cache.Validate("a_high.mp3") {
+		t.Fatal("intact file should be valid")
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载时发现长度与索引不一致
+	os.Truncate(filepath.Join(dir, "a_high.mp3"), 1000)
+	reloaded := NewAudioCache(dir, cache.indexFile)
+	if _, ok := reloaded.Lookup("a", AudioQualityHigh, false); ok {
+		t.Error("truncated file should be removed from index")
+	}
+	if cacheFileExists(dir, "a_high.mp3") || !cacheFileExists(reloaded.quarantineDir, "a_high.mp3") {
+		t.Error("truncated file should be moved to quarantine")
+	}
+}
+
+func TestAudioCacheValidatesLegacyFiles(t *testing.T) {
+	cache, dir := newTestAudioCache(t)
+
+	os.WriteFile(filepath.Join(dir, "good.mp3"), testMP3Data(20), 0644)
+	os.WriteFile(filepath.Join(dir, "error.mp3"), []byte("<html>403 Forbidden</html>"), 0644)
+	cache = NewAudioCache(dir, cache.indexFile)
+
+	if !cache.Validate("good.mp3") {
+		t.Error("decodable legacy file should be valid")
+	}
+	cache.mutex.Lock()
+	checksum := cache.entries["good.mp3"].Checksum
+	cache.mutex.Unlock()
+	if checksum == "" {
+		t.Error("checksum should be filled in after validation")
+	}
+
+	if cache.Validate("error.mp3") || !cacheFileExists(cache.quarantineDir, "error.mp3") {
+		t.Error("undecodable legacy file should be quarantined")
+	}
+}
+
+func TestVerifyCache(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	dir := c.mp3Dir
+
+	os.WriteFile(filepath.Join(dir, "good_high.mp3"), testMP3Data(20), 0644)
+	c.audioCache.Record("good_high.mp3", "good", AudioQualityHigh)
+	os.WriteFile(filepath.Join(dir, "bad_high.mp3"), testMP3Data(20), 0644)
+	c.audioCache.Record("bad_high.mp3", "bad", AudioQualityHigh)
+
+	// 内容被改写但长度和修改时间不变，只有完整校验能发现
+	badPath := filepath.Join(dir, "bad_high.mp3")
+	info, _ := os.Stat(badPath)
+	corrupted := testMP3Data(20)
+	corrupted[500] = 0xAA
+	os.WriteFile(badPath, corrupted, 0644)
+	os.Chtimes(badPath, info.ModTime(), info.ModTime())
+	if !c.audioCache.Validate("bad_high.mp3") {
+		t.Fatal("quick validation should trust unchanged size and mtime")
+	}
+
+	if resp := c.VerifyCache(); !resp.Success {
+		t.Fatalf("VerifyCache: %s", resp.Message)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	report := c.GetCacheVerifyReport().Data
+	for report.Running && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		report = c.GetCacheVerifyReport().Data
+	}
+
+	if report.Running || report.Checked != 2 || len(report.Corrupt) != 1 || report.Corrupt[0] != "bad_high.mp3" {
+		t.Fatalf("report = %+v", report)
+	}
+	if _, ok := c.lookupCachedFile("bad", AudioQualityHigh, false); ok {
+		t.Error("corrupt file should no longer be served")
+	}
+	if _, ok := c.lookupCachedFile("good", AudioQualityHigh, false); !ok {
+		t.Error("good file should still be cached")
+	}
+}
+
+func TestStreamAudioRejectsErrorPage(t *testing.T) {
+	c, _ := newTestCacheService(t)
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html>link expired</html>"))
+	}))
+	defer remote.Close()
+
+	if resp := c.CacheAudioFile(testStreamHash, []string{remote.URL}); resp.Success {
+		t.Errorf("error page should not be cached: %+v", resp)
+	}
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// audioDownloadStallTimeout 下载在该时间内没有任何数据到达时视为失败
+const audioDownloadStallTimeout = 30 * time.Second
+
+// audioFormatWaitTimeout 返回本地播放地址前等待识别音频格式的最长时间
+const audioFormatWaitTimeout = 10 * time.Second
+
+// audioSniffSize 识别音频格式需要的文件头长度
+const audioSniffSize = 12
+
+// audioDownload 正在进行的音频下载，同一首歌的同一音质同时只有一个下载任务
+// 下载数据先写入临时文件，本地HTTP服务可以在下载过程中读取已到达的部分
+type audioDownload struct {
+	songHash string
+	quality  string
+	key      string // 缓存key，同时作为下载任务的key
+	tempPath string // 下载中的临时文件路径
+	writer   *os.File
+
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	fileName string // 识别格式后确定的缓存文件名
+	head     []byte // 用于识别格式的文件头
+	written  int64  // 已写入临时文件的字节数
+	total    int64  // 文件总大小，未知时为 -1
+	done     bool   // 下载已结束（成功或失败）
+	err      error
+	refs     int // 下载协程与正在读取的请求数，归零后才重命名或删除临时文件
+
+	finished chan struct{} // 临时文件处理完成后关闭
+}
+
+// startAudioDownload 开始下载歌曲到缓存，已有相同歌曲的下载任务时直接复用
+// 已有满足音质要求的缓存时不会下载，返回缓存的文件名
+func (c *CacheService) startAudioDownload(songHash string, quality string, urls []string) (*audioDownload, string, error) {
+	quality = normalizeAudioQuality(quality)
+	key := c.getCacheKey(songHash, quality)
+
+	c.downloadsMutex.Lock()
+	defer c.downloadsMutex.Unlock()
+
+	if download := c.downloads[key]; download != nil {
+		fmt.Printf("🎵 复用正在进行的下载: %s\n", songHash)
+		return download, "", nil
+	}
+	if file, ok := c.lookupCachedFile(songHash, quality, false); ok {
+		return nil, file, nil
+	}
+
+	if err := c.ensureCacheDir(); err != nil {
+		return nil, "", err
+	}
+	// 每个任务使用独立的临时文件，失败的任务仍被读取时不影响新的下载
+	writer, err := os.CreateTemp(c.mp3Dir, key+".*.tmp")
+	if err != nil {
+		return nil, "", err
+	}
+
+	download := &audioDownload{
+		songHash: songHash,
+		quality:  quality,
+		key:      key,
+		tempPath: writer.Name(),
+		writer:   writer,
+		total:    -1,
+		refs:     1,
+		finished: make(chan struct{}),
+	}
+	download.cond = sync.NewCond(&download.mutex)
+	c.downloads[key] = download
+
+	go c.runAudioDownload(download, urls)
+	return download, "", nil
+}
+
+// attachAudioDownload 获取正在进行的下载任务并增加引用，使用完毕后需调用 releaseAudioDownload
+func (c *CacheService) attachAudioDownload(key string) *audioDownload {
+	c.downloadsMutex.Lock()
+	defer c.downloadsMutex.Unlock()
+
+	download := c.downloads[key]
+	if download == nil {
+		return nil
+	}
+	download.mutex.Lock()
+	download.refs++
+	download.mutex.Unlock()
+	return download
+}
+
+// activeDownloadFile 获取正在下载的歌曲的缓存文件名，格式尚未识别时返回 false
+func (c *CacheService) activeDownloadFile(songHash string, quality string) (string, bool) {
+	c.downloadsMutex.Lock()
+	download := c.downloads[c.getCacheKey(songHash, quality)]
+	c.downloadsMutex.Unlock()
+	if download == nil {
+		return "", false
+	}
+
+	download.mutex.Lock()
+	defer download.mutex.Unlock()
+	return download.fileName, download.fileName != ""
+}
+
+// runAudioDownload 依次尝试各个地址下载，中途失败时从已下载的位置继续
+func (c *CacheService) runAudioDownload(download *audioDownload, urls []string) {
+	err := errors.New("没有可用的播放地址")
+	for i, url := range urls {
+		fmt.Printf("🎵 尝试下载音频文件 (%d/%d): %s\n", i+1, len(urls), url)
+		if err = download.fetch(url); err == nil {
+			break
+		}
+		fmt.Printf("⚠️ 下载失败 (%d/%d): %v\n", i+1, len(urls), err)
+	}
+	if err != nil {
+		err = fmt.Errorf("所有URL下载失败: %v", err)
+	}
+
+	download.writer.Close()
+	download.mutex.Lock()
+	download.done = true
+	download.err = err
+	if err == nil {
+		download.total = download.written
+	}
+	download.cond.Broadcast()
+	download.mutex.Unlock()
+
+	if err != nil {
+		// 失败的任务立即移除，后续请求可以重新下载
+		c.downloadsMutex.Lock()
+		if c.downloads[download.key] == download {
+			delete(c.downloads, download.key)
+		}
+		c.downloadsMutex.Unlock()
+	}
+	c.releaseAudioDownload(download)
+}
+
+// releaseAudioDownload 释放下载任务的引用，最后一个引用释放时处理临时文件
+func (c *CacheService) releaseAudioDownload(download *audioDownload) {
+	c.downloadsMutex.Lock()
+	download.mutex.Lock()
+	download.refs--
+	last := download.refs == 0
+	fileName := download.fileName
+	download.mutex.Unlock()
+	if !last {
+		c.downloadsMutex.Unlock()
+		return
+	}
+
+	// 持有锁完成重命名，新的请求要么复用任务，要么直接读取缓存文件
+	if c.downloads[download.key] == download {
+		delete(c.downloads, download.key)
+	}
+	err := download.result()
+	if err == nil {
+		if err = os.Rename(download.tempPath, filepath.Join(c.mp3Dir, fileName)); err != nil {
+			download.mutex.Lock()
+			download.err = err
+			download.mutex.Unlock()
+		}
+	}
+	if err != nil {
+		os.Remove(download.tempPath)
+	}
+	c.downloadsMutex.Unlock()
+
+	if err == nil {
+		fmt.Printf("✅ 音频文件下载成功: %s\n", fileName)
+		c.audioCache.Record(fileName, download.songHash, download.quality)
+	} else {
+		fmt.Printf("❌ 音频文件缓存失败: %s, %v\n", download.songHash, err)
+	}
+	close(download.finished)
+}
+
+// result 获取下载结果
+func (d *audioDownload) result() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.err
+}
+
+// fetch 从单个地址下载剩余的数据
+func (d *audioDownload) fetch(url string) error {
+	// 不限制总时长，只在长时间没有数据时中断
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stall := time.AfterFunc(audioDownloadStallTimeout, cancel)
+	defer stall.Stop()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	// 设置请求头
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "audio/mpeg,audio/*,*/*")
+	req.Header.Set("Accept-Encoding", "identity")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	d.mutex.Lock()
+	offset := d.written
+	d.mutex.Unlock()
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case offset == 0 && resp.StatusCode == http.StatusOK:
+		d.mutex.Lock()
+		d.total = resp.ContentLength
+		d.mutex.Unlock()
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		// 续传时必须从已下载的位置开始
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != offset {
+			return fmt.Errorf("无法续传: %s", resp.Header.Get("Content-Range"))
+		}
+	default:
+		return fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+	}
+
+	// 地址过期时部分服务器返回错误页面，不能当作音频缓存
+	contentType := resp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/html") || strings.HasPrefix(contentType, "application/json") {
+		return fmt.Errorf("返回的不是音频数据: %s", contentType)
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			stall.Reset(audioDownloadStallTimeout)
+			if _, err := d.writer.Write(buf[:n]); err != nil {
+				return err
+			}
+			d.mutex.Lock()
+			d.written += int64(n)
+			if d.fileName == "" {
+				d.head = append(d.head, buf[:n]...)
+				if len(d.head) >= audioSniffSize {
+					d.fileName = d.key + detectAudioFormat(d.head, contentType, url)
+				}
+			}
+			d.cond.Broadcast()
+			d.mutex.Unlock()
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.total >= 0 && d.written < d.total {
+		return io.ErrUnexpectedEOF
+	}
+	if d.written == 0 {
+		return errors.New("文件为空")
+	}
+	if d.fileName == "" {
+		// 文件比格式头还短，按已有数据识别
+		d.fileName = d.key + detectAudioFormat(d.head, contentType, url)
+		d.cond.Broadcast()
+	}
+	return nil
+}
+
+// waitFormat 等待识别出音频格式，返回缓存文件名
+func (d *audioDownload) waitFormat(ctx context.Context) (string, error) {
+	stop := context.AfterFunc(ctx, func() {
+		d.mutex.Lock()
+		d.cond.Broadcast()
+		d.mutex.Unlock()
+	})
+	defer stop()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for d.fileName == "" && !d.done && ctx.Err() == nil {
+		d.cond.Wait()
+	}
+	switch {
+	case d.fileName != "":
+		return d.fileName, nil
+	case ctx.Err() != nil:
+		return "", ctx.Err()
+	default:
+		return "", d.err
+	}
+}
+
+// waitFor 等待指定位置的数据到达，返回从该位置起可读取的字节数
+func (d *audioDownload) waitFor(ctx context.Context, offset int64) (int64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for offset >= d.written && !d.done && ctx.Err() == nil {
+		d.cond.Wait()
+	}
+	switch {
+	case offset < d.written:
+		return d.written - offset, nil
+	case ctx.Err() != nil:
+		return 0, ctx.Err()
+	case d.err != nil:
+		return 0, d.err
+	default:
+		return 0, io.EOF
+	}
+}
+
+// size 等待文件总大小确定
+func (d *audioDownload) size(ctx context.Context) (int64, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for d.total < 0 && !d.done && ctx.Err() == nil {
+		d.cond.Wait()
+	}
+	switch {
+	case d.total >= 0:
+		return d.total, nil
+	case ctx.Err() != nil:
+		return 0, ctx.Err()
+	default:
+		return 0, d.err
+	}
+}
+
+// audioStreamReader 读取下载中的临时文件，数据尚未到达时阻塞等待
+type audioStreamReader struct {
+	download *audioDownload
+	file     *os.File
+	ctx      context.Context
+	offset   int64
+}
+
+func (r *audioStreamReader) Read(p []byte) (int, error) {
+	available, err := r.download.waitFor(r.ctx, r.offset)
+	if available == 0 {
+		return 0, err
+	}
+	if int64(len(p)) > available {
+		p = p[:available]
+	}
+	n, err := r.file.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *audioStreamReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		size, err := r.download.size(r.ctx)
+		if err != nil {
+			return 0, err
+		}
+		offset += size
+	default:
+		return 0, errors.New("无效的whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("无效的偏移量")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// serveAudioDownload 从下载中的临时文件响应请求，支持Range
+func (c *CacheService) serveAudioDownload(w http.ResponseWriter, r *http.Request, download *audioDownload) {
+	file, err := os.Open(download.tempPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	// 客户端断开时唤醒等待中的读取
+	stop := context.AfterFunc(r.Context(), func() {
+		download.mutex.Lock()
+		download.cond.Broadcast()
+		download.mutex.Unlock()
+	})
+	defer stop()
+
+	name := filepath.Base(r.URL.Path)
+	fmt.Printf("🎵 边下边播: %s (%s)\n", name, r.Header.Get("Range"))
+	reader := &audioStreamReader{download: download, file: file, ctx: r.Context()}
+	http.ServeContent(w, r, name, time.Time{}, reader)
+}
+
+// StreamAudioFile 开始缓存指定音质的音频文件并返回本地播放地址，下载完成前即可播放和拖动
+func (c *CacheService) StreamAudioFile(songHash string, quality string, urls []string) CacheResponse {
+	if songHash == "" {
+		return CacheResponse{
+			Success: false,
+			Message: "歌曲hash不能为空",
+		}
+	}
+
+	if len(urls) == 0 {
+		return CacheResponse{
+			Success: false,
+			Message: "播放地址列表不能为空",
+		}
+	}
+
+	if message, unavailable := c.serverUnavailable(); unavailable {
+		return CacheResponse{
+			Success: false,
+			Message: message,
+		}
+	}
+
+	download, fileName, err := c.startAudioDownload(songHash, quality, urls)
+	if err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: fmt.Sprintf("缓存失败: %v", err),
+		}
+	}
+	if download == nil {
+		return CacheResponse{
+			Success: true,
+			Message: "文件已缓存",
+			Data:    c.getLocalURL(fileName),
+		}
+	}
+
+	// 播放地址的扩展名取决于实际的音频格式，需要等到收到文件头
+	ctx, cancel := context.WithTimeout(context.Background(), audioFormatWaitTimeout)
+	defer cancel()
+	if fileName, err = download.waitFormat(ctx); err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: fmt.Sprintf("缓存失败: %v", err),
+		}
+	}
+
+	return CacheResponse{
+		Success: true,
+		Message: "正在缓存",
+		Data:    c.getLocalURL(fileName),
+	}
+}
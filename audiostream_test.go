@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testStreamHash = "STREAMHASH0123456789"
+
+var testStreamPayload = bytes.Repeat([]byte("0123456789"), 10)
+
+func newTestCacheService(t *testing.T) (*CacheService, *httptest.Server) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	c := NewCacheService()
+	if err := c.ensureCacheDir(); err != nil {
+		t.Fatal(err)
+	}
+	local := httptest.NewServer(c.newHTTPHandler())
+	t.Cleanup(local.Close)
+	// 本地URL按实际监听的地址生成，指向测试服务器
+	c.serverAddr = local.Listener.Addr().String()
+	return c, local
+}
+
+// withCacheService 测试期间把 c 设为全局缓存服务，测试结束后恢复
+// 全局实例在测试之间共享，使用它的测试不能调用 t.Parallel()
+func withCacheService(t *testing.T, c *CacheService) {
+	t.Helper()
+	previous := globalCacheService
+	globalCacheService = c
+	t.Cleanup(func() { globalCacheService = previous })
+}
+
+// newTestAppState 在缓存服务的目录中创建应用数据，测试期间设为全局实例
+func newTestAppState(t *testing.T, c *CacheService) *AppState {
+	t.Helper()
+	state := NewAppState(c.cacheDir, c.broadcastLyricsMessage)
+	withAppState(t, state)
+	return state
+}
+
+// withAppState 测试期间把 s 设为全局应用数据，测试结束后恢复，同样不能调用 t.Parallel()
+func withAppState(t *testing.T, s *AppState) {
+	t.Helper()
+	previous := globalAppState
+	globalAppState = s
+	t.Cleanup(func() { globalAppState = previous })
+}
+
+// localTestURL 把缓存服务返回的URL指向测试服务器，保留路径和令牌
+func localTestURL(t *testing.T, local *httptest.Server, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parse %q: %v", rawURL, err)
+	}
+	return local.URL + u.RequestURI()
+}
+
+// localURLFile 缓存服务返回的URL中的文件名
+func localURLFile(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+// getRange 请求本地服务，rangeHeader 为空时请求整个文件
+func getRange(t *testing.T, rawURL string, rangeHeader string) (int, []byte) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", rawURL, nil)
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Errorf("GET %s: %v", rawURL, err)
+		return 0, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body
+}
+
+func TestStreamAudioWhileDownloading(t *testing.T) {
+	c, local := newTestCacheService(t)
+
+	// 远程服务先返回前40字节，收到信号后再返回剩余部分
+	var requests atomic.Int32
+	release := make(chan struct{})
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Length", fmt.Sprint(len(testStreamPayload)))
+		w.Write(testStreamPayload[:40])
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write(testStreamPayload[40:])
+	}))
+	defer remote.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	first := c.StreamAudioFile(testStreamHash, AudioQualityHigh, []string{remote.URL})
+	second := c.StreamAudioFile(testStreamHash, AudioQualityHigh, []string{remote.URL})
+	if !first.Success || first.Data != second.Data {
+		t.Fatalf("StreamAudioFile = %+v, %+v", first, second)
+	}
+	if cached := c.GetCachedURL(testStreamHash); !cached.Success || cached.Data != first.Data {
+		t.Errorf("GetCachedURL during download = %+v", cached)
+	}
+
+	songURL := localTestURL(t, local, first.Data)
+
+	// 已到达的部分可以立即读取
+	status, body := getRange(t, songURL, "bytes=0-9")
+	if status != http.StatusPartialContent || !bytes.Equal(body, testStreamPayload[:10]) {
+		t.Fatalf("range 0-9 = %d %q", status, body)
+	}
+
+	// 尚未到达的部分需要等待
+	type result struct {
+		status int
+		body   []byte
+	}
+	tail := make(chan result, 1)
+	go func() {
+		status, body := getRange(t, songURL, "bytes=90-99")
+		tail <- result{status, body}
+	}()
+	select {
+	case r := <-tail:
+		t.Fatalf("range 90-99 returned before data arrived: %d %q", r.status, r.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	r := <-tail
+	if r.status != http.StatusPartialContent || !bytes.Equal(r.body, testStreamPayload[90:]) {
+		t.Fatalf("range 90-99 = %d %q", r.status, r.body)
+	}
+
+	// 等待下载完成，整个过程只请求一次远程地址
+	if resp := c.CacheAudioFile(testStreamHash, []string{remote.URL}); !resp.Success {
+		t.Fatalf("CacheAudioFile: %s", resp.Message)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("remote requested %d times, want 1", n)
+	}
+	if _, ok := c.activeDownloadFile(testStreamHash, AudioQualityHigh); ok {
+		t.Error("download should be finished")
+	}
+	if status, body := getRange(t, songURL, ""); status != http.StatusOK || !bytes.Equal(body, testStreamPayload) {
+		t.Errorf("cached file = %d %q", status, body)
+	}
+	if stats := c.audioCache.Stats(); stats.FileCount != 1 || stats.TotalSize != int64(len(testStreamPayload)) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestStreamAudioResumeFromBackup(t *testing.T) {
+	c, _ := newTestCacheService(t)
+
+	// 主地址中途断开
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(testStreamPayload)))
+		w.Write(testStreamPayload[:40])
+	}))
+	defer broken.Close()
+
+	var rangeHeader atomic.Value
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader.Store(r.Header.Get("Range"))
+		http.ServeContent(w, r, "song.mp3", time.Time{}, bytes.NewReader(testStreamPayload))
+	}))
+	defer backup.Close()
+
+	if resp := c.CacheAudioFile(testStreamHash, []string{broken.URL, backup.URL}); !resp.Success {
+		t.Fatalf("CacheAudioFile: %s", resp.Message)
+	}
+	if got := rangeHeader.Load(); got != "bytes=40-" {
+		t.Errorf("backup Range = %v, want bytes=40-", got)
+	}
+	fileName, _ := c.lookupCachedFile(testStreamHash, AudioQualityHigh, false)
+	data, err := os.ReadFile(filepath.Join(c.mp3Dir, fileName))
+	if err != nil || !bytes.Equal(data, testStreamPayload) {
+		t.Errorf("cached data = %q, %v", data, err)
+	}
+}
+
+func TestStreamAudioFailureCleansUp(t *testing.T) {
+	c, _ := newTestCacheService(t)
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusForbidden)
+	}))
+	defer remote.Close()
+
+	if resp := c.CacheAudioFile(testStreamHash, []string{remote.URL}); resp.Success {
+		t.Fatal("expected failure")
+	}
+	if _, ok := c.lookupCachedFile(testStreamHash, AudioQualityLow, true); ok {
+		t.Error("failed download should not be cached")
+	}
+	files, _ := os.ReadDir(c.mp3Dir)
+	if len(files) != 0 {
+		t.Errorf("leftover files: %v", files)
+	}
+}
+
+func TestClearCacheDuringDownload(t *testing.T) {
+	c, local := newTestCacheService(t)
+
+	release := make(chan struct{})
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(testStreamPayload)))
+		w.Write(testStreamPayload[:40])
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write(testStreamPayload[40:])
+	}))
+	defer remote.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	stream := c.StreamAudioFile(testStreamHash, AudioQualityHigh, []string{remote.URL})
+	if !stream.Success {
+		t.Fatalf("StreamAudioFile: %s", stream.Message)
+	}
+	songURL := localTestURL(t, local, stream.Data)
+	if status, _ := getRange(t, songURL, "bytes=0-9"); status != http.StatusPartialContent {
+		t.Fatalf("range 0-9 = %d", status)
+	}
+
+	// 中断的下载留下的临时文件会被清理，正在下载的不会
+	stale := filepath.Join(c.mp3Dir, "stale.123.tmp")
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c.ClearCache(false)
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temp file should be removed")
+	}
+
+	// 清理后仍可以读取已下载的部分，下载完成后正常缓存
+	if status, body := getRange(t, songURL, "bytes=10-19"); status != http.StatusPartialContent || !bytes.Equal(body, testStreamPayload[10:20]) {
+		t.Fatalf("range 10-19 after clear = %d %q", status, body)
+	}
+	close(release)
+	if resp := c.CacheAudioFile(testStreamHash, []string{remote.URL}); !resp.Success {
+		t.Fatalf("CacheAudioFile: %s", resp.Message)
+	}
+	fileName, ok := c.lookupCachedFile(testStreamHash, AudioQualityHigh, false)
+	if !ok {
+		t.Fatal("download should be cached after clear")
+	}
+	if data, err := os.ReadFile(filepath.Join(c.mp3Dir, fileName)); err != nil || !bytes.Equal(data, testStreamPayload) {
+		t.Errorf("cached data = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// 后端连接状态
+const (
+	BackendStateUnknown     = "unknown"     // 尚未探测
+	BackendStateOnline      = "online"      // 至少一个地址可用
+	BackendStateUnreachable = "unreachable" // 所有地址都无法连接
+	BackendStateOffline     = "offline"     // 已开启离线模式，不访问后端
+)
+
+// backendStatusEvent 后端状态变化时发送给前端的事件名
+const backendStatusEvent = "backend:status"
+
+// backendHealthTimeout 单个地址健康检查的超时时间
+const backendHealthTimeout = 3 * time.Second
+
+// backendReconnectInterval 后端不可达时重新探测的间隔
+const backendReconnectInterval = 30 * time.Second
+
+// BackendStatus 后端连接状态
+type BackendStatus struct {
+	State     string   `json:"state"`
+	ActiveURL string   `json:"active_url"`
+	Endpoints []string `json:"endpoints"`
+	Source    string   `json:"source"` // 地址来源：settings、env、cli
+	LastCheck int64    `json:"last_check"`
+	LastError string   `json:"last_error"`
+	Offline   bool     `json:"offline"` // 是否处于离线状态（手动开启或后端不可达）
+}
+
+// BackendStatusResponse 后端状态响应结构
+type BackendStatusResponse = ApiResponse[BackendStatus]
+
+// BackendManager 管理后端服务地址、健康状态与故障切换
+type BackendManager struct {
+	endpoints []string
+	active    int
+	source    string
+	state     string
+	lastCheck time.Time
+	lastError string
+	offline   bool // 设置中开启的离线模式
+	app       *application.App
+	mutex     sync.RWMutex
+}
+
+// 全局后端管理器实例
+var GlobalBackendManager = &BackendManager{
+	endpoints: []string{defaultBaseApi},
+	source:    "settings",
+	state:     BackendStateUnknown,
+}
+
+// normalizeEndpoints 去除空白、末尾斜杠与重复项，保持原有顺序
+func normalizeEndpoints(urls []string) []string {
+	seen := make(map[string]bool)
+	var endpoints []string
+	for _, raw := range urls {
+		endpoint := strings.TrimRight(strings.TrimSpace(raw), "/")
+		if endpoint == "" || seen[endpoint] {
+			continue
+		}
+		seen[endpoint] = true
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// resolveBackendEndpoints 按 命令行 > 环境变量 > 设置 的优先级确定后端地址列表
+func resolveBackendEndpoints(network NetworkSettings, options CommandLineOptions) ([]string, string) {
+	if endpoints := normalizeEndpoints(strings.Split(options.ApiURL, ",")); len(endpoints) > 0 {
+		return endpoints, "cli"
+	}
+	if endpoints := normalizeEndpoints(strings.Split(os.Getenv(baseApiEnv), ",")); len(endpoints) > 0 {
+		return endpoints, "env"
+	}
+
+	endpoints := normalizeEndpoints(append([]string{network.ApiBaseURL}, network.FallbackApiURLs...))
+	if len(endpoints) == 0 {
+		endpoints = []string{defaultBaseApi}
+	}
+	return endpoints, "settings"
+}
+
+// SetApp 设置应用实例，用于向前端发送状态事件
+func (b *BackendManager) SetApp(app *application.App) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.app = app
+}
+
+// Configure 设置后端地址列表，第一个为主地址，其余按顺序作为备用地址
+func (b *BackendManager) Configure(endpoints []string, source string) {
+	endpoints = normalizeEndpoints(endpoints)
+	if len(endpoints) == 0 {
+		endpoints = []string{defaultBaseApi}
+	}
+
+	b.mutex.Lock()
+	b.endpoints = endpoints
+	b.active = 0
+	b.source = source
+	b.state = BackendStateUnknown
+	b.lastError = ""
+	b.mutex.Unlock()
+
+	log.Printf("🌐 后端地址(%s): %s", source, strings.Join(endpoints, ", "))
+}
+
+// ApplySettings 根据设置更新后端地址与离线模式，命令行或环境变量指定地址时地址设置不生效
+func (b *BackendManager) ApplySettings(network NetworkSettings) {
+	if b.SetOffline(network.OfflineMode) && !network.OfflineMode {
+		go b.CheckHealth()
+	}
+
+	b.mutex.RLock()
+	source := b.source
+	current := strings.Join(b.endpoints, ",")
+	b.mutex.RUnlock()
+
+	if source != "settings" {
+		return
+	}
+
+	endpoints, _ := resolveBackendEndpoints(network, CommandLineOptions{})
+	if strings.Join(endpoints, ",") == current {
+		return
+	}
+	b.Configure(endpoints, "settings")
+	go b.CheckHealth()
+}
+
+// SetOffline 开启或关闭离线模式，返回状态是否发生变化
+func (b *BackendManager) SetOffline(enabled bool) bool {
+	b.mutex.Lock()
+	changed := b.offline != enabled
+	b.offline = enabled
+	b.mutex.Unlock()
+
+	if changed {
+		if enabled {
+			log.Printf("✈️ 已开启离线模式，仅播放已缓存和本地音乐")
+		} else {
+			log.Printf("🌐 已关闭离线模式")
+		}
+		b.emitStatus()
+	}
+	return changed
+}
+
+// OfflineMode 是否在设置中开启了离线模式
+func (b *BackendManager) OfflineMode() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.offline
+}
+
+// IsOffline 是否处于离线状态：手动开启离线模式或所有后端地址都无法连接
+func (b *BackendManager) IsOffline() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.offline || b.state == BackendStateUnreachable
+}
+
+// BaseURL 返回当前使用的后端地址
+func (b *BackendManager) BaseURL() string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.endpoints[b.active]
+}
+
+// candidates 返回按尝试顺序排列的地址，当前地址在前，其余保持配置顺序
+func (b *BackendManager) candidates() []string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	candidates := make([]string, 0, len(b.endpoints))
+	candidates = append(candidates, b.endpoints[b.active])
+	for i, endpoint := range b.endpoints {
+		if i != b.active {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	return candidates
+}
+
+// markOnline 记录某个地址可用，并切换为当前地址
+func (b *BackendManager) markOnline(endpoint string) {
+	b.mutex.Lock()
+	changed := b.state != BackendStateOnline || b.endpoints[b.active] != endpoint
+	for i, candidate := range b.endpoints {
+		if candidate == endpoint {
+			if i != b.active {
+				log.Printf("🔀 后端地址切换: %s -> %s", b.endpoints[b.active], endpoint)
+			}
+			b.active = i
+			break
+		}
+	}
+	b.state = BackendStateOnline
+	b.lastCheck = time.Now()
+	b.lastError = ""
+	b.mutex.Unlock()
+
+	if changed {
+		b.emitStatus()
+	}
+}
+
+// markUnreachable 记录所有地址均不可用
+func (b *BackendManager) markUnreachable(err error) {
+	b.mutex.Lock()
+	changed := b.state != BackendStateUnreachable
+	b.state = BackendStateUnreachable
+	b.lastCheck = time.Now()
+	b.lastError = err.Error()
+	b.mutex.Unlock()
+
+	if changed {
+		log.Printf("❌ 后端服务不可达: %v", err)
+		b.emitStatus()
+	}
+}
+
+// Status 返回当前后端状态
+func (b *BackendManager) Status() BackendStatus {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	status := BackendStatus{
+		State:     b.state,
+		ActiveURL: b.endpoints[b.active],
+		Endpoints: append([]string(nil), b.endpoints...),
+		Source:    b.source,
+		LastError: b.lastError,
+		Offline:   b.offline || b.state == BackendStateUnreachable,
+	}
+	if b.offline {
+		status.State = BackendStateOffline
+	}
+	if !b.lastCheck.IsZero() {
+		status.LastCheck = b.lastCheck.Unix()
+	}
+	return status
+}
+
+// emitStatus 向前端发送后端状态事件
+func (b *BackendManager) emitStatus() {
+	b.mutex.RLock()
+	app := b.app
+	b.mutex.RUnlock()
+
+	if app != nil {
+		app.Event.Emit(backendStatusEvent, b.Status())
+	}
+}
+
+// CheckHealth 按顺序探测所有地址，使用第一个可用的地址
+func (b *BackendManager) CheckHealth() BackendStatus {
+	b.mutex.RLock()
+	endpoints := append([]string(nil), b.endpoints...)
+	b.mutex.RUnlock()
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if err := probeEndpoint(endpoint); err != nil {
+			log.Printf("⚠️ 后端健康检查失败: %s, %v", endpoint, err)
+			lastErr = err
+			continue
+		}
+		log.Printf("✅ 后端健康检查通过: %s", endpoint)
+		b.markOnline(endpoint)
+		return b.Status()
+	}
+
+	b.markUnreachable(lastErr)
+	return b.Status()
+}
+
+// Monitor 启动时探测后端，之后在后端不可达期间定期重新探测，恢复连接时通知前端
+func (b *BackendManager) Monitor() {
+	if !b.OfflineMode() {
+		b.CheckHealth()
+	}
+
+	ticker := time.NewTicker(backendReconnectInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		b.mutex.RLock()
+		reconnect := !b.offline && b.state == BackendStateUnreachable
+		b.mutex.RUnlock()
+
+		if reconnect {
+			b.CheckHealth()
+		}
+	}
+}
+
+// probeEndpoint 探测单个地址，只要能收到HTTP响应即视为可用
+func probeEndpoint(endpoint string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), backendHealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/", nil)
+	if err != nil {
+		return fmt.Errorf("无效的后端地址: %v", err)
+	}
+
+	resp, err := defaultApiClient.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// BackendService 向前端暴露后端连接状态
+type BackendService struct{}
+
+// NewBackendService 创建后端状态服务实例
+func NewBackendService() *BackendService {
+	return &BackendService{}
+}
+
+// GetBackendStatus 获取后端连接状态
+func (s *BackendService) GetBackendStatus() BackendStatusResponse {
+	return BackendStatusResponse{
+		Success: true,
+		Message: "获取后端状态成功",
+		Data:    GlobalBackendManager.Status(),
+	}
+}
+
+// CheckBackend 重新探测后端服务
+func (s *BackendService) CheckBackend() BackendStatusResponse {
+	if GlobalBackendManager.OfflineMode() {
+		return BackendStatusResponse{
+			Success:   false,
+			Message:   "已开启离线模式，请在设置中关闭后重试",
+			ErrorCode: ApiErrOffline,
+			Data:      GlobalBackendManager.Status(),
+		}
+	}
+
+	status := GlobalBackendManager.CheckHealth()
+	if status.State != BackendStateOnline {
+		return BackendStatusResponse{
+			Success:   false,
+			Message:   "后端服务不可达",
+			ErrorCode: ApiErrBackendUnreachable,
+			Data:      status,
+		}
+	}
+
+	return BackendStatusResponse{
+		Success: true,
+		Message: "后端服务可用",
+		Data:    status,
+	}
+}
@@ -14,16 +14,40 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"wmplayer/internal/lyrics"
 )
 
 // CacheService 音频缓存服务（包含OSD歌词功能）
 type CacheService struct {
-	server        *http.Server
-	cacheDir      string
-	mp3Dir        string
-	serverPort    string
-	localMusicMap map[string]string // 本地音乐hash到文件路径的映射
-	localMapFile  string            // 本地音乐映射文件路径
+	server     *http.Server
+	cacheDir   string
+	mp3Dir     string
+	serverPort string      // 首选端口，被占用时改用空闲端口
+	audioCache *AudioCache // 缓存索引与容量管理
+	// 本地音乐hash到文件路径的映射和映射文件，修改映射和写盘时持有写锁，保证按顺序写入
+	localMusicMap      map[string]string
+	localMapFile       string
+	localMusicMapMutex sync.RWMutex
+	// 本地HTTP服务本次运行的访问令牌和监听地址，默认只监听回环地址
+	// serverAddr 为实际监听的地址，所有本地URL都由它生成，服务未启动时为空
+	serverToken string
+	listenHost  string
+	serverAddr  string
+	serverErr   error
+	serverMutex sync.Mutex
+	// 正在进行的音频下载，key为缓存文件名
+	downloads      map[string]*audioDownload
+	downloadsMutex sync.Mutex
+	// 在线播放音质，来自设置
+	streamingQuality string
+	qualityMutex     sync.RWMutex
+	// 最近一次缓存校验的结果
+	verifyReport CacheVerifyReport
+	verifyMutex  sync.Mutex
+	// 本地音乐的歌词文件夹和在线歌词开关，来自设置
+	localMusicSettings LocalMusicSettings
+	localMusicMutex    sync.RWMutex
 	// OSD歌词相关字段
 	osdClients sync.Map // 使用 sync.Map 管理客户端: *http.Request -> chan LyricsMessage
 	// OSD歌词进程管理
@@ -50,7 +74,17 @@ type LyricsMessage struct {
 	Text     string `json:"text"` // 原始文本或KRC JSON数据
 	SongName string `json:"songName"`
 	Artist   string `json:"artist"`
-	Format   string `json:"format,omitempty"` // 歌词格式：lrc, krc
+	Format   string `json:"format,omitempty"` // 歌词格式：lrc, krc, plain
+	// Line 解析后的歌词行，包含行时间和KRC的逐字时间，客户端无需再解析Text
+	Line *lyrics.Line `json:"line,omitempty"`
+	// 当前行的翻译和音译，按设置中桌面歌词显示的层填写
+	Translation  string `json:"translation,omitempty"`
+	Romanization string `json:"romanization,omitempty"`
+	// 以下字段用于歌词时钟推送的 lyrics_line、lyrics_word 和 playback_status 消息
+	Index     int   `json:"index"`     // 当前行序号，-1表示歌词尚未开始
+	WordIndex int   `json:"wordIndex"` // 当前字在行内的序号，-1表示没有逐字时间或尚未开始
+	Position  int64 `json:"position"`  // 播放位置，毫秒
+	Playing   bool  `json:"playing"`
 }
 
 // NewCacheService 创建新的缓存服务实例
@@ -59,13 +93,22 @@ func NewCacheService() *CacheService {
 	cacheDir := filepath.Join(homeDir, ".cache", "gomusic")
 	mp3Dir := filepath.Join(cacheDir, "cache", "mp3")
 	localMapFile := filepath.Join(cacheDir, "cache", "local_music_map.json")
+	indexFile := filepath.Join(cacheDir, "cache", "audio_cache_index.json")
 
 	service := &CacheService{
-		cacheDir:      cacheDir,
-		mp3Dir:        mp3Dir,
-		serverPort:    "18911", // 本地HTTP服务端口
-		localMusicMap: make(map[string]string),
-		localMapFile:  localMapFile,
+		cacheDir:         cacheDir,
+		mp3Dir:           mp3Dir,
+		serverPort:       "18911", // 本地HTTP服务的首选端口
+		serverToken:      newServerToken(),
+		listenHost:       localServerHost,
+		localMusicMap:    make(map[string]string),
+		localMapFile:     localMapFile,
+		audioCache:       NewAudioCache(mp3Dir, indexFile),
+		downloads:        make(map[string]*audioDownload),
+		streamingQuality: defaultStreamingQuality,
+		localMusicSettings: LocalMusicSettings{
+			OnlineLyrics: true,
+		},
 		// osdClients 使用 sync.Map，无需初始化
 	}
 
@@ -92,64 +135,81 @@ func (c *CacheService) StartHTTPServerWithOSDLyrics() error {
 		c.StopHTTPServer()
 	}
 
-	// 创建自定义的文件服务器处理器，添加调试信息
-	fileServer := http.FileServer(http.Dir(c.cacheDir))
-
-	// 包装文件服务器，添加日志和错误处理
-	wrappedHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		fmt.Printf("🎵 HTTP请求: %s %s\n", r.Method, r.URL.Path)
-
-		// 检查文件是否存在
-		filePath := filepath.Join(c.cacheDir, r.URL.Path)
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			fmt.Printf("❌ 文件不存在: %s\n", filePath)
-			http.NotFound(w, r)
-			return
-		}
+	// 同步监听端口，启动失败直接返回错误，不会把无法访问的URL交给前端
+	c.serverMutex.Lock()
+	host := c.listenHost
+	c.serverMutex.Unlock()
+	listener, err := listenLocalServer(host, c.serverPort)
+	if err != nil {
+		c.serverMutex.Lock()
+		c.serverErr = err
+		c.serverMutex.Unlock()
+		return err
+	}
 
-		// 设置CORS头
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Range")
+	server := &http.Server{Handler: c.newHTTPHandler()}
+	c.server = server
+	c.serverMutex.Lock()
+	c.serverAddr = listener.Addr().String()
+	c.serverErr = nil
+	c.serverMutex.Unlock()
+	if err := c.writeServerInfo(); err != nil {
+		fmt.Printf("⚠️ 保存本地服务信息失败: %v\n", err)
+	}
 
-		// 处理OPTIONS请求
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	fmt.Printf("🎵 本地HTTP缓存服务器启动在 %s\n", listener.Addr())
+	fmt.Printf("🎵 缓存根目录: %s\n", c.cacheDir)
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("❌ HTTP服务器异常退出: %v\n", err)
 		}
+	}()
+	return nil
+}
 
-		fmt.Printf("✅ 提供文件: %s\n", filePath)
-		fileServer.ServeHTTP(w, r)
-	})
-
-	// 设置路由
+// newHTTPHandler 创建本地HTTP服务的路由
+// 只提供音频缓存、封面缓存和OSD歌词SSE，缓存目录中的其它文件不对外提供
+// 音频和封面需要令牌，只读的歌词SSE允许本机不带令牌连接
+func (c *CacheService) newHTTPHandler() http.Handler {
 	mux := http.NewServeMux()
-	mux.Handle("/", wrappedHandler)
-
-	// 如果提供了OSD歌词服务，添加SSE端点
-	mux.HandleFunc("/api/osd-lyrics/sse", func(w http.ResponseWriter, r *http.Request) {
-		c.handleOSDLyricsSSE(w, r)
-	})
+	mux.Handle("/cache/mp3/", c.secured(c.handleAudioFile, false))
+	mux.Handle("/cache/covers/", c.secured(func(w http.ResponseWriter, r *http.Request) {
+		serveCacheFile(w, r, filepath.Join(c.cacheDir, "cache", "covers"), strings.TrimPrefix(r.URL.Path, "/cache/covers/"))
+	}, false))
+	mux.Handle("/api/osd-lyrics/sse", c.secured(c.handleOSDLyricsSSE, true))
 	fmt.Printf("✅ OSD歌词SSE端点已注册: /api/osd-lyrics/sse\n")
 
-	// 创建HTTP服务器
-	c.server = &http.Server{
-		Addr:    ":" + c.serverPort,
-		Handler: mux,
+	return mux
+}
+
+// handleAudioFile 提供缓存的音频文件，正在下载的音频边下边播
+func (c *CacheService) handleAudioFile(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("🎵 HTTP请求: %s %s\n", r.Method, r.URL.Path)
+	fileName := strings.TrimPrefix(r.URL.Path, "/cache/mp3/")
+	if _, ok := cacheFilePath(c.mp3Dir, fileName); !ok {
+		fmt.Printf("🚫 非法的文件路径: %q\n", fileName)
+		http.NotFound(w, r)
+		return
 	}
 
-	// 在goroutine中启动服务器
-	go func() {
-		fmt.Printf("🎵 本地HTTP缓存服务器启动在端口 %s\n", c.serverPort)
-		fmt.Printf("🎵 缓存根目录: %s\n", c.cacheDir)
-		if err := c.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("❌ HTTP服务器启动失败: %v\n", err)
-		}
-	}()
+	// 按实际格式返回MIME类型，避免FLAC等格式被识别为未知类型
+	if mimeType := audioMimeType(filepath.Ext(fileName)); mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
 
-	// 等待一下确保服务器启动
-	time.Sleep(100 * time.Millisecond)
-	return nil
+	// 正在下载的音频直接从临时文件边下边播
+	key := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if download := c.attachAudioDownload(key); download != nil {
+		defer c.releaseAudioDownload(download)
+		c.serveAudioDownload(w, r, download)
+		return
+	}
+
+	// 记录音频文件的访问时间，用于LRU淘汰
+	if _, err := os.Stat(filepath.Join(c.mp3Dir, fileName)); err == nil {
+		c.audioCache.Touch(fileName)
+	}
+	serveCacheFile(w, r, c.mp3Dir, fileName)
 }
 
 // StopHTTPServer 停止HTTP服务器
@@ -170,11 +230,22 @@ func (c *CacheService) StopHTTPServer() error {
 			c.server.Close()
 		}
 		c.server = nil
+		c.serverMutex.Lock()
+		c.serverAddr = ""
+		c.serverMutex.Unlock()
+		c.removeServerInfo()
 		return err
 	}
 	return nil
 }
 
+// flushStores 保存延迟写盘的缓存索引，退出前调用，与HTTP服务器是否启动无关
+func (c *CacheService) flushStores() {
+	if err := c.audioCache.Flush(); err != nil {
+		log.Printf("⚠️ 保存缓存索引失败: %v", err)
+	}
+}
+
 // ensureCacheDir 确保缓存目录存在
 func (c *CacheService) ensureCacheDir() error {
 	// 创建主缓存目录
@@ -199,111 +270,51 @@ func (c *CacheService) generateFileHash(songHash string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// getCachedFilePath 获取缓存文件路径
-func (c *CacheService) getCachedFilePath(songHash string) string {
-	fileHash := c.generateFileHash(songHash)
-	return filepath.Join(c.mp3Dir, fileHash+".mp3")
-}
-
-// isCached 检查文件是否已缓存
-func (c *CacheService) isCached(songHash string) bool {
-	// 需要判断一下
-	filePath := c.getCachedFilePath(songHash)
-	_, err := os.Stat(filePath)
-	return err == nil
+// getCacheKey 获取缓存key，同一首歌的不同音质分别缓存，文件名为key加上实际格式的扩展名
+func (c *CacheService) getCacheKey(songHash string, quality string) string {
+	return c.generateFileHash(songHash) + "_" + normalizeAudioQuality(quality)
 }
 
-// getLocalURL 获取本地缓存文件的URL
-func (c *CacheService) getLocalURL(songHash string) string {
-	fileHash := c.generateFileHash(songHash)
-	return fmt.Sprintf("http://127.0.0.1:%s/cache/mp3/%s.mp3", c.serverPort, fileHash)
-}
-
-// downloadAndCache 下载并缓存音频文件
-func (c *CacheService) downloadAndCache(songHash string, urls []string) (string, error) {
-	// 检查是否已缓存
-	if c.isCached(songHash) {
-		fmt.Printf("✅ 文件已缓存: %s\n", songHash)
-		return c.getLocalURL(songHash), nil
+// lookupCachedFile 查找满足音质要求的缓存文件名，高音质的缓存可以满足低音质的请求
+// allowLower 为 true 时没有满足要求的缓存也会返回较低音质的文件
+func (c *CacheService) lookupCachedFile(songHash string, quality string, allowLower bool) (string, bool) {
+	// 旧版本的缓存文件名为 md5(hash).mp3，没有记录hash和音质，第一次查找时补充
+	legacyFile := c.generateFileHash(songHash) + ".mp3"
+	if _, err := os.Stat(filepath.Join(c.mp3Dir, legacyFile)); err == nil {
+		c.audioCache.AssignHash(legacyFile, songHash)
 	}
-
-	// 确保缓存目录存在
-	if err := c.ensureCacheDir(); err != nil {
-		return "", err
-	}
-
-	filePath := c.getCachedFilePath(songHash)
-
-	// 尝试从多个URL下载
-	for i, url := range urls {
-		if url == "" {
-			continue
-		}
-
-		fmt.Printf("🎵 尝试下载音频文件 (%d/%d): %s\n", i+1, len(urls), url)
-
-		if err := c.downloadFile(url, filePath); err != nil {
-			fmt.Printf("⚠️ 下载失败 (%d/%d): %v\n", i+1, len(urls), err)
-			continue
+	// 校验失败的文件会被隔离，继续查找其他音质的缓存
+	for {
+		fileName, ok := c.audioCache.Lookup(songHash, normalizeAudioQuality(quality), allowLower)
+		if !ok || c.audioCache.Validate(fileName) {
+			return fileName, ok
 		}
-
-		// 下载成功
-		fmt.Printf("✅ 音频文件下载成功: %s\n", filePath)
-		return c.getLocalURL(songHash), nil
 	}
-
-	return "", fmt.Errorf("所有URL下载失败")
 }
 
-// downloadFile 下载单个文件
-func (c *CacheService) downloadFile(url, filePath string) error {
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// 创建请求
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-
-	// 设置请求头
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "audio/mpeg,audio/*,*/*")
-	req.Header.Set("Accept-Encoding", "identity")
-	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
-	req.Header.Set("Cache-Control", "no-cache")
+// getLocalURL 获取缓存文件的本地URL
+func (c *CacheService) getLocalURL(fileName string) string {
+	return c.cacheFileURL("mp3", fileName)
+}
 
-	// 发送请求
-	resp, err := client.Do(req)
+// downloadAndCache 下载并缓存音频文件，同一首歌同时只会下载一次
+func (c *CacheService) downloadAndCache(songHash string, quality string, urls []string) (string, error) {
+	download, fileName, err := c.startAudioDownload(songHash, quality, urls)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP状态码: %d", resp.StatusCode)
+		return "", err
 	}
-
-	// 创建临时文件
-	tempFile := filePath + ".tmp"
-	out, err := os.Create(tempFile)
-	if err != nil {
-		return err
+	if download == nil {
+		fmt.Printf("✅ 文件已缓存: %s\n", songHash)
+		return c.getLocalURL(fileName), nil
 	}
-	defer out.Close()
 
-	// 复制数据
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		os.Remove(tempFile)
-		return err
+	// 等待下载完成
+	<-download.finished
+	if err := download.result(); err != nil {
+		return "", err
 	}
-
-	// 重命名临时文件为最终文件
-	return os.Rename(tempFile, filePath)
+	fileName, _ = download.waitFormat(context.Background())
+	return c.getLocalURL(fileName), nil
 }
 
 // CacheAudioFile 缓存音频文件（供前端调用）
@@ -322,6 +333,13 @@ func (c *CacheService) CacheAudioFile(songHash string, urls []string) CacheRespo
 		}
 	}
 
+	if message, unavailable := c.serverUnavailable(); unavailable {
+		return CacheResponse{
+			Success: false,
+			Message: message,
+		}
+	}
+
 	// 过滤空URL
 	validUrls := make([]string, 0, len(urls))
 	for _, url := range urls {
@@ -338,7 +356,7 @@ func (c *CacheService) CacheAudioFile(songHash string, urls []string) CacheRespo
 	}
 
 	// 下载并缓存
-	localURL, err := c.downloadAndCache(songHash, validUrls)
+	localURL, err := c.downloadAndCache(songHash, c.StreamingQuality(), validUrls)
 	if err != nil {
 		return CacheResponse{
 			Success: false,
@@ -362,17 +380,34 @@ func (c *CacheService) GetCachedURL(songHash string) CacheResponse {
 		}
 	}
 
+	if message, unavailable := c.serverUnavailable(); unavailable {
+		return CacheResponse{
+			Success: false,
+			Message: message,
+		}
+	}
+
 	// 检查是否是本地音乐hash（以"local-"开头）
 	if strings.HasPrefix(songHash, "local-") {
 		return c.getLocalMusicURL(songHash)
 	}
 
-	// 在线音乐的缓存检查
-	if c.isCached(songHash) {
+	// 在线音乐的缓存检查，需要满足当前的音质设置
+	quality := c.StreamingQuality()
+	if fileName, ok := c.lookupCachedFile(songHash, quality, false); ok {
 		return CacheResponse{
 			Success: true,
 			Message: "文件已缓存",
-			Data:    c.getLocalURL(songHash),
+			Data:    c.getLocalURL(fileName),
+		}
+	}
+
+	// 正在下载的歌曲可以边下边播
+	if fileName, ok := c.activeDownloadFile(songHash, quality); ok {
+		return CacheResponse{
+			Success: true,
+			Message: "文件正在缓存",
+			Data:    c.getLocalURL(fileName),
 		}
 	}
 
@@ -382,6 +417,89 @@ func (c *CacheService) GetCachedURL(songHash string) CacheResponse {
 	}
 }
 
+// ApplyCacheSettings 应用缓存设置
+func (c *CacheService) ApplyCacheSettings(settings CacheSettings) {
+	c.audioCache.SetMaxSize(int64(settings.MaxSizeMB) << 20)
+}
+
+// ApplyQualitySettings 应用音质设置，之后的播放按新的音质查找和缓存
+func (c *CacheService) ApplyQualitySettings(settings QualitySettings) {
+	c.qualityMutex.Lock()
+	c.streamingQuality = normalizeAudioQuality(settings.StreamingQuality)
+	c.qualityMutex.Unlock()
+}
+
+// StreamingQuality 获取当前的在线播放音质
+func (c *CacheService) StreamingQuality() string {
+	c.qualityMutex.RLock()
+	defer c.qualityMutex.RUnlock()
+	return c.streamingQuality
+}
+
+// GetCacheStats 获取缓存统计信息（供设置页面调用）
+func (c *CacheService) GetCacheStats() ApiResponse[CacheStats] {
+	return ApiResponse[CacheStats]{
+		Success: true,
+		Message: "获取缓存统计成功",
+		Data:    c.audioCache.Stats(),
+	}
+}
+
+// PruneCache 按容量上限淘汰最久未播放的缓存文件
+func (c *CacheService) PruneCache() ApiResponse[CachePruneResult] {
+	result := c.audioCache.Prune()
+	return ApiResponse[CachePruneResult]{
+		Success: true,
+		Message: fmt.Sprintf("已清理 %d 个缓存文件", result.RemovedCount),
+		Data:    result,
+	}
+}
+
+// ClearCache 清空音频缓存，keepPinned 为 true 时保留已下载和我喜欢的歌曲
+func (c *CacheService) ClearCache(keepPinned bool) ApiResponse[CachePruneResult] {
+	// 持有下载锁，清理期间不会开始新的下载或重命名临时文件
+	c.downloadsMutex.Lock()
+	activeTemps := make(map[string]bool, len(c.downloads))
+	for _, download := range c.downloads {
+		activeTemps[filepath.Base(download.tempPath)] = true
+	}
+	result := c.audioCache.Clear(keepPinned, activeTemps)
+	c.downloadsMutex.Unlock()
+
+	if err := c.audioCache.Flush(); err != nil {
+		fmt.Printf("⚠️ 保存缓存索引失败: %v\n", err)
+	}
+	return ApiResponse[CachePruneResult]{
+		Success: true,
+		Message: fmt.Sprintf("已删除 %d 个缓存文件", result.RemovedCount),
+		Data:    result,
+	}
+}
+
+// SetCachePinned 固定或取消固定歌曲缓存，固定的歌曲不会被自动淘汰
+func (c *CacheService) SetCachePinned(songHash string, pinned bool) CacheResponse {
+	if songHash == "" {
+		return CacheResponse{
+			Success: false,
+			Message: "歌曲hash不能为空",
+		}
+	}
+
+	if pinned {
+		c.audioCache.Pin(songHash, CachePinFavorite)
+		return CacheResponse{
+			Success: true,
+			Message: "已固定缓存",
+		}
+	}
+
+	c.audioCache.Unpin(songHash, CachePinFavorite)
+	return CacheResponse{
+		Success: true,
+		Message: "已取消固定缓存",
+	}
+}
+
 // RegisterLocalMusic 注册本地音乐hash到文件路径的映射（供前端调用）
 func (c *CacheService) RegisterLocalMusic(localHash, filePath string) CacheResponse {
 	if localHash == "" || filePath == "" {
@@ -391,6 +509,7 @@ func (c *CacheService) RegisterLocalMusic(localHash, filePath string) CacheRespo
 		}
 	}
 
+	c.localMusicMapMutex.Lock()
 	if c.localMusicMap == nil {
 		c.localMusicMap = make(map[string]string)
 	}
@@ -399,9 +518,10 @@ func (c *CacheService) RegisterLocalMusic(localHash, filePath string) CacheRespo
 	fmt.Printf("🎵 注册本地音乐映射: %s -> %s\n", localHash, filePath)
 
 	// 保存映射到文件
-	if err := c.saveLocalMusicMap(); err != nil {
+	if err := c.saveLocalMusicMapLocked(); err != nil {
 		fmt.Printf("⚠️ 保存本地音乐映射失败: %v\n", err)
 	}
+	c.localMusicMapMutex.Unlock()
 
 	return CacheResponse{
 		Success: true,
@@ -412,7 +532,7 @@ func (c *CacheService) RegisterLocalMusic(localHash, filePath string) CacheRespo
 // getLocalMusicURL 获取本地音乐的缓存URL
 func (c *CacheService) getLocalMusicURL(localHash string) CacheResponse {
 	// 从映射中查找文件路径
-	filePath, exists := c.localMusicMap[localHash]
+	filePath, exists := c.localMusicPath(localHash)
 	if !exists {
 		return CacheResponse{
 			Success: false,
@@ -454,10 +574,11 @@ func (c *CacheService) getLocalMusicURL(localHash string) CacheResponse {
 			}
 		}
 		fmt.Printf("✅ 本地音乐文件已缓存: %s -> %s\n", filePath, cachedFilePath)
+		c.audioCache.Record(cachedFileName, localHash, "")
 	}
 
 	// 生成本地HTTP URL
-	localURL := fmt.Sprintf("http://127.0.0.1:%s/cache/mp3/%s", c.serverPort, cachedFileName)
+	localURL := c.getLocalURL(cachedFileName)
 
 	return CacheResponse{
 		Success: true,
@@ -536,6 +657,8 @@ func (c *CacheService) loadLocalMusicMap() {
 		return
 	}
 
+	c.localMusicMapMutex.Lock()
+	defer c.localMusicMapMutex.Unlock()
 	if c.localMusicMap == nil {
 		c.localMusicMap = make(map[string]string)
 	}
@@ -555,14 +678,29 @@ func (c *CacheService) loadLocalMusicMap() {
 
 	// 如果有无效映射被清理，保存更新后的映射
 	if validCount != len(loadedMap) {
-		if err := c.saveLocalMusicMap(); err != nil {
+		if err := c.saveLocalMusicMapLocked(); err != nil {
 			fmt.Printf("⚠️ 保存清理后的本地音乐映射失败: %v\n", err)
 		}
 	}
 }
 
+// localMusicPath 查找本地音乐hash对应的文件路径
+func (c *CacheService) localMusicPath(hash string) (string, bool) {
+	c.localMusicMapMutex.RLock()
+	defer c.localMusicMapMutex.RUnlock()
+	filePath, ok := c.localMusicMap[hash]
+	return filePath, ok
+}
+
 // saveLocalMusicMap 保存本地音乐映射到文件
 func (c *CacheService) saveLocalMusicMap() error {
+	c.localMusicMapMutex.Lock()
+	defer c.localMusicMapMutex.Unlock()
+	return c.saveLocalMusicMapLocked()
+}
+
+// saveLocalMusicMapLocked 保存本地音乐映射到文件，调用方需持有 localMusicMapMutex
+func (c *CacheService) saveLocalMusicMapLocked() error {
 	// 确保缓存目录存在
 	if err := c.ensureCacheDir(); err != nil {
 		return fmt.Errorf("创建缓存目录失败: %v", err)
@@ -589,7 +727,6 @@ func (c *CacheService) handleOSDLyricsSSE(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// 创建消息通道
 	msgChan := make(chan LyricsMessage, 10)
@@ -601,6 +738,11 @@ func (c *CacheService) handleOSDLyricsSSE(w http.ResponseWriter, r *http.Request
 	fmt.Fprintf(w, "data: %s\n\n", `{"type":"connected","message":"OSD歌词SSE连接成功"}`)
 	w.(http.Flusher).Flush()
 
+	// 立即发送当前歌词行，不必等到下一行开始
+	if state := GetAppState(); state != nil {
+		msgChan <- state.lyricsClock.CurrentMessage()
+	}
+
 	// 监听客户端断开连接
 	ctx := r.Context()
 	defer func() {
@@ -614,7 +756,6 @@ func (c *CacheService) handleOSDLyricsSSE(w http.ResponseWriter, r *http.Request
 			return
 		case message := <-msgChan:
 			data, _ := json.Marshal(message)
-			fmt.Printf("📤 [OSD歌词] 发送消息到客户端: %s\n", string(data))
 			fmt.Fprintf(w, "data: %s\n\n", data)
 			w.(http.Flusher).Flush()
 		case <-time.After(30 * time.Second):
@@ -635,33 +776,40 @@ func (c *CacheService) handleOSDLyricsSSE(w http.ResponseWriter, r *http.Request
 
 // UpdateCurrentLyrics 更新当前显示的歌词行
 func (c *CacheService) UpdateCurrentLyrics(lyricsText string, songName string, artist string) OSDLyricsResponse {
-	// 检测原始歌词格式
-	format := "lrc" // 默认格式
-	if strings.HasPrefix(lyricsText, "[") && strings.Contains(lyricsText, ",") && strings.Contains(lyricsText, "]<") {
-		// KRC格式特征：[数字,数字]<数字,数字,数字>
-		format = "krc"
-		fmt.Printf("🎵 [OSD歌词] 收到原始KRC歌词: %s - %s\n", songName, artist)
-	} else if strings.HasPrefix(lyricsText, "[") && strings.Contains(lyricsText, ":") && strings.Contains(lyricsText, "]") {
-		// LRC格式特征：[mm:ss.xx]
-		format = "lrc"
-		fmt.Printf("🎵 [OSD歌词] 收到原始LRC歌词: %s - %s\n", songName, artist)
-	} else {
-		fmt.Printf("🎵 [OSD歌词] 收到纯文本歌词: %s - %s: %s\n", songName, artist, lyricsText)
-	}
+	// 在后端解析歌词行，OSD直接使用结构化的时间轴
+	timeline := lyrics.Parse(lyricsText)
+	fmt.Printf("🎵 [OSD歌词] 收到%s歌词: %s - %s\n", strings.ToUpper(timeline.Format), songName, artist)
 
-	// 广播原始歌词消息
 	message := LyricsMessage{
 		Type:     "lyrics_update",
-		Text:     lyricsText, // 直接发送原始歌词文本
+		Text:     lyricsText, // 保留原始歌词文本，兼容旧版OSD
 		SongName: songName,
 		Artist:   artist,
-		Format:   format,
+		Format:   timeline.Format,
+	}
+	if len(timeline.Lines) > 0 {
+		message.Line = &timeline.Lines[0]
 	}
 
 	c.broadcastLyricsMessage(message)
 	return OSDLyricsResponse{Success: true, Message: "OSD歌词更新成功"}
 }
 
+// LoadLyrics 切换歌曲时加载歌词，之后由后端的歌词时钟按播放进度推送歌词行
+// hash 和 filePath（本地音乐）用于应用用户保存的歌词偏移
+// 播放状态和位置通过 MediaKeyService 的 UpdatePlaybackStatus 和 UpdatePlayerPosition 上报
+func (c *CacheService) LoadLyrics(hash string, filePath string, lyricsText string, songName string, artist string) OSDLyricsResponse {
+	state, err := currentAppState()
+	if err != nil {
+		return OSDLyricsResponse{Success: false, Message: err.Error()}
+	}
+	timeline := lyrics.Parse(lyricsText)
+	key := lyricsOffsetKey(hash, c.resolveLyricsFilePath(hash, filePath))
+	state.lyricsClock.Load(key, timeline, state.lyricsOffsets.Get(key), songName, artist)
+	fmt.Printf("🎵 [OSD歌词] 加载%s歌词: %s - %s，共 %d 行\n", strings.ToUpper(timeline.Format), songName, artist, len(timeline.Lines))
+	return OSDLyricsResponse{Success: true, Message: "歌词已加载"}
+}
+
 // SetEnabled 设置OSD歌词开关状态
 func (cs *CacheService) SetEnabled(enabled bool) CacheResponse {
 	log.Printf("🎵 设置OSD歌词状态: %v", enabled)
@@ -716,7 +864,7 @@ func (cs *CacheService) startOSDLyricsProcess() error {
 		}
 	}
 
-	// 启动OSD歌词程序（使用默认SSE URL）
+	// 启动OSD歌词程序，SSE地址带访问令牌，通过环境变量传给OSD
 	cs.osdProcess = exec.Command(osdPath)
 
 	// 继承当前进程的环境变量（包括DISPLAY等）
@@ -739,10 +887,11 @@ func (cs *CacheService) startOSDLyricsProcess() error {
 
 	// 强制使用X11后端，确保窗口管理器功能正常
 	env = append(env, "GDK_BACKEND=x11")
+	env = append(env, "WMPLAYER_SSE_URL="+cs.osdLyricsSSEURL())
 
 	cs.osdProcess.Env = env
 
-	log.Printf("🎵 启动OSD歌词程序: %s (将自动连接到默认SSE端点)", osdPath)
+	log.Printf("🎵 启动OSD歌词程序: %s (SSE端点通过 WMPLAYER_SSE_URL 传递)", osdPath)
 
 	if err := cs.osdProcess.Start(); err != nil {
 		cs.osdProcess = nil
@@ -808,14 +957,12 @@ func (cs *CacheService) IsEnabled() bool {
 }
 
 // broadcastLyricsMessage 向所有连接的客户端广播歌词消息
+// 歌词时钟每个字都会广播一次，这里只在发送失败时输出日志
 func (c *CacheService) broadcastLyricsMessage(message LyricsMessage) {
-	clientCount := 0
-
 	// 遍历 sync.Map 中的所有客户端
 	c.osdClients.Range(func(key, value interface{}) bool {
 		req := key.(*http.Request)
 		msgChan := value.(chan LyricsMessage)
-		clientCount++
 
 		select {
 		case msgChan <- message:
@@ -826,12 +973,6 @@ func (c *CacheService) broadcastLyricsMessage(message LyricsMessage) {
 		}
 		return true // 继续遍历
 	})
-
-	if clientCount == 0 {
-		fmt.Printf("📡 [OSD歌词] 无客户端连接，跳过广播\n")
-	} else {
-		fmt.Printf("📡 [OSD歌词] 广播到 %d 个客户端\n", clientCount)
-	}
 }
 
 // addOSDClient 添加OSD歌词SSE客户端
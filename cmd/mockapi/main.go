@@ -0,0 +1,28 @@
+// mockapi 启动一个使用内置数据的模拟音乐API服务，用于在没有真实后端时开发前端
+//
+//	go run ./cmd/mockapi -addr 127.0.0.1:40000
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"wmplayer/internal/mockapi"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:40000", "监听地址")
+	flag.Parse()
+
+	handler := mockapi.NewHandler()
+	logged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("🌐 %s %s", r.Method, r.URL.RequestURI())
+		handler.ServeHTTP(w, r)
+	})
+
+	log.Printf("✅ 模拟API服务已启动: http://%s (内置数据 %d 个)", *addr, len(mockapi.Fixtures()))
+	if err := http.ListenAndServe(*addr, logged); err != nil {
+		log.Fatalf("❌ 模拟API服务启动失败: %v", err)
+	}
+}
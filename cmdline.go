@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"io"
+)
+
+// CommandLineOptions 命令行参数
+type CommandLineOptions struct {
+	ApiURL string   // 后端服务地址，多个地址用逗号分隔
+	Toggle bool     // 切换播放/暂停，通常用于转发给已运行的实例
+	Files  []string // 要打开播放的音乐文件
+
+	// 控制运行中实例的参数，只发送命令，不启动界面
+	PlayPause bool     // 播放/暂停
+	Next      bool     // 下一首
+	Prev      bool     // 上一首
+	Volume    int      // 设置音量 0-100，未设置时为 -1
+	Status    bool     // 输出播放状态
+	JSON      bool     // 以JSON格式输出播放状态
+	Enqueue   []string // 添加到播放列表末尾的音乐文件
+}
+
+// parseCommandLine 解析命令行参数，无法识别的参数会被忽略，位置参数作为要打开的文件
+func parseCommandLine(args []string) CommandLineOptions {
+	options := CommandLineOptions{Volume: -1}
+
+	flags := flag.NewFlagSet("wmplayer", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&options.ApiURL, "api-url", "", "后端服务地址，多个地址用逗号分隔")
+	flags.BoolVar(&options.Toggle, "toggle", false, "切换播放/暂停")
+	flags.BoolVar(&options.PlayPause, "play-pause", false, "运行中的实例播放/暂停")
+	flags.BoolVar(&options.Next, "next", false, "运行中的实例播放下一首")
+	flags.BoolVar(&options.Prev, "prev", false, "运行中的实例播放上一首")
+	flags.IntVar(&options.Volume, "volume", -1, "设置运行中实例的音量 0-100")
+	flags.BoolVar(&options.Status, "status", false, "输出运行中实例的播放状态")
+	flags.BoolVar(&options.JSON, "json", false, "以JSON格式输出播放状态")
+	flags.Func("enqueue", "把音乐文件添加到运行中实例的播放列表，可以重复使用", func(file string) error {
+		options.Enqueue = append(options.Enqueue, file)
+		return nil
+	})
+
+	// 跳过无法解析的参数继续解析，避免桌面环境传入的参数导致启动失败
+	// 解析停在位置参数上时，把它作为文件记下再继续
+	for len(args) > 0 {
+		err := flags.Parse(args)
+		remaining := flags.Args()
+		switch {
+		case err != nil && len(remaining) == len(args):
+			// ---x、-= 等格式错误的参数不会被消耗，需要手动跳过
+			remaining = remaining[1:]
+		case err == nil && len(remaining) > 0:
+			if remaining[0] != "" {
+				options.Files = append(options.Files, remaining[0])
+			}
+			remaining = remaining[1:]
+		}
+		args = remaining
+	}
+
+	return options
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandLineFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		toggle bool
+		files  []string
+	}{
+		{"flags and files", []string{"--api-url", "http://a", "--unknown", "a.flac", "--toggle", "b.mp3"}, true, []string{"a.flac", "b.mp3"}},
+		{"bad flag syntax", []string{"---x", "a.flac", "-=", "--toggle"}, true, []string{"a.flac"}},
+		{"only bad flags", []string{"-=", "---x"}, false, nil},
+		{"empty argument", []string{"", "a.flac"}, false, []string{"a.flac"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := parseCommandLine(tt.args)
+			if options.Toggle != tt.toggle || !reflect.DeepEqual(options.Files, tt.files) {
+				t.Errorf("options = %+v", options)
+			}
+		})
+	}
+}
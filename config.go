@@ -1,4 +1,7 @@
 package main
 
-// baseApi 后端服务的地址
-var baseApi = "http://127.0.0.1:40000"
+// defaultBaseApi 默认的后端服务地址
+const defaultBaseApi = "http://127.0.0.1:40000"
+
+// baseApiEnv 覆盖后端服务地址的环境变量，多个地址用逗号分隔，第一个为主地址
+const baseApiEnv = "WMPLAYER_API_URL"
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestGetNewSongs(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&DiscoverService{}).GetNewSongs()
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	song := resp.Data[0]
+	if song.TimeLength != 215 || song.AlbumID != "1000001" || song.UnionCover == "" {
+		t.Errorf("song = %+v", song)
+	}
+}
+
+func TestGetNewAlbumsByCategory(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&DiscoverService{}).GetNewAlbumsByCategory()
+	if !resp.Success {
+		t.Fatalf("GetNewAlbumsByCategory failed: %s", resp.Message)
+	}
+	chn := resp.Data["chn"]
+	if len(chn) != 1 || chn[0].ReleaseDate != "2024-06-01" || chn[0].SongCount != 10 {
+		t.Errorf("chn = %+v", chn)
+	}
+	if len(resp.Data["eur"]) != 1 || len(resp.Data["jpn"]) != 0 {
+		t.Errorf("data = %+v", resp.Data)
+	}
+}
+
+func TestGetNewAlbums(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&DiscoverService{}).GetNewAlbums()
+	if !resp.Success || len(resp.Data) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	// 按 chn、eur、jpn、kor 的顺序合并
+	if resp.Data[0].ID != "1000001" || resp.Data[1].ID != "1000002" {
+		t.Errorf("order = %s, %s", resp.Data[0].ID, resp.Data[1].ID)
+	}
+}
+
+func TestGetRecommendSongs(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := (&DiscoverService{}).GetRecommendSongs("unknown")
+	if !resp.Success || len(resp.Data) != 1 || resp.Data[0].AlbumID != "960399" {
+		t.Fatalf("resp = %+v", resp)
+	}
+
+	// 未知分类使用默认的 card_id
+	req, _ := srv.LastRequest("/top/card")
+	if req.Query.Get("card_id") != "1" {
+		t.Errorf("card_id = %q, want 1", req.Query.Get("card_id"))
+	}
+}
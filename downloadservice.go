@@ -191,6 +191,7 @@ func (d *DownloadService) AddDownloadRecord(request AddDownloadRecordRequest) Do
 	}
 
 	fmt.Printf("✅ 添加下载记录: %s\n", request.SongName)
+	pinCachedSong(request.Hash, CachePinDownload)
 	return DownloadRecordsResponse{
 		Success: true,
 		Message: "下载记录添加成功",
@@ -271,6 +272,7 @@ func (d *DownloadService) DeleteDownloadRecord(request DeleteDownloadRecordReque
 			}
 
 			fmt.Printf("✅ 删除下载记录: %s\n", record.SongName)
+			unpinCachedSong(record.Hash, CachePinDownload)
 			return DownloadRecordsResponse{
 				Success: true,
 				Message: "下载记录删除成功",
@@ -301,6 +303,7 @@ func (d *DownloadService) ClearDownloadRecords() DownloadRecordsResponse {
 	}
 
 	fmt.Println("✅ 清空下载记录成功")
+	unpinCachedSong("", CachePinDownload)
 	return DownloadRecordsResponse{
 		Success: true,
 		Message: "下载记录清空成功",
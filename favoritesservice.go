@@ -3,11 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -69,230 +70,205 @@ type AddFavoriteResponse struct {
 	Data      string `json:"data"`
 }
 
-// readCookieFromFile 从全局Cookie管理器读取cookie
-func (f *FavoritesService) readCookieFromFile() (string, error) {
-	cookie := GlobalCookieManager.GetCookie()
-	return cookie, nil
+// favoriteSongItem 歌单歌曲接口 data.info 数组的元素
+type favoriteSongItem struct {
+	Hash       apiString     `json:"hash"`
+	Name       apiString     `json:"name"`
+	Timelen    apiInt        `json:"timelen"`
+	AlbumInfo  apiAlbumInfo  `json:"albuminfo"`
+	AlbumID    apiString     `json:"album_id"`
+	SingerInfo apiSingers    `json:"singerinfo"`
+	Cover      apiString     `json:"cover"`
+	TransParam apiTransParam `json:"trans_param"`
+	Mixsongid  apiInt        `json:"mixsongid"`
 }
 
-// GetFavoritesSongs 获取我喜欢的歌曲
-func (f *FavoritesService) GetFavoritesSongs(page int, pageSize int) FavoritesSongResponse {
-	log.Printf("开始获取我喜欢的歌曲，页码: %d, 页大小: %d", page, pageSize)
+// favoriteSongsData 歌单歌曲接口 data 字段的结构
+type favoriteSongsData struct {
+	Info     *apiList[favoriteSongItem] `json:"info"`
+	SongList apiList[struct {
+		FileName apiString `json:"filename"`
+	}] `json:"song_list"`
+}
 
-	// 设置默认值
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
+// userPlaylistItem /user/playlist 接口 data.info 数组的元素
+type userPlaylistItem struct {
+	GlobalCollectionID apiString `json:"global_collection_id"`
+	ListID             apiInt    `json:"listid"`
+	Name               apiString `json:"name"`
+	Intro              apiString `json:"intro"`
+	Pic                apiString `json:"pic"`
+	Count              apiInt    `json:"count"`
+	Type               apiInt    `json:"type"`
+	CreateTime         apiInt    `json:"create_time"`
+	UpdateTime         apiInt    `json:"update_time"`
+	CreateUserPic      apiString `json:"create_user_pic"`
+	CreateUsername     apiString `json:"list_create_username"`
+}
 
-	// 读取cookie
-	cookie, err := f.readCookieFromFile()
-	if err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
+// localFavoritesData 我喜欢的歌曲的本地副本，离线时使用
+type localFavoritesData struct {
+	Songs      []FavoritesSongData `json:"songs"`
+	UpdateTime time.Time           `json:"update_time"`
+}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/playlist/track/all/new", baseApi)
+// localFavoritesMutex 保护本地副本文件的读写
+var localFavoritesMutex sync.Mutex
 
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("listid", "2") // 我喜欢的歌单ID固定为2
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
+// getLocalFavoritesFilePath 获取本地副本文件路径
+func (f *FavoritesService) getLocalFavoritesFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
 
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
+	cacheDir := filepath.Join(homeDir, ".cache", "gomusic")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("创建缓存目录失败: %v", err)
+	}
 
-	log.Printf("调用我喜欢的歌曲API: %s", requestURL)
+	return filepath.Join(cacheDir, "favorites_songs.json"), nil
+}
 
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+// loadLocalFavorites 加载本地副本，文件不存在时返回空列表
+func (f *FavoritesService) loadLocalFavorites() (*localFavoritesData, error) {
+	filePath, err := f.getLocalFavoritesFilePath()
+	if err != nil {
+		return nil, err
 	}
 
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &localFavoritesData{Songs: []FavoritesSongData{}}, nil
+	}
 	if err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("请求失败: %v", err),
-		}
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+	var favorites localFavoritesData
+	if err := json.Unmarshal(data, &favorites); err != nil {
+		return nil, err
 	}
+	return &favorites, nil
+}
 
-	log.Printf("我喜欢的歌曲API响应状态码: %d", resp.StatusCode)
+// saveLocalFavorites 用在线获取的一页歌曲更新本地副本
+// 不足一页说明已是最后一页，之后的旧记录一并删除
+func (f *FavoritesService) saveLocalFavorites(page int, pageSize int, songs []FavoritesSongData) error {
+	localFavoritesMutex.Lock()
+	defer localFavoritesMutex.Unlock()
 
-	// 打印响应内容用于调试
-	bodyStr := string(body)
-	if len(bodyStr) > 1000 {
-		log.Printf("我喜欢的歌曲API响应前1000字符: %s", bodyStr[:1000])
-	} else {
-		log.Printf("我喜欢的歌曲API响应: %s", bodyStr)
+	favorites, err := f.loadLocalFavorites()
+	if err != nil {
+		favorites = &localFavoritesData{}
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	start := (page - 1) * pageSize
+	if start > len(favorites.Songs) {
+		// 前面的页尚未保存，无法确定位置
+		return nil
 	}
 
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return FavoritesSongResponse{
-			Success: false,
-			Message: errorMsg,
-		}
+	merged := append([]FavoritesSongData{}, favorites.Songs[:start]...)
+	merged = append(merged, songs...)
+	if len(songs) == pageSize && start+pageSize < len(favorites.Songs) {
+		merged = append(merged, favorites.Songs[start+pageSize:]...)
 	}
+	favorites.Songs = merged
+	favorites.UpdateTime = time.Now()
 
-	// 获取data对象
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
-		}
+	filePath, err := f.getLocalFavoritesFilePath()
+	if err != nil {
+		return err
 	}
-
-	dataMap, ok := dataInterface.(map[string]interface{})
-	if !ok {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: "data字段格式错误",
-		}
+	data, err := json.MarshalIndent(favorites, "", "  ")
+	if err != nil {
+		return err
 	}
+	return os.WriteFile(filePath, data, 0644)
+}
 
-	// 获取info数组和song_list数组
-	infoInterface, infoExists := dataMap["info"]
-	songListInterface, songListExists := dataMap["song_list"]
-
-	if !infoExists {
+// localFavoritesSongs 从本地副本获取一页我喜欢的歌曲
+func (f *FavoritesService) localFavoritesSongs(page int, pageSize int) FavoritesSongResponse {
+	localFavoritesMutex.Lock()
+	favorites, err := f.loadLocalFavorites()
+	localFavoritesMutex.Unlock()
+	if err != nil {
 		return FavoritesSongResponse{
-			Success: false,
-			Message: "响应中缺少info字段",
+			Success:   false,
+			Message:   fmt.Sprintf("读取本地我喜欢的歌曲失败: %v", err),
+			ErrorCode: ApiErrOffline,
 		}
 	}
 
-	infoArray, ok := infoInterface.([]interface{})
-	if !ok {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: "info字段格式错误",
-		}
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(favorites.Songs) {
+		start = len(favorites.Songs)
 	}
-
-	// song_list字段可能不存在，如果不存在就使用空数组
-	var songListArray []interface{}
-	if songListExists {
-		songListArray, ok = songListInterface.([]interface{})
-		if !ok {
-			log.Printf("song_list字段格式错误，将使用空数组")
-			songListArray = []interface{}{}
-		}
+	if end > len(favorites.Songs) {
+		end = len(favorites.Songs)
 	}
 
-	// 转换为前端需要的格式
-	var favoritesSongsList []FavoritesSongData
-
-	// 根据用户提供的数据对应关系进行映射
-	// hash: $.data.info[0].hash
-	// songname: $.data.info[0].name
-	// filename: $.data.song_list[0].filename
-	// timelength: $.data.info[0].timelen/1000
-	// albumname: $.data.info[0].albuminfo.name
-	// album_id: $.data.info[0].album_id
-	// author_name: $.data.info[0].singerinfo[0].name
-	// union_cover: $.data.info[0].cover
-
-	// 遍历info数组，每个元素代表一首歌曲
-	for i, infoItem := range infoArray {
-		infoMap, ok := infoItem.(map[string]interface{})
-		if !ok {
-			log.Printf("跳过无效的info[%d]项", i)
-			continue
-		}
-
-		song := FavoritesSongData{}
-
-		// hash: $.data.info[i].hash
-		if hash, ok := infoMap["hash"].(string); ok {
-			song.Hash = hash
-		}
-
-		// songname: $.data.info[i].name (修正后的映射)
-		// 需要处理：去掉艺术家名称、扩展名和"- "分隔符
-		if name, ok := infoMap["name"].(string); ok {
-			song.SongName = f.processSongName(name)
-		}
+	log.Printf("离线模式，使用本地保存的我喜欢的歌曲，共%d首", len(favorites.Songs))
+	return FavoritesSongResponse{
+		Success: true,
+		Message: "离线模式：显示本地保存的我喜欢的歌曲",
+		Data:    append([]FavoritesSongData{}, favorites.Songs[start:end]...),
+	}
+}
 
-		// filename: $.data.song_list[i].filename
-		if i < len(songListArray) {
-			if songItem, ok := songListArray[i].(map[string]interface{}); ok {
-				if filename, ok := songItem["filename"].(string); ok {
-					song.FileName = filename
-				}
-			}
-		}
+// GetFavoritesSongs 获取我喜欢的歌曲
+// 离线或后端不可达时返回上次在线获取时保存的本地副本
+func (f *FavoritesService) GetFavoritesSongs(page int, pageSize int) FavoritesSongResponse {
+	log.Printf("开始获取我喜欢的歌曲，页码: %d, 页大小: %d", page, pageSize)
 
-		// timelength: $.data.info[i].timelen/1000
-		if timelen, ok := infoMap["timelen"].(float64); ok {
-			song.TimeLength = int(timelen / 1000)
-		} else if timelenInt, ok := infoMap["timelen"].(int); ok {
-			song.TimeLength = timelenInt / 1000
-		}
+	// 设置默认值
+	page, pageSize = normalizePaging(page, pageSize)
 
-		// albumname: $.data.info[i].albuminfo.name
-		if albumInfo, ok := infoMap["albuminfo"].(map[string]interface{}); ok {
-			if albumName, ok := albumInfo["name"].(string); ok {
-				song.AlbumName = albumName
-			}
-		}
+	if GlobalBackendManager.IsOffline() {
+		return f.localFavoritesSongs(page, pageSize)
+	}
 
-		// album_id: $.data.info[i].album_id
-		if albumID, ok := infoMap["album_id"].(string); ok {
-			song.AlbumID = albumID
-		} else if albumIDFloat, ok := infoMap["album_id"].(float64); ok {
-			song.AlbumID = fmt.Sprintf("%.0f", albumIDFloat)
+	data, err := apiGetData[favoriteSongsData](apiRequest{
+		Path: "/playlist/track/all/new",
+		Params: url.Values{
+			"listid":   {"2"}, // 我喜欢的歌单ID固定为2
+			"page":     {fmt.Sprintf("%d", page)},
+			"pagesize": {fmt.Sprintf("%d", pageSize)},
+		},
+		Cookie: true,
+	}, "API请求失败")
+	if err != nil {
+		if isOfflineError(err) {
+			return f.localFavoritesSongs(page, pageSize)
 		}
+		return apiFailure[[]FavoritesSongData](err)
+	}
 
-		// author_name: $.data.info[i].singerinfo[0].name
-		if singerInfo, ok := infoMap["singerinfo"].([]interface{}); ok && len(singerInfo) > 0 {
-			if singer, ok := singerInfo[0].(map[string]interface{}); ok {
-				if singerName, ok := singer["name"].(string); ok {
-					song.AuthorName = singerName
-				}
-			}
+	if data.Info == nil {
+		return FavoritesSongResponse{
+			Success: false,
+			Message: "响应中缺少info字段",
 		}
+	}
 
-		// union_cover: $.data.info[i].cover
-		if cover, ok := infoMap["cover"].(string); ok {
-			song.UnionCover = cover
+	// 转换为前端需要的格式
+	// filename 取自 song_list 中相同下标的元素，其余字段取自 info
+	var favoritesSongsList []FavoritesSongData
+	for i, item := range *data.Info {
+		song := FavoritesSongData{
+			Hash:       item.Hash.String(),
+			SongName:   f.processSongName(item.Name.String()),
+			TimeLength: item.Timelen.Int() / 1000, // 毫秒转秒
+			AlbumName:  item.AlbumInfo.Name.String(),
+			AlbumID:    item.AlbumID.String(),
+			AuthorName: item.SingerInfo.FirstName(),
+			UnionCover: item.Cover.String(),
+			Mixsongid:  item.Mixsongid.Int(),
 		}
-
-		// mixsongid: $.data.info[i].mixsongid
-		if mixsongid, ok := infoMap["mixsongid"].(float64); ok {
-			song.Mixsongid = int(mixsongid)
+		if i < len(data.SongList) {
+			song.FileName = data.SongList[i].FileName.String()
 		}
 
 		favoritesSongsList = append(favoritesSongsList, song)
@@ -300,6 +276,25 @@ func (f *FavoritesService) GetFavoritesSongs(page int, pageSize int) FavoritesSo
 
 	log.Printf("成功获取我喜欢的歌曲，共%d首", len(favoritesSongsList))
 
+	// 我喜欢的歌曲不参与缓存淘汰
+	for _, song := range favoritesSongsList {
+		pinCachedSong(song.Hash, CachePinFavorite)
+		recordSongMetadata(SongMetadata{
+			Hash:       song.Hash,
+			SongName:   song.SongName,
+			FileName:   song.FileName,
+			AuthorName: song.AuthorName,
+			AlbumName:  song.AlbumName,
+			AlbumID:    song.AlbumID,
+			TimeLength: song.TimeLength,
+			UnionCover: song.UnionCover,
+		})
+	}
+
+	if err := f.saveLocalFavorites(page, pageSize, favoritesSongsList); err != nil {
+		log.Printf("保存我喜欢的歌曲本地副本失败: %v", err)
+	}
+
 	return FavoritesSongResponse{
 		Success:   true,
 		Message:   "获取我喜欢的歌曲成功",
@@ -328,85 +323,30 @@ func (f *FavoritesService) AddFavorite(request AddFavoriteRequest) AddFavoriteRe
 		}
 	}
 
-	// 读取cookie
-	cookie, err := f.readCookieFromFile()
-	if err != nil {
-		return AddFavoriteResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/playlist/tracks/add", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("listid", "2") // 我喜欢的歌单ID固定为2
-	queryParams.Add("data", fmt.Sprintf("%s|%s", request.SongName, request.Hash))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用添加收藏API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return AddFavoriteResponse{
-			Success: false,
-			Message: fmt.Sprintf("请求失败: %v", err),
-		}
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/playlist/tracks/add",
+		Params: url.Values{
+			"listid": {"2"}, // 我喜欢的歌单ID固定为2
+			"data":   {fmt.Sprintf("%s|%s", request.SongName, request.Hash)},
+		},
+		Cookie: true,
+	})
+	if err == nil && !envelope.statusIs(1) {
+		err = envelope.failure("添加收藏失败")
 	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		log.Printf("添加收藏失败: %v", err)
+		apiErr := asApiError(err)
 		return AddFavoriteResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	log.Printf("添加收藏API响应状态码: %d", resp.StatusCode)
-
-	// 打印响应内容用于调试
-	bodyStr := string(body)
-	if len(bodyStr) > 500 {
-		log.Printf("添加收藏API响应前500字符: %s", bodyStr[:500])
-	} else {
-		log.Printf("添加收藏API响应: %s", bodyStr)
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return AddFavoriteResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "添加收藏失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AddFavoriteResponse{
-			Success: false,
-			Message: errorMsg,
+			Success:   false,
+			Message:   apiErr.Message,
+			ErrorCode: apiErr.Code,
+			Status:    apiErr.Status,
 		}
 	}
 
 	log.Printf("成功添加收藏歌曲: %s", request.SongName)
+	pinCachedSong(request.Hash, CachePinFavorite)
 
 	return AddFavoriteResponse{
 		Success:   true,
@@ -439,186 +379,40 @@ type PlaylistResponse = ApiResponse[[]PlaylistData]
 func (f *FavoritesService) GetUserPlaylists() PlaylistResponse {
 	log.Println("开始获取用户歌单...")
 
-	// 读取cookie
-	cookie, err := f.readCookieFromFile()
-	if err != nil {
-		return PlaylistResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/user/playlist", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("cookie", cookie)
-	queryParams.Add("pagesize", "100")
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用用户歌单API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return PlaylistResponse{
-			Success: false,
-			Message: fmt.Sprintf("请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := apiGetData[struct {
+		Info *apiList[userPlaylistItem] `json:"info"`
+	}](apiRequest{
+		Path:   "/user/playlist",
+		Params: url.Values{"pagesize": {"100"}},
+		Cookie: true,
+	}, "API请求失败")
 	if err != nil {
-		return PlaylistResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	log.Printf("用户歌单API响应状态码: %d", resp.StatusCode)
-
-	// 打印响应内容用于调试
-	bodyStr := string(body)
-	if len(bodyStr) > 1000 {
-		log.Printf("用户歌单API响应前1000字符: %s", bodyStr[:1000])
-	} else {
-		log.Printf("用户歌单API响应: %s", bodyStr)
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return PlaylistResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "API请求失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return PlaylistResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 获取data对象
-	dataInterface, ok := apiResponse["data"]
-	if !ok {
-		return PlaylistResponse{
-			Success: false,
-			Message: "响应中缺少data字段",
-		}
-	}
-
-	dataMap, ok := dataInterface.(map[string]interface{})
-	if !ok {
-		return PlaylistResponse{
-			Success: false,
-			Message: "data字段格式错误",
-		}
+		return apiFailure[[]PlaylistData](err)
 	}
 
-	// 获取info数组
-	infoInterface, infoExists := dataMap["info"]
-	if !infoExists {
+	if data.Info == nil {
 		return PlaylistResponse{
 			Success: false,
 			Message: "响应中缺少info字段",
 		}
 	}
 
-	infoArray, ok := infoInterface.([]interface{})
-	if !ok {
-		return PlaylistResponse{
-			Success: false,
-			Message: "info字段格式错误",
-		}
-	}
-
-	// 转换为前端需要的格式
+	// 转换为前端需要的格式，每个元素代表一个歌单
 	var playlistsList []PlaylistData
-
-	// 遍历info数组，每个元素代表一个歌单
-	for i, infoItem := range infoArray {
-		infoMap, ok := infoItem.(map[string]interface{})
-		if !ok {
-			log.Printf("跳过无效的info[%d]项", i)
-			continue
-		}
-
-		playlist := PlaylistData{}
-
-		// global_collection_id
-		if globalCollectionID, ok := infoMap["global_collection_id"].(string); ok {
-			playlist.GlobalCollectionID = globalCollectionID
-		}
-
-		// listid
-		if listID, ok := infoMap["listid"].(float64); ok {
-			playlist.ListID = int(listID)
-		}
-
-		// name - 歌单名称
-		if name, ok := infoMap["name"].(string); ok {
-			playlist.Name = name
-		}
-
-		// intro - 歌单说明
-		if intro, ok := infoMap["intro"].(string); ok {
-			playlist.Intro = intro
-		}
-
-		// pic - 封面
-		if pic, ok := infoMap["pic"].(string); ok {
-			playlist.UnionCover = pic
-		}
-
-		// count - 歌曲总数
-		if count, ok := infoMap["count"].(float64); ok {
-			playlist.Count = int(count)
-		}
-
-		// type - 歌单类型，0-我创建的，1-我收藏的
-		if playlistType, ok := infoMap["type"].(float64); ok {
-			playlist.Type = int(playlistType)
-		}
-
-		// create_time - 创建时间
-		if createTime, ok := infoMap["create_time"].(float64); ok {
-			playlist.CreateTime = int64(createTime)
-		}
-
-		// update_time - 更新时间
-		if updateTime, ok := infoMap["update_time"].(float64); ok {
-			playlist.UpdateTime = int64(updateTime)
-		}
-
-		// create_user_pic - 创建者头像
-		if createUserPic, ok := infoMap["create_user_pic"].(string); ok {
-			playlist.CreateUserPic = createUserPic
-		}
-
-		// list_create_username - 创建者用户名
-		if createUsername, ok := infoMap["list_create_username"].(string); ok {
-			playlist.CreateUsername = createUsername
-		}
-
-		playlistsList = append(playlistsList, playlist)
+	for _, item := range *data.Info {
+		playlistsList = append(playlistsList, PlaylistData{
+			GlobalCollectionID: item.GlobalCollectionID.String(),
+			ListID:             item.ListID.Int(),
+			Name:               item.Name.String(),
+			Intro:              item.Intro.String(),
+			UnionCover:         item.Pic.String(),
+			Count:              item.Count.Int(),
+			Type:               item.Type.Int(),
+			CreateTime:         int64(item.CreateTime),
+			UpdateTime:         int64(item.UpdateTime),
+			CreateUserPic:      item.CreateUserPic.String(),
+			CreateUsername:     item.CreateUsername.String(),
+		})
 	}
 
 	log.Printf("成功获取用户歌单，共%d个", len(playlistsList))
@@ -643,178 +437,57 @@ func (f *FavoritesService) GetPlaylistSongs(globalCollectionID string) Favorites
 		}
 	}
 
-	// 读取cookie
-	cookie, err := f.readCookieFromFile()
-	if err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/playlist/track/all", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("id", globalCollectionID)
-	queryParams.Add("pagesize", "200")
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用歌单歌曲API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/playlist/track/all",
+		Params: url.Values{
+			"id":       {globalCollectionID},
+			"pagesize": {"200"},
+		},
+		Cookie: true,
+	})
 	if err != nil {
 		log.Printf("歌单歌曲API请求失败: %v", err)
-		return FavoritesSongResponse{
-			Success: false,
-			Message: "API请求失败",
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	log.Printf("歌单歌曲API响应状态码: %d", resp.StatusCode)
-
-	// 打印响应内容用于调试
-	bodyStr := string(body)
-	if len(bodyStr) > 1000 {
-		log.Printf("歌单歌曲API响应前1000字符: %s", bodyStr[:1000])
-	} else {
-		log.Printf("歌单歌曲API响应: %s", bodyStr)
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return FavoritesSongResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+		return apiFailure[[]FavoritesSongData](err)
 	}
 
-	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); ok && status != 1 {
-		message := "获取歌单歌曲失败"
-		if msg, ok := apiResponse["error"].(string); ok {
-			message = msg
-		}
-		return FavoritesSongResponse{
-			Success: false,
-			Message: message,
-		}
+	// 该接口缺少status字段时也视为成功
+	if envelope.Status != nil && !envelope.statusIs(1) {
+		return apiFailure[[]FavoritesSongData](envelope.failure("获取歌单歌曲失败"))
 	}
 
-	// 提取data字段
-	data, ok := apiResponse["data"].(map[string]interface{})
-	if !ok {
+	data, err := decodeApiData[favoriteSongsData](envelope)
+	if err != nil {
 		return FavoritesSongResponse{
 			Success: false,
 			Message: "响应数据格式错误",
 		}
 	}
-
-	// 提取info字段（歌曲列表）
-	info, ok := data["info"].([]interface{})
-	if !ok {
+	if data.Info == nil {
 		return FavoritesSongResponse{
 			Success: false,
 			Message: "歌曲列表数据格式错误",
 		}
 	}
 
-	// 解析歌曲数据 - 使用与GetFavoritesSongs相同的逻辑
+	// 解析歌曲数据
 	var songs []FavoritesSongData
-	for i, songItem := range info {
-		songMap, ok := songItem.(map[string]interface{})
-		if !ok {
-			log.Printf("跳过无效的歌曲项[%d]", i)
+	for _, item := range *data.Info {
+		// 只添加有效的歌曲（至少有hash）
+		if item.Hash == "" {
 			continue
 		}
 
-		song := FavoritesSongData{}
-
-		// hash
-		if hash, ok := songMap["hash"].(string); ok {
-			song.Hash = hash
-		}
-
-		// songname - 从name字段获取，并处理格式
-		if name, ok := songMap["name"].(string); ok {
-			song.SongName = f.processSongName(name)
-		}
-
-		// filename - 从trans_param中获取
-		if transParam, ok := songMap["trans_param"].(map[string]interface{}); ok {
-			// 尝试从不同的字段获取filename
-			if filename, ok := transParam["filename"].(string); ok {
-				song.FileName = filename
-			}
-		}
-
-		// author_name - 从singerinfo获取
-		if singerInfo, ok := songMap["singerinfo"].([]interface{}); ok && len(singerInfo) > 0 {
-			if singer, ok := singerInfo[0].(map[string]interface{}); ok {
-				if singerName, ok := singer["name"].(string); ok {
-					song.AuthorName = singerName
-				}
-			}
-		}
-
-		// album_name - 从albuminfo获取
-		if albumInfo, ok := songMap["albuminfo"].(map[string]interface{}); ok {
-			if albumName, ok := albumInfo["name"].(string); ok {
-				song.AlbumName = albumName
-			}
-		}
-
-		// album_id
-		if albumID, ok := songMap["album_id"].(string); ok {
-			song.AlbumID = albumID
-		} else if albumIDFloat, ok := songMap["album_id"].(float64); ok {
-			song.AlbumID = fmt.Sprintf("%.0f", albumIDFloat)
-		}
-
-		// time_length - 从timelen获取，转换为秒
-		if timelen, ok := songMap["timelen"].(float64); ok {
-			song.TimeLength = int(timelen / 1000)
-		} else if timelenInt, ok := songMap["timelen"].(int); ok {
-			song.TimeLength = timelenInt / 1000
-		}
-
-		// union_cover - 从trans_param中获取
-		if transParam, ok := songMap["trans_param"].(map[string]interface{}); ok {
-			if unionCover, ok := transParam["union_cover"].(string); ok {
-				song.UnionCover = unionCover
-			}
-		}
-
-		// mixsongid
-		if mixsongid, ok := songMap["mixsongid"].(float64); ok {
-			song.Mixsongid = int(mixsongid)
-		}
-
-		// 只添加有效的歌曲（至少有hash）
-		if song.Hash != "" {
-			songs = append(songs, song)
-		}
+		songs = append(songs, FavoritesSongData{
+			Hash:       item.Hash.String(),
+			SongName:   f.processSongName(item.Name.String()),
+			FileName:   item.TransParam.Filename.String(),
+			TimeLength: item.Timelen.Int() / 1000, // 毫秒转秒
+			AlbumName:  item.AlbumInfo.Name.String(),
+			AlbumID:    item.AlbumID.String(),
+			AuthorName: item.SingerInfo.FirstName(),
+			UnionCover: item.TransParam.UnionCover.String(),
+			Mixsongid:  item.Mixsongid.Int(),
+		})
 	}
 
 	log.Printf("成功获取歌单歌曲列表，共%d首歌曲", len(songs))
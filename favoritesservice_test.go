@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+func TestGetFavoritesSongs(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := (&FavoritesService{}).GetFavoritesSongs(1, 50)
+	if !resp.Success || len(resp.Data) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	first, second := resp.Data[0], resp.Data[1]
+	if first.SongName != "晴天" || first.FileName != "周杰伦 - 晴天" || first.TimeLength != 269 || first.Mixsongid != 32042830 {
+		t.Errorf("first = %+v", first)
+	}
+	if second.AlbumID != "979856" || second.Mixsongid != 32042831 {
+		t.Errorf("second = %+v", second)
+	}
+
+	req, _ := srv.LastRequest("/playlist/track/all/new")
+	if req.Query.Get("listid") != "2" || req.Query.Get("pagesize") != "50" {
+		t.Errorf("query = %v", req.Query)
+	}
+	assertCookieSent(t, srv, "/playlist/track/all/new", true)
+}
+
+func TestGetFavoritesSongsOffline(t *testing.T) {
+	newMockAPI(t)
+	f := &FavoritesService{}
+
+	// 在线获取时保存本地副本
+	if resp := f.GetFavoritesSongs(1, 50); !resp.Success {
+		t.Fatalf("GetFavoritesSongs: %s", resp.Message)
+	}
+
+	enableOfflineMode(t)
+	resp := f.GetFavoritesSongs(1, 50)
+	if !resp.Success || len(resp.Data) != 2 || resp.Data[0].SongName != "晴天" {
+		t.Fatalf("resp = %+v, want local copy", resp)
+	}
+	if resp := f.GetFavoritesSongs(2, 50); !resp.Success || len(resp.Data) != 0 {
+		t.Errorf("page 2 = %+v, want empty", resp)
+	}
+}
+
+func TestGetFavoritesSongsMissingInfo(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/playlist/track/all/new", `{"status":1,"data":{"song_list":[]}}`)
+
+	if resp := (&FavoritesService{}).GetFavoritesSongs(1, 30); resp.Success {
+		t.Errorf("resp = %+v, want failure", resp)
+	}
+}
+
+func TestFavoritesServiceGetPlaylistSongs(t *testing.T) {
+	srv := newMockAPI(t)
+	// 该接口没有 status 字段，info 中缺少 hash 的条目应被跳过
+	srv.HandleJSON("/playlist/track/all", `{
+		"data": {
+			"info": [
+				{"hash": "A1B2C3D4E5F60718293A4B5C6D7E8F90", "name": "周杰伦 - 晴天", "timelen": 269000,
+				 "trans_param": {"filename": "周杰伦 - 晴天"}, "singerinfo": [{"name": "周杰伦"}]},
+				{"name": "无效条目"}
+			]
+		}
+	}`)
+
+	resp := (&FavoritesService{}).GetPlaylistSongs("collection_3_1234567_2_0")
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if song := resp.Data[0]; song.FileName != "周杰伦 - 晴天" || song.AuthorName != "周杰伦" || song.TimeLength != 269 {
+		t.Errorf("song = %+v", song)
+	}
+}
+
+func TestGetUserPlaylists(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&FavoritesService{}).GetUserPlaylists()
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if playlist := resp.Data[0]; playlist.ListID != 2 || playlist.Name != "我喜欢" || playlist.UpdateTime != 1718000000 {
+		t.Errorf("playlist = %+v", playlist)
+	}
+}
+
+func TestAddFavorite(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := (&FavoritesService{}).AddFavorite(AddFavoriteRequest{SongName: "晴天", Hash: "A1B2C3D4E5F60718293A4B5C6D7E8F90"})
+	if !resp.Success {
+		t.Fatalf("AddFavorite failed: %s", resp.Message)
+	}
+	req, _ := srv.LastRequest("/playlist/tracks/add")
+	if req.Query.Get("data") != "晴天|A1B2C3D4E5F60718293A4B5C6D7E8F90" {
+		t.Errorf("data = %q", req.Query.Get("data"))
+	}
+}
@@ -0,0 +1,130 @@
+// 后端连接状态提示
+// 后端服务不可达或开启离线模式时在页面顶部显示提示条，恢复后自动隐藏
+// 离线状态变化时在 window 上派发 connectivity-change 事件，供其他页面刷新数据
+
+import {Events} from "@wailsio/runtime";
+import * as BackendService from "./bindings/wmplayer/backendservice.js";
+
+class BackendStatusIndicator {
+    constructor() {
+        this.banner = null;
+        this.offline = false;
+        window.isOffline = false;
+        Events.On('backend:status', (event) => {
+            const status = event && event.data !== undefined ? event.data : event;
+            this.update(Array.isArray(status) ? status[0] : status);
+        });
+        this.refresh();
+        console.log('🌐 后端状态提示初始化完成');
+    }
+
+    // 主动获取一次当前状态
+    async refresh() {
+        try {
+            const response = await BackendService.GetBackendStatus();
+            if (response && response.data) {
+                this.update(response.data);
+            }
+        } catch (error) {
+            console.error('❌ 获取后端状态失败:', error);
+        }
+    }
+
+    // 重新探测后端
+    async retry() {
+        this.setMessage('正在重新连接后端服务...');
+        try {
+            const response = await BackendService.CheckBackend();
+            if (response && response.data) {
+                this.update(response.data);
+            }
+        } catch (error) {
+            console.error('❌ 重新连接后端失败:', error);
+        }
+    }
+
+    update(status) {
+        if (!status) {
+            return;
+        }
+        console.log('🌐 后端状态:', status.state, status.active_url);
+        if (status.state === 'offline') {
+            this.show(false);
+            this.setMessage('离线模式：仅可播放已缓存和本地音乐，可在设置中关闭');
+        } else if (status.state === 'unreachable') {
+            this.show(true);
+            const endpoints = (status.endpoints || []).join(', ');
+            this.setMessage(`无法连接后端服务（${endpoints}），已切换为离线状态，仅可播放已缓存和本地音乐`);
+        } else {
+            this.hide();
+        }
+
+        const offline = !!status.offline;
+        if (offline !== this.offline) {
+            this.offline = offline;
+            window.isOffline = offline;
+            window.dispatchEvent(new CustomEvent('connectivity-change', { detail: { offline, status } }));
+        }
+    }
+
+    show(retryable) {
+        if (!this.banner) {
+            this.banner = document.createElement('div');
+            this.banner.className = 'backend-status-banner';
+            this.banner.style.cssText = `
+                position: fixed;
+                top: 0;
+                left: 0;
+                right: 0;
+                z-index: 10001;
+                display: flex;
+                align-items: center;
+                justify-content: center;
+                gap: 12px;
+                padding: 8px 16px;
+                background: #f44336;
+                color: white;
+                font-size: 13px;
+            `;
+
+            this.messageEl = document.createElement('span');
+            const retryButton = document.createElement('button');
+            retryButton.textContent = '重试';
+            retryButton.style.cssText = `
+                border: 1px solid rgba(255,255,255,0.8);
+                background: transparent;
+                color: white;
+                border-radius: 4px;
+                padding: 2px 10px;
+                cursor: pointer;
+            `;
+            retryButton.addEventListener('click', () => this.retry());
+            this.retryButton = retryButton;
+
+            this.banner.appendChild(this.messageEl);
+            this.banner.appendChild(retryButton);
+            document.body.appendChild(this.banner);
+        }
+
+        // 手动开启的离线模式不需要重试
+        this.banner.style.background = retryable ? '#f44336' : '#607d8b';
+        this.retryButton.style.display = retryable ? '' : 'none';
+    }
+
+    setMessage(message) {
+        if (this.messageEl) {
+            this.messageEl.textContent = message;
+        }
+    }
+
+    hide() {
+        if (this.banner && this.banner.parentNode) {
+            this.banner.parentNode.removeChild(this.banner);
+        }
+        this.banner = null;
+        this.messageEl = null;
+        this.retryButton = null;
+    }
+}
+
+window.backendStatusIndicator = new BackendStatusIndicator();
@@ -42,6 +42,14 @@ class FavoritesPageManager {
 
         // 绑定滚动事件进行懒加载
         this.bindScrollEvent();
+
+        // 离线状态变化时重新加载，在线列表与本地副本可能不同
+        if (!this.connectivityBound) {
+            this.connectivityBound = true;
+            window.addEventListener('connectivity-change', () => {
+                this.loadFavoritesSongs(true);
+            });
+        }
     }
 
     // 绑定滚动事件
@@ -149,8 +149,53 @@ function preloadCoverImage(url) {
     });
 }
 
+// 最近一次请求播放地址的歌曲，用于丢弃切歌后才返回的歌词
+let lyricsRequestHash = null;
+// 当前歌词所属的本地音乐文件路径，用于按文件保存歌词偏移
+let lyricsFilePath = '';
+
+// 播放地址返回时歌词尚未获取完成，单独等待后台获取的歌词
+async function loadLyricsLater(hash) {
+    try {
+        const response = await HomepageService.GetSongLyrics(hash);
+        if (!response.success || lyricsRequestHash !== hash) {
+            return;
+        }
+        console.log('🎵 后台获取歌词成功，歌词长度:', response.data.length);
+        currentSongLyrics = response.data;
+        window.currentSongLyrics = response.data;
+        updateLyricsDisplay(response.data);
+    } catch (error) {
+        console.warn('⚠️ 后台获取歌词失败:', error);
+    }
+}
+
+// 获取本地音乐的歌词：同名歌词文件、歌词文件夹、内嵌歌词，都没有时在线搜索
+async function loadLocalLyrics(hash, filePath) {
+    try {
+        console.log('🎵 找到本地音乐文件，获取歌词:', filePath);
+        const { GetLocalMusicLyrics } = await import('./bindings/wmplayer/localmusicservice.js');
+        const response = await GetLocalMusicLyrics(filePath);
+        if (lyricsRequestHash !== hash) {
+            return;
+        }
+        if (!response.success || !response.data) {
+            console.log('🎵 本地音乐没有歌词信息');
+            return;
+        }
+        console.log('🎵 本地音乐歌词获取成功，长度:', response.data.length);
+        currentSongLyrics = response.data;
+        window.currentSongLyrics = response.data;
+        updateLyricsDisplay(response.data);
+    } catch (error) {
+        console.warn('⚠️ 获取本地音乐歌词失败:', error);
+    }
+}
+
 // 获取歌曲播放地址和歌词
 async function getSongPlayUrls(hash) {
+    lyricsRequestHash = hash;
+    lyricsFilePath = '';
     try {
         console.log('🎵 正在获取播放地址和歌词...', hash);
 
@@ -158,9 +203,8 @@ async function getSongPlayUrls(hash) {
         if (hash.startsWith('local-')) {
             console.log('🎵 检测到本地音乐hash，获取播放地址和歌词');
             try {
-                // 动态导入 CacheService 和 LocalMusicService
+                // 动态导入 CacheService
                 const { GetCachedURL } = await import('./bindings/wmplayer/cacheservice.js');
-                const { GetLocalMusicLyrics } = await import('./bindings/wmplayer/localmusicservice.js');
                 
                 // 获取播放地址
                 const cacheResponse = await GetCachedURL(hash);
@@ -168,41 +212,23 @@ async function getSongPlayUrls(hash) {
                 if (cacheResponse.success && cacheResponse.data) {
                     console.log('🎵 本地音乐播放地址获取成功:', cacheResponse.data);
                     
-                    // 尝试获取本地音乐的歌词
-                    let lyrics = null;
-                    try {
-                        // 从缓存服务获取文件路径，然后获取歌词
-                        // 这里需要从hash映射中获取文件路径
-                        console.log('🎵 尝试获取本地音乐歌词...');
-                        
-                        // 先尝试从当前播放的本地音乐文件中获取歌词
-                        if (window.localMusicFiles && Array.isArray(window.localMusicFiles)) {
-                            const currentFile = window.localMusicFiles.find(file => {
-                                const localHash = 'local-' + (file.hash || file.file_path || file.filename);
-                                return localHash === hash;
-                            });
-                            
-                            if (currentFile && currentFile.file_path) {
-                                console.log('🎵 找到本地音乐文件，获取歌词:', currentFile.file_path);
-                                const lyricsResponse = await GetLocalMusicLyrics(currentFile.file_path);
-                                if (lyricsResponse.success && lyricsResponse.data) {
-                                    lyrics = lyricsResponse.data;
-                                    console.log('🎵 本地音乐歌词获取成功，长度:', lyrics.length);
-                                } else {
-                                    console.log('🎵 本地音乐没有歌词信息');
-                                }
-                            }
+                    // 先清除上一首歌的歌词
+                    currentSongLyrics = null;
+                    window.currentSongLyrics = null;
+                    updateLyricsDisplay(null);
+
+                    // 歌词可能来自歌词文件或在线搜索，不阻塞播放
+                    if (window.localMusicFiles && Array.isArray(window.localMusicFiles)) {
+                        const currentFile = window.localMusicFiles.find(file => {
+                            const localHash = 'local-' + (file.hash || file.file_path || file.filename);
+                            return localHash === hash;
+                        });
+
+                        if (currentFile && currentFile.file_path) {
+                            lyricsFilePath = currentFile.file_path;
+                            loadLocalLyrics(hash, currentFile.file_path);
                         }
-                    } catch (lyricsError) {
-                        console.warn('⚠️ 获取本地音乐歌词失败:', lyricsError);
                     }
-                    
-                    // 保存歌词到全局变量
-                    currentSongLyrics = lyrics;
-                    window.currentSongLyrics = lyrics;
-                    
-                    // 更新右侧歌词显示
-                    updateLyricsDisplay(lyrics);
 
                     return [cacheResponse.data];
                 } else {
@@ -241,7 +267,8 @@ async function getSongPlayUrls(hash) {
             if (lyrics) {
                 console.log('获取歌词成功，歌词长度:', lyrics.length);
             } else {
-                console.log('未获取到歌词');
+                console.log('未获取到歌词，等待后台获取');
+                loadLyricsLater(hash);
             }
 
             // 返回播放地址数组，优先使用主地址，备用地址作为后备
@@ -416,6 +443,13 @@ export function initHomePage() {
     // 初始化FM播放跟踪
     initFmPlaybackTracking();
 
+    // 初始化歌词偏移按钮
+    initLyricsOffsetControls();
+    const lyricsPickerBtn = document.getElementById('lyricsPickerBtn');
+    if (lyricsPickerBtn) {
+        lyricsPickerBtn.addEventListener('click', showLyricsPicker);
+    }
+
     if (fmCover) {
         fmCover.addEventListener('click', toggleFM);
     }
@@ -2328,8 +2362,9 @@ function renderVirtualPlaylist(container) {
 
 // 🔧 内存泄漏修复：普通播放列表渲染（小于100首歌曲）
 function renderNormalPlaylist(container, playlist, currentIndex) {
-    // 清空现有列表
+    // 清空现有列表，重新渲染后之前的多选失效
     container.innerHTML = '';
+    lastSelectedPlaylistIndex = -1;
 
     // 生成播放列表项（限制数量以防止内存问题）
     const maxItems = Math.min(playlist.length, 100);
@@ -2341,6 +2376,8 @@ function renderNormalPlaylist(container, playlist, currentIndex) {
         const playlistItem = document.createElement('div');
         playlistItem.className = `playlist-item-card${isActive ? ' active' : ''}`;
         playlistItem.dataset.index = index;
+        playlistItem.dataset.entryId = song.entry_id || '';
+        playlistItem.draggable = true;
 
         // 处理封面图片
         const coverUrl = song.union_cover ? song.union_cover.replace('{size}', '36') : '';
@@ -2366,14 +2403,39 @@ function renderNormalPlaylist(container, playlist, currentIndex) {
             <div class="item-duration">${duration}</div>
         `;
 
-        // 添加点击事件 - 使用统一的 PlayerController
-        playlistItem.addEventListener('click', () => {
+        // 添加点击事件 - 使用统一的 PlayerController，按住 Ctrl/Shift 时多选
+        playlistItem.addEventListener('click', (e) => {
+            if (e.ctrlKey || e.metaKey || e.shiftKey) {
+                selectPlaylistItems(container, index, e.shiftKey);
+                return;
+            }
             console.log(`播放列表项被点击，索引: ${index}`);
             if (window.PlayerController) {
                 window.PlayerController.playByIndex(index);
             }
         });
 
+        // 拖动调整顺序，按条目ID移动，播放列表同时被修改时不会移动错歌曲
+        playlistItem.addEventListener('dragstart', (e) => {
+            e.dataTransfer.setData('text/plain', song.entry_id || '');
+            e.dataTransfer.effectAllowed = 'move';
+        });
+        playlistItem.addEventListener('dragover', (e) => {
+            e.preventDefault();
+            playlistItem.classList.add('drag-over');
+        });
+        playlistItem.addEventListener('dragleave', () => {
+            playlistItem.classList.remove('drag-over');
+        });
+        playlistItem.addEventListener('drop', (e) => {
+            e.preventDefault();
+            playlistItem.classList.remove('drag-over');
+            const entryId = e.dataTransfer.getData('text/plain');
+            if (entryId && entryId !== song.entry_id && window.PlaylistManager) {
+                window.PlaylistManager.moveSong(entryId, index);
+            }
+        });
+
         container.appendChild(playlistItem);
     }
 
@@ -2391,6 +2453,28 @@ function renderNormalPlaylist(container, playlist, currentIndex) {
     }
 }
 
+// 多选播放列表项，range 为 true 时选中上次选择的项到当前项之间的所有项
+let lastSelectedPlaylistIndex = -1;
+function selectPlaylistItems(container, index, range) {
+    const items = container.querySelectorAll('.playlist-item-card');
+    if (range && lastSelectedPlaylistIndex >= 0) {
+        const [start, end] = [Math.min(lastSelectedPlaylistIndex, index), Math.max(lastSelectedPlaylistIndex, index)];
+        items.forEach(item => {
+            const itemIndex = parseInt(item.dataset.index);
+            if (itemIndex >= start && itemIndex <= end) {
+                item.classList.add('selected');
+            }
+        });
+    } else {
+        items[index]?.classList.toggle('selected');
+    }
+    lastSelectedPlaylistIndex = index;
+
+    if (window.PlaylistManager) {
+        window.PlaylistManager.updateEditControls();
+    }
+}
+
 // 格式化时长（秒转换为 mm:ss 格式）
 function formatDuration(seconds) {
     if (!seconds || seconds <= 0) return '--:--';
@@ -2427,10 +2511,20 @@ function updateDailyDate() {
     }
 }
 
+// 最近一次更新歌词显示的序号，用于丢弃切歌后才解析完成的歌词
+let lyricsDisplayToken = 0;
+
 // 更新右侧歌词显示
-function updateLyricsDisplay(lyricsContent) {
+async function updateLyricsDisplay(lyricsContent) {
+    // 歌词交给后端的歌词时钟，由后端按播放进度推送到桌面歌词和MPRIS
+    if (window.loadLyricsToOSD) {
+        window.loadLyricsToOSD(lyricsContent, lyricsRequestHash, lyricsFilePath);
+    }
+    updateLyricsOffsetControls();
+
     const lyricsDisplay = document.querySelector('.lyrics-display');
     if (!lyricsDisplay) return;
+    const token = ++lyricsDisplayToken;
 
     if (!lyricsContent) {
         // 如果没有歌词，显示默认信息
@@ -2441,8 +2535,11 @@ function updateLyricsDisplay(lyricsContent) {
     }
 
     try {
-        // 使用统一的歌词解析函数
-        const lyricsLines = parseLyrics(lyricsContent);
+        // 使用后端解析的歌词时间轴
+        const lyricsLines = await parseLyrics(lyricsContent, lyricsRequestHash, lyricsFilePath);
+        if (token !== lyricsDisplayToken) {
+            return;
+        }
 
         if (lyricsLines.length === 0) {
             lyricsDisplay.innerHTML = '<div class="lyrics-line no-lyrics">歌词解析失败</div>';
@@ -2473,6 +2570,18 @@ function updateLyricsDisplay(lyricsContent) {
     }
 }
 
+// 生成翻译和音译层的HTML，是否显示由 applyLyricsLayerSettings 控制
+function generateLyricsLayersHTML(line) {
+    let html = '';
+    if (line.romanization) {
+        html += `<div class="lyrics-layer lyrics-romanization">${line.romanization}</div>`;
+    }
+    if (line.translation) {
+        html += `<div class="lyrics-layer lyrics-translation">${line.translation}</div>`;
+    }
+    return html;
+}
+
 // 根据歌词格式生成HTML
 function generateLyricsHTML(lyricsLines) {
     if (!lyricsLines || lyricsLines.length === 0) return '';
@@ -2486,174 +2595,355 @@ function generateLyricsHTML(lyricsLines) {
                 `<span class="lyrics-word" data-start-time="${word.startTime}" data-end-time="${word.endTime}" data-word-index="${wordIndex}">${word.text}</span>`
             ).join('') : line.text;
 
-            return `<div class="lyrics-line krc-line" data-time="${line.time}" data-end-time="${line.endTime}" data-index="${index}">${wordsHTML}</div>`;
+            return `<div class="lyrics-line krc-line" data-time="${line.time}" data-end-time="${line.endTime}" data-index="${index}">${wordsHTML}${generateLyricsLayersHTML(line)}</div>`;
         }).join('');
     } else {
         // LRC格式或纯文本：按行显示
         return lyricsLines.map((line, index) =>
-            `<div class="lyrics-line lrc-line" data-time="${line.time}" data-index="${index}">${line.text}</div>`
+            `<div class="lyrics-line lrc-line" data-time="${line.time}" data-index="${index}">${line.text}${generateLyricsLayersHTML(line)}</div>`
         ).join('');
     }
 }
 
-// 检测歌词格式
-function detectLyricsFormat(lyricsContent) {
-    if (!lyricsContent) return 'unknown';
+// 按界面设置显示或隐藏歌词的翻译和音译层
+function applyLyricsLayerSettings() {
+    const settings = window.appSettings?.interface || {};
+    document.body.classList.toggle('lyrics-show-translation', settings.lyricsTranslation !== false);
+    document.body.classList.toggle('lyrics-show-romanization', settings.lyricsRomanization === true);
+}
 
-    // KRC格式特征：包含 [数字,数字] 和 <数字,数字,数字> 标记
-    const krcPattern = /\[\d+,\d+\].*?<\d+,\d+,\d+>/;
-    if (krcPattern.test(lyricsContent)) {
-        return 'krc';
-    }
+window.applyLyricsLayerSettings = applyLyricsLayerSettings;
 
-    // LRC格式特征：包含 [mm:ss.xx] 时间标签
-    const lrcPattern = /\[\d{2}:\d{2}\.\d{2}\]/;
-    if (lrcPattern.test(lyricsContent)) {
-        return 'lrc';
+// 解析歌词：由后端解析为时间轴（毫秒），再转换为页面使用的格式（秒）
+// 传入歌曲hash或本地文件路径时，后端会应用保存的歌词偏移
+async function parseLyrics(lyricsContent, hash = '', filePath = '') {
+    const response = await HomepageService.ParseLyrics(lyricsContent, hash || '', filePath || '');
+    if (!response.success || !response.data) {
+        return [];
     }
 
-    return 'plain'; // 纯文本
-}
+    const timeline = response.data;
+    console.log('🎵 检测到歌词格式:', timeline.format);
 
-// 统一的歌词解析函数
-function parseLyrics(lyricsContent) {
-    const format = detectLyricsFormat(lyricsContent);
-    console.log('🎵 检测到歌词格式:', format);
+    return (timeline.lines || []).map((line, index) => {
+        // 纯文本歌词没有时间信息，假设每行3秒
+        const time = timeline.synced ? line.start / 1000 : index * 3;
+        const duration = line.duration / 1000;
+        const words = (line.words || []).filter(word => word.text.trim()).map(word => ({
+            text: word.text,
+            startTime: word.start / 1000,
+            duration: word.duration / 1000,
+            endTime: (word.start + word.duration) / 1000
+        }));
 
-    switch (format) {
-        case 'krc':
-            return parseKRCLyrics(lyricsContent);
-        case 'lrc':
-            return parseLRCLyrics(lyricsContent);
-        case 'plain':
-            return parsePlainLyrics(lyricsContent);
-        default:
-            return [];
-    }
+        return {
+            time: time,
+            duration: duration,
+            endTime: time + duration,
+            text: line.text,
+            words: words.length > 0 ? words : undefined,
+            translation: line.translation || '',
+            romanization: line.romanization || '',
+            format: timeline.format
+        };
+    });
 }
 
-// 解析KRC格式歌词（包含逐字时间戳）
-function parseKRCLyrics(krcContent) {
-    if (!krcContent) return [];
-
-    const lines = krcContent.split('\n');
-    const lyricsLines = [];
+// 歌词偏移每次微调的毫秒数
+const LYRICS_OFFSET_STEP = 100;
+
+// 初始化歌词偏移按钮，偏移按歌曲保存，正值让歌词提前显示
+function initLyricsOffsetControls() {
+    const bindings = [
+        ['lyricsOffsetEarlierBtn', () => adjustLyricsOffset(LYRICS_OFFSET_STEP)],
+        ['lyricsOffsetLaterBtn', () => adjustLyricsOffset(-LYRICS_OFFSET_STEP)],
+        ['lyricsOffsetResetBtn', () => adjustLyricsOffset(null)],
+        ['lyricsOffsetWriteBtn', writeLyricsOffsetToFile]
+    ];
+    bindings.forEach(([id, handler]) => {
+        const btn = document.getElementById(id);
+        if (btn) {
+            btn.addEventListener('click', handler);
+        }
+    });
+}
 
-    // KRC格式正则表达式
-    // [171960,5040]<0,240,0>你<240,150,0>走<390,300,0>之<690,570,0>后
-    const lineRegex = /^\[(\d+),(\d+)\](.*)$/;
+// 显示当前歌曲的歌词偏移，本地音乐才显示写回文件按钮
+async function updateLyricsOffsetControls(offset) {
+    const valueBtn = document.getElementById('lyricsOffsetResetBtn');
+    const writeBtn = document.getElementById('lyricsOffsetWriteBtn');
+    if (writeBtn) {
+        writeBtn.style.display = lyricsFilePath ? '' : 'none';
+    }
+    if (!valueBtn) return;
 
-    for (const line of lines) {
-        const trimmedLine = line.trim();
-        if (!trimmedLine) continue;
+    if (offset === undefined) {
+        offset = 0;
+        if (lyricsRequestHash) {
+            try {
+                const { GetLyricsOffset } = await import('./bindings/wmplayer/cacheservice.js');
+                const response = await GetLyricsOffset(lyricsRequestHash, lyricsFilePath);
+                offset = response.success ? response.data : 0;
+            } catch (error) {
+                console.warn('⚠️ 获取歌词偏移失败:', error);
+            }
+        }
+    }
+    const seconds = offset / 1000;
+    valueBtn.textContent = `${seconds > 0 ? '+' : ''}${seconds.toFixed(1)}s`;
+    valueBtn.classList.toggle('active', offset !== 0);
+}
 
-        const lineMatch = trimmedLine.match(lineRegex);
-        if (lineMatch) {
-            const startTime = parseInt(lineMatch[1]) / 1000; // 转换为秒
-            const duration = parseInt(lineMatch[2]) / 1000; // 转换为秒
-            const content = lineMatch[3];
+// 微调歌词偏移，delta 为 null 时重置
+async function adjustLyricsOffset(delta) {
+    if (!lyricsRequestHash) {
+        showToast('当前没有播放歌曲', 'info');
+        return;
+    }
 
-            // 解析每个字符的时间戳
-            const words = [];
-            const wordRegex = /<(\d+),(\d+),\d+>([^<]*)/g;
-            let match;
-            let currentTime = startTime;
+    try {
+        const { AdjustLyricsOffset, SetLyricsOffset } = await import('./bindings/wmplayer/cacheservice.js');
+        const response = delta === null
+            ? await SetLyricsOffset(lyricsRequestHash, lyricsFilePath, 0)
+            : await AdjustLyricsOffset(lyricsRequestHash, lyricsFilePath, delta);
+        if (!response.success) {
+            showToast(response.message || '调整歌词偏移失败', 'error');
+            return;
+        }
 
-            while ((match = wordRegex.exec(content)) !== null) {
-                const wordStartOffset = parseInt(match[1]) / 1000; // 相对于行开始的偏移时间
-                const wordDuration = parseInt(match[2]) / 1000; // 字符持续时间
-                const text = match[3];
+        // 后端的歌词时钟已按新的偏移推送，这里重新解析右侧的歌词
+        updateLyricsOffsetControls(response.data);
+        await refreshLyricsDisplay();
+    } catch (error) {
+        console.error('❌ 调整歌词偏移失败:', error);
+        showToast('调整歌词偏移失败', 'error');
+    }
+}
 
-                if (text.trim()) {
-                    words.push({
-                        text: text,
-                        startTime: startTime + wordStartOffset,
-                        duration: wordDuration,
-                        endTime: startTime + wordStartOffset + wordDuration
-                    });
-                }
-            }
+// 把本地音乐的歌词偏移写入同名LRC文件的 [offset:] 标签
+async function writeLyricsOffsetToFile() {
+    if (!lyricsFilePath) return;
 
-            if (words.length > 0) {
-                lyricsLines.push({
-                    time: startTime,
-                    duration: duration,
-                    endTime: startTime + duration,
-                    text: words.map(w => w.text).join(''),
-                    words: words,
-                    format: 'krc',
-                    originalLine: trimmedLine // 保存原始行文本
-                });
+    try {
+        const { WriteLyricsOffsetToFile } = await import('./bindings/wmplayer/cacheservice.js');
+        const response = await WriteLyricsOffsetToFile(lyricsFilePath);
+        showToast(response.message, response.success ? 'success' : 'error');
+        if (response.success) {
+            // 重新读取写入后的歌词文件，偏移已包含在文件中，需要重新加载到歌词时钟
+            const { GetLocalMusicLyrics } = await import('./bindings/wmplayer/localmusicservice.js');
+            const lyricsResponse = await GetLocalMusicLyrics(lyricsFilePath);
+            if (lyricsResponse.success && lyricsResponse.data) {
+                currentSongLyrics = lyricsResponse.data;
+                window.currentSongLyrics = lyricsResponse.data;
             }
+            await updateLyricsDisplay(currentSongLyrics);
         }
+    } catch (error) {
+        console.error('❌ 写入歌词偏移失败:', error);
+        showToast('写入歌词偏移失败', 'error');
     }
+}
 
-    // 按时间排序
-    lyricsLines.sort((a, b) => a.time - b.time);
+// 按新的偏移重新解析并显示当前歌词，不重新加载到歌词时钟
+async function refreshLyricsDisplay() {
+    const lyricsDisplay = document.querySelector('.lyrics-display');
+    if (!lyricsDisplay || !currentSongLyrics) return;
+    const token = ++lyricsDisplayToken;
 
-    return lyricsLines;
+    const lyricsLines = await parseLyrics(currentSongLyrics, lyricsRequestHash, lyricsFilePath);
+    if (token !== lyricsDisplayToken || lyricsLines.length === 0) {
+        return;
+    }
+    currentLyricsLines = lyricsLines;
+    window.currentLyricsLines = lyricsLines;
+    currentActiveLyricsIndex = -1;
+    lyricsDisplay.innerHTML = generateLyricsHTML(lyricsLines);
+    addLyricsClickListeners();
 }
 
-// 解析LRC格式歌词
-function parseLRCLyrics(lrcContent) {
-    if (!lrcContent) return [];
-
-    const lines = lrcContent.split('\n');
-    const lyricsLines = [];
+// 转义HTML特殊字符
+function escapeLyricsHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text || '';
+    return div.innerHTML.replace(/"/g, '&quot;');
+}
 
-    // LRC时间标签正则表达式: [mm:ss.xx]
-    const timeRegex = /\[(\d{2}):(\d{2})\.(\d{2})\]/g;
+// 格式化候选歌词的时长（毫秒）
+function formatCandidateDuration(ms) {
+    if (!ms) return '--:--';
+    const seconds = Math.round(ms / 1000);
+    return `${Math.floor(seconds / 60)}:${(seconds % 60).toString().padStart(2, '0')}`;
+}
 
-    for (const line of lines) {
-        const trimmedLine = line.trim();
-        if (!trimmedLine) continue;
+// 显示歌词选择弹窗，列出当前歌曲的全部候选歌词
+function showLyricsPicker() {
+    if (!lyricsRequestHash) {
+        showToast('当前没有播放歌曲', 'info');
+        return;
+    }
+    closeLyricsPicker();
+
+    const song = window.PlayerController ? window.PlayerController.getCurrentSong() : null;
+    const songName = song?.songname || song?.title || '';
+    const artist = song?.author_name || song?.artist || '';
+    // 本地音乐没有酷狗的hash，只能按关键词搜索
+    const keyword = lyricsRequestHash.startsWith('local-') ? [artist, songName].filter(Boolean).join(' - ') : '';
+
+    const modal = document.createElement('div');
+    modal.className = 'lyrics-modal-overlay lyrics-picker-overlay';
+    modal.innerHTML = `
+        <div class="lyrics-modal lyrics-picker">
+            <div class="lyrics-modal-header">
+                <div class="lyrics-song-info">
+                    <h3 class="lyrics-song-title">选择歌词</h3>
+                    <p class="lyrics-song-artist">${escapeLyricsHtml([songName, artist].filter(Boolean).join(' - '))}</p>
+                </div>
+                <button class="lyrics-modal-close" data-action="close">
+                    <i class="fas fa-times"></i>
+                </button>
+            </div>
+            <div class="lyrics-picker-search">
+                <input type="text" class="lyrics-picker-keyword" placeholder="留空按歌曲搜索，或输入“歌手 - 歌名”" value="${escapeLyricsHtml(keyword)}">
+                <button class="lyrics-picker-btn" data-action="search">搜索</button>
+            </div>
+            <div class="lyrics-modal-content">
+                <div class="lyrics-picker-list">正在搜索歌词...</div>
+                <div class="lyrics-picker-preview"></div>
+            </div>
+        </div>
+    `;
+    document.body.appendChild(modal);
 
-        const matches = [...trimmedLine.matchAll(timeRegex)];
-        if (matches.length > 0) {
-            const lastMatch = matches[matches.length - 1];
-            const minutes = parseInt(lastMatch[1]);
-            const seconds = parseInt(lastMatch[2]);
-            const centiseconds = parseInt(lastMatch[3]);
+    const hash = lyricsRequestHash;
+    const keywordInput = modal.querySelector('.lyrics-picker-keyword');
+    let candidates = [];
 
-            const time = minutes * 60 + seconds + centiseconds / 100;
-            const text = trimmedLine.substring(lastMatch.index + lastMatch[0].length).trim();
+    modal.addEventListener('click', async (e) => {
+        if (e.target === modal) {
+            closeLyricsPicker();
+            return;
+        }
+        const actionEl = e.target.closest('[data-action]');
+        if (!actionEl) return;
 
-            if (text) {
-                lyricsLines.push({
-                    time: time,
-                    text: text,
-                    format: 'lrc',
-                    originalLine: trimmedLine // 保存原始LRC行文本
-                });
-            }
+        const candidate = candidates[Number(actionEl.dataset.index)];
+        switch (actionEl.dataset.action) {
+            case 'close':
+                closeLyricsPicker();
+                break;
+            case 'search':
+                candidates = await searchLyricsCandidates(modal, hash, keywordInput.value);
+                break;
+            case 'preview':
+                await previewLyricsCandidate(modal, candidate);
+                break;
+            case 'select':
+                await selectLyricsCandidate(hash, candidate);
+                break;
         }
+    });
+    keywordInput.addEventListener('keydown', async (e) => {
+        if (e.key === 'Enter') {
+            candidates = await searchLyricsCandidates(modal, hash, keywordInput.value);
+        }
+    });
+
+    searchLyricsCandidates(modal, hash, keyword).then(result => {
+        candidates = result;
+    });
+}
+
+// 关闭歌词选择弹窗
+function closeLyricsPicker() {
+    const modal = document.querySelector('.lyrics-picker-overlay');
+    if (modal) {
+        modal.remove();
     }
+}
+
+// 搜索候选歌词并显示在弹窗中，返回候选歌词列表
+async function searchLyricsCandidates(modal, hash, keyword) {
+    const list = modal.querySelector('.lyrics-picker-list');
+    list.textContent = '正在搜索歌词...';
+    modal.querySelector('.lyrics-picker-preview').innerHTML = '';
 
-    // 按时间排序
-    lyricsLines.sort((a, b) => a.time - b.time);
+    try {
+        const response = await HomepageService.GetLyricsCandidates(hash, keyword.trim());
+        if (!response.success) {
+            list.textContent = response.message || '搜索歌词失败';
+            return [];
+        }
 
-    return lyricsLines;
+        const { candidates, selected_id: selectedId, chosen } = response.data;
+        if (!candidates || candidates.length === 0) {
+            list.textContent = '没有找到候选歌词';
+            return [];
+        }
+
+        list.innerHTML = candidates.map((candidate, index) => `
+            <div class="lyrics-candidate ${candidate.id === selectedId ? 'selected' : ''}">
+                <div class="lyrics-candidate-info">
+                    <div class="lyrics-candidate-title">${escapeLyricsHtml(candidate.song || '未知歌曲')} - ${escapeLyricsHtml(candidate.singer || '未知歌手')}</div>
+                    <div class="lyrics-candidate-detail">
+                        ${formatCandidateDuration(candidate.duration)} · ${escapeLyricsHtml(candidate.source || '未知来源')} · 匹配度 ${candidate.score}
+                        ${candidate.id === selectedId ? `<span class="lyrics-candidate-current">${chosen ? '已选择' : '当前使用'}</span>` : ''}
+                    </div>
+                </div>
+                <button class="lyrics-picker-btn" data-action="preview" data-index="${index}">预览</button>
+                <button class="lyrics-picker-btn primary" data-action="select" data-index="${index}">使用</button>
+            </div>
+        `).join('');
+        return candidates;
+    } catch (error) {
+        console.error('❌ 搜索候选歌词失败:', error);
+        list.textContent = '搜索歌词失败';
+        return [];
+    }
 }
 
-// 解析纯文本歌词
-function parsePlainLyrics(plainContent) {
-    if (!plainContent) return [];
+// 在弹窗中预览候选歌词
+async function previewLyricsCandidate(modal, candidate) {
+    if (!candidate) return;
+    const preview = modal.querySelector('.lyrics-picker-preview');
+    preview.textContent = '正在加载歌词...';
+
+    try {
+        const response = await HomepageService.PreviewLyricsCandidate(candidate.id, candidate.accesskey);
+        if (!response.success) {
+            preview.textContent = response.message || '获取歌词失败';
+            return;
+        }
+        const lines = await parseLyrics(response.data);
+        preview.innerHTML = lines.map(line => `<p class="lyrics-picker-line">${escapeLyricsHtml(line.text)}</p>`).join('');
+        preview.scrollTop = 0;
+    } catch (error) {
+        console.error('❌ 预览候选歌词失败:', error);
+        preview.textContent = '获取歌词失败';
+    }
+}
 
-    const lines = plainContent.split('\n');
-    const lyricsLines = [];
+// 使用候选歌词，后端会记住这首歌的选择
+async function selectLyricsCandidate(hash, candidate) {
+    if (!candidate) return;
 
-    lines.forEach((line, index) => {
-        const trimmedLine = line.trim();
-        if (trimmedLine) {
-            lyricsLines.push({
-                time: index * 3, // 假设每行3秒
-                text: trimmedLine,
-                format: 'plain'
-            });
+    try {
+        const response = await HomepageService.SelectLyricsCandidate(hash, candidate.id, candidate.accesskey);
+        if (!response.success) {
+            showToast(response.message || '使用歌词失败', 'error');
+            return;
         }
-    });
+        closeLyricsPicker();
+        showToast('已使用选择的歌词', 'success');
 
-    return lyricsLines;
+        if (lyricsRequestHash === hash) {
+            currentSongLyrics = response.data;
+            window.currentSongLyrics = response.data;
+            await updateLyricsDisplay(response.data);
+        }
+    } catch (error) {
+        console.error('❌ 使用候选歌词失败:', error);
+        showToast('使用歌词失败', 'error');
+    }
 }
 
 // 当前高亮的歌词行索引，用于避免重复滚动
@@ -2720,13 +3010,8 @@ function updateLRCLyricsHighlight(currentTime) {
 
     // 只有当高亮行发生变化时才更新DOM和OSD歌词
     if (activeIndex !== currentActiveLyricsIndex && activeIndex >= 0) {
+        // OSD歌词由后端的歌词时钟推送，这里只更新页面高亮
         currentActiveLyricsIndex = activeIndex;
-
-        // 发送完整的行数据到OSD（与KRC格式保持一致）
-        const currentLine = currentLyricsLines[activeIndex];
-        if (currentLine && window.sendKRCLineToOSD) {
-            window.sendKRCLineToOSD(currentLine);
-        }
     } else if (activeIndex !== currentActiveLyricsIndex) {
         // 行变化但没有活跃行（可能是歌曲结束）
         currentActiveLyricsIndex = activeIndex;
@@ -2783,19 +3068,8 @@ function updateKRCLyricsHighlight(currentTime) {
     // 检查是否有变化
     const hasLineChanged = activeLineIndex !== lastActiveLineIndex;
 
-    // 只有当行发生变化时才更新OSD歌词（不需要字级变化触发）
-    if (hasLineChanged && activeLineIndex >= 0) {
-        // 更新记录的状态
-        lastActiveLineIndex = activeLineIndex;
-        lastActiveWordIndex = activeWordIndex;
-
-        // 发送完整的KRC行数据到OSD
-        const currentLine = currentLyricsLines[activeLineIndex];
-        if (currentLine && window.sendKRCLineToOSD) {
-            window.sendKRCLineToOSD(currentLine);
-        }
-    } else if (hasLineChanged) {
-        // 行变化但没有活跃行（可能是歌曲结束）
+    // 记录行变化，OSD歌词由后端的歌词时钟推送
+    if (hasLineChanged) {
         lastActiveLineIndex = activeLineIndex;
         lastActiveWordIndex = activeWordIndex;
     }
@@ -709,7 +709,7 @@ async function handlePlaybackEnd() {
                 }, 500);
             }
         } else if (currentPlaylist.shuffle_mode) {
-            // 随机播放：总是有下一首（会重新生成随机顺序）
+            // 随机播放：开启列表循环时每轮播完重新打乱，否则播完一轮后没有下一首
             console.log('🔀 随机播放：播放下一首随机歌曲');
             // 🔧 内存泄漏修复：使用全局资源管理器管理定时器
             if (window.GlobalResourceManager) {
@@ -143,6 +143,11 @@ function applySettingsToFrontend(settings) {
         console.log('🎨 主题设置已应用:', settings.interface.theme);
     }
 
+    // 应用歌词翻译和音译的显示设置
+    if (window.applyLyricsLayerSettings) {
+        window.applyLyricsLayerSettings();
+    }
+
     // 应用音量设置
     if (settings.playback && settings.playback.volume !== undefined) {
         // 延迟应用音量设置，等待播放器初始化
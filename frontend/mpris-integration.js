@@ -9,9 +9,7 @@ class MPRISIntegration {
     constructor() {
         this.isEnabled = false;
         this.lastSong = null;
-        this.lastPlaybackStatus = null;
         this.lastVolume = null;
-        this.lastPosition = 0;
         this.updateInterval = null;
         
         this.init();
@@ -75,11 +73,8 @@ class MPRISIntegration {
     }
     
     // 设置播放器事件监听
+    // 歌曲和音量变化总是上报给后端，命令行 --status 也需要这些信息，MPRIS未启用时后端只记录状态
     setupPlayerEventListeners() {
-        if (!this.isEnabled) {
-            return;
-        }
-        
         // 监听歌曲变化
         if (window.PlaylistManager) {
             const originalSetCurrentSong = window.PlaylistManager.setCurrentSong;
@@ -97,6 +92,8 @@ class MPRISIntegration {
             window.UnifiedPlayerController.on('volumeChanged', (data) => {
                 this.onVolumeChanged(data.volume);
             });
+            // 上报初始音量
+            this.onVolumeChanged(window.UnifiedPlayerController.getVolume());
             
             window.UnifiedPlayerController.on('playStateChanged', (isPlaying) => {
                 this.onPlayStateChanged(isPlaying);
@@ -119,29 +116,14 @@ class MPRISIntegration {
                 return;
             }
             
-            // 检查播放状态变化
-            const isPlaying = !audioElement.paused;
-            const playbackStatus = isPlaying ? 'Playing' : (audioElement.ended ? 'Stopped' : 'Paused');
-            
-            if (this.lastPlaybackStatus !== playbackStatus) {
-                await this.updatePlaybackStatus(playbackStatus);
-                this.lastPlaybackStatus = playbackStatus;
-            }
-            
-            // 检查音量变化
+            // 播放状态和位置由 osd-lyrics.js 通过 UpdatePlaybackStatus/UpdatePlayerPosition 上报，
+            // 后端会同时更新MPRIS和歌词时钟，这里只同步音量
             const volume = audioElement.volume;
             if (this.lastVolume !== volume) {
                 await this.updateVolume(volume);
                 this.lastVolume = volume;
             }
             
-            // 检查播放位置变化
-            const position = Math.floor(audioElement.currentTime * 1000000); // 转换为微秒
-            if (Math.abs(this.lastPosition - position) > 1000000) { // 超过1秒差异才更新
-                await this.updatePosition(position);
-                this.lastPosition = position;
-            }
-            
         } catch (error) {
             console.error('❌ 同步播放状态失败:', error);
         }
@@ -149,7 +131,7 @@ class MPRISIntegration {
     
     // 歌曲变化事件
     async onSongChanged(song) {
-        if (!this.isEnabled || !song) {
+        if (!song) {
             return;
         }
         
@@ -184,10 +166,6 @@ class MPRISIntegration {
     
     // 音量变化事件
     async onVolumeChanged(volume) {
-        if (!this.isEnabled) {
-            return;
-        }
-        
         // 音量范围转换：0-100 -> 0.0-1.0
         const volumeFloat = volume / 100.0;
         await this.updateVolume(volumeFloat);
@@ -218,11 +196,11 @@ class MPRISIntegration {
         try {
             console.log('🎵 MPRIS: 更新元数据', { title, artist, album, duration });
 
-            // 动态导入MediaKeyService绑定
-            const { UpdateMPRISMetadata } = await import('./bindings/wmplayer/mediakeyservice.js');
+            // 动态导入MediaKeyService绑定，后端记录状态并在MPRIS启用时同步
+            const { UpdateSongMetadata } = await import('./bindings/wmplayer/mediakeyservice.js');
 
-            if (UpdateMPRISMetadata) {
-                await UpdateMPRISMetadata(title, artist, album, artUrl, duration);
+            if (UpdateSongMetadata) {
+                await UpdateSongMetadata(title, artist, album, artUrl, duration);
                 console.log('✅ MPRIS元数据更新成功');
             }
 
@@ -236,11 +214,11 @@ class MPRISIntegration {
         try {
             console.log('🎵 MPRIS: 更新音量', volume);
 
-            // 动态导入MediaKeyService绑定
-            const { UpdateMPRISVolume } = await import('./bindings/wmplayer/mediakeyservice.js');
+            // 动态导入MediaKeyService绑定，后端记录状态并在MPRIS启用时同步
+            const { UpdatePlayerVolume } = await import('./bindings/wmplayer/mediakeyservice.js');
 
-            if (UpdateMPRISVolume) {
-                await UpdateMPRISVolume(volume);
+            if (UpdatePlayerVolume) {
+                await UpdatePlayerVolume(volume);
                 // console.log('✅ MPRIS音量更新成功');
             }
 
@@ -249,24 +227,6 @@ class MPRISIntegration {
         }
     }
 
-    // 更新播放位置
-    async updatePosition(position) {
-        try {
-            // 位置更新比较频繁，减少日志输出
-            // console.log('🎵 MPRIS: 更新播放位置', position);
-
-            // 动态导入MediaKeyService绑定
-            const { UpdateMPRISPosition } = await import('./bindings/wmplayer/mediakeyservice.js');
-
-            if (UpdateMPRISPosition) {
-                await UpdateMPRISPosition(position);
-            }
-
-        } catch (error) {
-            console.error('❌ 更新MPRIS播放位置失败:', error);
-        }
-    }
-    
     // 销毁
     destroy() {
         if (this.updateInterval) {
@@ -23,9 +23,9 @@ function cleanupOSDResources() {
     
     // 清理事件监听器
     osdResources.listeners.forEach((listeners, element) => {
-        listeners.forEach(({ event, handler }) => {
+        listeners.forEach(({ event, handler, capture }) => {
             try {
-                element.removeEventListener(event, handler);
+                element.removeEventListener(event, handler, capture);
             } catch (error) {
                 console.warn('清理OSD事件监听器时出错:', error);
             }
@@ -49,8 +49,8 @@ async function initOSDLyrics() {
 
     try {
         // 导入所有必要的绑定服务方法
-        const { UpdateCurrentLyrics, SetEnabled, IsEnabled } = await import('./bindings/wmplayer/cacheservice.js');
-        osdLyricsService = { UpdateCurrentLyrics, SetEnabled, IsEnabled };
+        const { UpdateCurrentLyrics, LoadLyrics, SetEnabled, IsEnabled } = await import('./bindings/wmplayer/cacheservice.js');
+        osdLyricsService = { UpdateCurrentLyrics, LoadLyrics, SetEnabled, IsEnabled };
         osdLyricsInitialized = true;
         console.log('✅ OSD歌词服务初始化完成');
 
@@ -183,45 +183,88 @@ async function updateOSDLyrics(lyricsText, songName = '', artist = '') {
     }
 }
 
-// 发送原始歌词行到OSD（简化版本，只发送当前行，OSD自己计算播放进度）
-async function sendKRCLineToOSD(lyricsLine) {
+// 把当前歌曲的歌词交给后端的歌词时钟，之后由后端按播放进度推送歌词行
+// hash 和 filePath（本地音乐）用于应用保存的歌词偏移
+async function loadLyricsToOSD(lyricsText, hash = '', filePath = '') {
     if (!osdLyricsService) {
         return;
     }
 
     try {
-        // 获取当前播放的歌曲信息
         const currentSong = getCurrentSong();
         const songName = currentSong?.songname || currentSong?.title || '';
         const artist = currentSong?.author_name || currentSong?.artist || '';
 
-        // 获取原始歌词行文本
-        const originalLine = lyricsLine.originalLine || '';
-        const format = lyricsLine.format || 'lrc';
-
-        if (!originalLine) {
-            console.warn('⚠️ 没有原始歌词行文本');
-            return;
+        const response = await osdLyricsService.LoadLyrics(hash || '', filePath || '', lyricsText || '', songName, artist);
+        if (!response.success) {
+            console.warn('⚠️ 加载歌词到歌词时钟失败:', response.message);
         }
 
-        // console.log(`🎵 发送原始${format.toUpperCase()}歌词行到OSD:`, originalLine);
+        // 歌词可能在歌曲播放中途才返回，立即上报当前位置
+        reportPlaybackPosition();
+    } catch (error) {
+        console.error('❌ 加载歌词到歌词时钟失败:', error);
+    }
+}
 
-        // 直接发送原始歌词行，OSD歌词自己计算播放进度
-        const response = await osdLyricsService.UpdateCurrentLyrics(
-            originalLine,
-            songName,
-            artist
-        );
+// 上次上报播放位置的时间
+let lastPositionReport = 0;
 
-        if (!response.success) {
-            console.warn('⚠️ 发送歌词失败:', response.message);
-        }
+// 上报播放位置（微秒），后端歌词时钟据此校准
+async function reportPlaybackPosition() {
+    const audioElement = document.querySelector('audio');
+    if (!audioElement) {
+        return;
+    }
 
+    lastPositionReport = Date.now();
+    try {
+        const { UpdatePlayerPosition } = await import('./bindings/wmplayer/mediakeyservice.js');
+        await UpdatePlayerPosition(Math.floor(audioElement.currentTime * 1000000));
     } catch (error) {
-        console.error('❌ 发送歌词失败:', error);
+        console.warn('⚠️ 上报播放位置失败:', error);
     }
 }
 
+// 上报播放状态：Playing、Paused 或 Stopped
+async function reportPlaybackStatus(status) {
+    try {
+        const { UpdatePlaybackStatus } = await import('./bindings/wmplayer/mediakeyservice.js');
+        await UpdatePlaybackStatus(status);
+    } catch (error) {
+        console.warn('⚠️ 上报播放状态失败:', error);
+    }
+}
+
+// 监听音频元素的播放事件，把状态和位置同步给后端的歌词时钟
+// 媒体事件不冒泡，在捕获阶段监听以覆盖之后创建的音频元素
+function setupPlaybackClockSync() {
+    const handlers = {
+        playing: () => {
+            reportPlaybackStatus('Playing');
+            reportPlaybackPosition();
+        },
+        pause: () => reportPlaybackStatus('Paused'),
+        ended: () => reportPlaybackStatus('Stopped'),
+        seeked: () => reportPlaybackPosition(),
+        timeupdate: () => {
+            // 后端时钟会自行推算位置，这里每秒校准一次即可
+            if (Date.now() - lastPositionReport >= 1000) {
+                reportPlaybackPosition();
+            }
+        }
+    };
+
+    const listeners = [];
+    Object.entries(handlers).forEach(([event, handler]) => {
+        document.addEventListener(event, handler, true);
+        listeners.push({ event, handler, capture: true });
+    });
+    osdResources.listeners.set(document, listeners);
+
+    console.log('✅ 歌词时钟播放状态同步已设置');
+}
+
 // getCurrentPlayTime 函数已移除，OSD歌词自己计算播放进度
 
 // 更新当前OSD歌词（从当前播放的歌曲获取信息）
@@ -291,39 +334,16 @@ function showMessage(message, type = 'info') {
 // 监听歌词高亮变化（已移除MutationObserver，改为直接在歌词高亮函数中调用）
 function setupLyricsHighlightListener() {
     // 不再使用MutationObserver，因为会导致频繁更新
-    // 现在直接在updateLRCLyricsHighlight和updateKRCLyricsHighlight中调用updateCurrentOSDLyrics
+    // 歌词行由后端的歌词时钟按播放进度推送
     console.log('✅ 歌词高亮监听器已设置（使用直接调用方式）');
 }
 
-// 监听歌曲切换
-function setupSongChangeListener() {
-    // 监听歌曲信息更新事件
-    document.addEventListener('songInfoUpdated', (e) => {
-        if (e.detail) {
-            // 歌曲切换时，先显示歌曲信息，然后等待歌词加载
-            const song = e.detail;
-            const songName = song.songname || song.title || '';
-            const artist = song.author_name || song.artist || '';
-
-            // 先显示歌曲信息
-            updateOSDLyrics('♪ ♫ ♪ ♫', songName, artist);
-
-            // 延迟一段时间后更新歌词（等待歌词加载）
-            setTimeout(() => {
-                updateCurrentOSDLyrics();
-            }, 1000);
-        }
-    });
-    
-    console.log('✅ 歌曲切换监听器已设置');
-}
-
 // 页面加载完成后初始化
 document.addEventListener('DOMContentLoaded', () => {
     console.log('🎵 页面加载完成，初始化OSD歌词功能');
     initOSDLyrics();
     setupLyricsHighlightListener();
-    setupSongChangeListener();
+    setupPlaybackClockSync();
 });
 
 // 导出函数供其他模块使用
@@ -331,10 +351,10 @@ window.OSDLyrics = {
     updateOSDLyrics,
     updateCurrentOSDLyrics,
     toggleOSDLyrics,
-    sendKRCLineToOSD,
+    loadLyricsToOSD,
     isEnabled: () => osdLyricsEnabled
 };
 
 // 同时暴露主要函数到全局作用域
-window.sendKRCLineToOSD = sendKRCLineToOSD;
+window.loadLyricsToOSD = loadLyricsToOSD;
 window.toggleOSDLyrics = toggleOSDLyrics;
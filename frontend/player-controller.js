@@ -35,7 +35,7 @@ async function playSong(song) {
 
     try {
         // 清空当前播放列表，设置为单曲播放
-        const success = await window.PlaylistManager.setPlaylist([song], 0, '单曲播放', true, 'normal');
+        const success = await window.PlaylistManager.setPlaylist([song], 0, '单曲播放', true);
         if (!success) {
             console.error('❌ 设置单曲播放列表失败');
             return false;
@@ -50,7 +50,8 @@ async function playSong(song) {
 }
 
 // 统一播放函数 - 歌单播放
-async function playPlaylist(songs, startIndex = 0, playlistName = '播放列表', playMode = 'repeat_all') {
+// playMode 为空时保持用户选择的随机和循环模式
+async function playPlaylist(songs, startIndex = 0, playlistName = '播放列表', playMode = '') {
     console.log('🎵 播放歌单:', { songs: songs.length, startIndex, playlistName, playMode });
 
     if (!songs || songs.length === 0) {
@@ -59,7 +59,8 @@ async function loadPlaylistFromCache() {
 }
 
 // 设置播放列表（歌单播放）
-async function setPlaylist(songs, currentIndex = 0, name = '播放列表', clearFirst = true, playMode = 'repeat_all') {
+// playMode 为空时保持用户选择的随机和循环模式
+async function setPlaylist(songs, currentIndex = 0, name = '播放列表', clearFirst = true, playMode = '') {
     try {
         console.log('🎵 设置播放列表:', { songs: songs.length, currentIndex, name, clearFirst, playMode });
         console.log('🎵 第一首歌曲原始数据:', songs[0]);
@@ -326,6 +327,11 @@ function hasNext() {
         return false;
     }
 
+    // 后端按随机和循环模式计算是否还有下一首
+    if (typeof currentPlaylist.has_next === 'boolean') {
+        return currentPlaylist.has_next;
+    }
+
     // 兼容不同的字段名格式
     const playMode = currentPlaylist.play_mode || currentPlaylist.PlayMode || 'normal';
     const currentIndex = currentPlaylist.current_index ?? currentPlaylist.CurrentIndex ?? 0;
@@ -379,6 +385,88 @@ async function clearPlaylist() {
     }
 }
 
+// 调用后端编辑播放列表，成功后刷新显示
+async function editPlaylist(method, ...args) {
+    try {
+        const service = await import('./bindings/wmplayer/playlistservice.js');
+        const response = await service[method](...args);
+
+        if (response && response.success) {
+            currentPlaylist = response.data;
+            console.log(`✅ ${response.message}`);
+            updatePlaylistUI();
+            return true;
+        } else {
+            console.warn('⚠️ 编辑播放列表失败:', response?.message || '未知错误');
+            return false;
+        }
+    } catch (error) {
+        console.error('❌ 编辑播放列表失败:', error);
+        return false;
+    }
+}
+
+// 把条目ID对应的歌曲移动到 to
+function moveSong(entryId, to) {
+    return editPlaylist('MoveSong', entryId, to);
+}
+
+// 按条目ID移除多首歌曲
+function removeSongs(entryIds) {
+    return editPlaylist('RemoveSongs', entryIds);
+}
+
+// 移除重复的歌曲
+function deduplicate() {
+    return editPlaylist('DeduplicateQueue');
+}
+
+// 排序播放列表：name, artist, album, duration, reverse
+function sortQueue(by) {
+    return editPlaylist('SortQueue', by);
+}
+
+// 撤销最近一次修改
+function undo() {
+    return editPlaylist('UndoQueue');
+}
+
+// 绑定右侧播放列表的编辑按钮
+function bindPlaylistEditControls() {
+    const sortSelect = document.getElementById('queueSortSelect');
+    sortSelect?.addEventListener('change', async () => {
+        if (sortSelect.value) {
+            await sortQueue(sortSelect.value);
+            sortSelect.value = '';
+        }
+    });
+    document.getElementById('queueDedupeBtn')?.addEventListener('click', deduplicate);
+    document.getElementById('queueUndoBtn')?.addEventListener('click', undo);
+    document.getElementById('queueExportBtn')?.addEventListener('click', () => {
+        window.playlistsPageManager?.exportPlaylistFile({ source: 'queue', name: currentPlaylist.name || '播放列表' });
+    });
+    document.getElementById('queueRemoveSelectedBtn')?.addEventListener('click', () => {
+        const entryIds = Array.from(document.querySelectorAll('#playlistTab .playlist-item-card.selected'))
+            .map(item => item.dataset.entryId)
+            .filter(Boolean);
+        if (entryIds.length > 0) {
+            removeSongs(entryIds);
+        }
+    });
+}
+
+// 更新编辑按钮的可用状态
+function updatePlaylistEditControls() {
+    const undoBtn = document.getElementById('queueUndoBtn');
+    if (undoBtn) {
+        undoBtn.disabled = !(currentPlaylist.undo_count > 0);
+    }
+    const removeBtn = document.getElementById('queueRemoveSelectedBtn');
+    if (removeBtn) {
+        removeBtn.disabled = document.querySelectorAll('#playlistTab .playlist-item-card.selected').length === 0;
+    }
+}
+
 // 更新播放列表UI显示
 function updatePlaylistUI() {
     // 更新右侧播放列表显示
@@ -391,11 +479,13 @@ function updatePlaylistUI() {
             album_name: song.album_name,
             album_id: song.album_id,
             time_length: song.time_length,
-            union_cover: song.union_cover
+            union_cover: song.union_cover,
+            entry_id: song.entry_id
         }));
 
         window.updateRightSidebarPlaylist(songs, currentPlaylist.current_index, currentPlaylist.name);
     }
+    updatePlaylistEditControls();
 
     // 恢复上次播放的歌曲信息到播放器界面（但不播放）
     restoreLastPlayingSong();
@@ -508,6 +598,7 @@ function updatePlayModeButtons() {
 // 暴露到全局作用域
 window.PlaylistManager = {
     init: initPlaylistManager,
+    reload: loadPlaylistFromCache,
     setPlaylist,
     addToPlaylist,
     getCurrentSong,
@@ -516,6 +607,12 @@ window.PlaylistManager = {
     setCurrentIndex,
     updatePlayMode,
     clearPlaylist,
+    moveSong,
+    removeSongs,
+    deduplicate,
+    sortQueue,
+    undo,
+    updateEditControls: updatePlaylistEditControls,
     hasNext,
     getCurrentPlaylist: () => currentPlaylist,
     isLoaded: () => isPlaylistLoaded
@@ -523,3 +620,4 @@ window.PlaylistManager = {
 
 // 页面加载完成后初始化
 document.addEventListener('DOMContentLoaded', initPlaylistManager);
+document.addEventListener('DOMContentLoaded', bindPlaylistEditControls);
@@ -1,21 +1,141 @@
 // 收藏的歌单页面功能模块
 import { FavoritesService } from "./bindings/wmplayer/index.js";
 
+// 智能歌单条件的字段，type 决定可选的比较方式
+const SMART_FIELDS = {
+    source: { label: '来源', type: 'source' },
+    title: { label: '歌名', type: 'text' },
+    artist: { label: '歌手', type: 'text' },
+    album: { label: '专辑', type: 'text' },
+    genre: { label: '流派', type: 'text' },
+    format: { label: '文件格式', type: 'text' },
+    year: { label: '年份', type: 'number' },
+    duration: { label: '时长(秒)', type: 'number' },
+    play_count: { label: '播放次数', type: 'number' },
+    last_played: { label: '上次播放', type: 'time' },
+    added: { label: '加入音乐库', type: 'time' }
+};
+
+const SMART_OPERATORS = {
+    is: '是', is_not: '不是', contains: '包含', not_contains: '不包含',
+    eq: '等于', ne: '不等于', gt: '大于', gte: '至少', lt: '小于', lte: '至多',
+    in_last: '在最近', not_in_last: '不在最近'
+};
+
+const SMART_TYPE_OPERATORS = {
+    source: ['is', 'is_not'],
+    text: ['is', 'is_not', 'contains', 'not_contains'],
+    number: ['eq', 'ne', 'gt', 'gte', 'lt', 'lte'],
+    time: ['in_last', 'not_in_last']
+};
+
+const SMART_SOURCES = { library: '本地音乐', favorite: '我喜欢', history: '播放历史' };
+
+const SMART_SORTS = {
+    '': '默认',
+    play_count: '播放次数',
+    last_played: '最近播放',
+    added: '最近加入',
+    title: '歌名',
+    artist: '歌手'
+};
+
+// 常用的智能歌单模板
+const SMART_PRESETS = [
+    { name: '最近常听', sort: 'play_count', rules: [{ field: 'play_count', operator: 'gt', value: '5', days: 30 }] },
+    { name: '我喜欢但没听过', rules: [{ field: 'source', operator: 'is', value: 'favorite' }, { field: 'play_count', operator: 'eq', value: '0' }] },
+    { name: '本周新加入', sort: 'added', rules: [{ field: 'added', operator: 'in_last', value: '7' }] },
+    { name: '本地无损', rules: [{ field: 'source', operator: 'is', value: 'library' }, { field: 'format', operator: 'is', value: 'flac' }] },
+    { name: '好久没听', sort: 'play_count', rules: [{ field: 'play_count', operator: 'gte', value: '3' }, { field: 'last_played', operator: 'not_in_last', value: '90' }] }
+];
+
+// 转义插入到HTML中的用户输入
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text ?? '';
+    return div.innerHTML.replace(/"/g, '&quot;');
+}
+
+// 创建一行条件，切换字段时更新可选的比较方式和输入框
+function createSmartRuleRow(rule) {
+    const row = document.createElement('div');
+    row.className = 'smart-rule';
+    row.innerHTML = `
+        <select class="smart-rule-field">
+            ${Object.entries(SMART_FIELDS).map(([value, field]) => `<option value="${value}">${field.label}</option>`).join('')}
+        </select>
+        <input type="number" class="smart-rule-days" min="0" placeholder="最近N天" title="只统计最近几天的播放次数，留空为全部">
+        <select class="smart-rule-operator"></select>
+        <select class="smart-rule-source">
+            ${Object.entries(SMART_SOURCES).map(([value, label]) => `<option value="${value}">${label}</option>`).join('')}
+        </select>
+        <input type="text" class="smart-rule-value">
+        <span class="smart-rule-unit">天</span>
+        <button class="lyrics-picker-btn" data-action="remove-rule" title="删除条件"><i class="fas fa-times"></i></button>
+    `;
+
+    const fieldSelect = row.querySelector('.smart-rule-field');
+    const operatorSelect = row.querySelector('.smart-rule-operator');
+    const sourceSelect = row.querySelector('.smart-rule-source');
+    const valueInput = row.querySelector('.smart-rule-value');
+    const daysInput = row.querySelector('.smart-rule-days');
+
+    const update = () => {
+        const type = SMART_FIELDS[fieldSelect.value].type;
+        const current = operatorSelect.value;
+        operatorSelect.innerHTML = SMART_TYPE_OPERATORS[type]
+            .map(op => `<option value="${op}">${SMART_OPERATORS[op]}</option>`).join('');
+        if (SMART_TYPE_OPERATORS[type].includes(current)) {
+            operatorSelect.value = current;
+        }
+        sourceSelect.style.display = type === 'source' ? '' : 'none';
+        valueInput.style.display = type === 'source' ? 'none' : '';
+        valueInput.type = type === 'text' ? 'text' : 'number';
+        daysInput.style.display = fieldSelect.value === 'play_count' ? '' : 'none';
+        row.querySelector('.smart-rule-unit').style.display = type === 'time' ? '' : 'none';
+    };
+    // 来源使用下拉框，读取时同步到值输入框
+    sourceSelect.addEventListener('change', () => {
+        valueInput.value = sourceSelect.value;
+    });
+    fieldSelect.addEventListener('change', () => {
+        update();
+        if (fieldSelect.value === 'source') {
+            valueInput.value = sourceSelect.value;
+        }
+    });
+
+    fieldSelect.value = rule.field;
+    update();
+    operatorSelect.value = rule.operator;
+    valueInput.value = rule.value ?? '';
+    if (rule.field === 'source') {
+        sourceSelect.value = rule.value;
+    }
+    daysInput.value = rule.days || '';
+    return row;
+}
+
 // 收藏的歌单页面数据管理
 class PlaylistsPageManager {
     constructor() {
         this.data = {
             myPlaylists: [], // 我创建的歌单
-            collectedPlaylists: [] // 我收藏的歌单
+            collectedPlaylists: [], // 我收藏的歌单
+            localPlaylists: [], // 保存在本机的歌单
+            smartPlaylists: [] // 按条件生成的智能歌单
         };
         this.loading = {
             playlists: false
         };
-        this.currentTab = 'created'; // 'created' 或 'collected'
+        this.currentTab = 'created'; // 'created'、'collected'、'local' 或 'smart'
         this.stats = {
             totalCreated: 0,
-            totalCollected: 0
+            totalCollected: 0,
+            totalLocal: 0,
+            totalSmart: 0
         };
+        this.gridEventsBound = false;
     }
 
     // 初始化收藏的歌单页面
@@ -29,9 +149,10 @@ class PlaylistsPageManager {
     bindEvents() {
         // 标签页切换
         const filterTabs = document.querySelectorAll('#playlistsPage .filter-tab');
-        filterTabs.forEach(tab => {
-            tab.addEventListener('click', (e) => {
-                this.switchTab(e.target.textContent.includes('创建') ? 'created' : 'collected');
+        const tabNames = ['created', 'collected', 'local', 'smart'];
+        filterTabs.forEach((tab, index) => {
+            tab.addEventListener('click', () => {
+                this.switchTab(tabNames[index] || 'created');
             });
         });
 
@@ -52,6 +173,14 @@ class PlaylistsPageManager {
                 this.showCreatePlaylistDialog();
             });
         }
+
+        // 导入播放列表文件
+        const importBtn = document.querySelector('#playlistsPage .playlist-import-btn');
+        if (importBtn) {
+            importBtn.addEventListener('click', () => {
+                this.importPlaylistFile();
+            });
+        }
     }
 
     // 加载歌单
@@ -66,6 +195,10 @@ class PlaylistsPageManager {
         this.loading.playlists = true;
         this.showLoadingState();
 
+        // 本地歌单和智能歌单不依赖登录和网络
+        await this.loadLocalPlaylists();
+        await this.loadSmartPlaylists();
+
         try {
             const response = await FavoritesService.GetUserPlaylists();
             console.log('用户歌单API响应:', response);
@@ -80,20 +213,64 @@ class PlaylistsPageManager {
                 console.log('✅ 用户歌单加载成功，我创建的:', this.data.myPlaylists.length, '个，我收藏的:', this.data.collectedPlaylists.length, '个');
             } else {
                 console.error('❌ 用户歌单加载失败:', response.message);
-                this.showErrorState(response.message || '加载失败');
+                this.showOnlineError(response.message || '加载失败');
             }
         } catch (error) {
             console.error('❌ 用户歌单加载异常:', error);
-            this.showErrorState('网络错误，请稍后重试');
+            this.showOnlineError('网络错误，请稍后重试');
         } finally {
             this.loading.playlists = false;
         }
     }
 
+    // 在线歌单加载失败时，本地歌单页仍然正常显示
+    showOnlineError(message) {
+        this.updateStats();
+        if (this.currentTab === 'local' || this.currentTab === 'smart') {
+            this.renderPlaylists();
+        } else {
+            this.showErrorState(message);
+        }
+    }
+
+    // 加载本地歌单
+    async loadLocalPlaylists() {
+        try {
+            const { GetLocalPlaylists } = await import('./bindings/wmplayer/localplaylistservice.js');
+            const response = await GetLocalPlaylists();
+            if (response && response.success) {
+                this.data.localPlaylists = response.data || [];
+                console.log('✅ 本地歌单加载成功:', this.data.localPlaylists.length, '个');
+            } else {
+                console.warn('⚠️ 本地歌单加载失败:', response?.message);
+            }
+        } catch (error) {
+            console.error('❌ 本地歌单加载异常:', error);
+        }
+    }
+
+    // 加载智能歌单
+    async loadSmartPlaylists() {
+        try {
+            const { GetSmartPlaylists } = await import('./bindings/wmplayer/smartplaylistservice.js');
+            const response = await GetSmartPlaylists();
+            if (response && response.success) {
+                this.data.smartPlaylists = response.data || [];
+                console.log('✅ 智能歌单加载成功:', this.data.smartPlaylists.length, '个');
+            } else {
+                console.warn('⚠️ 智能歌单加载失败:', response?.message);
+            }
+        } catch (error) {
+            console.error('❌ 智能歌单加载异常:', error);
+        }
+    }
+
     // 更新统计信息
     updateStats() {
         this.stats.totalCreated = this.data.myPlaylists.length;
         this.stats.totalCollected = this.data.collectedPlaylists.length;
+        this.stats.totalLocal = this.data.localPlaylists.length;
+        this.stats.totalSmart = this.data.smartPlaylists.length;
 
         // 更新标签页显示
         const tabs = document.querySelectorAll('#playlistsPage .filter-tab');
@@ -101,6 +278,12 @@ class PlaylistsPageManager {
             tabs[0].textContent = `我创建的 (${this.stats.totalCreated})`;
             tabs[1].textContent = `我收藏的 (${this.stats.totalCollected})`;
         }
+        if (tabs.length >= 3) {
+            tabs[2].textContent = `本地歌单 (${this.stats.totalLocal})`;
+        }
+        if (tabs.length >= 4) {
+            tabs[3].textContent = `智能歌单 (${this.stats.totalSmart})`;
+        }
     }
 
     // 切换标签页
@@ -109,11 +292,9 @@ class PlaylistsPageManager {
         
         // 更新标签页样式
         const tabs = document.querySelectorAll('#playlistsPage .filter-tab');
+        const tabNames = ['created', 'collected', 'local', 'smart'];
         tabs.forEach((tabElement, index) => {
-            tabElement.classList.remove('active');
-            if ((tab === 'created' && index === 0) || (tab === 'collected' && index === 1)) {
-                tabElement.classList.add('active');
-            }
+            tabElement.classList.toggle('active', tabNames[index] === tab);
         });
 
         // 重新渲染歌单
@@ -128,6 +309,15 @@ class PlaylistsPageManager {
             return;
         }
 
+        if (this.currentTab === 'local') {
+            this.renderLocalPlaylists(container);
+            return;
+        }
+        if (this.currentTab === 'smart') {
+            this.renderSmartPlaylists(container);
+            return;
+        }
+
         const currentPlaylists = this.currentTab === 'created' ? this.data.myPlaylists : this.data.collectedPlaylists;
 
         if (currentPlaylists.length === 0) {
@@ -186,13 +376,403 @@ class PlaylistsPageManager {
         this.bindPlaylistEvents();
     }
 
-    // 绑定歌单项事件
+    // 渲染本地歌单列表
+    renderLocalPlaylists(container) {
+        const playlists = this.data.localPlaylists;
+        if (playlists.length === 0) {
+            container.innerHTML = `
+                <div class="empty-state">
+                    <div class="empty-icon">
+                        <i class="fas fa-list-music"></i>
+                    </div>
+                    <div class="empty-text">还没有本地歌单</div>
+                    <div class="empty-subtext">点击上方按钮把当前播放列表保存为本地歌单</div>
+                </div>
+            `;
+            return;
+        }
+
+        container.innerHTML = playlists.map(playlist => {
+            const coverUrl = playlist.union_cover ? playlist.union_cover.replace('{size}', '200') : '';
+            const updateTime = new Date(playlist.update_time).toLocaleDateString();
+
+            return `
+                <div class="new-album-item playlist-item${playlist.active ? ' active' : ''}" data-local-id="${playlist.id}">
+                    <div class="album-cover">
+                        ${coverUrl ?
+                            `<img src="${coverUrl}" alt="${playlist.name}" onerror="this.style.display='none'; this.nextElementSibling.style.display='flex';">
+                             <div class="cover-placeholder" style="display: none;">
+                                <i class="fas fa-list-music"></i>
+                             </div>` :
+                            `<div class="cover-placeholder">
+                                <i class="fas fa-list-music"></i>
+                             </div>`
+                        }
+                        <div class="album-overlay">
+                            <button class="play-album-btn" title="${playlist.active ? '正在播放' : '从上次的位置继续播放'}">
+                                <i class="fas fa-play"></i>
+                            </button>
+                        </div>
+                    </div>
+                    <div class="album-info">
+                        <div class="album-title">${playlist.name}</div>
+                        <div class="album-meta">
+                            <span class="album-count">${playlist.count}首</span>
+                            <span class="album-date">${updateTime}</span>
+                        </div>
+                        <div class="local-playlist-actions">
+                            <button data-action="rename" title="重命名"><i class="fas fa-edit"></i></button>
+                            <button data-action="duplicate" title="复制"><i class="fas fa-copy"></i></button>
+                            <button data-action="export" title="导出为 M3U8/PLS/XSPF"><i class="fas fa-file-export"></i></button>
+                            <button data-action="delete" title="删除"><i class="fas fa-trash"></i></button>
+                        </div>
+                    </div>
+                </div>
+            `;
+        }).join('');
+
+        this.bindPlaylistEvents();
+    }
+
+    // 本地歌单的播放和管理操作
+    async handleLocalPlaylistAction(id, action) {
+        const playlist = this.data.localPlaylists.find(p => p.id === id);
+        if (!playlist) return;
+
+        try {
+            const service = await import('./bindings/wmplayer/localplaylistservice.js');
+            let response;
+            switch (action) {
+                case 'play':
+                    response = await service.SwitchLocalPlaylist(id);
+                    if (response?.success && window.PlaylistManager && window.PlayerController) {
+                        await window.PlaylistManager.reload();
+                        await window.PlayerController.playCurrentSong();
+                    }
+                    break;
+                case 'rename': {
+                    const name = window.prompt('歌单名称', playlist.name);
+                    if (!name || name === playlist.name) return;
+                    response = await service.RenameLocalPlaylist(id, name);
+                    break;
+                }
+                case 'duplicate':
+                    response = await service.DuplicateLocalPlaylist(id, '');
+                    break;
+                case 'export':
+                    await this.exportPlaylistFile({ source: 'local', id, name: playlist.name });
+                    return;
+                case 'delete':
+                    if (!window.confirm(`删除本地歌单「${playlist.name}」？`)) return;
+                    response = await service.DeleteLocalPlaylist(id);
+                    if (response?.success && playlist.active && window.PlaylistManager) {
+                        await window.PlaylistManager.reload();
+                    }
+                    break;
+                default:
+                    return;
+            }
+
+            if (!response?.success) {
+                this.showToast(response?.message || '操作失败', 'error');
+                return;
+            }
+            await this.loadLocalPlaylists();
+            this.updateStats();
+            this.renderPlaylists();
+        } catch (error) {
+            console.error('❌ 本地歌单操作失败:', error);
+            this.showToast('操作失败: ' + error.message, 'error');
+        }
+    }
+
+    // 渲染智能歌单列表
+    renderSmartPlaylists(container) {
+        const playlists = this.data.smartPlaylists;
+        const createCard = `
+            <div class="new-album-item playlist-item smart-playlist-create" data-smart-action="create">
+                <div class="album-cover">
+                    <div class="cover-placeholder"><i class="fas fa-plus"></i></div>
+                </div>
+                <div class="album-info">
+                    <div class="album-title">新建智能歌单</div>
+                    <div class="album-meta"><span>按播放历史、我喜欢和本地音乐自动生成</span></div>
+                </div>
+            </div>
+        `;
+
+        container.innerHTML = createCard + playlists.map(playlist => {
+            const refreshed = playlist.refresh_time && !playlist.refresh_time.startsWith('0001')
+                ? `${playlist.count}首 · ${new Date(playlist.refresh_time).toLocaleDateString()}刷新`
+                : '尚未刷新';
+
+            return `
+                <div class="new-album-item playlist-item" data-smart-id="${playlist.id}">
+                    <div class="album-cover">
+                        <div class="cover-placeholder">
+                            <i class="fas fa-magic"></i>
+                        </div>
+                        <div class="album-overlay">
+                            <button class="play-album-btn" title="刷新并播放">
+                                <i class="fas fa-play"></i>
+                            </button>
+                        </div>
+                    </div>
+                    <div class="album-info">
+                        <div class="album-title">${escapeHtml(playlist.name)}</div>
+                        <div class="album-author_name">${this.describeSmartRules(playlist)}</div>
+                        <div class="album-meta">
+                            <span class="album-count">${refreshed}</span>
+                        </div>
+                        <div class="local-playlist-actions">
+                            <button data-action="refresh" title="刷新"><i class="fas fa-sync-alt"></i></button>
+                            <button data-action="edit" title="编辑条件"><i class="fas fa-edit"></i></button>
+                            <button data-action="delete" title="删除"><i class="fas fa-trash"></i></button>
+                        </div>
+                    </div>
+                </div>
+            `;
+        }).join('');
+
+        this.bindPlaylistEvents();
+    }
+
+    // 智能歌单条件的简短描述
+    describeSmartRules(playlist) {
+        const text = playlist.rules.map(rule => {
+            const field = SMART_FIELDS[rule.field]?.label || rule.field;
+            const op = SMART_OPERATORS[rule.operator] || rule.operator;
+            const value = rule.field === 'source' ? (SMART_SOURCES[rule.value] || rule.value) : rule.value;
+            const days = rule.days ? `（最近${rule.days}天）` : '';
+            const unit = SMART_FIELDS[rule.field]?.type === 'time' ? '天' : '';
+            return `${field}${days}${op}${value}${unit}`;
+        }).join(playlist.match === 'any' ? ' 或 ' : ' 且 ');
+        return escapeHtml(text);
+    }
+
+    // 智能歌单的播放和管理操作
+    async handleSmartPlaylistAction(id, action) {
+        const playlist = this.data.smartPlaylists.find(p => p.id === id);
+        if (!playlist) return;
+
+        try {
+            const service = await import('./bindings/wmplayer/smartplaylistservice.js');
+            let response;
+            switch (action) {
+                case 'play':
+                    response = await service.PlaySmartPlaylist(id);
+                    if (response?.success && window.PlaylistManager && window.PlayerController) {
+                        await window.PlaylistManager.reload();
+                        await window.PlayerController.playCurrentSong();
+                    }
+                    break;
+                case 'refresh':
+                    response = await service.RefreshSmartPlaylist(id);
+                    break;
+                case 'edit':
+                    this.showSmartPlaylistEditor(playlist);
+                    return;
+                case 'delete':
+                    if (!window.confirm(`删除智能歌单「${playlist.name}」？`)) return;
+                    response = await service.DeleteSmartPlaylist(id);
+                    break;
+                default:
+                    return;
+            }
+
+            if (!response?.success) {
+                this.showToast(response?.message || '操作失败', 'error');
+                return;
+            }
+            if (action === 'refresh') {
+                this.showToast(response.message);
+            }
+            await this.loadSmartPlaylists();
+            this.updateStats();
+            this.renderPlaylists();
+        } catch (error) {
+            console.error('❌ 智能歌单操作失败:', error);
+            this.showToast('操作失败: ' + error.message, 'error');
+        }
+    }
+
+    // 智能歌单条件编辑弹窗，playlist 为空时新建
+    showSmartPlaylistEditor(playlist = null) {
+        document.querySelector('.smart-playlist-overlay')?.remove();
+
+        const modal = document.createElement('div');
+        modal.className = 'lyrics-modal-overlay smart-playlist-overlay';
+        modal.innerHTML = `
+            <div class="lyrics-modal smart-playlist-editor">
+                <div class="lyrics-modal-header">
+                    <div class="lyrics-song-info">
+                        <h3 class="lyrics-song-title">${playlist ? '编辑智能歌单' : '新建智能歌单'}</h3>
+                    </div>
+                    <button class="lyrics-modal-close" data-action="close">
+                        <i class="fas fa-times"></i>
+                    </button>
+                </div>
+                <div class="smart-editor-body">
+                    <div class="smart-editor-row">
+                        <input type="text" class="smart-name" placeholder="歌单名称" value="${escapeHtml(playlist?.name || '')}">
+                        <select class="smart-preset">
+                            <option value="">使用模板…</option>
+                            ${SMART_PRESETS.map((preset, index) => `<option value="${index}">${preset.name}</option>`).join('')}
+                        </select>
+                    </div>
+                    <div class="smart-editor-row">
+                        <span>满足</span>
+                        <select class="smart-match">
+                            <option value="all">全部条件</option>
+                            <option value="any">任一条件</option>
+                        </select>
+                    </div>
+                    <div class="smart-rules"></div>
+                    <button class="lyrics-picker-btn" data-action="add-rule"><i class="fas fa-plus"></i> 添加条件</button>
+                    <div class="smart-editor-row">
+                        <span>排序</span>
+                        <select class="smart-sort">
+                            ${Object.entries(SMART_SORTS).map(([value, label]) => `<option value="${value}">${label}</option>`).join('')}
+                        </select>
+                        <span>最多</span>
+                        <input type="number" class="smart-limit" min="0" placeholder="不限">
+                        <span>首</span>
+                    </div>
+                    <div class="smart-preview-result"></div>
+                </div>
+                <div class="smart-editor-footer">
+                    <button class="lyrics-picker-btn" data-action="preview">预览</button>
+                    <button class="lyrics-picker-btn primary" data-action="save">保存</button>
+                </div>
+            </div>
+        `;
+        document.body.appendChild(modal);
+
+        const rulesContainer = modal.querySelector('.smart-rules');
+        const fillForm = (request) => {
+            modal.querySelector('.smart-match').value = request.match || 'all';
+            modal.querySelector('.smart-sort').value = request.sort || '';
+            modal.querySelector('.smart-limit').value = request.limit || '';
+            rulesContainer.innerHTML = '';
+            (request.rules || []).forEach(rule => rulesContainer.appendChild(createSmartRuleRow(rule)));
+        };
+        const readForm = () => ({
+            name: modal.querySelector('.smart-name').value,
+            match: modal.querySelector('.smart-match').value,
+            sort: modal.querySelector('.smart-sort').value,
+            limit: parseInt(modal.querySelector('.smart-limit').value) || 0,
+            rules: Array.from(rulesContainer.querySelectorAll('.smart-rule')).map(row => ({
+                field: row.querySelector('.smart-rule-field').value,
+                operator: row.querySelector('.smart-rule-operator').value,
+                value: row.querySelector('.smart-rule-value').value,
+                days: parseInt(row.querySelector('.smart-rule-days').value) || 0
+            }))
+        });
+        fillForm(playlist || { rules: [{ field: 'play_count', operator: 'gt', value: '5', days: 30 }] });
+
+        modal.querySelector('.smart-preset').addEventListener('change', (e) => {
+            const preset = SMART_PRESETS[e.target.value];
+            if (!preset) return;
+            const nameInput = modal.querySelector('.smart-name');
+            if (!nameInput.value) {
+                nameInput.value = preset.name;
+            }
+            fillForm(preset);
+        });
+
+        modal.addEventListener('click', async (e) => {
+            if (e.target === modal) {
+                modal.remove();
+                return;
+            }
+            const actionEl = e.target.closest('[data-action]');
+            if (!actionEl) return;
+
+            const service = await import('./bindings/wmplayer/smartplaylistservice.js');
+            switch (actionEl.dataset.action) {
+                case 'close':
+                    modal.remove();
+                    break;
+                case 'add-rule':
+                    rulesContainer.appendChild(createSmartRuleRow({ field: 'artist', operator: 'contains', value: '' }));
+                    break;
+                case 'remove-rule':
+                    actionEl.closest('.smart-rule').remove();
+                    break;
+                case 'preview': {
+                    const request = readForm();
+                    request.name = request.name || '预览';
+                    const response = await service.PreviewSmartPlaylist(request);
+                    const result = modal.querySelector('.smart-preview-result');
+                    if (!response?.success) {
+                        result.textContent = response?.message || '预览失败';
+                        return;
+                    }
+                    const names = response.data.songs.slice(0, 5)
+                        .map(song => [song.author_name, song.songname].filter(Boolean).join(' - '));
+                    result.textContent = `${response.message}${names.length ? '：' + names.join('，') : ''}${response.data.songs.length > 5 ? '……' : ''}`;
+                    break;
+                }
+                case 'save': {
+                    const request = readForm();
+                    const response = playlist
+                        ? await service.UpdateSmartPlaylist(playlist.id, request)
+                        : await service.CreateSmartPlaylist(request);
+                    if (!response?.success) {
+                        this.showToast(response?.message || '保存失败', 'error');
+                        return;
+                    }
+                    modal.remove();
+                    this.showToast(`已保存智能歌单「${response.data.name}」`);
+                    await this.handleSmartPlaylistRefresh(response.data.id);
+                    break;
+                }
+            }
+        });
+    }
+
+    // 保存条件后立即刷新一次，显示歌曲数量
+    async handleSmartPlaylistRefresh(id) {
+        await this.loadSmartPlaylists();
+        await this.handleSmartPlaylistAction(id, 'refresh');
+    }
+
+    // 绑定歌单项事件，容器只绑定一次，重新渲染时不会重复触发
     bindPlaylistEvents() {
         const container = document.querySelector('#playlistsPage .playlists-grid');
-        if (!container) return;
+        if (!container || this.gridEventsBound) return;
+        this.gridEventsBound = true;
 
         // 按钮事件
         container.addEventListener('click', (e) => {
+            // 智能歌单
+            if (e.target.closest('[data-smart-action="create"]')) {
+                this.showSmartPlaylistEditor();
+                return;
+            }
+            const smartCard = e.target.closest('.playlist-item[data-smart-id]');
+            if (smartCard) {
+                const actionButton = e.target.closest('[data-action]');
+                if (actionButton) {
+                    this.handleSmartPlaylistAction(smartCard.dataset.smartId, actionButton.dataset.action);
+                } else if (e.target.closest('.play-album-btn')) {
+                    this.handleSmartPlaylistAction(smartCard.dataset.smartId, 'play');
+                }
+                return;
+            }
+
+            // 本地歌单
+            const localCard = e.target.closest('.playlist-item[data-local-id]');
+            if (localCard) {
+                const actionButton = e.target.closest('[data-action]');
+                if (actionButton) {
+                    this.handleLocalPlaylistAction(localCard.dataset.localId, actionButton.dataset.action);
+                } else if (e.target.closest('.play-album-btn')) {
+                    this.handleLocalPlaylistAction(localCard.dataset.localId, 'play');
+                }
+                return;
+            }
+
             // 播放按钮事件
             if (e.target.closest('.play-album-btn')) {
                 e.stopPropagation(); // 阻止事件冒泡
@@ -213,6 +793,21 @@ class PlaylistsPageManager {
         // 双击播放
         container.addEventListener('dblclick', (e) => {
             const playlistCard = e.target.closest('.playlist-item');
+            if (playlistCard?.dataset.smartId) {
+                if (!e.target.closest('[data-action]')) {
+                    this.handleSmartPlaylistAction(playlistCard.dataset.smartId, 'play');
+                }
+                return;
+            }
+            if (playlistCard?.dataset.smartAction) {
+                return;
+            }
+            if (playlistCard?.dataset.localId) {
+                if (!e.target.closest('[data-action]')) {
+                    this.handleLocalPlaylistAction(playlistCard.dataset.localId, 'play');
+                }
+                return;
+            }
             if (playlistCard) {
                 const playlistId = playlistCard.dataset.playlistId;
                 this.playPlaylist(playlistId);
@@ -386,10 +981,83 @@ class PlaylistsPageManager {
         // TODO: 实现右键菜单功能
     }
 
-    // 显示创建歌单对话框
-    showCreatePlaylistDialog() {
+    // 把当前播放列表保存为本地歌单
+    async showCreatePlaylistDialog() {
         console.log('➕ 显示创建歌单对话框');
-        // TODO: 实现创建歌单对话框
+        const current = window.PlaylistManager?.getCurrentPlaylist();
+        const name = window.prompt('本地歌单名称', current?.name || '新建歌单');
+        if (!name) return;
+
+        try {
+            const { CreateLocalPlaylist } = await import('./bindings/wmplayer/localplaylistservice.js');
+            const response = await CreateLocalPlaylist({ name, songs: current?.songs || [] });
+            if (!response?.success) {
+                this.showToast(response?.message || '创建歌单失败', 'error');
+                return;
+            }
+            this.showToast(`已保存本地歌单「${response.data.name}」`);
+            await this.loadLocalPlaylists();
+            this.updateStats();
+            this.switchTab('local');
+        } catch (error) {
+            console.error('❌ 创建本地歌单失败:', error);
+            this.showToast('创建歌单失败: ' + error.message, 'error');
+        }
+    }
+
+    // 导入播放列表文件为本地歌单，列出没有找到的歌曲
+    async importPlaylistFile() {
+        const path = window.prompt('播放列表文件路径（M3U/M3U8、PLS 或 XSPF）');
+        if (!path) return;
+
+        try {
+            const { ImportPlaylistFile } = await import('./bindings/wmplayer/playlistfileservice.js');
+            const response = await ImportPlaylistFile(path.trim(), '');
+            const unmatched = response?.data?.unmatched || [];
+            if (unmatched.length > 0) {
+                console.warn('⚠️ 没有导入的歌曲:', unmatched);
+                const lines = unmatched.slice(0, 10).map(entry =>
+                    `${entry.index + 1}. ${entry.title || entry.location}：${entry.reason}`);
+                if (unmatched.length > 10) {
+                    lines.push(`……还有 ${unmatched.length - 10} 首`);
+                }
+                window.alert(`${response.message}\n\n${lines.join('\n')}`);
+            }
+            if (!response?.success) {
+                this.showToast(response?.message || '导入失败', 'error');
+                return;
+            }
+            this.showToast(response.message);
+            await this.loadLocalPlaylists();
+            this.updateStats();
+            this.switchTab('local');
+        } catch (error) {
+            console.error('❌ 导入播放列表文件失败:', error);
+            this.showToast('导入失败: ' + error.message, 'error');
+        }
+    }
+
+    // 导出播放列表文件，格式由扩展名决定
+    async exportPlaylistFile({ source, id = '', name = '播放列表' }) {
+        const path = window.prompt('导出的文件路径（.m3u8、.pls 或 .xspf）', `${name}.m3u8`);
+        if (!path) return;
+        const relative = window.confirm('使用相对于播放列表文件的路径？\n选择「取消」使用绝对路径');
+
+        try {
+            const { ExportPlaylistFile } = await import('./bindings/wmplayer/playlistfileservice.js');
+            const response = await ExportPlaylistFile({ source, id, path: path.trim(), format: '', relative_paths: relative });
+            if (!response?.success) {
+                this.showToast(response?.message || '导出失败', 'error');
+                return;
+            }
+            if (response.data.missing.length > 0) {
+                console.warn('⚠️ 没有本地文件的歌曲:', response.data.missing);
+            }
+            this.showToast(`${response.message}: ${response.data.path}`);
+        } catch (error) {
+            console.error('❌ 导出播放列表文件失败:', error);
+            this.showToast('导出失败: ' + error.message, 'error');
+        }
     }
 
     // 过滤歌单
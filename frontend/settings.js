@@ -2,6 +2,7 @@
 
 // 导入设置服务
 import * as SettingsService from './bindings/wmplayer/settingsservice.js';
+import * as CacheService from './bindings/wmplayer/cacheservice.js';
 
 // 设置数据存储
 let settingsData = {
@@ -26,7 +27,11 @@ let settingsData = {
         language: 'zh-CN',
         showLyrics: true,
         showSpectrum: false,
-        miniPlayer: false
+        miniPlayer: false,
+        lyricsTranslation: true,
+        lyricsRomanization: false,
+        osdLyricsTranslation: true,
+        osdLyricsRomanization: false
     },
     // 下载设置
     download: {
@@ -55,6 +60,22 @@ let settingsData = {
         closeAction: 'ask', // 'ask', 'minimize', 'exit'
         startMinimized: false,
         autoStart: false
+    },
+    // 网络设置
+    network: {
+        apiBaseUrl: '',
+        fallbackApiUrls: [],
+        offlineMode: false, // 只播放已缓存和本地音乐
+        lanAccess: false // 本地缓存服务默认只允许本机访问
+    },
+    // 缓存设置
+    cache: {
+        maxSizeMB: 2048 // 0 表示不限制
+    },
+    // 本地音乐设置
+    localMusic: {
+        lyricsFolder: '', // 集中存放歌词文件的文件夹
+        onlineLyrics: true // 没有歌词文件时在线搜索
     }
 };
 
@@ -65,8 +86,37 @@ window.initSettingsPage = async () => {
     applyAllSettings();
     renderSettingsPage();
     await loadSettingsPath();
+    await loadCacheStats();
+    await loadCachedSongs('');
 };
 
+// 格式化字节数
+function formatBytes(bytes) {
+    if (bytes >= 1024 * 1024 * 1024) {
+        return (bytes / 1024 / 1024 / 1024).toFixed(1) + ' GB';
+    }
+    return (bytes / 1024 / 1024).toFixed(1) + ' MB';
+}
+
+// 加载缓存占用情况
+async function loadCacheStats() {
+    const usageElement = document.getElementById('cacheUsage');
+    if (!usageElement) return;
+
+    try {
+        const response = await CacheService.GetCacheStats();
+        if (response.success && response.data) {
+            const stats = response.data;
+            const limit = stats.max_size > 0 ? formatBytes(stats.max_size) : '不限制';
+            usageElement.textContent = `已使用 ${formatBytes(stats.total_size)} / ${limit}，共 ${stats.file_count} 首` +
+                (stats.pinned_count > 0 ? `（固定 ${stats.pinned_count} 首，${formatBytes(stats.pinned_size)}）` : '');
+        }
+    } catch (error) {
+        console.error('获取缓存统计失败:', error);
+        usageElement.textContent = '获取缓存信息失败';
+    }
+}
+
 // 加载设置文件路径
 async function loadSettingsPath() {
     try {
@@ -250,7 +300,7 @@ function renderSettingsPage() {
             <div class="settings-item">
                 <div class="settings-item-info">
                     <div class="settings-item-title">流媒体音质</div>
-                    <div class="settings-item-description">在线播放时的音质</div>
+                    <div class="settings-item-description">在线播放时的音质，已缓存的更高音质会直接使用</div>
                 </div>
                 <div class="settings-item-control">
                     <select class="settings-select" onchange="updateSetting('quality.streamingQuality', this.value)">
@@ -312,6 +362,62 @@ function renderSettingsPage() {
                     </label>
                 </div>
             </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">歌词翻译</div>
+                    <div class="settings-item-description">在歌词下方显示翻译（歌词提供时）</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.interface.lyricsTranslation !== false ? 'checked' : ''}
+                               onchange="updateSetting('interface.lyricsTranslation', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">歌词音译</div>
+                    <div class="settings-item-description">在歌词下方显示罗马音等音译（歌词提供时）</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.interface.lyricsRomanization ? 'checked' : ''}
+                               onchange="updateSetting('interface.lyricsRomanization', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">桌面歌词翻译</div>
+                    <div class="settings-item-description">桌面歌词同时显示翻译</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.interface.osdLyricsTranslation !== false ? 'checked' : ''}
+                               onchange="updateSetting('interface.osdLyricsTranslation', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">桌面歌词音译</div>
+                    <div class="settings-item-description">桌面歌词同时显示音译</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.interface.osdLyricsRomanization ? 'checked' : ''}
+                               onchange="updateSetting('interface.osdLyricsRomanization', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
         </div>
 
         <!-- 下载设置 -->
@@ -347,6 +453,133 @@ function renderSettingsPage() {
             </div>
         </div>
 
+        <!-- 本地音乐设置 -->
+        <div class="settings-group">
+            <h3 class="settings-group-title">
+                <i class="fas fa-folder-open"></i>
+                本地音乐
+            </h3>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">歌词文件夹</div>
+                    <div class="settings-item-description">优先使用音频旁的同名 .lrc/.krc 文件，其次在此文件夹中按文件名或“歌手 - 歌名”查找</div>
+                </div>
+                <div class="settings-item-control">
+                    <input type="text" class="settings-input" placeholder="歌词文件夹路径"
+                           value="${escapeHtml(settingsData.localMusic?.lyricsFolder || '').replace(/"/g, '&quot;')}"
+                           onchange="updateSetting('localMusic.lyricsFolder', this.value.trim())">
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">在线搜索歌词</div>
+                    <div class="settings-item-description">没有歌词文件和内嵌歌词时，按歌名、歌手和时长在线搜索并缓存</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.localMusic?.onlineLyrics !== false ? 'checked' : ''}
+                               onchange="updateSetting('localMusic.onlineLyrics', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+        </div>
+
+        <!-- 网络设置 -->
+        <div class="settings-group">
+            <h3 class="settings-group-title">
+                <i class="fas fa-wifi"></i>
+                网络设置
+            </h3>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">离线模式</div>
+                    <div class="settings-item-description">不再访问在线服务，只播放已缓存和本地音乐；后端无法连接时也会自动进入离线状态</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.network?.offlineMode ? 'checked' : ''}
+                               onchange="updateSetting('network.offlineMode', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">允许局域网访问</div>
+                    <div class="settings-item-description">本地缓存服务默认只允许本机访问；开启后局域网设备凭访问令牌也可以连接，重启应用后生效</div>
+                </div>
+                <div class="settings-item-control">
+                    <label class="settings-switch">
+                        <input type="checkbox" ${settingsData.network?.lanAccess ? 'checked' : ''}
+                               onchange="updateSetting('network.lanAccess', this.checked)">
+                        <span class="settings-switch-slider"></span>
+                    </label>
+                </div>
+            </div>
+        </div>
+
+        <!-- 缓存设置 -->
+        <div class="settings-group">
+            <h3 class="settings-group-title">
+                <i class="fas fa-hdd"></i>
+                缓存设置
+            </h3>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">缓存上限</div>
+                    <div class="settings-item-description">超出后自动删除最久未播放的歌曲，已下载和我喜欢的歌曲不会被删除</div>
+                    <div class="settings-path" id="cacheUsage">加载中...</div>
+                </div>
+                <div class="settings-item-control">
+                    <select class="settings-select" onchange="updateSetting('cache.maxSizeMB', parseInt(this.value))">
+                        ${[512, 1024, 2048, 5120, 10240, 0].map(size => `
+                        <option value="${size}" ${settingsData.cache?.maxSizeMB === size ? 'selected' : ''}>${size === 0 ? '不限制' : size >= 1024 ? size / 1024 + ' GB' : size + ' MB'}</option>`).join('')}
+                    </select>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">已缓存歌曲</div>
+                    <div class="settings-item-description">查看和搜索已缓存的歌曲，可导出为“歌手 - 歌名”命名的音频文件（默认导出到下载目录）</div>
+                </div>
+                <div class="settings-item-control">
+                    <input type="text" class="settings-input" placeholder="搜索歌名、歌手、专辑"
+                           oninput="loadCachedSongs(this.value)">
+                    <button class="settings-button" onclick="exportCachedSongs()">导出</button>
+                </div>
+            </div>
+            <div class="settings-cached-list" id="cachedSongList"></div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">清除缓存</div>
+                    <div class="settings-item-description">删除已缓存的歌曲，可选择保留已下载和我喜欢的歌曲</div>
+                </div>
+                <div class="settings-item-control">
+                    <button class="settings-button" onclick="clearAudioCache(true)">保留固定歌曲</button>
+                    <button class="settings-button" onclick="clearAudioCache(false)">全部清除</button>
+                </div>
+            </div>
+
+            <div class="settings-item">
+                <div class="settings-item-info">
+                    <div class="settings-item-title">校验缓存</div>
+                    <div class="settings-item-description">检查缓存文件是否完整，损坏的文件会被隔离并在下次播放时重新下载</div>
+                    <div class="settings-path" id="cacheVerifyStatus"></div>
+                </div>
+                <div class="settings-item-control">
+                    <button class="settings-button" onclick="verifyAudioCache()">开始校验</button>
+                </div>
+            </div>
+        </div>
+
         <!-- 应用行为设置 -->
         <div class="settings-group">
             <h3 class="settings-group-title">
@@ -466,6 +699,8 @@ window.updateSetting = async (path, value) => {
     let obj = settingsData;
 
     for (let i = 0; i < keys.length - 1; i++) {
+        // 旧版本设置文件中可能缺少该分组
+        obj[keys[i]] = obj[keys[i]] || {};
         obj = obj[keys[i]];
     }
 
@@ -520,9 +755,138 @@ function applySetting(path, value) {
                 window.closeAction = value;
             }
             break;
+        case 'cache.maxSizeMB':
+            // 上限变化后可能已淘汰部分缓存
+            loadCacheStats();
+            break;
     }
 }
 
+// 校验音频缓存，校验在后台进行，这里轮询进度
+window.verifyAudioCache = async () => {
+    const statusElement = document.getElementById('cacheVerifyStatus');
+
+    try {
+        const response = await CacheService.VerifyCache();
+        if (!response.success) {
+            if (statusElement) statusElement.textContent = response.message;
+            return;
+        }
+    } catch (error) {
+        console.error('校验缓存失败:', error);
+        if (statusElement) statusElement.textContent = '校验缓存失败';
+        return;
+    }
+
+    const poll = async () => {
+        const response = await CacheService.GetCacheVerifyReport();
+        if (!response.success || !response.data) return;
+
+        const report = response.data;
+        const element = document.getElementById('cacheVerifyStatus');
+        if (report.running) {
+            if (element) element.textContent = `正在校验 ${report.checked} / ${report.total}`;
+            setTimeout(poll, 1000);
+            return;
+        }
+
+        if (element) {
+            element.textContent = report.corrupt.length > 0
+                ? `校验完成，已隔离 ${report.corrupt.length} 个损坏文件`
+                : `校验完成，${report.checked} 个文件均完整`;
+        }
+        await loadCacheStats();
+    };
+    await poll();
+};
+
+// 清除音频缓存
+window.clearAudioCache = async (keepPinned) => {
+    const message = keepPinned
+        ? '确定要清除缓存吗？已下载和我喜欢的歌曲会保留。'
+        : '确定要清除全部缓存吗？';
+    if (!confirm(message)) return;
+
+    try {
+        const response = await CacheService.ClearCache(keepPinned);
+        if (response.success) {
+            console.log('缓存已清除:', response.message);
+            alert(`${response.message}，释放 ${formatBytes(response.data.freed_size)}`);
+        } else {
+            alert('清除缓存失败: ' + response.message);
+        }
+    } catch (error) {
+        console.error('清除缓存失败:', error);
+        alert('清除缓存失败');
+    }
+    await loadCacheStats();
+    await loadCachedSongs('');
+};
+
+// 当前显示的已缓存歌曲，导出时使用
+let cachedSongs = [];
+
+// 音质显示名称
+const cacheQualityLabels = { low: '标准', medium: '高品质', high: '无损' };
+
+// 加载已缓存歌曲列表，keyword 不为空时只显示匹配的歌曲
+window.loadCachedSongs = async (keyword) => {
+    const listElement = document.getElementById('cachedSongList');
+    if (!listElement) return;
+
+    try {
+        const response = await CacheService.ListCachedSongs(keyword || '');
+        if (!response.success) {
+            listElement.textContent = response.message;
+            return;
+        }
+        cachedSongs = response.data || [];
+        if (cachedSongs.length === 0) {
+            listElement.innerHTML = '<div class="settings-cached-empty">没有已缓存的歌曲</div>';
+            return;
+        }
+        listElement.innerHTML = cachedSongs.map(song => {
+            const name = song.songname || song.filename || song.hash;
+            const detail = [song.author_name, song.album_name].filter(Boolean).join(' · ');
+            const quality = cacheQualityLabels[song.quality] || '';
+            return `
+                <div class="settings-cached-song" title="${song.hash}">
+                    <div class="settings-cached-song-name">${escapeHtml(name)}${song.pinned ? ' <i class="fas fa-thumbtack"></i>' : ''}</div>
+                    <div class="settings-cached-song-detail">${escapeHtml(detail)}</div>
+                    <div class="settings-cached-song-size">${quality} ${formatBytes(song.size)}</div>
+                </div>`;
+        }).join('');
+    } catch (error) {
+        console.error('获取已缓存歌曲失败:', error);
+        listElement.textContent = '获取已缓存歌曲失败';
+    }
+};
+
+// 导出当前列表中的歌曲
+window.exportCachedSongs = async () => {
+    if (cachedSongs.length === 0) {
+        alert('没有可导出的歌曲');
+        return;
+    }
+    const destDir = settingsData.download?.downloadPath || '';
+    if (!confirm(`确定要导出 ${cachedSongs.length} 首歌曲${destDir ? '到 ' + destDir : ''}吗？`)) return;
+
+    try {
+        const response = await CacheService.ExportCachedSongs(cachedSongs.map(song => song.hash), destDir);
+        alert(`${response.message}\n${response.data?.directory || ''}`);
+    } catch (error) {
+        console.error('导出缓存歌曲失败:', error);
+        alert('导出缓存歌曲失败');
+    }
+};
+
+// 转义HTML特殊字符
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text || '';
+    return div.innerHTML;
+}
+
 // 选择下载路径
 window.selectDownloadPath = async () => {
     try {
@@ -609,7 +973,11 @@ window.resetSettings = async () => {
                     language: 'zh-CN',
                     showLyrics: true,
                     showSpectrum: false,
-                    miniPlayer: false
+                    miniPlayer: false,
+                    lyricsTranslation: true,
+                    lyricsRomanization: false,
+                    osdLyricsTranslation: true,
+                    osdLyricsRomanization: false
                 },
                 download: {
                     downloadPath: '',
@@ -634,6 +1002,19 @@ window.resetSettings = async () => {
                     closeAction: 'ask',
                     startMinimized: false,
                     autoStart: false
+                },
+                network: {
+                    apiBaseUrl: '',
+                    fallbackApiUrls: [],
+                    offlineMode: false,
+                    lanAccess: false
+                },
+                cache: {
+                    maxSizeMB: 2048
+                },
+                localMusic: {
+                    lyricsFolder: '',
+                    onlineLyrics: true
                 }
             };
 
@@ -73,8 +73,29 @@ class SystrayController {
                 this.handleToggleOSDLyrics();
             });
 
+            // 再次启动应用时转发过来的音乐文件
+            window.Events.On('instance:open-files', (event) => {
+                console.log('🎵 收到打开文件事件:', event);
+                this.handleOpenFiles(event?.data ?? event);
+            });
+
+            // 媒体键、MPRIS或命令行设置音量
+            window.Events.On('mediakey:set-volume', (event) => {
+                console.log('🎵 收到设置音量事件:', event);
+                this.handleSetVolume(event?.data ?? event);
+            });
+
+            // 命令行向播放列表添加了歌曲
+            window.Events.On('instance:playlist-changed', () => {
+                console.log('🎵 收到播放列表变化事件');
+                this.handlePlaylistChanged();
+            });
+
             this.eventListenersInitialized = true;
             console.log('✅ 系统托盘事件监听器注册完成');
+
+            // 通知后端可以处理启动时传入的文件和 --toggle
+            window.Events.Emit('instance:ready');
         } catch (error) {
             console.error('❌ 注册系统托盘事件监听器失败:', error);
         }
@@ -100,6 +121,67 @@ class SystrayController {
         }
     }
 
+    // 处理打开的音乐文件：替换播放列表并从第一首开始播放
+    async handleOpenFiles(files) {
+        // Wails 事件的数据可能被包装在数组中
+        if (Array.isArray(files) && files.length === 1 && Array.isArray(files[0])) {
+            files = files[0];
+        }
+        if (!Array.isArray(files) || files.length === 0) {
+            return;
+        }
+
+        const songs = files.map(file => ({
+            hash: 'local-' + file.hash,
+            songname: file.title || file.filename,
+            filename: file.filename,
+            author_name: file.artist,
+            album_name: file.album_name,
+            time_length: file.time_length,
+            union_cover: file.union_cover || ''
+        }));
+
+        try {
+            if (window.PlayerController && window.PlayerController.playPlaylist) {
+                const success = await window.PlayerController.playPlaylist(songs, 0, '打开的文件');
+                if (success) {
+                    this.showNotification('打开文件', `正在播放: ${songs[0].songname}`);
+                }
+            } else {
+                console.error('❌ PlayerController 不可用');
+                this.showNotification('错误', '播放器不可用');
+            }
+        } catch (error) {
+            console.error('❌ 播放打开的文件失败:', error);
+            this.showNotification('错误', '播放打开的文件失败');
+        }
+    }
+
+    // 处理设置音量（0-100）
+    handleSetVolume(volume) {
+        // Wails 事件的数据可能被包装在数组中
+        if (Array.isArray(volume)) {
+            volume = volume[0];
+        }
+        volume = Number(volume);
+        if (!Number.isFinite(volume)) {
+            return;
+        }
+
+        if (window.UnifiedPlayerController && window.UnifiedPlayerController.setVolume) {
+            window.UnifiedPlayerController.setVolume(volume);
+        } else {
+            console.error('❌ UnifiedPlayerController 不可用');
+        }
+    }
+
+    // 重新加载后端保存的播放列表
+    async handlePlaylistChanged() {
+        if (window.PlaylistManager && window.PlaylistManager.reload) {
+            await window.PlaylistManager.reload();
+        }
+    }
+
     // 处理上一首
     async handlePreviousSong() {
         console.log('🎵 系统托盘: 上一首');
@@ -3,23 +3,25 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"wmplayer/internal/lyrics"
 )
 
 // HomepageService 处理首页相关的服务
 type HomepageService struct {
 	cacheService *CacheService
+	appState     *AppState // 歌词缓存、候选歌词和歌曲元数据
 }
 
 // NewHomepageService 创建新的首页服务实例
-func NewHomepageService(cacheService *CacheService) *HomepageService {
+func NewHomepageService(cacheService *CacheService, appState *AppState) *HomepageService {
 	return &HomepageService{
 		cacheService: cacheService,
+		appState:     appState,
 	}
 }
 
@@ -63,6 +65,10 @@ type LyricsSearchData struct {
 	ID        string `json:"id"`
 	AccessKey string `json:"accesskey"`
 	Score     int    `json:"score"`
+	Song      string `json:"song"`
+	Singer    string `json:"singer"`
+	Duration  int    `json:"duration"` // 歌词对应的歌曲时长，毫秒
+	Source    string `json:"source"`   // 歌词来源，如官方推荐歌词、用户上传
 }
 
 // LyricsSearchResponse 歌词搜索响应结构
@@ -104,11 +110,55 @@ type DailyRecommendResponse = ApiResponse[[]DailyRecommendData]
 // AIRecommendResponse AI推荐歌曲响应结构
 type AIRecommendResponse = ApiResponse[[]AIRecommendData]
 
-// readCookieFromFile 从全局Cookie管理器读取cookie
-func (h *HomepageService) readCookieFromFile() (string, error) {
-	cookie := GlobalCookieManager.GetCookie()
-	// HomepageService 允许没有cookie的情况，返回空字符串而不是错误
-	return cookie, nil
+// recommendSongListData 推荐类接口 data 字段的结构
+type recommendSongListData[T any] struct {
+	SongList apiList[T] `json:"song_list"`
+}
+
+// fmSongItem /personal/fm 接口 data.song_list 数组的元素
+type fmSongItem struct {
+	Hash        apiString      `json:"hash"`
+	SongName    apiString      `json:"songname"`
+	FileName    apiString      `json:"filename"`
+	TimeLength  apiInt         `json:"timelength_320"`
+	AuthorName  apiString      `json:"author_name"`
+	RelateGoods apiRelateGoods `json:"relate_goods"`
+	TransParam  apiTransParam  `json:"trans_param"`
+}
+
+// songUrlPayload /song/url 接口的响应结构，播放地址位于顶层
+type songUrlPayload struct {
+	apiEnvelope
+	URL        apiList[string] `json:"url"`
+	BackupURL  apiList[string] `json:"backupUrl"`
+	TimeLength apiInt          `json:"timeLength"`
+}
+
+// dailyRecommendItem /everyday/recommend 接口 data.song_list 数组的元素
+type dailyRecommendItem struct {
+	Hash          apiString      `json:"hash"`
+	SongName      apiString      `json:"songname"`
+	FileName      *apiString     `json:"filename"`
+	TimeLength    *apiInt        `json:"time_length"`
+	TimeLength320 *apiInt        `json:"timelength_320"`
+	Timelength    *apiInt        `json:"timelength"`
+	AlbumName     apiString      `json:"album_name"`
+	AlbumID       apiString      `json:"album_id"`
+	AuthorName    apiString      `json:"author_name"`
+	SizableCover  apiString      `json:"sizable_cover"`
+	RelateGoods   apiRelateGoods `json:"relate_goods"`
+}
+
+// aiRecommendItem /ai/recommend 接口 data.song_list 数组的元素
+type aiRecommendItem struct {
+	Hash        apiString      `json:"hash"`
+	SongName    apiString      `json:"songname"`
+	FileName    apiString      `json:"filename"`
+	TimeLength  apiInt         `json:"time_length"`
+	AlbumID     apiString      `json:"album_id"`
+	AuthorName  apiString      `json:"author_name"`
+	RelateGoods apiRelateGoods `json:"relate_goods"`
+	TransParam  apiTransParam  `json:"trans_param"`
 }
 
 // GetPersonalFM 获取私人FM歌曲
@@ -121,9 +171,6 @@ func (h *HomepageService) GetPersonalFM(params FmRequestParams) FmResponse {
 		params.Action = "play"
 	}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/personal/fm", baseApi)
-
 	// 构建查询参数
 	queryParams := url.Values{}
 	if params.Hash != "" {
@@ -147,138 +194,42 @@ func (h *HomepageService) GetPersonalFM(params FmRequestParams) FmResponse {
 		queryParams.Add("remain_songcnt", fmt.Sprintf("%d", params.RemainSongCnt))
 	}
 
-	// 添加查询参数到URL
-	if len(queryParams) > 0 {
-		requestURL += "?" + queryParams.Encode()
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:   "/personal/fm",
+		Params: queryParams,
+		Cookie: true,
+	})
+	if err == nil && !envelope.errorCodeIs(0) {
+		err = envelope.failure("获取私人FM失败")
 	}
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return FmResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return FmResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return FmResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return FmResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		// 解析歌曲数据
-		var songs []FmSongData
-		if data, ok := apiResponse["data"].(map[string]any); ok {
-			if songList, ok := data["song_list"].([]any); ok {
-				for _, songItem := range songList {
-					if song, ok := songItem.(map[string]any); ok {
-						songData := FmSongData{}
-
-						// 提取基本信息
-						if hash, ok := song["hash"].(string); ok {
-							songData.Hash = hash
-						}
-						if songname, ok := song["songname"].(string); ok {
-							songData.SongName = songname
-						}
-						if filename, ok := song["filename"].(string); ok {
-							songData.FileName = filename
-						}
-						if timelength, ok := song["timelength_320"].(float64); ok {
-							songData.TimeLength = int(timelength)
-						}
-						if authorName, ok := song["author_name"].(string); ok {
-							songData.AuthorName = authorName
-						}
-
-						// 提取专辑信息
-						if relateGoods, ok := song["relate_goods"].([]any); ok && len(relateGoods) > 0 {
-							if album, ok := relateGoods[0].(map[string]any); ok {
-								if albumname, ok := album["albumname"].(string); ok {
-									songData.AlbumName = albumname
-								}
-							}
-							if len(relateGoods) > 1 {
-								if album, ok := relateGoods[1].(map[string]any); ok {
-									if albumID, ok := album["album_id"].(string); ok {
-										songData.AlbumID = albumID
-									}
-								}
-							}
-						}
-
-						// 提取封面信息
-						if transParam, ok := song["trans_param"].(map[string]any); ok {
-							if unionCover, ok := transParam["union_cover"].(string); ok {
-								songData.UnionCover = unionCover
-							}
-						}
-
-						songs = append(songs, songData)
-					}
-				}
-			}
-		}
-
-		return FmResponse{
-			Success:   true,
-			Message:   "获取私人FM成功",
-			ErrorCode: int(errorCode),
-			Data:      songs,
-		}
-	} else {
-		message := "获取私人FM失败"
-		errorCodeInt := -1
-		statusInt := 0
-
-		// 尝试从响应中获取错误信息
-		if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-			message = msg
-		} else if status, ok := apiResponse["status"].(float64); ok {
-			message = fmt.Sprintf("获取私人FM失败，状态码: %.0f", status)
-			statusInt = int(status)
-		}
-
-		if errorCode, ok := apiResponse["error_code"].(float64); ok {
-			errorCodeInt = int(errorCode)
-		}
-
-		return FmResponse{
-			Success:   false,
-			Message:   message,
-			ErrorCode: errorCodeInt,
-			Status:    statusInt,
-			Data:      []FmSongData{},
-		}
+		response := apiFailure[[]FmSongData](err)
+		response.Data = []FmSongData{}
+		return response
+	}
+
+	// 解析歌曲数据
+	data, _ := decodeApiData[recommendSongListData[fmSongItem]](envelope)
+
+	var songs []FmSongData
+	for _, song := range data.SongList {
+		songs = append(songs, FmSongData{
+			Hash:       song.Hash.String(),
+			SongName:   song.SongName.String(),
+			FileName:   song.FileName.String(),
+			TimeLength: song.TimeLength.Int(),
+			AlbumName:  song.RelateGoods.AlbumName(),
+			AlbumID:    song.RelateGoods.AlbumID(),
+			AuthorName: song.AuthorName.String(),
+			UnionCover: song.TransParam.UnionCover.String(),
+		})
+	}
+
+	return FmResponse{
+		Success:   true,
+		Message:   "获取私人FM成功",
+		ErrorCode: envelope.ErrorCode.Int(),
+		Data:      songs,
 	}
 }
 
@@ -327,341 +278,378 @@ func (h *HomepageService) ReportFMAction(hash, songID string, action string, pla
 	return h.GetPersonalFM(params)
 }
 
-// GetSongUrl 获取歌曲播放地址
-func (h *HomepageService) GetSongUrl(hash string) SongUrlResponse {
-	if hash == "" {
-		return SongUrlResponse{
-			Success: false,
-			Message: "歌曲hash不能为空",
-		}
+// fetchLyrics 在线获取歌曲的KRC和LRC歌词
+// 歌词接口没有这首歌时返回 NotFound 的记录，网络或接口错误时返回错误
+func (h *HomepageService) fetchLyrics(hash string) (*cachedLyrics, error) {
+	if GlobalBackendManager.IsOffline() {
+		return nil, &ApiError{Code: ApiErrOffline, Message: "离线模式下无法获取歌词"}
 	}
 
-	// 🎵 首先检查是否已缓存
-	if h.cacheService != nil {
-		if cachedResponse := h.cacheService.GetCachedURL(hash); cachedResponse.Success {
-			fmt.Printf("✅ 使用缓存的播放地址: %s\n", hash)
-
-			// 获取歌词内容
-			lyricsContent := ""
-			if cookie, err := h.readCookieFromFile(); err == nil {
-				if lyricsData, err := h.searchLyrics(hash, cookie); err == nil {
-					if lyrics, err := h.getLyrics(lyricsData.ID, lyricsData.AccessKey, cookie); err == nil {
-						lyricsContent = lyrics
-					}
-				}
-			}
+	// 用户选择过歌词时直接使用选择的歌词
+	if lyrics, ok := h.fetchChosenLyrics(hash); ok {
+		return lyrics, nil
+	}
 
-			return SongUrlResponse{
-				Success:   true,
-				Message:   "获取缓存播放地址成功",
-				ErrorCode: 0,
-				Data: SongUrlData{
-					URL:       cachedResponse.Data,
-					BackupURL: "",
-					Lyrics:    lyricsContent,
-				},
-			}
+	lyricsData, err := h.searchLyrics(hash)
+	if err != nil {
+		if _, ok := err.(*ApiError); ok {
+			return nil, err
 		}
+		return &cachedLyrics{NotFound: true}, nil
 	}
 
-	// 🎵 如果没有缓存，从API获取播放地址
-	fmt.Printf("🎵 从API获取播放地址: %s\n", hash)
+	lyrics, err := h.downloadLyrics(lyricsData)
+	if err == nil && !lyrics.NotFound {
+		fmt.Printf("✅ 获取到歌词: %s (KRC %d, LRC %d)\n", hash, len(lyrics.KRC), len(lyrics.LRC))
+	}
+	return lyrics, err
+}
 
-	// 读取cookie
-	cookie, err := h.readCookieFromFile()
-	if err != nil {
-		return SongUrlResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
+// downloadLyrics 下载搜索到的歌词的KRC和LRC格式
+// 两种格式都没有时返回 NotFound 的记录，网络或接口错误时返回错误
+func (h *HomepageService) downloadLyrics(lyricsData *LyricsSearchData) (*cachedLyrics, error) {
+	krc, krcErr := h.getLyricsWithFormat(lyricsData.ID, lyricsData.AccessKey, "krc")
+	lrc, lrcErr := h.getLyricsWithFormat(lyricsData.ID, lyricsData.AccessKey, "lrc")
+	if krc == "" && lrc == "" {
+		for _, err := range []error{krcErr, lrcErr} {
+			if _, ok := err.(*ApiError); ok {
+				return nil, err
+			}
 		}
+		return &cachedLyrics{NotFound: true}, nil
 	}
+	return &cachedLyrics{KRC: krc, LRC: lrc, CandidateID: lyricsData.ID}, nil
+}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/song/url", baseApi)
+// songLyrics 获取歌曲歌词，优先使用磁盘缓存
+// 缓存过期时先返回旧歌词并在后台刷新；没有缓存时在后台获取，最多等待 wait
+func (h *HomepageService) songLyrics(hash string, wait time.Duration) string {
+	return h.cachedLyricsContent(hash, h.fetchLyrics, wait)
+}
 
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("hash", hash)
-	queryParams.Add("cookie", cookie)
+// cachedLyricsContent 按key从磁盘缓存获取歌词，缓存过期或不存在时用 fetch 在后台获取
+func (h *HomepageService) cachedLyricsContent(key string, fetch func(key string) (*cachedLyrics, error), wait time.Duration) string {
+	if h.appState == nil {
+		lyrics, err := fetch(key)
+		if err != nil {
+			return ""
+		}
+		return lyrics.content()
+	}
 
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
+	lyricsCache := h.appState.lyricsCache
+	cached := lyricsCache.Get(key)
+	if cached != nil && (cached.fresh() || GlobalBackendManager.IsOffline()) {
+		return cached.content()
+	}
+	if GlobalBackendManager.IsOffline() {
+		return ""
+	}
 
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+	task := lyricsCache.Fetch(key, fetch)
+	if cached != nil {
+		return cached.content()
 	}
+	return task.wait(wait)
+}
 
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return SongUrlResponse{
+// GetSongLyrics 获取歌曲歌词，获取播放地址时未能及时返回的歌词可通过此接口获取
+func (h *HomepageService) GetSongLyrics(hash string) ApiResponse[string] {
+	if hash == "" {
+		return ApiResponse[string]{
 			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
+			Message: "歌曲hash不能为空",
 		}
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return SongUrlResponse{
+	content := h.songLyrics(hash, lyricsFetchTimeout)
+	if content == "" {
+		return ApiResponse[string]{
 			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
+			Message: "未找到歌词",
 		}
 	}
+	return ApiResponse[string]{
+		Success: true,
+		Message: "获取歌词成功",
+		Data:    content,
+	}
+}
 
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return SongUrlResponse{
+// GetLyricsTimeline 获取解析后的歌词时间轴，前端无需再自己解析KRC/LRC
+func (h *HomepageService) GetLyricsTimeline(hash string) ApiResponse[lyrics.Timeline] {
+	if hash == "" {
+		return ApiResponse[lyrics.Timeline]{
 			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
+			Message: "歌曲hash不能为空",
 		}
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SongUrlResponse{
+	content := h.songLyrics(hash, lyricsFetchTimeout)
+	if content == "" {
+		return ApiResponse[lyrics.Timeline]{
 			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
+			Message: "未找到歌词",
 		}
 	}
+	return ApiResponse[lyrics.Timeline]{
+		Success: true,
+		Message: "获取歌词时间轴成功",
+		Data:    h.lyricsTimeline(content, hash, ""),
+	}
+}
 
-	// 简化日志记录
-	fmt.Printf("🎵 GetSongUrl API调用成功\n")
-
-	// 收集所有播放地址用于缓存
-	var remoteUrls []string
-
-	// 从$.url数组获取主播放地址
-	if urlArray, ok := apiResponse["url"].([]any); ok && len(urlArray) > 0 {
-		for _, urlItem := range urlArray {
-			if urlStr, ok := urlItem.(string); ok && urlStr != "" {
-				remoteUrls = append(remoteUrls, urlStr)
-			}
-		}
+// ParseLyrics 解析歌词文本为时间轴，用于已经拿到歌词内容的页面（包括本地音乐的歌词）
+// 传入歌曲hash或本地文件路径时应用用户保存的歌词偏移
+func (h *HomepageService) ParseLyrics(content string, hash string, filePath string) ApiResponse[lyrics.Timeline] {
+	return ApiResponse[lyrics.Timeline]{
+		Success: true,
+		Message: "解析歌词成功",
+		Data:    h.lyricsTimeline(content, hash, filePath),
 	}
+}
 
-	// 从$.backupUrl数组获取备用播放地址
-	if backupUrlArray, ok := apiResponse["backupUrl"].([]any); ok && len(backupUrlArray) > 0 {
-		for _, backupUrlItem := range backupUrlArray {
-			if backupUrlStr, ok := backupUrlItem.(string); ok && backupUrlStr != "" {
-				remoteUrls = append(remoteUrls, backupUrlStr)
-			}
-		}
+// lyricsTimeline 解析歌词并应用用户保存的偏移
+func (h *HomepageService) lyricsTimeline(content string, hash string, filePath string) lyrics.Timeline {
+	timeline := lyrics.Parse(content)
+	if h.cacheService != nil {
+		timeline = timeline.WithOffset(h.cacheService.lyricsOffset(hash, filePath))
 	}
+	return timeline
+}
 
-	// 获取歌词内容
-	lyricsContent := ""
-	if lyricsData, err := h.searchLyrics(hash, cookie); err == nil {
-		if lyrics, err := h.getLyrics(lyricsData.ID, lyricsData.AccessKey, cookie); err == nil {
-			lyricsContent = lyrics
+// GetSongUrl 获取歌曲播放地址
+// 离线时只使用已缓存的文件，不请求后端
+func (h *HomepageService) GetSongUrl(hash string) SongUrlResponse {
+	if hash == "" {
+		return SongUrlResponse{
+			Success: false,
+			Message: "歌曲hash不能为空",
 		}
 	}
 
-	// 如果获取到播放地址，尝试缓存并返回本地地址
-	if len(remoteUrls) > 0 {
-
-		fmt.Printf("✅ 获取到 %d 个播放地址\n", len(remoteUrls))
-
-		// 🎵 同步缓存音频文件，确保立即返回本地URL
-		go func() {
-			if h.cacheService != nil {
-				fmt.Printf("🎵 开始同步缓存音频文件: %s\n", hash)
-				cacheResponse := h.cacheService.CacheAudioFile(hash, remoteUrls)
-				if cacheResponse.Success {
-					fmt.Printf("✅ 音频文件缓存成功: %s -> %s\n", hash, cacheResponse.Data)
-
-				} else {
-					fmt.Printf("❌ 音频文件缓存失败: %s, 错误: %s\n", hash, cacheResponse.Message)
+	// 🎵 首先检查是否已缓存
+	if h.cacheService != nil {
+		if cachedResponse := h.cacheService.GetCachedURL(hash); cachedResponse.Success {
+			fmt.Printf("✅ 使用缓存的播放地址: %s\n", hash)
 
-				}
+			return SongUrlResponse{
+				Success:   true,
+				Message:   "获取缓存播放地址成功",
+				ErrorCode: 0,
+				Data: SongUrlData{
+					URL:       cachedResponse.Data,
+					BackupURL: "",
+					Lyrics:    h.songLyrics(hash, lyricsWaitTimeout),
+				},
 			}
-		}()
-
-		return SongUrlResponse{
-			Success:   true,
-			Message:   "获取播放地址成功",
-			ErrorCode: 0,
-			Data: SongUrlData{
-				URL: remoteUrls[0],
-				BackupURL: func() string {
-					if len(remoteUrls) > 1 {
-						return remoteUrls[1]
-					}
-					return remoteUrls[0]
-				}(),
-				Lyrics: lyricsContent,
-			},
 		}
 	}
 
-	// 如果没有获取到播放地址，返回失败
-	{
-		message := "获取播放地址失败"
-		errorCodeInt := -1
-		statusInt := 0
-
-		// 尝试从响应中获取错误信息
-		if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-			message = msg
-		} else if status, ok := apiResponse["status"].(float64); ok {
-			message = fmt.Sprintf("获取播放地址失败，状态码: %.0f", status)
-			statusInt = int(status)
+	// ✈️ 离线时退而使用任意音质的缓存
+	if GlobalBackendManager.IsOffline() {
+		if response, ok := h.lowerQualityCachedUrl(hash); ok {
+			return response
 		}
-
-		if errorCode, ok := apiResponse["error_code"].(float64); ok {
-			errorCodeInt = int(errorCode)
-		}
-
 		return SongUrlResponse{
 			Success:   false,
-			Message:   message,
-			ErrorCode: errorCodeInt,
-			Status:    statusInt,
-			Data:      SongUrlData{},
+			Message:   "离线模式下只能播放已缓存的歌曲",
+			ErrorCode: ApiErrOffline,
 		}
 	}
-}
 
-// searchLyrics 搜索歌词
-func (h *HomepageService) searchLyrics(hash string, cookie string) (*LyricsSearchData, error) {
-	if hash == "" {
-		return nil, fmt.Errorf("歌曲hash不能为空")
+	// 🎵 如果没有缓存，按设置的音质从API获取播放地址
+	quality := defaultStreamingQuality
+	if h.cacheService != nil {
+		quality = h.cacheService.StreamingQuality()
 	}
+	fmt.Printf("🎵 从API获取播放地址: %s (音质: %s)\n", hash, quality)
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search/lyric", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("hash", hash)
-	queryParams.Add("cookie", cookie)
-	queryParams.Add("man", "no") // 只返回一个歌词
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	// 歌词与播放地址同时获取
+	lyricsResult := make(chan string, 1)
+	go func() {
+		lyricsResult <- h.songLyrics(hash, lyricsWaitTimeout)
+	}()
 
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	body, _, err := defaultApiClient.Get(apiRequest{
+		Path:   "/song/url",
+		Params: url.Values{"hash": {hash}, "quality": {audioQualityParam(quality)}},
+		Cookie: true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %v", err)
+		if response, ok := h.lowerQualityCachedUrl(hash); ok {
+			return response
+		}
+		return apiFailure[SongUrlData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %v", err)
+	// 播放地址与错误信息都位于响应顶层
+	var payload songUrlPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return SongUrlResponse{
+			Success:   false,
+			Message:   fmt.Sprintf("解析响应失败: %v", err),
+			ErrorCode: ApiErrDecode,
+		}
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %v", err)
+	// 收集所有播放地址用于缓存，主地址在前，备用地址在后
+	var remoteUrls []string
+	for _, urlStr := range append(payload.URL, payload.BackupURL...) {
+		if urlStr != "" {
+			remoteUrls = append(remoteUrls, urlStr)
+		}
 	}
 
-	// 检查是否有candidates数组
-	if candidates, ok := apiResponse["candidates"].([]interface{}); ok && len(candidates) > 0 {
-		if candidate, ok := candidates[0].(map[string]interface{}); ok {
-			lyricsData := &LyricsSearchData{}
+	// 如果没有获取到播放地址，返回失败
+	if len(remoteUrls) == 0 {
+		if response, ok := h.lowerQualityCachedUrl(hash); ok {
+			return response
+		}
+		return apiFailure[SongUrlData](payload.failure("获取播放地址失败"))
+	}
 
-			if id, ok := candidate["id"].(string); ok {
-				lyricsData.ID = id
-			}
+	fmt.Printf("✅ 获取到 %d 个播放地址\n", len(remoteUrls))
+	if h.appState != nil {
+		h.appState.metadata.Update(SongMetadata{Hash: hash, TimeLength: payload.TimeLength.Int()})
+	}
 
-			if accesskey, ok := candidate["accesskey"].(string); ok {
-				lyricsData.AccessKey = accesskey
-			}
+	lyricsContent := <-lyricsResult
 
-			if score, ok := candidate["score"].(float64); ok {
-				lyricsData.Score = int(score)
-			}
+	backupURL := remoteUrls[0]
+	if len(remoteUrls) > 1 {
+		backupURL = remoteUrls[1]
+	}
 
-			return lyricsData, nil
+	// 🎵 边下边播：返回本地服务地址，远程地址作为备用
+	playURL := remoteUrls[0]
+	if h.cacheService != nil {
+		if streamResponse := h.cacheService.StreamAudioFile(hash, quality, remoteUrls); streamResponse.Success {
+			fmt.Printf("🎵 边下边播: %s -> %s\n", hash, streamResponse.Data)
+			playURL = streamResponse.Data
+			backupURL = remoteUrls[0]
+		} else {
+			fmt.Printf("❌ 音频文件缓存失败: %s, 错误: %s\n", hash, streamResponse.Message)
 		}
 	}
 
-	return nil, fmt.Errorf("未找到歌词信息")
+	return SongUrlResponse{
+		Success:   true,
+		Message:   "获取播放地址成功",
+		ErrorCode: 0,
+		Data: SongUrlData{
+			URL:       playURL,
+			BackupURL: backupURL,
+			Lyrics:    lyricsContent,
+		},
+	}
 }
 
-// getLyrics 获取歌词内容，优先尝试KRC格式，失败时降级到LRC格式
-func (h *HomepageService) getLyrics(id string, accesskey string, cookie string) (string, error) {
-	if id == "" || accesskey == "" {
-		return "", fmt.Errorf("歌词ID或AccessKey不能为空")
-	}
+// lowerQualityCachedUrl 无法获取在线播放地址时，退而使用已缓存的较低音质文件
+func (h *HomepageService) lowerQualityCachedUrl(hash string) (SongUrlResponse, bool) {
+	if h.cacheService == nil {
+		return SongUrlResponse{}, false
+	}
+	fileName, ok := h.cacheService.lookupCachedFile(hash, AudioQualityHigh, true)
+	localURL := h.cacheService.getLocalURL(fileName)
+	if !ok || localURL == "" {
+		return SongUrlResponse{}, false
+	}
+
+	fmt.Printf("⚠️ 无法获取在线播放地址，使用较低音质的缓存: %s\n", fileName)
+	return SongUrlResponse{
+		Success:   true,
+		Message:   "使用已缓存的较低音质",
+		ErrorCode: 0,
+		Data: SongUrlData{
+			URL:    localURL,
+			Lyrics: h.songLyrics(hash, lyricsWaitTimeout),
+		},
+	}, true
+}
 
-	// 首先尝试获取KRC格式歌词（包含逐字时间戳）
-	krcLyrics, err := h.getLyricsWithFormat(id, accesskey, cookie, "krc")
-	if err == nil && krcLyrics != "" {
-		fmt.Printf("✅ 获取到KRC格式歌词，长度: %d\n", len(krcLyrics))
-		return krcLyrics, nil
+// searchLyrics 搜索歌词
+func (h *HomepageService) searchLyrics(hash string) (*LyricsSearchData, error) {
+	if hash == "" {
+		return nil, fmt.Errorf("歌曲hash不能为空")
 	}
 
-	fmt.Printf("⚠️ KRC格式歌词获取失败，降级到LRC格式: %v\n", err)
-
-	// 降级到LRC格式
-	lrcLyrics, err := h.getLyricsWithFormat(id, accesskey, cookie, "lrc")
-	if err == nil && lrcLyrics != "" {
-		fmt.Printf("✅ 获取到LRC格式歌词，长度: %d\n", len(lrcLyrics))
-		return lrcLyrics, nil
+	candidates, err := searchLyricCandidates(url.Values{
+		"hash": {hash},
+		"man":  {"no"}, // 只返回一个歌词
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return "", fmt.Errorf("获取歌词失败: %v", err)
+	return &candidates[0], nil
 }
 
-// getLyricsWithFormat 获取指定格式的歌词内容
-func (h *HomepageService) getLyricsWithFormat(id string, accesskey string, cookie string, format string) (string, error) {
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/lyric", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("id", id)
-	queryParams.Add("accesskey", accesskey)
-	queryParams.Add("decode", "true")
-	queryParams.Add("fmt", format)
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+// searchLyricCandidates 调用歌词搜索接口，没有候选歌词时返回错误
+func searchLyricCandidates(params url.Values) ([]LyricsSearchData, error) {
+	result, err := apiGet[struct {
+		Candidates apiList[struct {
+			ID        apiString `json:"id"`
+			AccessKey apiString `json:"accesskey"`
+			Score     apiInt    `json:"score"`
+			Song      apiString `json:"song"`
+			Singer    apiString `json:"singer"`
+			Duration  apiInt    `json:"duration"`
+			Source    apiString `json:"product_from"`
+		}] `json:"candidates"`
+	}](apiRequest{
+		Path:    "/search/lyric",
+		Params:  params,
+		Cookie:  true,
 		Timeout: 10 * time.Second,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return "", fmt.Errorf("请求失败: %v", err)
+	if len(result.Candidates) == 0 {
+		return nil, fmt.Errorf("未找到歌词信息")
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
+	candidates := make([]LyricsSearchData, 0, len(result.Candidates))
+	for _, candidate := range result.Candidates {
+		candidates = append(candidates, LyricsSearchData{
+			ID:        candidate.ID.String(),
+			AccessKey: candidate.AccessKey.String(),
+			Score:     candidate.Score.Int(),
+			Song:      candidate.Song.String(),
+			Singer:    candidate.Singer.String(),
+			Duration:  candidate.Duration.Int(),
+			Source:    candidate.Source.String(),
+		})
 	}
+	return candidates, nil
+}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return "", fmt.Errorf("解析响应失败: %v", err)
+// getLyricsWithFormat 获取指定格式的歌词内容
+func (h *HomepageService) getLyricsWithFormat(id string, accesskey string, format string) (string, error) {
+	result, err := apiGet[struct {
+		DecodeContent *apiString `json:"decodeContent"`
+	}](apiRequest{
+		Path: "/lyric",
+		Params: url.Values{
+			"id":        {id},
+			"accesskey": {accesskey},
+			"decode":    {"true"},
+			"fmt":       {format},
+		},
+		Cookie:  true,
+		Timeout: 10 * time.Second,
+	})
+	if err != nil {
+		return "", err
 	}
 
 	// 获取解码后的歌词内容
-	if decodeContent, ok := apiResponse["decodeContent"].(string); ok {
-		return decodeContent, nil
+	if result.DecodeContent == nil {
+		return "", fmt.Errorf("未找到歌词内容")
 	}
 
-	return "", fmt.Errorf("未找到歌词内容")
+	return result.DecodeContent.String(), nil
 }
 
 // GetDailyRecommend 获取每日推荐歌曲
@@ -671,180 +659,53 @@ func (h *HomepageService) GetDailyRecommend(platform string) DailyRecommendRespo
 		platform = "ios"
 	}
 
-	// 读取cookie
-	cookie, err := h.readCookieFromFile()
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:   "/everyday/recommend",
+		Params: url.Values{"platform": {platform}},
+		Cookie: true,
+	})
 	if err != nil {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/everyday/recommend", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("cookie", cookie)
-	queryParams.Add("platform", platform)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[[]DailyRecommendData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := decodeApiData[recommendSongListData[dailyRecommendItem]](envelope)
 	if err != nil {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var objResponse map[string]any
-	if err := json.Unmarshal(body, &objResponse); err != nil {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查是否有data字段
-	dataField, ok := objResponse["data"]
-	if !ok {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: "响应中没有data字段",
-		}
-	}
-
-	// 获取data对象
-	dataObj, ok := dataField.(map[string]any)
-	if !ok {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: "data字段格式不正确",
-		}
-	}
-
-	// 获取song_list数组
-	songListField, ok := dataObj["song_list"]
-	if !ok {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: "data中没有song_list字段",
-		}
-	}
-
-	songList, ok := songListField.([]any)
-	if !ok {
-		return DailyRecommendResponse{
-			Success: false,
-			Message: "song_list字段格式不正确",
-		}
-	}
-
-	var apiResponse []map[string]any
-	for _, item := range songList {
-		if itemMap, ok := item.(map[string]any); ok {
-			apiResponse = append(apiResponse, itemMap)
-		}
+		return apiFailure[[]DailyRecommendData](err)
 	}
 
 	// 转换为前端需要的格式
 	var dailyRecommendList []DailyRecommendData
-	for _, item := range apiResponse {
-		song := DailyRecommendData{}
-
-		// 安全地提取字段
-		if hash, ok := item["hash"].(string); ok {
-			song.Hash = hash
-		}
-		if songname, ok := item["songname"].(string); ok {
-			song.SongName = songname
-		}
-		if authorName, ok := item["author_name"].(string); ok {
-			song.AuthorName = authorName
-		}
-		if albumName, ok := item["album_name"].(string); ok {
-			song.AlbumName = albumName
-		}
-		if albumID, ok := item["album_id"].(string); ok {
-			song.AlbumID = albumID
-		}
-		if filename, ok := item["filename"].(string); ok {
-			song.FileName = filename
+	for _, item := range data.SongList {
+		song := DailyRecommendData{
+			Hash:       item.Hash.String(),
+			SongName:   item.SongName.String(),
+			AlbumName:  item.AlbumName.String(),
+			AlbumID:    item.AlbumID.String(),
+			AuthorName: item.AuthorName.String(),
+			UnionCover: item.SizableCover.String(),
+		}
+
+		if item.FileName != nil {
+			song.FileName = item.FileName.String()
 		} else {
 			// 如果没有filename字段，使用默认格式
 			song.FileName = fmt.Sprintf("%s - %s", song.AuthorName, song.SongName)
 		}
+
 		// 处理时长字段，优先使用time_length字段
-		if timeLength, ok := item["time_length"].(float64); ok {
-			song.TimeLength = int(timeLength)
-		} else if timeLengthInt, ok := item["time_length"].(int); ok {
-			song.TimeLength = timeLengthInt
-		} else if timeLengthStr, ok := item["time_length"].(string); ok {
-			if len(timeLengthStr) > 0 {
-				var timeInt int
-				fmt.Sscanf(timeLengthStr, "%d", &timeInt)
-				song.TimeLength = timeInt
-			}
-		} else if timelength320, ok := item["timelength_320"].(float64); ok {
-			song.TimeLength = int(timelength320)
-		} else if timelength320Int, ok := item["timelength_320"].(int); ok {
-			song.TimeLength = timelength320Int
-		} else if timelength320Str, ok := item["timelength_320"].(string); ok {
-			if len(timelength320Str) > 0 {
-				var timeInt int
-				fmt.Sscanf(timelength320Str, "%d", &timeInt)
-				song.TimeLength = timeInt
+		for _, timeLength := range []*apiInt{item.TimeLength, item.TimeLength320, item.Timelength} {
+			if timeLength != nil {
+				song.TimeLength = timeLength.Int()
+				break
 			}
-		} else if timelength, ok := item["timelength"].(float64); ok {
-			song.TimeLength = int(timelength)
-		} else if timelengthInt, ok := item["timelength"].(int); ok {
-			song.TimeLength = timelengthInt
-		}
-		if unionCover, ok := item["sizable_cover"].(string); ok {
-			song.UnionCover = unionCover
 		}
 
 		// 从relate_goods数组中提取专辑信息
-		if relateGoods, ok := item["relate_goods"].([]any); ok && len(relateGoods) > 0 {
-			if firstGoods, ok := relateGoods[0].(map[string]any); ok {
-				if albumname, ok := firstGoods["album_name"].(string); ok {
-					song.AlbumName = albumname
-				}
-			}
-			if len(relateGoods) > 1 {
-				if secondGoods, ok := relateGoods[1].(map[string]any); ok {
-					if albumId, ok := secondGoods["album_id"].(string); ok {
-						song.AlbumID = albumId
-					}
-				}
-			}
+		if albumName := item.RelateGoods.AlbumName(); albumName != "" {
+			song.AlbumName = albumName
+		}
+		if albumID := item.RelateGoods.AlbumID(); albumID != "" {
+			song.AlbumID = albumID
 		}
 
 		dailyRecommendList = append(dailyRecommendList, song)
@@ -861,15 +722,6 @@ func (h *HomepageService) GetDailyRecommend(platform string) DailyRecommendRespo
 func (h *HomepageService) GetAIRecommend() AIRecommendResponse {
 	log.Println("🤖 开始获取AI推荐歌曲...")
 
-	// 读取cookie
-	cookie, err := h.readCookieFromFile()
-	if err != nil {
-		return AIRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
 	// 首先获取我喜欢的歌曲列表，提取mixsongid
 	favoritesService := &FavoritesService{}
 	favoritesResponse := favoritesService.GetFavoritesSongs(1, 50) // 获取前50首用于AI推荐
@@ -902,170 +754,27 @@ func (h *HomepageService) GetAIRecommend() AIRecommendResponse {
 	albumAudioIds := strings.Join(mixsongids, ",")
 	log.Printf("使用的album_audio_id: %s", albumAudioIds)
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/ai/recommend", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("album_audio_id", albumAudioIds)
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	log.Printf("调用AI推荐API: %s", requestURL)
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	data, err := apiGetData[recommendSongListData[aiRecommendItem]](apiRequest{
+		Path:   "/ai/recommend",
+		Params: url.Values{"album_audio_id": {albumAudioIds}},
+		Cookie: true,
+	}, "AI推荐API请求失败")
 	if err != nil {
-		return AIRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return AIRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	log.Printf("AI推荐API响应状态码: %d", resp.StatusCode)
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return AIRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var objResponse map[string]any
-	if err := json.Unmarshal(body, &objResponse); err != nil {
-		return AIRecommendResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应状态
-	if status, ok := objResponse["status"].(float64); !ok || status != 1 {
-		errorMsg := "AI推荐API请求失败"
-		if msg, ok := objResponse["error"].(string); ok {
-			errorMsg = msg
-		}
-		return AIRecommendResponse{
-			Success: false,
-			Message: errorMsg,
-		}
-	}
-
-	// 检查是否有data字段
-	dataField, ok := objResponse["data"]
-	if !ok {
-		return AIRecommendResponse{
-			Success: false,
-			Message: "响应中没有data字段",
-		}
-	}
-
-	// 获取data对象
-	dataObj, ok := dataField.(map[string]any)
-	if !ok {
-		return AIRecommendResponse{
-			Success: false,
-			Message: "data字段格式不正确",
-		}
-	}
-
-	// 获取song_list数组
-	songListField, ok := dataObj["song_list"]
-	if !ok {
-		return AIRecommendResponse{
-			Success: false,
-			Message: "data中没有song_list字段",
-		}
-	}
-
-	songList, ok := songListField.([]any)
-	if !ok {
-		return AIRecommendResponse{
-			Success: false,
-			Message: "song_list字段格式不正确",
-		}
+		return apiFailure[[]AIRecommendData](err)
 	}
 
 	var aiRecommendList []AIRecommendData
-	for _, item := range songList {
-		itemMap, ok := item.(map[string]any)
-		if !ok {
-			continue
-		}
-
-		song := AIRecommendData{}
-
-		// 按照用户提供的数据对应关系进行映射
-		// hash: $.data.song_list[0].hash
-		if hash, ok := itemMap["hash"].(string); ok {
-			song.Hash = hash
-		}
-
-		// songname: $.data.song_list[0].songname
-		if songname, ok := itemMap["songname"].(string); ok {
-			song.SongName = songname
-		}
-
-		// filename: $.data.song_list[0].filename
-		if filename, ok := itemMap["filename"].(string); ok {
-			song.FileName = filename
-		}
-
-		// timelength: $.data.song_list[0].time_length
-		if timeLength, ok := itemMap["time_length"].(float64); ok {
-			song.TimeLength = int(timeLength)
-		} else if timeLengthInt, ok := itemMap["time_length"].(int); ok {
-			song.TimeLength = timeLengthInt
-		}
-
-		// albumname: $.data.song_list[0].relate_goods[0].albumname
-		if relateGoods, ok := itemMap["relate_goods"].([]any); ok && len(relateGoods) > 0 {
-			if firstGoods, ok := relateGoods[0].(map[string]any); ok {
-				if albumname, ok := firstGoods["albumname"].(string); ok {
-					song.AlbumName = albumname
-				}
-			}
-		}
-
-		// album_id: $.data.song_list[0].album_id
-		if albumID, ok := itemMap["album_id"].(string); ok {
-			song.AlbumID = albumID
-		} else if albumIDFloat, ok := itemMap["album_id"].(float64); ok {
-			song.AlbumID = fmt.Sprintf("%.0f", albumIDFloat)
-		}
-
-		// author_name: $.data.song_list[0].author_name
-		if authorName, ok := itemMap["author_name"].(string); ok {
-			song.AuthorName = authorName
-		}
-
-		// union_cover: $.data.song_list[0].trans_param.union_cover
-		if transParam, ok := itemMap["trans_param"].(map[string]any); ok {
-			if unionCover, ok := transParam["union_cover"].(string); ok {
-				song.UnionCover = unionCover
-			}
-		}
-
-		aiRecommendList = append(aiRecommendList, song)
+	for _, item := range data.SongList {
+		aiRecommendList = append(aiRecommendList, AIRecommendData{
+			Hash:       item.Hash.String(),
+			SongName:   item.SongName.String(),
+			FileName:   item.FileName.String(),
+			TimeLength: item.TimeLength.Int(),
+			AlbumName:  item.RelateGoods.AlbumName(),
+			AlbumID:    item.AlbumID.String(),
+			AuthorName: item.AuthorName.String(),
+			UnionCover: item.TransParam.UnionCover.String(),
+		})
 	}
 
 	log.Printf("成功获取AI推荐歌曲，共%d首", len(aiRecommendList))
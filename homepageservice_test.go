@@ -0,0 +1,166 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"wmplayer/internal/mockapi"
+)
+
+func TestGetPersonalFM(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := NewHomepageService(nil, nil).GetPersonalFMSimple("")
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	song := resp.Data[0]
+	if song.AlbumName != "叶惠美" || song.AlbumID != "960399" || song.TimeLength != 269 || song.UnionCover == "" {
+		t.Errorf("song = %+v", song)
+	}
+
+	req, _ := srv.LastRequest("/personal/fm")
+	if req.Query.Get("mode") != "normal" || req.Query.Get("action") != "play" {
+		t.Errorf("query = %v", req.Query)
+	}
+	assertCookieSent(t, srv, "/personal/fm", true)
+}
+
+func TestGetPersonalFMFailure(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/personal/fm", `{"status":0,"error_code":20018}`)
+
+	resp := NewHomepageService(nil, nil).GetPersonalFMSimple("normal")
+	if resp.Success || resp.ErrorCode != 20018 || resp.Data == nil {
+		t.Errorf("resp = %+v, want failure with empty list", resp)
+	}
+}
+
+func TestGetSongUrl(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := NewHomepageService(nil, nil).GetSongUrl("A1B2C3D4E5F60718293A4B5C6D7E8F90")
+	if !resp.Success {
+		t.Fatalf("GetSongUrl failed: %s", resp.Message)
+	}
+	if !strings.HasSuffix(resp.Data.URL, "qingtian_main.mp3") || !strings.HasSuffix(resp.Data.BackupURL, "qingtian_backup.mp3") {
+		t.Errorf("urls = %q, %q", resp.Data.URL, resp.Data.BackupURL)
+	}
+	// 优先使用KRC格式的歌词
+	if !strings.Contains(resp.Data.Lyrics, "[29350,3220]") {
+		t.Errorf("lyrics = %q, want KRC content", resp.Data.Lyrics)
+	}
+
+	req, _ := srv.LastRequest("/lyric")
+	if req.Query.Get("id") != "12345678" || req.Query.Get("accesskey") != "ABCDEF0123456789ABCDEF0123456789" {
+		t.Errorf("lyric query = %v", req.Query)
+	}
+	// 默认按无损音质请求
+	if req, _ := srv.LastRequest("/song/url"); req.Query.Get("quality") != "flac" {
+		t.Errorf("song/url quality = %q, want flac", req.Query.Get("quality"))
+	}
+}
+
+func TestGetSongUrlLyricsFallbackToLRC(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.Handle("/lyric", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fmt") == "krc" {
+			w.Write([]byte(`{"status":200,"decodeContent":""}`))
+			return
+		}
+		body, _ := mockapi.Fixture("lyric")
+		w.Write(body)
+	})
+
+	resp := NewHomepageService(nil, nil).GetSongUrl("A1B2C3D4E5F60718293A4B5C6D7E8F90")
+	if !resp.Success || !strings.Contains(resp.Data.Lyrics, "[00:29.35]故事的小黄花") {
+		t.Errorf("lyrics = %q, want LRC content", resp.Data.Lyrics)
+	}
+}
+
+func TestGetSongUrlNoUrls(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/song/url", `{"status":2,"error_code":20010,"url":[],"backupUrl":""}`)
+
+	resp := NewHomepageService(nil, nil).GetSongUrl("A1B2C3D4E5F60718293A4B5C6D7E8F90")
+	if resp.Success || resp.ErrorCode != 20010 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestGetSongUrlFallsBackToLowerQualityCache(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/song/url", `{"status":2,"error_code":20010,"url":[],"backupUrl":""}`)
+
+	c, _ := newTestCacheService(t)
+	hash := "A1B2C3D4E5F60718293A4B5C6D7E8F90"
+	fileName := c.getCacheKey(hash, AudioQualityLow) + ".mp3"
+	writeCacheFile(t, c.mp3Dir, fileName, 10)
+	c.audioCache.Record(fileName, hash, AudioQualityLow)
+
+	resp := NewHomepageService(c, newTestAppState(t, c)).GetSongUrl(hash)
+	if !resp.Success || resp.Data.URL != c.getLocalURL(fileName) {
+		t.Errorf("resp = %+v, want lower quality cache", resp)
+	}
+}
+
+func TestGetSongUrlOffline(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	enableOfflineMode(t)
+
+	hash := "A1B2C3D4E5F60718293A4B5C6D7E8F90"
+	fileName := c.getCacheKey(hash, AudioQualityMedium) + ".mp3"
+	writeCacheFile(t, c.mp3Dir, fileName, 10)
+	c.audioCache.Record(fileName, hash, AudioQualityMedium)
+	state := newTestAppState(t, c)
+	state.lyricsCache.Put(&cachedLyrics{Hash: hash, LRC: "[00:29.35]故事的小黄花"})
+
+	// 离线时使用缓存的歌词
+	h := NewHomepageService(c, state)
+	if resp := h.GetSongUrl(hash); !resp.Success || resp.Data.URL != c.getLocalURL(fileName) || resp.Data.Lyrics == "" {
+		t.Errorf("resp = %+v, want cached file with saved lyrics", resp)
+	}
+	if resp := h.GetSongUrl("0F1E2D3C4B5A69788796A5B4C3D2E1F0"); resp.Success || resp.ErrorCode != ApiErrOffline {
+		t.Errorf("resp = %+v, want offline failure", resp)
+	}
+	if _, ok := srv.LastRequest("/song/url"); ok {
+		t.Error("/song/url should not be requested in offline mode")
+	}
+}
+
+func TestGetDailyRecommend(t *testing.T) {
+	newMockAPI(t)
+
+	resp := NewHomepageService(nil, nil).GetDailyRecommend("")
+	if !resp.Success || len(resp.Data) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	first, second := resp.Data[0], resp.Data[1]
+	// 缺少 filename 时由歌手和歌名拼接，专辑信息取自 relate_goods
+	if first.FileName != "周杰伦 - 晴天" || first.AlbumName != "叶惠美" || first.AlbumID != "960399" || first.TimeLength != 269 {
+		t.Errorf("first = %+v", first)
+	}
+	if second.FileName != "周杰伦 - 七里香" || second.TimeLength != 299 {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestGetAIRecommend(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := NewHomepageService(nil, nil).GetAIRecommend()
+	if !resp.Success || len(resp.Data) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if song := resp.Data[0]; song.AlbumName != "七里香" || song.AlbumID != "979856" {
+		t.Errorf("song = %+v", song)
+	}
+
+	// 使用我喜欢的歌曲中的 mixsongid
+	req, _ := srv.LastRequest("/ai/recommend")
+	if req.Query.Get("album_audio_id") != "32042830,32042831" {
+		t.Errorf("album_audio_id = %q", req.Query.Get("album_audio_id"))
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// instanceRequestTimeout 与运行中实例通信的超时时间
+const instanceRequestTimeout = 5 * time.Second
+
+// 其它实例或命令行转发的请求类型
+const (
+	instanceCommandActivate  = "activate"   // 显示主窗口并处理转发的命令行参数
+	instanceCommandPlayPause = "play_pause" // 播放/暂停
+	instanceCommandNext      = "next"       // 下一首
+	instanceCommandPrevious  = "previous"   // 上一首
+	instanceCommandVolume    = "volume"     // 设置音量，参数为 0-100
+	instanceCommandStatus    = "status"     // 查询播放状态
+	instanceCommandEnqueue   = "enqueue"    // 把文件添加到播放列表末尾
+)
+
+// 转发给前端的事件
+const (
+	instanceOpenFilesEvent       = "instance:open-files"
+	instancePlaylistChangedEvent = "instance:playlist-changed"
+)
+
+// instanceReadyEvent 前端注册好事件监听后发给后端，之后才处理第一次启动时的参数
+const instanceReadyEvent = "instance:ready"
+
+// errInstanceRunning 已经有实例在运行
+var errInstanceRunning = errors.New("wmplayer 已在运行")
+
+// InstanceRequest 第二次启动或命令行发给运行中实例的请求
+type InstanceRequest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	WorkDir string   `json:"workdir,omitempty"` // 发起请求的工作目录，用于解析相对路径
+}
+
+// InstanceResponse 运行中实例的响应
+type InstanceResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// InstanceLock 单实例锁，持有期间监听本地socket，接收其它实例转发的请求
+type InstanceLock struct {
+	listener net.Listener
+}
+
+// instanceSocketPath 单实例socket的路径，优先放在只有当前用户可访问的 XDG_RUNTIME_DIR 中
+func instanceSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "wmplayer.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("wmplayer-%d.sock", os.Getuid()))
+}
+
+// acquireInstanceLock 获取单实例锁，已有实例运行时返回 errInstanceRunning
+// socket文件存在但连不上说明上次没有正常退出，删除后重新监听
+func acquireInstanceLock(path string) (*InstanceLock, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		if conn, dialErr := net.DialTimeout("unix", path, instanceRequestTimeout); dialErr == nil {
+			conn.Close()
+			return nil, errInstanceRunning
+		}
+		if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
+			return nil, fmt.Errorf("删除残留的实例socket失败: %v", removeErr)
+		}
+		if listener, err = net.Listen("unix", path); err != nil {
+			return nil, fmt.Errorf("监听实例socket失败: %v", err)
+		}
+	}
+	// 只允许当前用户连接
+	if err := os.Chmod(path, 0600); err != nil {
+		log.Printf("⚠️ 设置实例socket权限失败: %v", err)
+	}
+	return &InstanceLock{listener: listener}, nil
+}
+
+// Serve 在后台处理其它实例转发的请求，每个连接一个请求
+func (l *InstanceLock) Serve(handler func(InstanceRequest) InstanceResponse) {
+	go func() {
+		for {
+			conn, err := l.listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Printf("⚠️ 实例socket停止监听: %v", err)
+				}
+				return
+			}
+			go handleInstanceConn(conn, handler)
+		}
+	}()
+}
+
+// Close 释放单实例锁，socket文件随监听关闭删除
+func (l *InstanceLock) Close() error {
+	return l.listener.Close()
+}
+
+// handleInstanceConn 读取一个请求并写回响应
+func handleInstanceConn(conn net.Conn, handler func(InstanceRequest) InstanceResponse) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(instanceRequestTimeout))
+
+	var request InstanceRequest
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		json.NewEncoder(conn).Encode(InstanceResponse{Success: false, Message: fmt.Sprintf("请求格式错误: %v", err)})
+		return
+	}
+	log.Printf("📨 收到实例请求: %s %v", request.Command, request.Args)
+	json.NewEncoder(conn).Encode(handler(request))
+}
+
+// sendInstanceRequest 把请求发给运行中的实例并等待响应
+func sendInstanceRequest(path string, request InstanceRequest) (InstanceResponse, error) {
+	conn, err := net.DialTimeout("unix", path, instanceRequestTimeout)
+	if err != nil {
+		return InstanceResponse{}, fmt.Errorf("连接运行中的实例失败: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(instanceRequestTimeout))
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return InstanceResponse{}, fmt.Errorf("发送请求失败: %v", err)
+	}
+	var response InstanceResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return InstanceResponse{}, fmt.Errorf("读取响应失败: %v", err)
+	}
+	return response, nil
+}
+
+// InstanceController 在运行中的实例里处理转发的请求
+type InstanceController struct {
+	activate  func()                      // 显示并聚焦主窗口
+	emit      func(name string, data any) // 发送事件到前端
+	mediaKeys *MediaKeyService            // 播放控制和播放状态
+	playlist  *PlaylistService
+
+	startupMutex sync.Mutex
+	startup      *InstanceRequest // 第一次启动时传入的文件和 --toggle，前端准备好后处理
+}
+
+// NewInstanceController 创建实例请求处理器
+func NewInstanceController(activate func(), emit func(name string, data any), mediaKeys *MediaKeyService) *InstanceController {
+	return &InstanceController{
+		activate:  activate,
+		emit:      emit,
+		mediaKeys: mediaKeys,
+		playlist:  &PlaylistService{},
+	}
+}
+
+// SetStartupArgs 记录第一次启动时的参数，前端准备好后与转发的参数同样处理
+func (c *InstanceController) SetStartupArgs(args []string, workDir string) {
+	options := parseCommandLine(args)
+	if !options.Toggle && len(options.Files) == 0 {
+		return
+	}
+
+	c.startupMutex.Lock()
+	defer c.startupMutex.Unlock()
+	c.startup = &InstanceRequest{Command: instanceCommandActivate, Args: args, WorkDir: workDir}
+}
+
+// FrontendReady 前端准备好时处理第一次启动时的参数，只处理一次，刷新页面不会重复打开
+func (c *InstanceController) FrontendReady() {
+	c.startupMutex.Lock()
+	request := c.startup
+	c.startup = nil
+	c.startupMutex.Unlock()
+	if request == nil {
+		return
+	}
+
+	if response := c.Handle(*request); !response.Success {
+		log.Printf("⚠️ 处理启动参数失败: %s", response.Message)
+	} else {
+		log.Printf("✅ %s", response.Message)
+	}
+}
+
+// Handle 处理一个实例请求
+func (c *InstanceController) Handle(request InstanceRequest) InstanceResponse {
+	switch request.Command {
+	case instanceCommandActivate:
+		return c.handleActivate(request)
+	case instanceCommandPlayPause:
+		return mediaKeyResponse(c.mediaKeys.HandleMediaKeyEvent("play_pause"))
+	case instanceCommandNext:
+		return mediaKeyResponse(c.mediaKeys.HandleMediaKeyEvent("next_track"))
+	case instanceCommandPrevious:
+		return mediaKeyResponse(c.mediaKeys.HandleMediaKeyEvent("previous_track"))
+	case instanceCommandVolume:
+		return c.handleVolume(request)
+	case instanceCommandStatus:
+		return InstanceResponse{
+			Success: true,
+			Message: "获取播放状态成功",
+			Data:    c.mediaKeys.GetPlayerStatus(),
+		}
+	case instanceCommandEnqueue:
+		return c.handleEnqueue(request)
+	default:
+		return InstanceResponse{
+			Success: false,
+			Message: "未知的命令: " + request.Command,
+		}
+	}
+}
+
+// handleActivate 显示主窗口，并执行第二次启动时传入的参数
+func (c *InstanceController) handleActivate(request InstanceRequest) InstanceResponse {
+	options := parseCommandLine(request.Args)
+	if c.activate != nil {
+		c.activate()
+	}
+
+	if options.Toggle {
+		c.emit(togglePlayPauseEvent, nil)
+	}
+	if len(options.Files) > 0 {
+		files := resolveRequestPaths(request.WorkDir, options.Files)
+		opened := (&LocalMusicService{}).OpenMusicFiles(files)
+		if !opened.Success {
+			return InstanceResponse{Success: false, Message: opened.Message}
+		}
+		c.emit(instanceOpenFilesEvent, opened.Data)
+		return InstanceResponse{Success: true, Message: opened.Message}
+	}
+
+	return InstanceResponse{Success: true, Message: "已切换到运行中的窗口"}
+}
+
+// handleVolume 设置音量，参数为 0-100
+func (c *InstanceController) handleVolume(request InstanceRequest) InstanceResponse {
+	if len(request.Args) != 1 {
+		return InstanceResponse{Success: false, Message: "需要一个音量参数"}
+	}
+	volume, err := strconv.Atoi(request.Args[0])
+	if err != nil || volume < 0 || volume > 100 {
+		return InstanceResponse{Success: false, Message: "音量必须是 0-100 的整数: " + request.Args[0]}
+	}
+	return mediaKeyResponse(c.mediaKeys.setVolume(volume))
+}
+
+// handleEnqueue 把音乐文件添加到播放列表末尾，已在列表中的歌曲跳过，然后通知前端刷新播放列表
+func (c *InstanceController) handleEnqueue(request InstanceRequest) InstanceResponse {
+	if len(request.Args) == 0 {
+		return InstanceResponse{Success: false, Message: "没有要添加的文件"}
+	}
+	opened := (&LocalMusicService{}).OpenMusicFiles(resolveRequestPaths(request.WorkDir, request.Args))
+	if !opened.Success {
+		return InstanceResponse{Success: false, Message: opened.Message}
+	}
+
+	added := 0
+	for _, file := range opened.Data {
+		result := c.playlist.AddToPlaylist(AddToPlaylistRequest{Song: file.playlistSong()})
+		if result.Success {
+			added++
+		} else {
+			log.Printf("⚠️ 添加到播放列表失败: %s, %s", file.FilePath, result.Message)
+		}
+	}
+	if added == 0 {
+		return InstanceResponse{Success: false, Message: "没有添加新的歌曲"}
+	}
+
+	c.emit(instancePlaylistChangedEvent, nil)
+	return InstanceResponse{
+		Success: true,
+		Message: fmt.Sprintf("已添加 %d 首歌曲到播放列表", added),
+	}
+}
+
+// mediaKeyResponse 把媒体键动作的处理结果转换为实例响应
+func mediaKeyResponse(result map[string]any) InstanceResponse {
+	success, _ := result["success"].(bool)
+	message, _ := result["message"].(string)
+	return InstanceResponse{Success: success, Message: message}
+}
+
+// resolveRequestPaths 把相对路径按发起请求的工作目录转换为绝对路径
+func resolveRequestPaths(workDir string, paths []string) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !filepath.IsAbs(path) && workDir != "" {
+			path = filepath.Join(workDir, path)
+		}
+		resolved = append(resolved, filepath.Clean(path))
+	}
+	return resolved
+}
+
+// forwardToRunningInstance 第二次启动时把参数转发给运行中的实例
+func forwardToRunningInstance(path string, args []string) error {
+	workDir, _ := os.Getwd()
+	response, err := sendInstanceRequest(path, InstanceRequest{
+		Command: instanceCommandActivate,
+		Args:    args,
+		WorkDir: workDir,
+	})
+	if err != nil {
+		return err
+	}
+	if !response.Success {
+		return errors.New(response.Message)
+	}
+	log.Printf("✅ %s", response.Message)
+	return nil
+}
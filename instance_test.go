@@ -0,0 +1,172 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// emittedEvent 测试中记录发送到前端的事件
+type emittedEvent struct {
+	name string
+	data any
+}
+
+// newTestInstanceLock 在临时目录中获取单实例锁
+func newTestInstanceLock(t *testing.T) (*InstanceLock, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "i.sock")
+	lock, err := acquireInstanceLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lock.Close() })
+	return lock, path
+}
+
+func TestInstanceLockIsExclusive(t *testing.T) {
+	lock, path := newTestInstanceLock(t)
+	if _, err := acquireInstanceLock(path); err != errInstanceRunning {
+		t.Fatalf("second acquire = %v, want errInstanceRunning", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, %v", info, err)
+	}
+
+	// 释放后可以重新获取
+	lock.Close()
+	again, err := acquireInstanceLock(path)
+	if err != nil {
+		t.Fatalf("acquire after close: %v", err)
+	}
+	again.Close()
+}
+
+func TestInstanceLockReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "i.sock")
+	// 模拟异常退出留下的socket文件
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("stale socket should exist: %v", err)
+	}
+
+	lock, err := acquireInstanceLock(path)
+	if err != nil {
+		t.Fatalf("acquire with stale socket: %v", err)
+	}
+	lock.Close()
+}
+
+func TestForwardToRunningInstance(t *testing.T) {
+	lock, path := newTestInstanceLock(t)
+	requests := make(chan InstanceRequest, 1)
+	lock.Serve(func(request InstanceRequest) InstanceResponse {
+		requests <- request
+		return InstanceResponse{Success: true, Message: "ok"}
+	})
+
+	if err := forwardToRunningInstance(path, []string{"--toggle", "song.flac"}); err != nil {
+		t.Fatal(err)
+	}
+	request := <-requests
+	workDir, _ := os.Getwd()
+	if request.Command != instanceCommandActivate || !reflect.DeepEqual(request.Args, []string{"--toggle", "song.flac"}) || request.WorkDir != workDir {
+		t.Errorf("request = %+v", request)
+	}
+
+	// 运行中的实例返回失败时转发也失败
+	failing, failingPath := newTestInstanceLock(t)
+	failing.Serve(func(InstanceRequest) InstanceResponse {
+		return InstanceResponse{Success: false, Message: "没有可以播放的音乐文件"}
+	})
+	if err := forwardToRunningInstance(failingPath, nil); err == nil || !strings.Contains(err.Error(), "没有可以播放") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestInstanceControllerActivate(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+
+	var mutex sync.Mutex
+	var events []emittedEvent
+	activated := 0
+	controller := NewInstanceController(func() { activated++ }, func(name string, data any) {
+		mutex.Lock()
+		events = append(events, emittedEvent{name, data})
+		mutex.Unlock()
+	}, NewMediaKeyService())
+
+	if resp := controller.Handle(InstanceRequest{Command: instanceCommandActivate}); !resp.Success || activated != 1 || len(events) != 0 {
+		t.Fatalf("plain activate = %+v, activated %d, events %v", resp, activated, events)
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "晴天.mp3"), "not really audio")
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "not audio")
+	resp := controller.Handle(InstanceRequest{
+		Command: instanceCommandActivate,
+		Args:    []string{"--toggle", "晴天.mp3", "notes.txt", "missing.flac"},
+		WorkDir: dir,
+	})
+	if !resp.Success || activated != 2 || len(events) != 2 {
+		t.Fatalf("activate with args = %+v, events %v", resp, events)
+	}
+	if events[0].name != togglePlayPauseEvent {
+		t.Errorf("first event = %s", events[0].name)
+	}
+	files, ok := events[1].data.([]LocalMusicFile)
+	if events[1].name != instanceOpenFilesEvent || !ok || len(files) != 1 || files[0].Title != "晴天" {
+		t.Fatalf("open files event = %+v", events[1])
+	}
+	// 打开的文件注册了本地音乐映射，可以直接按hash播放
+	if got, _ := c.localMusicPath("local-" + files[0].Hash); got != filepath.Join(dir, "晴天.mp3") {
+		t.Errorf("local music map = %q", got)
+	}
+
+	if resp := controller.Handle(InstanceRequest{Command: instanceCommandActivate, Args: []string{"missing.flac"}, WorkDir: dir}); resp.Success {
+		t.Errorf("missing file = %+v, want failure", resp)
+	}
+	if resp := controller.Handle(InstanceRequest{Command: "unknown"}); resp.Success {
+		t.Errorf("unknown command = %+v", resp)
+	}
+}
+
+func TestInstanceControllerStartupArgs(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+
+	var events []emittedEvent
+	controller := NewInstanceController(nil, func(name string, data any) {
+		events = append(events, emittedEvent{name, data})
+	}, NewMediaKeyService())
+
+	// 没有文件和 --toggle 时不需要处理
+	controller.SetStartupArgs([]string{"--api-url", "http://a"}, "")
+	controller.FrontendReady()
+	if len(events) != 0 {
+		t.Fatalf("plain launch events = %v", events)
+	}
+
+	// 前端准备好之前不发送事件，准备好后只处理一次
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "晴天.mp3"), "not really audio")
+	controller.SetStartupArgs([]string{"--toggle", "晴天.mp3"}, dir)
+	if len(events) != 0 {
+		t.Fatalf("events before ready = %v", events)
+	}
+	controller.FrontendReady()
+	controller.FrontendReady()
+	if len(events) != 2 || events[0].name != togglePlayPauseEvent || events[1].name != instanceOpenFilesEvent {
+		t.Errorf("events = %+v", events)
+	}
+}
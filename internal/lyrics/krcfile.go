@@ -0,0 +1,40 @@
+package lyrics
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+)
+
+// krcFileMagic 加密KRC歌词文件的文件头
+var krcFileMagic = []byte("krc1")
+
+// krcFileKey 加密KRC歌词文件的异或密钥
+var krcFileKey = []byte{64, 71, 97, 119, 94, 50, 116, 71, 81, 54, 49, 45, 206, 210, 110, 105}
+
+// DecodeFile 把歌词文件的内容转换为文本
+// 酷狗客户端保存的 .krc 文件是异或加密后的zlib数据，其它文件按UTF-8文本返回
+func DecodeFile(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, krcFileMagic) {
+		return string(data), nil
+	}
+
+	encrypted := data[len(krcFileMagic):]
+	decrypted := make([]byte, len(encrypted))
+	for i, b := range encrypted {
+		decrypted[i] = b ^ krcFileKey[i%len(krcFileKey)]
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(decrypted))
+	if err != nil {
+		return "", errors.New("KRC歌词文件格式错误")
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", errors.New("KRC歌词文件解压失败")
+	}
+	return string(content), nil
+}
@@ -0,0 +1,40 @@
+package lyrics
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+// encodeKRCFile 按酷狗客户端的格式加密KRC歌词
+func encodeKRCFile(content string) []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte(content))
+	writer.Close()
+
+	data := append([]byte{}, krcFileMagic...)
+	for i, b := range compressed.Bytes() {
+		data = append(data, b^krcFileKey[i%len(krcFileKey)])
+	}
+	return data
+}
+
+func TestDecodeFile(t *testing.T) {
+	content := "[ti:富士山下]\n[1000,800]<0,300,0>拦<300,500,0>路\n"
+
+	decoded, err := DecodeFile(encodeKRCFile(content))
+	if err != nil || decoded != content {
+		t.Fatalf("DecodeFile(encrypted) = %q, %v", decoded, err)
+	}
+	if timeline := Parse(decoded); timeline.Format != FormatKRC || len(timeline.Lines) != 1 {
+		t.Errorf("timeline = %+v", timeline)
+	}
+
+	if decoded, err := DecodeFile([]byte("[00:01.00]第一行")); err != nil || decoded != "[00:01.00]第一行" {
+		t.Errorf("DecodeFile(text) = %q, %v", decoded, err)
+	}
+	if _, err := DecodeFile([]byte("krc1broken")); err == nil {
+		t.Error("broken KRC file should fail")
+	}
+}
@@ -0,0 +1,466 @@
+// Package lyrics 把LRC和KRC格式的歌词解析为带时间轴的结构
+//
+// LRC支持一行多个时间标签、[offset:] 偏移、ti/ar/al/by 等元数据标签和时间相同的翻译行，
+// KRC支持 [行开始,行时长] 和 <字偏移,字时长,0> 形式的逐字时间，
+// 以及 [language:] 标签中的翻译和音译（罗马音）。
+// 所有时间的单位都是毫秒。
+package lyrics
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 歌词格式
+const (
+	FormatKRC   = "krc"
+	FormatLRC   = "lrc"
+	FormatPlain = "plain"
+)
+
+// 歌词的附加层，与原文的行一一对应
+const (
+	LayerTranslation  = "translation"
+	LayerRomanization = "romanization"
+)
+
+// Word 逐字歌词中的一个字或词
+type Word struct {
+	Start        int64  `json:"start"`
+	Duration     int64  `json:"duration"`
+	Text         string `json:"text"`
+	Romanization string `json:"romanization,omitempty"` // 逐字的音译
+}
+
+// Line 一行歌词
+type Line struct {
+	Start    int64  `json:"start"`
+	Duration int64  `json:"duration"` // 为0时表示持续到歌曲结束
+	Text     string `json:"text"`
+	Words    []Word `json:"words,omitempty"` // 只有KRC歌词有逐字时间
+	// 翻译和音译层，KRC歌词由 [language:] 标签提供，LRC歌词的翻译来自时间相同的第二行
+	Translation  string `json:"translation,omitempty"`
+	Romanization string `json:"romanization,omitempty"`
+}
+
+// Timeline 解析后的歌词时间轴
+type Timeline struct {
+	Format string            `json:"format"`
+	Synced bool              `json:"synced"` // 纯文本歌词没有时间信息
+	Offset int64             `json:"offset"` // 歌词中 [offset:] 标签的值，已应用到各行的时间上
+	Meta   map[string]string `json:"meta,omitempty"`
+	Layers []string          `json:"layers,omitempty"` // 歌词包含的附加层：translation、romanization
+	Lines  []Line            `json:"lines"`
+}
+
+var (
+	krcLinePattern = regexp.MustCompile(`(?m)^\s*\[\d+,\d+\]`)
+	lrcTimePattern = regexp.MustCompile(`\[\d+:\d+(?:[.:]\d+)?\]`)
+	krcWordPattern = regexp.MustCompile(`<(\d+),(\d+),-?\d+>([^<]*)`)
+	metaKeyPattern = regexp.MustCompile(`^[A-Za-z_#]+$`)
+)
+
+// DetectFormat 检测歌词格式
+func DetectFormat(content string) string {
+	if krcLinePattern.MatchString(content) {
+		return FormatKRC
+	}
+	if lrcTimePattern.MatchString(content) {
+		return FormatLRC
+	}
+	return FormatPlain
+}
+
+// Parse 自动检测格式并解析歌词
+func Parse(content string) Timeline {
+	content = strings.TrimPrefix(content, "\ufeff")
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	switch DetectFormat(content) {
+	case FormatKRC:
+		return parseKRC(lines)
+	case FormatLRC:
+		return parseLRC(lines)
+	default:
+		return parsePlain(lines)
+	}
+}
+
+// tag 行首的一个方括号标签
+type tag struct {
+	kind  int // tagTime, tagKRC 或 tagMeta
+	start int64
+	dur   int64
+	key   string
+	value string
+}
+
+const (
+	tagTime = iota
+	tagKRC
+	tagMeta
+)
+
+// splitTags 取出行首能识别的标签，返回标签和剩余的文本
+// 遇到无法识别的方括号（如 [副歌]）时停止，把它当作歌词文本
+func splitTags(line string) ([]tag, string) {
+	var tags []tag
+	for strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			break
+		}
+		t, ok := parseTag(line[1:end])
+		if !ok {
+			break
+		}
+		tags = append(tags, t)
+		line = line[end+1:]
+	}
+	return tags, line
+}
+
+// parseTag 解析方括号内的内容
+func parseTag(body string) (tag, bool) {
+	if start, dur, ok := parseKRCTag(body); ok {
+		return tag{kind: tagKRC, start: start, dur: dur}, true
+	}
+	if ms, ok := parseTimestamp(body); ok {
+		return tag{kind: tagTime, start: ms}, true
+	}
+	key, value, ok := strings.Cut(body, ":")
+	if !ok || !metaKeyPattern.MatchString(key) {
+		return tag{}, false
+	}
+	return tag{kind: tagMeta, key: strings.ToLower(key), value: strings.TrimSpace(value)}, true
+}
+
+// parseKRCTag 解析KRC的行时间 171960,5040
+func parseKRCTag(body string) (int64, int64, bool) {
+	startText, durText, ok := strings.Cut(body, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err1 := strconv.ParseInt(startText, 10, 64)
+	dur, err2 := strconv.ParseInt(durText, 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return start, dur, true
+}
+
+// parseTimestamp 解析LRC时间标签，支持 mm:ss、mm:ss.xx、mm:ss.xxx 和 mm:ss:xx
+func parseTimestamp(body string) (int64, bool) {
+	minText, rest, ok := strings.Cut(body, ":")
+	if !ok {
+		return 0, false
+	}
+	secText, fracText := rest, ""
+	if i := strings.IndexAny(rest, ".:"); i >= 0 {
+		secText, fracText = rest[:i], rest[i+1:]
+	}
+
+	minutes, err := strconv.ParseInt(minText, 10, 64)
+	if err != nil || minutes < 0 {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, false
+	}
+
+	var ms int64
+	if fracText != "" {
+		// 小数部分按位数换算：.5 -> 500ms，.45 -> 450ms，.456 -> 456ms
+		if len(fracText) > 3 {
+			fracText = fracText[:3]
+		}
+		frac, err := strconv.ParseInt(fracText, 10, 64)
+		if err != nil || frac < 0 {
+			return 0, false
+		}
+		for i := len(fracText); i < 3; i++ {
+			frac *= 10
+		}
+		ms = frac
+	}
+	return (minutes*60+seconds)*1000 + ms, true
+}
+
+// addMeta 记录元数据标签，offset 标签记录为时间偏移
+func (t *Timeline) addMeta(key, value string) {
+	if key == "offset" {
+		if offset, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64); err == nil {
+			t.Offset = offset
+		}
+		return
+	}
+	if value == "" {
+		return
+	}
+	if t.Meta == nil {
+		t.Meta = make(map[string]string)
+	}
+	t.Meta[key] = value
+}
+
+// shift 返回应用偏移后的时间
+// 按LRC的约定，正的 offset 让歌词提前显示
+func (t *Timeline) shift(ms int64) int64 {
+	ms -= t.Offset
+	if ms < 0 {
+		return 0
+	}
+	return ms
+}
+
+// parseLRC 解析LRC歌词
+// 空文本的时间标签只用来结束上一行，不单独生成歌词行；时间相同的行合并，后面的行作为翻译
+func parseLRC(lines []string) Timeline {
+	timeline := Timeline{Format: FormatLRC, Synced: true}
+	var entries []Line
+
+	for _, raw := range lines {
+		tags, text := splitTags(strings.TrimSpace(raw))
+		text = strings.TrimSpace(text)
+		for _, t := range tags {
+			switch t.kind {
+			case tagTime:
+				entries = append(entries, Line{Start: t.start, Text: text})
+			case tagMeta:
+				timeline.addMeta(t.key, t.value)
+			}
+		}
+	}
+
+	for i := range entries {
+		entries[i].Start = timeline.shift(entries[i].Start)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Start < entries[j].Start })
+
+	timeline.Lines = make([]Line, 0, len(entries))
+	for i := 0; i < len(entries); {
+		// 同一时间的多行（常见的原文加翻译）合并为一行，第一行为原文，其余作为翻译
+		start := entries[i].Start
+		var texts []string
+		for ; i < len(entries) && entries[i].Start == start; i++ {
+			if entries[i].Text != "" {
+				texts = append(texts, entries[i].Text)
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		line := Line{Start: start, Text: texts[0], Translation: strings.Join(texts[1:], " / ")}
+		if i < len(entries) {
+			line.Duration = entries[i].Start - start
+		}
+		if line.Translation != "" && len(timeline.Layers) == 0 {
+			timeline.Layers = []string{LayerTranslation}
+		}
+		timeline.Lines = append(timeline.Lines, line)
+	}
+	return timeline
+}
+
+// parseKRC 解析KRC歌词，字的时间是相对于行开始的偏移
+func parseKRC(lines []string) Timeline {
+	timeline := Timeline{Format: FormatKRC, Synced: true}
+	// 附加层按歌词行在文件中的顺序对应，包括没有文本的行
+	var sources []int
+	count := 0
+
+	for _, raw := range lines {
+		tags, content := splitTags(strings.TrimSpace(raw))
+		var line *Line
+		for _, t := range tags {
+			switch t.kind {
+			case tagKRC:
+				line = &Line{Start: t.start, Duration: t.dur}
+			case tagMeta:
+				timeline.addMeta(t.key, t.value)
+			}
+		}
+		if line == nil {
+			continue
+		}
+		count++
+
+		var text strings.Builder
+		matches := krcWordPattern.FindAllStringSubmatch(content, -1)
+		for _, match := range matches {
+			offset, _ := strconv.ParseInt(match[1], 10, 64)
+			dur, _ := strconv.ParseInt(match[2], 10, 64)
+			if match[3] == "" {
+				continue
+			}
+			line.Words = append(line.Words, Word{Start: line.Start + offset, Duration: dur, Text: match[3]})
+			text.WriteString(match[3])
+		}
+		if len(matches) == 0 {
+			// 没有逐字时间的行，整行作为文本
+			text.WriteString(content)
+		}
+		line.Text = strings.TrimSpace(text.String())
+		if line.Text == "" {
+			continue
+		}
+		timeline.Lines = append(timeline.Lines, *line)
+		sources = append(sources, count-1)
+	}
+
+	if language, ok := timeline.Meta["language"]; ok {
+		delete(timeline.Meta, "language")
+		timeline.applyLanguage(language, sources)
+	}
+
+	for i := range timeline.Lines {
+		line := &timeline.Lines[i]
+		line.Start = timeline.shift(line.Start)
+		for j := range line.Words {
+			line.Words[j].Start = timeline.shift(line.Words[j].Start)
+		}
+	}
+	sort.SliceStable(timeline.Lines, func(i, j int) bool { return timeline.Lines[i].Start < timeline.Lines[j].Start })
+	if timeline.Lines == nil {
+		timeline.Lines = []Line{}
+	}
+	return timeline
+}
+
+// parsePlain 解析没有时间信息的纯文本歌词，行首的元数据标签仍会被识别
+func parsePlain(lines []string) Timeline {
+	timeline := Timeline{Format: FormatPlain, Lines: []Line{}}
+
+	for _, raw := range lines {
+		text := strings.TrimSpace(raw)
+		tags, rest := splitTags(text)
+		if len(tags) > 0 && strings.TrimSpace(rest) == "" {
+			for _, t := range tags {
+				timeline.addMeta(t.key, t.value)
+			}
+			continue
+		}
+		if text != "" {
+			timeline.Lines = append(timeline.Lines, Line{Text: text})
+		}
+	}
+	return timeline
+}
+
+// LineAt 返回 ms 时正在显示的行，即最后一个已经开始的行，歌词开始前返回-1
+// 两行之间的间奏仍显示上一行
+func (t Timeline) LineAt(ms int64) int {
+	if !t.Synced {
+		return -1
+	}
+	return sort.Search(len(t.Lines), func(i int) bool { return t.Lines[i].Start > ms }) - 1
+}
+
+// WordAt 返回 ms 时正在唱的字，即最后一个已经开始的字，没有逐字时间或尚未开始时返回-1
+func (l Line) WordAt(ms int64) int {
+	return sort.Search(len(l.Words), func(i int) bool { return l.Words[i].Start > ms }) - 1
+}
+
+// krcLanguage KRC歌词 [language:] 标签的内容，base64编码的JSON
+type krcLanguage struct {
+	Content []struct {
+		Type         int        `json:"type"` // 0为翻译，1为音译
+		LyricContent [][]string `json:"lyricContent"`
+	} `json:"content"`
+}
+
+// applyLanguage 解析 [language:] 标签，把翻译和音译写入对应的行
+// 翻译每行一个字符串，音译每个字一个字符串，字数一致时同时写入逐字音译
+func (t *Timeline) applyLanguage(value string, sources []int) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return
+	}
+	var language krcLanguage
+	if err := json.Unmarshal(data, &language); err != nil {
+		return
+	}
+
+	for _, content := range language.Content {
+		layer := ""
+		switch content.Type {
+		case 0:
+			layer = LayerTranslation
+		case 1:
+			layer = LayerRomanization
+		default:
+			continue
+		}
+
+		found := false
+		for i := range t.Lines {
+			if sources[i] >= len(content.LyricContent) {
+				continue
+			}
+			parts := content.LyricContent[sources[i]]
+			text := strings.TrimSpace(strings.Join(parts, ""))
+			if text == "" {
+				continue
+			}
+			found = true
+
+			line := &t.Lines[i]
+			if layer == LayerTranslation {
+				line.Translation = text
+				continue
+			}
+			line.Romanization = strings.Join(strings.Fields(strings.Join(parts, "")), " ")
+			if len(parts) == len(line.Words) {
+				for j := range line.Words {
+					line.Words[j].Romanization = strings.TrimSpace(parts[j])
+				}
+			}
+		}
+		if found {
+			t.Layers = append(t.Layers, layer)
+		}
+	}
+}
+
+// WithLayers 返回只保留指定附加层的行，用于按设置显示翻译和音译
+func (l Line) WithLayers(translation bool, romanization bool) Line {
+	if !translation {
+		l.Translation = ""
+	}
+	if !romanization && l.Romanization != "" {
+		l.Romanization = ""
+		words := make([]Word, len(l.Words))
+		for i, word := range l.Words {
+			word.Romanization = ""
+			words[i] = word
+		}
+		l.Words = words
+	}
+	return l
+}
+
+// WithOffset 返回整体偏移后的时间轴，offset 与 [offset:] 标签含义相同，正值让歌词提前显示
+// 用于叠加用户为某首歌调整的偏移，原时间轴不会被修改
+func (t Timeline) WithOffset(offset int64) Timeline {
+	if offset == 0 {
+		return t
+	}
+	shifted := Timeline{Offset: offset}
+	t.Offset += offset
+	t.Lines = append([]Line(nil), t.Lines...)
+	for i := range t.Lines {
+		line := &t.Lines[i]
+		line.Start = shifted.shift(line.Start)
+		line.Words = append([]Word(nil), line.Words...)
+		for j := range line.Words {
+			line.Words[j].Start = shifted.shift(line.Words[j].Start)
+		}
+	}
+	return t
+}
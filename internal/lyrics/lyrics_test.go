@@ -0,0 +1,202 @@
+package lyrics
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	cases := map[string]string{
+		"[171960,5040]<0,240,0>你<240,150,0>走": FormatKRC,
+		"[ti:歌名]\n[00:12.34]歌词":               FormatLRC,
+		"[01:02]歌词":                           FormatLRC,
+		"第一行\n第二行":                            FormatPlain,
+		"[副歌] 第一行":                            FormatPlain,
+	}
+	for content, want := range cases {
+		if got := DetectFormat(content); got != want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", content, got, want)
+		}
+	}
+}
+
+func TestParseLRC(t *testing.T) {
+	content := "\ufeff[ti:晴天]\r\n[ar:周杰伦]\r\n[offset:500]\r\n" +
+		"[00:10.00][00:40.50]故事的小黄花\r\n" +
+		"[00:20.5]从出生那年就飘着\r\n" +
+		"[00:30.123]\r\n" +
+		"[00:50:20]童年的荡秋千\r\n"
+
+	timeline := Parse(content)
+	if timeline.Format != FormatLRC || !timeline.Synced || timeline.Offset != 500 {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+	if timeline.Meta["ti"] != "晴天" || timeline.Meta["ar"] != "周杰伦" {
+		t.Errorf("meta = %v", timeline.Meta)
+	}
+
+	// offset 为正时歌词提前 500ms，空文本的时间标签结束上一行
+	want := []Line{
+		{Start: 9500, Duration: 10500, Text: "故事的小黄花"},
+		{Start: 20000, Duration: 9623, Text: "从出生那年就飘着"},
+		{Start: 40000, Duration: 9700, Text: "故事的小黄花"},
+		{Start: 49700, Text: "童年的荡秋千"},
+	}
+	if !reflect.DeepEqual(timeline.Lines, want) {
+		t.Errorf("lines = %+v", timeline.Lines)
+	}
+}
+
+func TestParseKRC(t *testing.T) {
+	content := "[id:$00000000]\n[ar:陈奕迅]\n[ti:富士山下]\n[offset:-100]\n" +
+		"[2000,1000]<0,400,0>Hello <400,600,0>world\n" +
+		"[1000,800]<0,300,0>拦<300,500,0>路\n" +
+		"[3000,500]纯文本行\n" +
+		"[4000,500]<0,500,0> \n"
+
+	timeline := Parse(content)
+	if timeline.Format != FormatKRC || timeline.Offset != -100 || timeline.Meta["ti"] != "富士山下" {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+
+	want := []Line{
+		{Start: 1100, Duration: 800, Text: "拦路", Words: []Word{
+			{Start: 1100, Duration: 300, Text: "拦"},
+			{Start: 1400, Duration: 500, Text: "路"},
+		}},
+		{Start: 2100, Duration: 1000, Text: "Hello world", Words: []Word{
+			{Start: 2100, Duration: 400, Text: "Hello "},
+			{Start: 2500, Duration: 600, Text: "world"},
+		}},
+		{Start: 3100, Duration: 500, Text: "纯文本行"},
+	}
+	if !reflect.DeepEqual(timeline.Lines, want) {
+		t.Errorf("lines = %+v", timeline.Lines)
+	}
+}
+
+func TestParsePlain(t *testing.T) {
+	timeline := Parse("[ti:纯音乐]\n\n第一行\n[副歌] 第二行\n")
+	if timeline.Format != FormatPlain || timeline.Synced || timeline.Meta["ti"] != "纯音乐" {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+	want := []Line{{Text: "第一行"}, {Text: "[副歌] 第二行"}}
+	if !reflect.DeepEqual(timeline.Lines, want) {
+		t.Errorf("lines = %+v", timeline.Lines)
+	}
+
+	if empty := Parse(""); empty.Lines == nil || len(empty.Lines) != 0 {
+		t.Errorf("empty lyrics should have an empty line list: %+v", empty)
+	}
+}
+
+func TestLineAndWordAt(t *testing.T) {
+	timeline := Parse("[1000,800]<0,300,0>拦<300,500,0>路\n[3000,500]<0,200,0>富<200,300,0>士\n")
+
+	cases := []struct {
+		ms   int64
+		line int
+		word int
+	}{
+		{0, -1, -1},
+		{1000, 0, 0},
+		{1299, 0, 0},
+		{1300, 0, 1},
+		{2500, 0, 1}, // 间奏时仍显示上一行
+		{3250, 1, 1},
+		{9000, 1, 1},
+	}
+	for _, c := range cases {
+		line := timeline.LineAt(c.ms)
+		word := -1
+		if line >= 0 {
+			word = timeline.Lines[line].WordAt(c.ms)
+		}
+		if line != c.line || word != c.word {
+			t.Errorf("at %dms: line %d word %d, want line %d word %d", c.ms, line, word, c.line, c.word)
+		}
+	}
+
+	if plain := Parse("第一行"); plain.LineAt(1000) != -1 {
+		t.Error("unsynced lyrics should have no current line")
+	}
+}
+
+func TestParseLRCSameTimestamp(t *testing.T) {
+	// 原文和翻译使用相同的时间标签，空文本的标签不影响合并
+	content := "[00:01.00]Yesterday\n" +
+		"[00:01.00]昨天\n" +
+		"[00:01.00]\n" +
+		"[00:04.00]All my troubles seemed so far away\n" +
+		"[00:04.00]烦恼似乎远去\n" +
+		"[00:09.00]Now it looks as though they're here to stay\n"
+
+	timeline := Parse(content)
+	want := []Line{
+		{Start: 1000, Duration: 3000, Text: "Yesterday", Translation: "昨天"},
+		{Start: 4000, Duration: 5000, Text: "All my troubles seemed so far away", Translation: "烦恼似乎远去"},
+		{Start: 9000, Text: "Now it looks as though they're here to stay"},
+	}
+	if !reflect.DeepEqual(timeline.Lines, want) {
+		t.Errorf("lines = %+v", timeline.Lines)
+	}
+	if !reflect.DeepEqual(timeline.Layers, []string{LayerTranslation}) {
+		t.Errorf("layers = %v", timeline.Layers)
+	}
+}
+
+func TestParseKRCLanguageLayers(t *testing.T) {
+	// 第二行没有文本，但仍占用附加层中的一行
+	language := `{"content":[` +
+		`{"language":0,"type":1,"lyricContent":[["a ","na ","ta"],[],["ha","ru"]]},` +
+		`{"language":0,"type":0,"lyricContent":[["你"],[""],["春天"]]}` +
+		`],"version":1}`
+	content := "[ti:テスト]\n[language:" + base64.StdEncoding.EncodeToString([]byte(language)) + "]\n" +
+		"[1000,900]<0,300,0>あ<300,300,0>な<600,300,0>た\n" +
+		"[2000,500]\n" +
+		"[3000,500]<0,250,0>は<250,250,0>る\n"
+
+	timeline := Parse(content)
+	if _, ok := timeline.Meta["language"]; ok {
+		t.Error("language tag should not be kept in meta")
+	}
+	if !reflect.DeepEqual(timeline.Layers, []string{LayerRomanization, LayerTranslation}) {
+		t.Errorf("layers = %v", timeline.Layers)
+	}
+	if len(timeline.Lines) != 2 {
+		t.Fatalf("lines = %+v", timeline.Lines)
+	}
+
+	first, second := timeline.Lines[0], timeline.Lines[1]
+	if first.Translation != "你" || first.Romanization != "a na ta" || first.Words[1].Romanization != "na" {
+		t.Errorf("first line = %+v", first)
+	}
+	if second.Translation != "春天" || second.Romanization != "haru" || second.Words[0].Romanization != "ha" {
+		t.Errorf("second line = %+v", second)
+	}
+
+	// 只保留翻译时去掉音译，且不修改原来的行
+	filtered := first.WithLayers(true, false)
+	if filtered.Translation != "你" || filtered.Romanization != "" || filtered.Words[0].Romanization != "" {
+		t.Errorf("filtered = %+v", filtered)
+	}
+	if first.Words[0].Romanization != "a" {
+		t.Error("WithLayers should not modify the original words")
+	}
+}
+
+func TestWithOffset(t *testing.T) {
+	timeline := Parse("[offset:100]\n[1000,500]<0,200,0>拦<200,300,0>路\n")
+	shifted := timeline.WithOffset(-300)
+
+	if shifted.Offset != -200 || shifted.Lines[0].Start != 1200 || shifted.Lines[0].Words[1].Start != 1400 {
+		t.Errorf("shifted = %+v", shifted)
+	}
+	if timeline.Offset != 100 || timeline.Lines[0].Start != 900 || timeline.Lines[0].Words[1].Start != 1100 {
+		t.Errorf("original timeline should not change: %+v", timeline)
+	}
+	if early := timeline.WithOffset(5000); early.Lines[0].Start != 0 {
+		t.Errorf("times should not go below zero: %+v", early.Lines[0])
+	}
+}
@@ -0,0 +1,188 @@
+// Package mockapi 提供一个模拟酷狗音乐API的HTTP服务，用于离线开发和测试
+//
+// 响应数据来自内嵌的 fixtures 目录，请求路径 /a/b 对应文件 a_b.json，
+// 带有 type 或 fmt 参数时优先使用 a_b_<值>.json（如 /search?type=song -> search_song.json）。
+package mockapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// variantParams 用于选择同一路径下不同响应文件的查询参数
+var variantParams = []string{"type", "fmt"}
+
+// Request 记录一次收到的请求，便于测试断言参数与Cookie
+type Request struct {
+	Path   string
+	Query  url.Values
+	Cookie string
+}
+
+// Handler 模拟API的请求处理器，可单独挂载到任意HTTP服务
+type Handler struct {
+	mutex     sync.Mutex
+	overrides map[string]http.HandlerFunc
+	requests  []Request
+}
+
+// NewHandler 创建模拟API处理器
+func NewHandler() *Handler {
+	return &Handler{
+		overrides: make(map[string]http.HandlerFunc),
+	}
+}
+
+// Handle 为指定路径注册自定义处理函数，优先于内置数据
+func (h *Handler) Handle(path string, handler http.HandlerFunc) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.overrides[normalizePath(path)] = handler
+}
+
+// HandleJSON 让指定路径固定返回给定的JSON内容
+func (h *Handler) HandleJSON(path string, body string) {
+	h.Handle(path, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []byte(body))
+	})
+}
+
+// Requests 返回已收到的所有请求
+func (h *Handler) Requests() []Request {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]Request(nil), h.requests...)
+}
+
+// LastRequest 返回指定路径最近一次收到的请求
+func (h *Handler) LastRequest(path string) (Request, bool) {
+	path = normalizePath(path)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for i := len(h.requests) - 1; i >= 0; i-- {
+		if h.requests[i].Path == path {
+			return h.requests[i], true
+		}
+	}
+	return Request{}, false
+}
+
+// Reset 清空请求记录与自定义处理函数
+func (h *Handler) Reset() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.overrides = make(map[string]http.HandlerFunc)
+	h.requests = nil
+}
+
+// ServeHTTP 按路径返回自定义处理结果或内置数据
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := normalizePath(r.URL.Path)
+	query := r.URL.Query()
+
+	h.mutex.Lock()
+	h.requests = append(h.requests, Request{
+		Path:   path,
+		Query:  query,
+		Cookie: query.Get("cookie"),
+	})
+	override := h.overrides[path]
+	h.mutex.Unlock()
+
+	if override != nil {
+		override(w, r)
+		return
+	}
+
+	// 根路径用于健康检查
+	if path == "/" {
+		writeJSON(w, http.StatusOK, []byte(`{"status":1,"msg":"mock api"}`))
+		return
+	}
+
+	body, err := lookupFixture(path, query)
+	if err != nil {
+		notFound, _ := json.Marshal(map[string]any{
+			"status":     0,
+			"error_code": http.StatusNotFound,
+			"error_msg":  "mock api: no fixture for " + path,
+		})
+		writeJSON(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// Server 基于 httptest 的模拟API服务
+type Server struct {
+	*Handler
+	URL    string
+	server *httptest.Server
+}
+
+// New 启动一个监听本地随机端口的模拟API服务
+func New() *Server {
+	handler := NewHandler()
+	server := httptest.NewServer(handler)
+	return &Server{
+		Handler: handler,
+		URL:     server.URL,
+		server:  server,
+	}
+}
+
+// Close 关闭模拟API服务
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Fixture 读取内置的响应数据，name 不含 .json 后缀
+func Fixture(name string) ([]byte, error) {
+	return fixtures.ReadFile("fixtures/" + name + ".json")
+}
+
+// Fixtures 列出所有内置响应数据的名称
+func Fixtures() []string {
+	entries, _ := fs.ReadDir(fixtures, "fixtures")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return names
+}
+
+// lookupFixture 根据路径和查询参数查找响应数据
+func lookupFixture(path string, query url.Values) ([]byte, error) {
+	name := strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+	for _, param := range variantParams {
+		if value := query.Get(param); value != "" {
+			if body, err := Fixture(name + "_" + value); err == nil {
+				return body, nil
+			}
+		}
+	}
+	return Fixture(name)
+}
+
+// normalizePath 清理路径，保证以 / 开头且没有末尾斜杠
+func normalizePath(p string) string {
+	return path.Clean("/" + p)
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
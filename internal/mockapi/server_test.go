@@ -0,0 +1,127 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func get(t *testing.T, rawURL string) (int, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatalf("GET %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode %s: %v\n%s", rawURL, err, body)
+	}
+	return resp.StatusCode, payload
+}
+
+func TestFixturesAreValidJSON(t *testing.T) {
+	names := Fixtures()
+	if len(names) == 0 {
+		t.Fatal("no fixtures embedded")
+	}
+	for _, name := range names {
+		body, err := Fixture(name)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if !json.Valid(body) {
+			t.Errorf("fixture %s is not valid JSON", name)
+		}
+	}
+}
+
+func TestServeFixtureByPath(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+
+	status, payload := get(t, srv.URL+"/login/qr/key?timestamp=1")
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	data, _ := payload["data"].(map[string]any)
+	if data["qrcode"] != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("qrcode = %v", data["qrcode"])
+	}
+}
+
+func TestServeFixtureVariant(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+
+	_, songs := get(t, srv.URL+"/search?keywords=x&type=song")
+	_, artists := get(t, srv.URL+"/search?keywords=x&type=author")
+	songList := songs["data"].(map[string]any)["lists"].([]any)
+	artistList := artists["data"].(map[string]any)["lists"].([]any)
+	if _, ok := songList[0].(map[string]any)["FileHash"]; !ok {
+		t.Errorf("type=song did not serve song fixture: %v", songList[0])
+	}
+	if _, ok := artistList[0].(map[string]any)["AuthorName"]; !ok {
+		t.Errorf("type=author did not serve author fixture: %v", artistList[0])
+	}
+
+	// 没有对应变体时回退到默认数据
+	_, lrc := get(t, srv.URL+"/lyric?fmt=lrc")
+	_, krc := get(t, srv.URL+"/lyric?fmt=krc")
+	if lrc["fmt"] != "lrc" || krc["fmt"] != "krc" {
+		t.Errorf("fmt variants = %v, %v", lrc["fmt"], krc["fmt"])
+	}
+}
+
+func TestHealthAndNotFound(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+
+	if status, _ := get(t, srv.URL+"/"); status != http.StatusOK {
+		t.Errorf("health status = %d, want 200", status)
+	}
+	status, payload := get(t, srv.URL+"/no/such/endpoint")
+	if status != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", status)
+	}
+	if payload["status"] != float64(0) {
+		t.Errorf("payload status = %v, want 0", payload["status"])
+	}
+}
+
+func TestOverrideAndRequestLog(t *testing.T) {
+	srv := New()
+	defer srv.Close()
+
+	srv.HandleJSON("/song/url/", `{"status":0,"error_code":20010}`)
+	_, payload := get(t, srv.URL+"/song/url?hash=abc&cookie=token%3Dt%3Buserid%3D1")
+	if payload["error_code"] != float64(20010) {
+		t.Errorf("override not used: %v", payload)
+	}
+
+	req, ok := srv.LastRequest("/song/url")
+	if !ok {
+		t.Fatal("request not recorded")
+	}
+	if req.Query.Get("hash") != "abc" {
+		t.Errorf("hash = %q", req.Query.Get("hash"))
+	}
+	if req.Cookie != "token=t;userid=1" {
+		t.Errorf("cookie = %q", req.Cookie)
+	}
+
+	srv.Reset()
+	if len(srv.Requests()) != 0 {
+		t.Error("Reset did not clear requests")
+	}
+	if _, payload := get(t, srv.URL+"/song/url?hash=abc"); payload["status"] != float64(1) {
+		t.Errorf("Reset did not remove override: %v", payload)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dhowden/tag"
+
+	"wmplayer/internal/lyrics"
+)
+
+// localLyricsExts 本地歌词文件的扩展名，同名时LRC优先，调整的歌词偏移可以写回LRC文件
+var localLyricsExts = []string{".lrc", ".LRC", ".krc", ".KRC"}
+
+// localLyricsDurationTolerance 在线搜索本地音乐歌词时，候选歌词与音频时长允许的误差（毫秒）
+const localLyricsDurationTolerance = 3000
+
+// unknownArtist 本地音乐没有歌手标签时显示的名称
+const unknownArtist = "未知艺术家"
+
+// ApplyLocalMusicSettings 应用本地音乐设置中的歌词文件夹和在线歌词开关
+func (c *CacheService) ApplyLocalMusicSettings(settings LocalMusicSettings) {
+	c.localMusicMutex.Lock()
+	c.localMusicSettings = settings
+	c.localMusicMutex.Unlock()
+}
+
+// localMusicConfig 获取当前的本地音乐设置
+func (c *CacheService) localMusicConfig() LocalMusicSettings {
+	c.localMusicMutex.RLock()
+	defer c.localMusicMutex.RUnlock()
+	return c.localMusicSettings
+}
+
+// currentLocalMusicSettings 获取本地音乐设置，缓存服务未创建时使用默认设置
+func currentLocalMusicSettings() LocalMusicSettings {
+	if cacheService := GetCacheService(); cacheService != nil {
+		return cacheService.localMusicConfig()
+	}
+	return LocalMusicSettings{OnlineLyrics: true}
+}
+
+// lyricsFileNames 歌词文件夹中可能的歌词文件名（不含扩展名）：音频文件名、“歌手 - 歌名”和歌名
+func lyricsFileNames(audioPath string, title string, artist string) []string {
+	base := filepath.Base(audioPath)
+	names := []string{strings.TrimSuffix(base, filepath.Ext(base))}
+	if title != "" {
+		if artist != "" && artist != unknownArtist {
+			names = append(names, artist+" - "+title)
+		}
+		names = append(names, title)
+	}
+	return names
+}
+
+// findLyricsFile 查找本地音乐的歌词文件，没有时返回空字符串
+// 先找音频同目录的同名 .lrc/.krc 文件，再在歌词文件夹中按 lyricsFileNames 查找
+func findLyricsFile(audioPath string, lyricsFolder string, title string, artist string) string {
+	candidates := make([]string, 0, len(localLyricsExts)*4)
+	sidecar := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+	for _, ext := range localLyricsExts {
+		candidates = append(candidates, sidecar+ext)
+	}
+	if lyricsFolder != "" {
+		for _, name := range lyricsFileNames(audioPath, title, artist) {
+			// 歌名中可能含有路径分隔符，这类文件名无法直接使用
+			if strings.ContainsAny(name, `/\`) {
+				continue
+			}
+			for _, ext := range localLyricsExts {
+				candidates = append(candidates, filepath.Join(lyricsFolder, name+ext))
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// readLyricsFile 读取歌词文件，酷狗客户端加密的KRC文件自动解密
+func readLyricsFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	content, err := lyrics.DecodeFile(data)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(content, "\ufeff"), nil
+}
+
+// readLyricsTags 读取音频的歌名和歌手标签，用于在歌词文件夹中查找歌词
+func readLyricsTags(audioPath string) (string, string) {
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return "", ""
+	}
+	defer file.Close()
+
+	metadata, err := tag.ReadFrom(file)
+	if err != nil {
+		return "", ""
+	}
+	return metadata.Title(), metadata.Artist()
+}
+
+// localLyricsCacheKey 本地音乐在线歌词的缓存key，与本地音乐映射的hash一致
+func localLyricsCacheKey(fileHash string) string {
+	return "local-" + fileHash
+}
+
+// fetchLocalLyrics 返回按歌名、歌手和时长（秒）在线搜索本地音乐歌词的获取函数
+// 没有时长相近的候选歌词时返回 NotFound 的记录，避免匹配到同名的其它歌曲
+func (h *HomepageService) fetchLocalLyrics(title string, artist string, duration int) func(key string) (*cachedLyrics, error) {
+	return func(key string) (*cachedLyrics, error) {
+		if GlobalBackendManager.IsOffline() {
+			return nil, &ApiError{Code: ApiErrOffline, Message: "离线模式下无法获取歌词"}
+		}
+		if lyrics, ok := h.fetchChosenLyrics(key); ok {
+			return lyrics, nil
+		}
+
+		keyword := title
+		if artist != "" && artist != unknownArtist {
+			keyword = artist + " - " + title
+		}
+		params := url.Values{
+			"keywords": {keyword},
+			"man":      {"yes"}, // 返回全部候选歌词，按时长挑选
+		}
+		if duration > 0 {
+			params.Set("duration", strconv.Itoa(duration*1000))
+		}
+
+		candidates, err := searchLyricCandidates(params)
+		if err != nil {
+			if _, ok := err.(*ApiError); ok {
+				return nil, err
+			}
+			return &cachedLyrics{NotFound: true}, nil
+		}
+
+		candidate := pickLyricsCandidate(candidates, int64(duration)*1000)
+		if candidate == nil {
+			fmt.Printf("📝 没有时长匹配的在线歌词: %s\n", keyword)
+			return &cachedLyrics{NotFound: true}, nil
+		}
+
+		result, err := h.downloadLyrics(candidate)
+		if err == nil && !result.NotFound {
+			fmt.Printf("✅ 获取到本地音乐的在线歌词: %s -> %s - %s\n", keyword, candidate.Singer, candidate.Song)
+		}
+		return result, err
+	}
+}
+
+// pickLyricsCandidate 选择时长误差在容差内、评分最高的候选歌词
+// duration 为0（时长未知）或候选歌词没有时长时不按时长过滤
+func pickLyricsCandidate(candidates []LyricsSearchData, duration int64) *LyricsSearchData {
+	var best *LyricsSearchData
+	for i := range candidates {
+		candidate := &candidates[i]
+		if duration > 0 && candidate.Duration > 0 {
+			diff := int64(candidate.Duration) - duration
+			if diff > localLyricsDurationTolerance || diff < -localLyricsDurationTolerance {
+				continue
+			}
+		}
+		if best == nil || candidate.Score > best.Score {
+			best = candidate
+		}
+	}
+	return best
+}
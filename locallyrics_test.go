@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile 写入测试文件并返回路径
+func writeTestFile(t *testing.T, path string, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindLyricsFile(t *testing.T) {
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "lyrics")
+	audio := writeTestFile(t, filepath.Join(dir, "music", "01 晴天.flac"), "audio")
+
+	if found := findLyricsFile(audio, folder, "晴天", "周杰伦"); found != "" {
+		t.Fatalf("found = %s, want none", found)
+	}
+
+	// 歌词文件夹中按“歌手 - 歌名”查找
+	byTitle := writeTestFile(t, filepath.Join(folder, "周杰伦 - 晴天.krc"), "[1000,500]晴天")
+	if found := findLyricsFile(audio, folder, "晴天", "周杰伦"); found != byTitle {
+		t.Errorf("found = %s, want %s", found, byTitle)
+	}
+	if found := findLyricsFile(audio, "", "晴天", "周杰伦"); found != "" {
+		t.Errorf("lyrics folder should only be used when configured: %s", found)
+	}
+
+	// 同目录的同名文件优先，LRC优先于KRC
+	writeTestFile(t, filepath.Join(dir, "music", "01 晴天.krc"), "[1000,500]晴天")
+	sidecar := writeTestFile(t, filepath.Join(dir, "music", "01 晴天.lrc"), "[00:01.00]晴天")
+	if found := findLyricsFile(audio, folder, "晴天", "周杰伦"); found != sidecar {
+		t.Errorf("found = %s, want %s", found, sidecar)
+	}
+}
+
+func TestGetLocalMusicLyricsFromFile(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+	newTestAppState(t, c)
+
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "lyrics")
+	c.ApplyLocalMusicSettings(LocalMusicSettings{LyricsFolder: folder})
+	audio := writeTestFile(t, filepath.Join(dir, "song.mp3"), "not really audio")
+	writeTestFile(t, filepath.Join(folder, "song.lrc"), "\ufeff[00:01.00]歌词文件夹")
+
+	l := &LocalMusicService{}
+	if resp := l.GetLocalMusicLyrics(audio); !resp.Success || resp.Data != "[00:01.00]歌词文件夹" {
+		t.Errorf("folder lyrics = %+v", resp)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "song.lrc"), "[00:01.00]同名文件")
+	if resp := l.GetLocalMusicLyrics(audio); resp.Data != "[00:01.00]同名文件" {
+		t.Errorf("sidecar lyrics = %+v", resp)
+	}
+
+	// 关闭在线歌词且没有歌词文件时不访问网络
+	other := writeTestFile(t, filepath.Join(dir, "other.mp3"), "other audio")
+	if resp := l.GetLocalMusicLyrics(other); resp.Success {
+		t.Errorf("resp = %+v, want no lyrics", resp)
+	}
+}
+
+func TestGetLocalMusicLyricsOnline(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+	newTestAppState(t, c)
+
+	audio := writeTestFile(t, filepath.Join(t.TempDir(), "晴天.mp3"), "not really audio")
+	l := &LocalMusicService{}
+	first := l.GetLocalMusicLyrics(audio)
+	if !first.Success || !strings.Contains(first.Data, "[29350,3220]") {
+		t.Fatalf("first = %+v, want online KRC", first)
+	}
+	request, ok := srv.LastRequest("/search/lyric")
+	if !ok || request.Query.Get("keywords") != "晴天" || request.Query.Get("man") != "yes" {
+		t.Errorf("search request = %+v", request)
+	}
+
+	// 匹配结果按文件hash缓存，再次获取时不再搜索
+	srv.Reset()
+	if second := l.GetLocalMusicLyrics(audio); second.Data != first.Data {
+		t.Errorf("second = %+v", second)
+	}
+	if _, ok := srv.LastRequest("/search/lyric"); ok {
+		t.Error("cached local lyrics should not be searched again")
+	}
+}
+
+func TestPickLyricsCandidate(t *testing.T) {
+	candidates := []LyricsSearchData{
+		{ID: "live", Score: 90, Duration: 300000},
+		{ID: "studio", Score: 60, Duration: 269000},
+		{ID: "unknown", Score: 50},
+	}
+	if best := pickLyricsCandidate(candidates, 270000); best == nil || best.ID != "studio" {
+		t.Errorf("best = %+v, want studio", best)
+	}
+	if best := pickLyricsCandidate(candidates, 0); best == nil || best.ID != "live" {
+		t.Errorf("best without duration = %+v, want live", best)
+	}
+	if best := pickLyricsCandidate(candidates[:1], 200000); best != nil {
+		t.Errorf("best = %+v, want none", best)
+	}
+}
@@ -17,6 +17,17 @@ import (
 // LocalMusicService 本地音乐服务结构体
 type LocalMusicService struct{}
 
+// localAudioFormats 支持的本地音频格式
+var localAudioFormats = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".wav":  true,
+	".m4a":  true,
+	".aac":  true,
+	".ogg":  true,
+	".wma":  true,
+}
+
 // LocalMusicFile 本地音乐文件信息
 type LocalMusicFile struct {
 	FilePath     string `json:"file_path"`     // 文件路径
@@ -32,10 +43,28 @@ type LocalMusicFile struct {
 	Format       string `json:"format"`        // 文件格式
 	Hash         string `json:"hash"`          // 文件哈希值
 	LastModified int64  `json:"last_modified"` // 最后修改时间
+	AddedAt      int64  `json:"added_at"`      // 加入音乐库的时间
 	UnionCover   string `json:"union_cover"`   // 封面图片URL
 	Lyrics       string `json:"lyrics"`        // 歌词内容
 }
 
+// playlistSong 转换为播放列表中的歌曲，hash 与前端播放本地音乐时使用的一致
+func (f LocalMusicFile) playlistSong() PlayerPlaylistSong {
+	songName := f.Title
+	if songName == "" {
+		songName = f.Filename
+	}
+	return PlayerPlaylistSong{
+		Hash:       "local-" + f.Hash,
+		SongName:   songName,
+		Filename:   f.Filename,
+		ArtistName: f.Artist,
+		AlbumName:  f.Album,
+		Duration:   f.Duration,
+		UnionCover: f.UnionCover,
+	}
+}
+
 // LocalMusicResponse 本地音乐响应结构
 type LocalMusicResponse struct {
 	Success bool             `json:"success"`
@@ -179,15 +208,6 @@ func (l *LocalMusicService) ScanMusicFolder(folderPath string) LocalMusicRespons
 	}
 
 	var musicFiles []LocalMusicFile
-	supportedFormats := map[string]bool{
-		".mp3":  true,
-		".flac": true,
-		".wav":  true,
-		".m4a":  true,
-		".aac":  true,
-		".ogg":  true,
-		".wma":  true,
-	}
 
 	// 遍历文件夹
 	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
@@ -201,7 +221,7 @@ func (l *LocalMusicService) ScanMusicFolder(folderPath string) LocalMusicRespons
 
 		// 检查文件扩展名
 		ext := strings.ToLower(filepath.Ext(path))
-		if !supportedFormats[ext] {
+		if !localAudioFormats[ext] {
 			return nil // 跳过不支持的格式
 		}
 
@@ -244,6 +264,44 @@ func (l *LocalMusicService) ScanMusicFolder(folderPath string) LocalMusicRespons
 	}
 }
 
+// OpenMusicFiles 解析指定的音乐文件并注册本地音乐映射，用于从命令行或文件管理器打开文件
+// 不存在或不支持的文件会被跳过，不修改扫描结果的缓存
+func (l *LocalMusicService) OpenMusicFiles(filePaths []string) LocalMusicResponse {
+	musicFiles := []LocalMusicFile{}
+	for _, filePath := range filePaths {
+		info, err := os.Stat(filePath)
+		if err != nil || info.IsDir() || !localAudioFormats[strings.ToLower(filepath.Ext(filePath))] {
+			fmt.Printf("⚠️ 跳过无法打开的文件: %s\n", filePath)
+			continue
+		}
+		musicFile, err := l.parseMusicFile(filePath)
+		if err != nil {
+			fmt.Printf("解析音乐文件失败 %s: %v\n", filePath, err)
+			continue
+		}
+		musicFiles = append(musicFiles, *musicFile)
+	}
+
+	if len(musicFiles) == 0 {
+		return LocalMusicResponse{
+			Success: false,
+			Message: "没有可以播放的音乐文件",
+			Data:    musicFiles,
+		}
+	}
+
+	if err := l.generateLocalMusicMappings(musicFiles); err != nil {
+		fmt.Printf("生成本地音乐映射失败: %v\n", err)
+	}
+
+	return LocalMusicResponse{
+		Success: true,
+		Message: fmt.Sprintf("打开了 %d 首音乐", len(musicFiles)),
+		Data:    musicFiles,
+		Stats:   l.calculateStats(musicFiles),
+	}
+}
+
 // parseMusicFile 解析音乐文件
 func (l *LocalMusicService) parseMusicFile(filePath string) (*LocalMusicFile, error) {
 	// 打开文件
@@ -410,10 +468,40 @@ func (l *LocalMusicService) cacheMusicFiles(musicFiles []LocalMusicFile) error {
 
 	cacheFile := filepath.Join(cacheDir, "music_cache.json")
 
+	// 加入时间取第一次扫描到文件的时间，之后修改标签也不会变化
+	// 复制音乐时常保留原来的修改时间，所以只在还没有记录时用修改时间补齐已有的文件
+	// 单独记录所有扫描过的文件，只扫描一个文件夹时不会丢失其他文件夹的记录
+	addedTimes, recorded := l.loadAddedTimes(cacheFile)
+	now := time.Now().Unix()
+	scanned := make(map[string]bool, len(musicFiles))
+	for i := range musicFiles {
+		path := musicFiles[i].FilePath
+		scanned[path] = true
+		added, ok := addedTimes[path]
+		if !ok {
+			added = now
+			if !recorded {
+				added = musicFiles[i].LastModified
+			}
+			addedTimes[path] = added
+		}
+		musicFiles[i].AddedAt = added
+	}
+	// 去掉已经不存在的文件的记录
+	for path := range addedTimes {
+		if scanned[path] {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(addedTimes, path)
+		}
+	}
+
 	// 创建缓存数据
 	cacheData := map[string]interface{}{
 		"timestamp":   time.Now().Unix(),
 		"music_files": musicFiles,
+		"added_times": addedTimes,
 	}
 
 	// 序列化为JSON
@@ -426,6 +514,20 @@ func (l *LocalMusicService) cacheMusicFiles(musicFiles []LocalMusicFile) error {
 	return os.WriteFile(cacheFile, jsonData, 0644)
 }
 
+// loadAddedTimes 读取缓存中记录的文件加入时间，缓存中还没有记录时返回 false
+func (l *LocalMusicService) loadAddedTimes(cacheFile string) (map[string]int64, bool) {
+	var cacheData struct {
+		AddedTimes map[string]int64 `json:"added_times"`
+	}
+	if data, err := os.ReadFile(cacheFile); err == nil {
+		json.Unmarshal(data, &cacheData)
+	}
+	if cacheData.AddedTimes == nil {
+		return make(map[string]int64), false
+	}
+	return cacheData.AddedTimes, true
+}
+
 // GetCachedMusicFiles 获取缓存的音乐文件
 func (l *LocalMusicService) GetCachedMusicFiles() LocalMusicResponse {
 	cacheDir, err := l.getCacheDir()
@@ -478,6 +580,8 @@ func (l *LocalMusicService) GetCachedMusicFiles() LocalMusicResponse {
 		itemBytes, _ := json.Marshal(item)
 		var musicFile LocalMusicFile
 		if err := json.Unmarshal(itemBytes, &musicFile); err == nil {
+			// 封面URL中的令牌每次运行都不同
+			musicFile.UnionCover = refreshLocalCacheURL(musicFile.UnionCover)
 			musicFiles = append(musicFiles, musicFile)
 		}
 	}
@@ -948,17 +1052,58 @@ func (l *LocalMusicService) GetLocalMusicLyrics(filePath string) CacheResponse {
 		}
 	}
 
-	if musicFile.Lyrics == "" {
+	settings := currentLocalMusicSettings()
+	h := NewHomepageService(GetCacheService(), GetAppState())
+	key := localLyricsCacheKey(musicFile.Hash)
+	fetch := h.fetchLocalLyrics(musicFile.Title, musicFile.Artist, musicFile.Duration)
+
+	// 用户在歌词选择中选过的歌词优先
+	if _, chosen := h.lyricsChoice(key); musicFile.Hash != "" && chosen {
+		if content := h.cachedLyricsContent(key, fetch, lyricsFetchTimeout); content != "" {
+			return CacheResponse{
+				Success: true,
+				Message: "获取歌词成功",
+				Data:    content,
+			}
+		}
+	}
+
+	// 其次使用同名歌词文件和歌词文件夹中的歌词，然后是内嵌的歌词标签
+	if lyricsFile := findLyricsFile(filePath, settings.LyricsFolder, musicFile.Title, musicFile.Artist); lyricsFile != "" {
+		content, err := readLyricsFile(lyricsFile)
+		if err == nil && strings.TrimSpace(content) != "" {
+			fmt.Printf("✅ 使用歌词文件: %s\n", lyricsFile)
+			return CacheResponse{
+				Success: true,
+				Message: "获取歌词成功",
+				Data:    content,
+			}
+		}
+		fmt.Printf("⚠️ 读取歌词文件失败 %s: %v\n", lyricsFile, err)
+	}
+
+	if musicFile.Lyrics != "" {
 		return CacheResponse{
-			Success: false,
-			Message: "该音乐文件不包含歌词信息",
+			Success: true,
+			Message: "获取歌词成功",
+			Data:    musicFile.Lyrics,
+		}
+	}
+
+	// 都没有时按歌名、歌手和时长在线搜索，结果按文件hash缓存
+	if settings.OnlineLyrics && musicFile.Hash != "" {
+		if content := h.cachedLyricsContent(key, fetch, lyricsFetchTimeout); content != "" {
+			return CacheResponse{
+				Success: true,
+				Message: "获取在线歌词成功",
+				Data:    content,
+			}
 		}
 	}
 
 	return CacheResponse{
-		Success: true,
-		Message: "获取歌词成功",
-		Data:    musicFile.Lyrics,
+		Success: false,
+		Message: "未找到歌词",
 	}
 }
 
@@ -1026,7 +1171,13 @@ func (l *LocalMusicService) GetLocalAudioURL(filePath string) CacheResponse {
 	}
 
 	// 生成本地HTTP URL
-	localURL := fmt.Sprintf("http://127.0.0.1:18911/cache/mp3/%s", cachedFileName)
+	localURL := localCacheURL("mp3", cachedFileName)
+	if localURL == "" {
+		return CacheResponse{
+			Success: false,
+			Message: "缓存服务未初始化",
+		}
+	}
 
 	return CacheResponse{
 		Success: true,
@@ -1107,7 +1258,10 @@ func (l *LocalMusicService) saveCoverToCache(fileHash string, imageData []byte,
 	}
 
 	// 生成本地HTTP URL
-	coverURL := fmt.Sprintf("http://127.0.0.1:18911/cache/covers/%s", coverFileName)
+	coverURL := localCacheURL("covers", coverFileName)
+	if coverURL == "" {
+		return "", fmt.Errorf("缓存服务未初始化")
+	}
 	return coverURL, nil
 }
 
@@ -0,0 +1,665 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 本地歌单的错误
+var (
+	errLocalPlaylistNotFound = errors.New("本地歌单不存在")
+	errLocalPlaylistName     = errors.New("歌单名称不能为空")
+)
+
+// LocalPlaylist 保存在本机的歌单，与在线的用户歌单无关
+// 除了歌曲还记录播放位置，切换回来时从上次的位置继续播放
+type LocalPlaylist struct {
+	ID              string               `json:"id"`
+	Name            string               `json:"name"`
+	Songs           []PlayerPlaylistSong `json:"songs"`
+	CurrentIndex    int                  `json:"current_index"`
+	ShuffleOrder    []int                `json:"shuffle_order,omitempty"` // 随机播放顺序，包含已播放的记录
+	ShufflePosition int                  `json:"shuffle_position,omitempty"`
+	CreateTime      time.Time            `json:"create_time"`
+	UpdateTime      time.Time            `json:"update_time"`
+}
+
+// LocalPlaylistInfo 歌单列表中的本地歌单摘要
+type LocalPlaylistInfo struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Count        int       `json:"count"`
+	UnionCover   string    `json:"union_cover"` // 第一首歌曲的封面
+	CurrentIndex int       `json:"current_index"`
+	Active       bool      `json:"active"` // 是否正在播放
+	CreateTime   time.Time `json:"create_time"`
+	UpdateTime   time.Time `json:"update_time"`
+}
+
+// LocalPlaylistsResponse 本地歌单列表响应结构
+type LocalPlaylistsResponse = ApiResponse[[]LocalPlaylistInfo]
+
+// LocalPlaylistResponse 本地歌单响应结构
+type LocalPlaylistResponse = ApiResponse[LocalPlaylist]
+
+// CreateLocalPlaylistRequest 创建本地歌单请求
+type CreateLocalPlaylistRequest struct {
+	Name  string               `json:"name"`
+	Songs []PlayerPlaylistSong `json:"songs"` // 初始歌曲，可以为空
+}
+
+// localPlaylistsData 本地歌单文件的内容
+type localPlaylistsData struct {
+	Queue     *LocalPlaylist   `json:"queue"` // 播放本地歌单时保存的临时播放列表
+	Playlists []*LocalPlaylist `json:"playlists"`
+}
+
+// LocalPlaylistStore 保存本地歌单，正在播放的歌单由播放队列持有
+//
+// 播放队列中的歌曲和位置是正在播放的歌单的最新状态，读取前先同步回歌单；
+// 切换歌单时保存当前的播放位置，再把目标歌单恢复到播放队列中
+type LocalPlaylistStore struct {
+	file      string
+	queue     *PlayQueue
+	saveTimer *time.Timer
+	saveMutex sync.Mutex // 串行写盘，先取得的快照先写入
+	mutex     sync.Mutex
+
+	scratch   *LocalPlaylist // 临时播放列表，ID 为空
+	playlists []*LocalPlaylist
+}
+
+// NewLocalPlaylistStore 创建本地歌单存储并加载已保存的歌单
+func NewLocalPlaylistStore(file string, queue *PlayQueue) *LocalPlaylistStore {
+	s := &LocalPlaylistStore{
+		file:    file,
+		queue:   queue,
+		scratch: &LocalPlaylist{Name: defaultQueueName, CurrentIndex: -1},
+	}
+	if data, err := os.ReadFile(file); err == nil {
+		var saved localPlaylistsData
+		if err := json.Unmarshal(data, &saved); err != nil {
+			fmt.Printf("⚠️ 解析本地歌单失败: %v\n", err)
+		} else {
+			if saved.Queue != nil {
+				s.scratch = saved.Queue
+				s.scratch.ID = ""
+			}
+			for _, playlist := range saved.Playlists {
+				if playlist != nil && playlist.ID != "" {
+					s.playlists = append(s.playlists, playlist)
+				}
+			}
+		}
+	}
+
+	// 正在播放的歌单已经不存在时，播放队列当作临时播放列表
+	if id := queue.PlaylistID(); id != "" && s.findLocked(id) == nil {
+		snapshot := queue.Snapshot()
+		queue.Restore(LocalPlaylist{
+			Name:            snapshot.Name,
+			Songs:           snapshot.Songs,
+			CurrentIndex:    snapshot.CurrentIndex,
+			ShuffleOrder:    snapshot.ShuffleOrder,
+			ShufflePosition: snapshot.ShufflePosition,
+		})
+	}
+	return s
+}
+
+// newRandomID 生成本地歌单和播放列表条目的随机ID
+func newRandomID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("生成随机ID失败: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
+
+// findLocked 查找歌单，ID 为空时是临时播放列表
+func (s *LocalPlaylistStore) findLocked(id string) *LocalPlaylist {
+	if id == "" {
+		return s.scratch
+	}
+	for _, playlist := range s.playlists {
+		if playlist.ID == id {
+			return playlist
+		}
+	}
+	return nil
+}
+
+// syncLocked 把播放队列的最新状态同步到正在播放的歌单
+func (s *LocalPlaylistStore) syncLocked() {
+	snapshot := s.queue.Snapshot()
+	playlist := s.findLocked(snapshot.PlaylistID)
+	if playlist == nil || !snapshot.UpdateTime.After(playlist.UpdateTime) {
+		return
+	}
+	playlist.Name = snapshot.Name
+	playlist.Songs = snapshot.Songs
+	playlist.CurrentIndex = snapshot.CurrentIndex
+	playlist.ShuffleOrder = snapshot.ShuffleOrder
+	playlist.ShufflePosition = snapshot.ShufflePosition
+	playlist.UpdateTime = snapshot.UpdateTime
+	s.scheduleSaveLocked()
+}
+
+// info 生成歌单摘要
+func (p *LocalPlaylist) info(active bool) LocalPlaylistInfo {
+	info := LocalPlaylistInfo{
+		ID:           p.ID,
+		Name:         p.Name,
+		Count:        len(p.Songs),
+		CurrentIndex: p.CurrentIndex,
+		Active:       active,
+		CreateTime:   p.CreateTime,
+		UpdateTime:   p.UpdateTime,
+	}
+	if len(p.Songs) > 0 {
+		info.UnionCover = p.Songs[0].UnionCover
+	}
+	return info
+}
+
+// clone 复制歌单，避免调用方修改存储中的数据
+func (p *LocalPlaylist) clone() LocalPlaylist {
+	playlist := *p
+	playlist.Songs = slices.Clone(p.Songs)
+	playlist.ShuffleOrder = slices.Clone(p.ShuffleOrder)
+	return playlist
+}
+
+// touchLocked 记录歌单的修改
+func (s *LocalPlaylistStore) touchLocked(playlist *LocalPlaylist) {
+	playlist.UpdateTime = time.Now()
+	s.scheduleSaveLocked()
+}
+
+// List 按保存的顺序列出本地歌单
+func (s *LocalPlaylistStore) List() []LocalPlaylistInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	active := s.queue.PlaylistID()
+	infos := make([]LocalPlaylistInfo, 0, len(s.playlists))
+	for _, playlist := range s.playlists {
+		infos = append(infos, playlist.info(playlist.ID == active))
+	}
+	return infos
+}
+
+// Get 获取歌单和其中的歌曲
+func (s *LocalPlaylistStore) Get(id string) (LocalPlaylist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	playlist := s.findLocked(id)
+	if id == "" || playlist == nil {
+		return LocalPlaylist{}, errLocalPlaylistNotFound
+	}
+	return playlist.clone(), nil
+}
+
+// Create 创建歌单，重复的歌曲只保留第一首
+func (s *LocalPlaylistStore) Create(name string, songs []PlayerPlaylistSong) (LocalPlaylist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return LocalPlaylist{}, errLocalPlaylistName
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	playlist := &LocalPlaylist{
+		ID:           newRandomID(),
+		Name:         name,
+		Songs:        uniqueSongs(nil, songs),
+		CurrentIndex: -1,
+		CreateTime:   now,
+		UpdateTime:   now,
+	}
+	if len(playlist.Songs) > 0 {
+		playlist.CurrentIndex = 0
+	}
+	s.playlists = append(s.playlists, playlist)
+	s.scheduleSaveLocked()
+	return playlist.clone(), nil
+}
+
+// Rename 重命名歌单，正在播放时同时修改播放队列的名称
+func (s *LocalPlaylistStore) Rename(id string, name string) (LocalPlaylist, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return LocalPlaylist{}, errLocalPlaylistName
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	playlist := s.findLocked(id)
+	if id == "" || playlist == nil {
+		return LocalPlaylist{}, errLocalPlaylistNotFound
+	}
+	playlist.Name = name
+	s.touchLocked(playlist)
+	if id == s.queue.PlaylistID() {
+		s.queue.Rename(name)
+	}
+	return playlist.clone(), nil
+}
+
+// Duplicate 复制歌单到列表末尾，name 为空时使用“原名称 副本”
+func (s *LocalPlaylistStore) Duplicate(id string, name string) (LocalPlaylist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	source := s.findLocked(id)
+	if id == "" || source == nil {
+		return LocalPlaylist{}, errLocalPlaylistNotFound
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = source.Name + " 副本"
+	}
+
+	now := time.Now()
+	playlist := &LocalPlaylist{
+		ID:           newRandomID(),
+		Name:         name,
+		Songs:        slices.Clone(source.Songs),
+		CurrentIndex: source.CurrentIndex,
+		CreateTime:   now,
+		UpdateTime:   now,
+	}
+	s.playlists = append(s.playlists, playlist)
+	s.scheduleSaveLocked()
+	return playlist.clone(), nil
+}
+
+// Delete 删除歌单，正在播放时先切换回临时播放列表
+func (s *LocalPlaylistStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	index := slices.IndexFunc(s.playlists, func(p *LocalPlaylist) bool { return p.ID == id })
+	if id == "" || index < 0 {
+		return errLocalPlaylistNotFound
+	}
+	if id == s.queue.PlaylistID() {
+		s.switchLocked("")
+	}
+	s.playlists = slices.Delete(s.playlists, index, index+1)
+	s.scheduleSaveLocked()
+	return nil
+}
+
+// AddSongs 把歌曲添加到歌单末尾，已在歌单中的歌曲跳过，返回添加的数量
+func (s *LocalPlaylistStore) AddSongs(id string, songs []PlayerPlaylistSong) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	playlist := s.findLocked(id)
+	if id == "" || playlist == nil {
+		return 0, errLocalPlaylistNotFound
+	}
+
+	// 正在播放的歌单直接修改播放队列，随机播放时新歌曲也会加入本轮
+	if id == s.queue.PlaylistID() {
+		added := 0
+		for _, song := range songs {
+			if _, err := s.queue.Add(song, false); err == nil {
+				added++
+			}
+		}
+		s.syncLocked()
+		return added, nil
+	}
+
+	count := len(playlist.Songs)
+	playlist.Songs = uniqueSongs(playlist.Songs, songs)
+	added := len(playlist.Songs) - count
+	if added > 0 {
+		if playlist.CurrentIndex == -1 {
+			playlist.CurrentIndex = 0
+		}
+		s.touchLocked(playlist)
+	}
+	return added, nil
+}
+
+// RemoveSong 从歌单移除歌曲
+func (s *LocalPlaylistStore) RemoveSong(id string, hash string) (LocalPlaylist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	playlist := s.findLocked(id)
+	if id == "" || playlist == nil {
+		return LocalPlaylist{}, errLocalPlaylistNotFound
+	}
+
+	if id == s.queue.PlaylistID() {
+		// 歌单中的歌曲不重复，按hash找到播放队列中对应的条目
+		songs := s.queue.Snapshot().Songs
+		index := slices.IndexFunc(songs, func(song PlayerPlaylistSong) bool { return song.Hash == hash })
+		if index < 0 {
+			return LocalPlaylist{}, errQueueNotFound
+		}
+		if _, err := s.queue.Remove(songs[index].EntryID); err != nil {
+			return LocalPlaylist{}, err
+		}
+		s.syncLocked()
+		return playlist.clone(), nil
+	}
+
+	index := slices.IndexFunc(playlist.Songs, func(song PlayerPlaylistSong) bool { return song.Hash == hash })
+	if index < 0 {
+		return LocalPlaylist{}, errQueueNotFound
+	}
+	playlist.Songs = slices.Delete(playlist.Songs, index, index+1)
+	if playlist.CurrentIndex > index || playlist.CurrentIndex >= len(playlist.Songs) {
+		playlist.CurrentIndex--
+	}
+	// 保存的随机顺序已经对不上，切换回来时重新生成
+	playlist.ShuffleOrder = nil
+	playlist.ShufflePosition = 0
+	s.touchLocked(playlist)
+	return playlist.clone(), nil
+}
+
+// MoveSong 调整歌单中歌曲的顺序
+func (s *LocalPlaylistStore) MoveSong(id string, from, to int) (LocalPlaylist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncLocked()
+
+	playlist := s.findLocked(id)
+	if id == "" || playlist == nil {
+		return LocalPlaylist{}, errLocalPlaylistNotFound
+	}
+
+	if id == s.queue.PlaylistID() {
+		songs := s.queue.Snapshot().Songs
+		if from < 0 || from >= len(songs) {
+			return LocalPlaylist{}, errQueueIndex
+		}
+		if _, err := s.queue.Move(songs[from].EntryID, to); err != nil {
+			return LocalPlaylist{}, err
+		}
+		s.syncLocked()
+		return playlist.clone(), nil
+	}
+
+	if from < 0 || from >= len(playlist.Songs) || to < 0 || to >= len(playlist.Songs) {
+		return LocalPlaylist{}, errQueueIndex
+	}
+	song := playlist.Songs[from]
+	playlist.Songs = slices.Insert(slices.Delete(playlist.Songs, from, from+1), to, song)
+	if playlist.CurrentIndex >= 0 {
+		playlist.CurrentIndex = movedIndex(playlist.CurrentIndex, from, to)
+	}
+	for i, index := range playlist.ShuffleOrder {
+		playlist.ShuffleOrder[i] = movedIndex(index, from, to)
+	}
+	s.touchLocked(playlist)
+	return playlist.clone(), nil
+}
+
+// Switch 切换正在播放的歌单，ID 为空时切换回临时播放列表
+func (s *LocalPlaylistStore) Switch(id string) (PlayerPlaylistData, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.switchLocked(id)
+}
+
+// switchLocked 保存当前歌单的播放位置，再把目标歌单恢复到播放队列
+func (s *LocalPlaylistStore) switchLocked(id string) (PlayerPlaylistData, error) {
+	target := s.findLocked(id)
+	if target == nil {
+		return PlayerPlaylistData{}, errLocalPlaylistNotFound
+	}
+	s.syncLocked()
+	if id == s.queue.PlaylistID() {
+		return s.queue.Snapshot(), nil
+	}
+	data := s.queue.Restore(target.clone())
+	s.scheduleSaveLocked()
+	return data, nil
+}
+
+// movedIndex 歌曲从 from 移动到 to 之后，原来位于 index 的歌曲的新位置
+func movedIndex(index, from, to int) int {
+	switch {
+	case index == from:
+		return to
+	case from < to && index > from && index <= to:
+		return index - 1
+	case from > to && index >= to && index < from:
+		return index + 1
+	}
+	return index
+}
+
+// uniqueSongs 把 songs 中不在 existing 里的歌曲追加到末尾
+func uniqueSongs(existing []PlayerPlaylistSong, songs []PlayerPlaylistSong) []PlayerPlaylistSong {
+	seen := make(map[string]bool, len(existing)+len(songs))
+	result := slices.Clone(existing)
+	for _, song := range existing {
+		seen[song.Hash] = true
+	}
+	for _, song := range songs {
+		if song.Hash == "" || seen[song.Hash] {
+			continue
+		}
+		seen[song.Hash] = true
+		result = append(result, song)
+	}
+	if result == nil {
+		result = []PlayerPlaylistSong{}
+	}
+	return result
+}
+
+// scheduleSaveLocked 延迟保存本地歌单，调用方需持有锁
+func (s *LocalPlaylistStore) scheduleSaveLocked() {
+	if s.saveTimer != nil {
+		return
+	}
+	s.saveTimer = time.AfterFunc(playQueueSaveDelay, func() {
+		if err := s.Flush(); err != nil {
+			fmt.Printf("⚠️ 保存本地歌单失败: %v\n", err)
+		}
+	})
+}
+
+// Flush 同步正在播放的歌单后立即写入文件
+func (s *LocalPlaylistStore) Flush() error {
+	s.saveMutex.Lock()
+	defer s.saveMutex.Unlock()
+
+	s.mutex.Lock()
+	s.syncLocked()
+	if s.saveTimer != nil {
+		s.saveTimer.Stop()
+		s.saveTimer = nil
+	}
+	data, err := json.MarshalIndent(localPlaylistsData{Queue: s.scratch, Playlists: s.playlists}, "", "  ")
+	s.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化本地歌单失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	tempFile := s.file + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入本地歌单失败: %v", err)
+	}
+	return os.Rename(tempFile, s.file)
+}
+
+// LocalPlaylistService 本地歌单服务
+type LocalPlaylistService struct{}
+
+// localPlaylists 获取本地歌单存储，本地歌单由应用数据持有
+func (l *LocalPlaylistService) localPlaylists() (*LocalPlaylistStore, error) {
+	state, err := currentAppState()
+	if err != nil {
+		return nil, err
+	}
+	return state.playlists, nil
+}
+
+// localPlaylistResult 把本地歌单的操作结果转换为响应
+func localPlaylistResult(playlist LocalPlaylist, err error, message string) LocalPlaylistResponse {
+	if err != nil {
+		return LocalPlaylistResponse{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	return LocalPlaylistResponse{
+		Success: true,
+		Message: message,
+		Data:    playlist,
+	}
+}
+
+// GetLocalPlaylists 获取本地歌单列表
+func (l *LocalPlaylistService) GetLocalPlaylists() LocalPlaylistsResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return LocalPlaylistsResponse{Success: false, Message: err.Error()}
+	}
+	return LocalPlaylistsResponse{
+		Success: true,
+		Message: "获取本地歌单成功",
+		Data:    store.List(),
+	}
+}
+
+// GetLocalPlaylist 获取本地歌单的歌曲
+func (l *LocalPlaylistService) GetLocalPlaylist(id string) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	playlist, err := store.Get(id)
+	return localPlaylistResult(playlist, err, "获取本地歌单成功")
+}
+
+// CreateLocalPlaylist 创建本地歌单，可以带上初始歌曲，例如保存当前播放列表
+func (l *LocalPlaylistService) CreateLocalPlaylist(request CreateLocalPlaylistRequest) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	for _, song := range request.Songs {
+		recordSongMetadata(song.metadata())
+	}
+	playlist, err := store.Create(request.Name, request.Songs)
+	return localPlaylistResult(playlist, err, "创建本地歌单成功")
+}
+
+// RenameLocalPlaylist 重命名本地歌单
+func (l *LocalPlaylistService) RenameLocalPlaylist(id string, name string) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	playlist, err := store.Rename(id, name)
+	return localPlaylistResult(playlist, err, "重命名本地歌单成功")
+}
+
+// DuplicateLocalPlaylist 复制本地歌单，name 为空时使用默认名称
+func (l *LocalPlaylistService) DuplicateLocalPlaylist(id string, name string) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	playlist, err := store.Duplicate(id, name)
+	return localPlaylistResult(playlist, err, "复制本地歌单成功")
+}
+
+// DeleteLocalPlaylist 删除本地歌单
+func (l *LocalPlaylistService) DeleteLocalPlaylist(id string) LocalPlaylistsResponse {
+	store, err := l.localPlaylists()
+	if err == nil {
+		err = store.Delete(id)
+	}
+	if err != nil {
+		return LocalPlaylistsResponse{Success: false, Message: err.Error()}
+	}
+	return LocalPlaylistsResponse{
+		Success: true,
+		Message: "删除本地歌单成功",
+		Data:    store.List(),
+	}
+}
+
+// AddSongsToLocalPlaylist 把歌曲添加到本地歌单末尾
+func (l *LocalPlaylistService) AddSongsToLocalPlaylist(id string, songs []PlayerPlaylistSong) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	for _, song := range songs {
+		recordSongMetadata(song.metadata())
+	}
+	added, err := store.AddSongs(id, songs)
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	if added == 0 {
+		return localPlaylistResult(LocalPlaylist{}, errQueueDuplicate, "")
+	}
+	playlist, err := store.Get(id)
+	return localPlaylistResult(playlist, err, fmt.Sprintf("已添加 %d 首歌曲", added))
+}
+
+// RemoveSongFromLocalPlaylist 从本地歌单移除歌曲
+func (l *LocalPlaylistService) RemoveSongFromLocalPlaylist(id string, hash string) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	playlist, err := store.RemoveSong(id, hash)
+	return localPlaylistResult(playlist, err, "移除歌曲成功")
+}
+
+// MoveLocalPlaylistSong 调整本地歌单中歌曲的顺序
+func (l *LocalPlaylistService) MoveLocalPlaylistSong(id string, from int, to int) LocalPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return localPlaylistResult(LocalPlaylist{}, err, "")
+	}
+	playlist, err := store.MoveSong(id, from, to)
+	return localPlaylistResult(playlist, err, "调整歌曲顺序成功")
+}
+
+// SwitchLocalPlaylist 切换正在播放的歌单并恢复它的播放位置，ID 为空时切换回临时播放列表
+func (l *LocalPlaylistService) SwitchLocalPlaylist(id string) PlayerPlaylistResponse {
+	store, err := l.localPlaylists()
+	if err != nil {
+		return playlistResult(PlayerPlaylistData{}, err, "")
+	}
+	data, err := store.Switch(id)
+	return playlistResult(data, err, "切换播放列表成功")
+}
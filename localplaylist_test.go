@@ -0,0 +1,216 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newTestLocalPlaylists 在临时目录创建播放队列和本地歌单存储
+func newTestLocalPlaylists(t *testing.T, dir string) (*PlayQueue, *LocalPlaylistStore) {
+	t.Helper()
+	queue := NewPlayQueue(filepath.Join(dir, "playlist.json"))
+	store := NewLocalPlaylistStore(filepath.Join(dir, "local_playlists.json"), queue)
+	t.Cleanup(func() {
+		queue.Flush()
+		store.Flush()
+	})
+	return queue, store
+}
+
+// songHashes 歌曲的hash列表
+func songHashes(songs []PlayerPlaylistSong) []string {
+	hashes := make([]string, len(songs))
+	for i, song := range songs {
+		hashes[i] = song.Hash
+	}
+	return hashes
+}
+
+func TestLocalPlaylistManagement(t *testing.T) {
+	_, store := newTestLocalPlaylists(t, t.TempDir())
+
+	if _, err := store.Create("  ", nil); err != errLocalPlaylistName {
+		t.Errorf("empty name = %v", err)
+	}
+	songs := testQueueSongs(3)
+	a, err := store.Create(" 跑步 ", append(songs, songs[0]))
+	if err != nil || a.Name != "跑步" || len(a.Songs) != 3 || a.CurrentIndex != 0 {
+		t.Fatalf("create = %+v, %v", a, err)
+	}
+	empty, _ := store.Create("空歌单", nil)
+	if empty.CurrentIndex != -1 || empty.Songs == nil {
+		t.Errorf("empty playlist = %+v", empty)
+	}
+
+	if renamed, err := store.Rename(a.ID, "晨跑"); err != nil || renamed.Name != "晨跑" {
+		t.Errorf("rename = %+v, %v", renamed, err)
+	}
+	copied, err := store.Duplicate(a.ID, "")
+	if err != nil || copied.ID == a.ID || copied.Name != "晨跑 副本" || len(copied.Songs) != 3 {
+		t.Fatalf("duplicate = %+v, %v", copied, err)
+	}
+
+	// 复制出的歌单独立修改
+	if added, _ := store.AddSongs(copied.ID, []PlayerPlaylistSong{{Hash: "new"}, {Hash: "s1"}}); added != 1 {
+		t.Errorf("added = %d", added)
+	}
+	moved, err := store.MoveSong(copied.ID, 3, 0)
+	if err != nil || moved.Songs[0].Hash != "new" || moved.CurrentIndex != 1 {
+		t.Errorf("move = %v, current %d, %v", songHashes(moved.Songs), moved.CurrentIndex, err)
+	}
+	if _, err := store.MoveSong(copied.ID, 0, 9); err != errQueueIndex {
+		t.Errorf("move out of range = %v", err)
+	}
+	original, _ := store.Get(a.ID)
+	if len(original.Songs) != 3 {
+		t.Errorf("original changed: %v", songHashes(original.Songs))
+	}
+
+	if err := store.Delete(empty.ID); err != nil {
+		t.Fatal(err)
+	}
+	infos := store.List()
+	if len(infos) != 2 || infos[0].ID != a.ID || infos[1].ID != copied.ID || infos[1].Count != 4 {
+		t.Errorf("list = %+v", infos)
+	}
+	if _, err := store.Get(""); err != errLocalPlaylistNotFound {
+		t.Errorf("get scratch = %v", err)
+	}
+	if err := store.Delete(empty.ID); err != errLocalPlaylistNotFound {
+		t.Errorf("delete twice = %v", err)
+	}
+}
+
+func TestLocalPlaylistSwitchRestoresPosition(t *testing.T) {
+	queue, store := newTestLocalPlaylists(t, t.TempDir())
+	queue.Replace("专辑", testQueueSongs(4), 2)
+
+	a, _ := store.Create("歌单", []PlayerPlaylistSong{{Hash: "a0"}, {Hash: "a1"}, {Hash: "a2"}})
+	data, err := store.Switch(a.ID)
+	if err != nil || data.PlaylistID != a.ID || data.Name != "歌单" || data.CurrentIndex != 0 {
+		t.Fatalf("switch = %+v, %v", data, err)
+	}
+	queue.Next()
+
+	// 切回临时播放列表时恢复之前的歌曲和位置
+	data, _ = store.Switch("")
+	if data.PlaylistID != "" || data.Name != "专辑" || data.CurrentIndex != 2 || len(data.Songs) != 4 {
+		t.Errorf("scratch = %+v", data)
+	}
+	data, _ = store.Switch(a.ID)
+	if data.CurrentIndex != 1 {
+		t.Errorf("playlist position = %d", data.CurrentIndex)
+	}
+	if infos := store.List(); !infos[0].Active || infos[0].CurrentIndex != 1 {
+		t.Errorf("list = %+v", infos)
+	}
+
+	// 随机播放时恢复保存的播放记录
+	queue.SetMode(true, RepeatOff)
+	queue.Next()
+	before := queue.Snapshot()
+	store.Switch("")
+	data, _ = store.Switch(a.ID)
+	if data.CurrentIndex != before.CurrentIndex || data.ShufflePosition != before.ShufflePosition {
+		t.Errorf("shuffle restored = %+v, before %+v", data, before)
+	}
+	if _, err := store.Switch("missing"); err != errLocalPlaylistNotFound {
+		t.Errorf("switch missing = %v", err)
+	}
+}
+
+func TestLocalPlaylistActiveEditsGoThroughQueue(t *testing.T) {
+	queue, store := newTestLocalPlaylists(t, t.TempDir())
+	a, _ := store.Create("歌单", testQueueSongs(3))
+	store.Switch(a.ID)
+	queue.Jump(1)
+
+	if added, _ := store.AddSongs(a.ID, []PlayerPlaylistSong{{Hash: "new"}}); added != 1 || len(queue.Snapshot().Songs) != 4 {
+		t.Errorf("added = %d, queue %v", added, songHashes(queue.Snapshot().Songs))
+	}
+	playlist, err := store.MoveSong(a.ID, 3, 0)
+	if err != nil || playlist.Songs[0].Hash != "new" {
+		t.Fatalf("move = %v, %v", songHashes(playlist.Songs), err)
+	}
+	if data := queue.Snapshot(); currentHash(data) != "s1" || data.CurrentIndex != 2 {
+		t.Errorf("current after move = %s at %d", currentHash(data), data.CurrentIndex)
+	}
+	playlist, _ = store.RemoveSong(a.ID, "s0")
+	if len(playlist.Songs) != 3 || currentHash(queue.Snapshot()) != "s1" {
+		t.Errorf("remove = %v", songHashes(playlist.Songs))
+	}
+	store.Rename(a.ID, "新名字")
+	if queue.Snapshot().Name != "新名字" {
+		t.Errorf("queue name = %s", queue.Snapshot().Name)
+	}
+
+	// 删除正在播放的歌单后回到临时播放列表
+	store.Delete(a.ID)
+	if data := queue.Snapshot(); data.PlaylistID != "" || len(data.Songs) != 0 {
+		t.Errorf("after delete = %+v", data)
+	}
+}
+
+func TestLocalPlaylistsPersistence(t *testing.T) {
+	dir := t.TempDir()
+	queue, store := newTestLocalPlaylists(t, dir)
+	queue.Replace("专辑", testQueueSongs(2), 1)
+	a, _ := store.Create("歌单", []PlayerPlaylistSong{{Hash: "a0"}, {Hash: "a1"}})
+	b, _ := store.Create("另一个", []PlayerPlaylistSong{{Hash: "b0"}})
+	store.Switch(a.ID)
+	queue.Next()
+	queue.Flush()
+	store.Flush()
+
+	queue, store = newTestLocalPlaylists(t, dir)
+	if data := queue.Snapshot(); data.PlaylistID != a.ID || data.CurrentIndex != 1 {
+		t.Fatalf("reloaded queue = %+v", data)
+	}
+	if data, _ := store.Switch(""); data.Name != "专辑" || data.CurrentIndex != 1 {
+		t.Errorf("reloaded scratch = %+v", data)
+	}
+	if infos := store.List(); len(infos) != 2 || infos[1].ID != b.ID {
+		t.Errorf("reloaded list = %+v", infos)
+	}
+
+	// 歌单文件中已经没有正在播放的歌单时当作临时播放列表
+	store.Switch(b.ID)
+	queue.Flush()
+	writeTestFile(t, filepath.Join(dir, "local_playlists.json"), `{"playlists":[]}`)
+	queue, store = newTestLocalPlaylists(t, dir)
+	if data := queue.Snapshot(); data.PlaylistID != "" || currentHash(data) != "b0" {
+		t.Errorf("orphaned queue = %+v", data)
+	}
+}
+
+func TestSetPlaylistKeepsLocalPlaylist(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	newTestAppState(t, c)
+
+	service := &LocalPlaylistService{}
+	created := service.CreateLocalPlaylist(CreateLocalPlaylistRequest{Name: "歌单", Songs: testQueueSongs(2)})
+	if !created.Success {
+		t.Fatalf("create = %+v", created)
+	}
+	if resp := service.SwitchLocalPlaylist(created.Data.ID); !resp.Success || resp.Data.PlaylistID != created.Data.ID {
+		t.Fatalf("switch = %+v", resp)
+	}
+
+	// 播放新的列表和清空播放列表都不会修改歌单
+	p := &PlaylistService{}
+	resp := p.SetPlaylist(SetPlaylistRequest{Songs: []PlayerPlaylistSong{{Hash: "x"}}, ClearFirst: true})
+	if !resp.Success || resp.Data.PlaylistID != "" {
+		t.Errorf("set playlist = %+v", resp)
+	}
+	p.ClearPlaylist()
+	if playlist := service.GetLocalPlaylist(created.Data.ID); len(playlist.Data.Songs) != 2 {
+		t.Errorf("playlist = %+v", playlist)
+	}
+
+	if resp := service.AddSongsToLocalPlaylist(created.Data.ID, testQueueSongs(2)); resp.Success {
+		t.Error("adding only existing songs should fail")
+	}
+	if resp := service.DeleteLocalPlaylist(created.Data.ID); !resp.Success || len(resp.Data) != 0 {
+		t.Errorf("delete = %+v", resp)
+	}
+}
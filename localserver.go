@@ -0,0 +1,299 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localServerHost 本地HTTP服务默认只监听回环地址，开启局域网访问时监听所有网卡
+const localServerHost = "127.0.0.1"
+
+// localServerTokenParam 访问本地HTTP服务的令牌参数，也可以通过 localServerTokenHeader 请求头传递
+const (
+	localServerTokenParam  = "token"
+	localServerTokenHeader = "X-WMPlayer-Token"
+)
+
+// localServerInfoFile 本地HTTP服务的地址和令牌，供OSD歌词等外部客户端读取，只有当前用户可读
+const localServerInfoFile = "server.json"
+
+// LocalServerInfo 本地HTTP服务的连接信息
+type LocalServerInfo struct {
+	URL    string `json:"url"`
+	Token  string `json:"token"`
+	SSEURL string `json:"sse_url"`
+	PID    int    `json:"pid"`
+}
+
+// newServerToken 生成本次运行的访问令牌，每次启动都不同
+func newServerToken() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("生成本地服务令牌失败: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ApplyNetworkSettings 应用网络设置中的局域网访问开关，重启本地HTTP服务后生效
+func (c *CacheService) ApplyNetworkSettings(settings NetworkSettings) {
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	if settings.LanAccess {
+		c.listenHost = ""
+	} else {
+		c.listenHost = localServerHost
+	}
+}
+
+// listenLocalServer 监听首选端口，端口被占用（如另一个实例或其它程序）时改用系统分配的空闲端口
+func listenLocalServer(host string, port string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err == nil {
+		return listener, nil
+	}
+	fmt.Printf("⚠️ 本地服务端口 %s 不可用，改用空闲端口: %v\n", port, err)
+	listener, fallbackErr := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("启动本地缓存服务失败: %v", fallbackErr)
+	}
+	return listener, nil
+}
+
+// serverBaseURL 本地HTTP服务的根地址，由实际监听的端口生成，服务未启动时返回空字符串
+// 前端和OSD始终通过回环地址访问，开启局域网访问时也一样
+func (c *CacheService) serverBaseURL() string {
+	port := c.listenPort()
+	if port == "" {
+		return ""
+	}
+	return "http://" + net.JoinHostPort(localServerHost, port)
+}
+
+// listenPort 本地HTTP服务实际监听的端口，服务未启动时返回空字符串
+func (c *CacheService) listenPort() string {
+	c.serverMutex.Lock()
+	addr := c.serverAddr
+	c.serverMutex.Unlock()
+	if addr == "" {
+		return ""
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	return port
+}
+
+// serverUnavailable 本地HTTP服务没有运行时返回原因，此时无法生成可以播放的本地URL
+func (c *CacheService) serverUnavailable() (string, bool) {
+	if c.serverBaseURL() != "" {
+		return "", false
+	}
+	c.serverMutex.Lock()
+	defer c.serverMutex.Unlock()
+	if c.serverErr != nil {
+		return fmt.Sprintf("本地缓存服务未启动: %v", c.serverErr), true
+	}
+	return "本地缓存服务未启动", true
+}
+
+// cacheFileURL 生成缓存目录中文件的URL，带本次运行的访问令牌，服务未启动时返回空字符串
+// dir 为 mp3 或 covers
+func (c *CacheService) cacheFileURL(dir string, fileName string) string {
+	base := c.serverBaseURL()
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/cache/%s/%s?%s=%s", base, dir, url.PathEscape(fileName), localServerTokenParam, c.serverToken)
+}
+
+// osdLyricsSSEURL 生成OSD歌词SSE端点的URL，带本次运行的访问令牌，服务未启动时返回空字符串
+func (c *CacheService) osdLyricsSSEURL() string {
+	base := c.serverBaseURL()
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/osd-lyrics/sse?%s=%s", base, localServerTokenParam, c.serverToken)
+}
+
+// localCacheURL 生成本地HTTP服务中缓存文件的URL，缓存服务未创建或未启动时返回空字符串
+func localCacheURL(dir string, fileName string) string {
+	if cacheService := GetCacheService(); cacheService != nil {
+		return cacheService.cacheFileURL(dir, fileName)
+	}
+	return ""
+}
+
+// refreshLocalCacheURL 把之前保存的缓存文件URL换成本次运行的地址和令牌，其它URL原样返回
+func refreshLocalCacheURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "http" || (u.Hostname() != localServerHost && u.Hostname() != "localhost") {
+		return rawURL
+	}
+	for _, dir := range []string{"mp3", "covers"} {
+		prefix := "/cache/" + dir + "/"
+		if strings.HasPrefix(u.Path, prefix) {
+			if fresh := localCacheURL(dir, strings.TrimPrefix(u.Path, prefix)); fresh != "" {
+				return fresh
+			}
+		}
+	}
+	return rawURL
+}
+
+// originAllowed 检查请求来源是否为应用自己的页面：wails 的页面（macOS/Linux 为 wails://localhost，
+// Windows 为 http://wails.localhost）或本地HTTP服务自己的端口，回环地址上的其它端口不接受
+// 没有Origin的请求（audio/img标签、OSD等本地客户端）只校验令牌
+func (c *CacheService) originAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "wails":
+		return u.Hostname() == "localhost"
+	case "http", "https":
+	default:
+		return false
+	}
+	switch u.Hostname() {
+	case "wails.localhost":
+		return true
+	case "localhost", "127.0.0.1", "::1":
+		port := c.listenPort()
+		return port != "" && u.Port() == port
+	}
+	return false
+}
+
+// tokenValid 检查请求携带的令牌
+func (c *CacheService) tokenValid(r *http.Request) bool {
+	token := r.URL.Query().Get(localServerTokenParam)
+	if token == "" {
+		token = r.Header.Get(localServerTokenHeader)
+	}
+	return c.serverToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.serverToken)) == 1
+}
+
+// loopbackRequest 请求是否来自本机的回环地址
+func loopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// secured 为本地HTTP服务的端点添加来源检查、CORS和令牌校验
+// loopbackWithoutToken 为 true 时，本机不带Origin的请求可以不带令牌，
+// 用于只读的歌词SSE，兼容使用默认地址连接、不知道令牌的OSD歌词程序
+func (c *CacheService) secured(next http.HandlerFunc, loopbackWithoutToken bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if !c.originAllowed(origin) {
+			fmt.Printf("🚫 拒绝来源: %s %s\n", origin, r.URL.Path)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if origin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Range, "+localServerTokenHeader)
+		}
+
+		// 预检请求不带令牌
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !c.tokenValid(r) && !(loopbackWithoutToken && origin == "" && loopbackRequest(r)) {
+			fmt.Printf("🚫 令牌无效: %s %s\n", r.RemoteAddr, r.URL.Path)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// cacheFilePath 把URL中的文件名解析为缓存目录中的文件路径
+// 只允许目录下的普通文件名，拒绝子目录、上级目录、隐藏文件和Windows的盘符或数据流
+func cacheFilePath(dir string, name string) (string, bool) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, "/\\:\x00") {
+		return "", false
+	}
+	if filepath.Base(name) != name || filepath.IsAbs(name) {
+		return "", false
+	}
+	return filepath.Join(dir, name), true
+}
+
+// serveCacheFile 提供缓存目录中的普通文件，支持Range请求
+func serveCacheFile(w http.ResponseWriter, r *http.Request, dir string, name string) {
+	filePath, ok := cacheFilePath(dir, name)
+	if !ok {
+		fmt.Printf("🚫 非法的文件路径: %q\n", name)
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		fmt.Printf("❌ 文件不存在: %s\n", filePath)
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	fmt.Printf("✅ 提供文件: %s\n", filePath)
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+// writeServerInfo 保存本地HTTP服务的地址和令牌，外部客户端读取后才能连接
+func (c *CacheService) writeServerInfo() error {
+	info := LocalServerInfo{
+		URL:    c.serverBaseURL(),
+		Token:  c.serverToken,
+		SSEURL: c.osdLyricsSSEURL(),
+		PID:    os.Getpid(),
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(c.cacheDir, localServerInfoFile)
+	if err := os.WriteFile(file+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// removeServerInfo 服务停止后删除连接信息
+func (c *CacheService) removeServerInfo() {
+	if err := os.Remove(filepath.Join(c.cacheDir, localServerInfoFile)); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️ 删除本地服务信息失败: %v\n", err)
+	}
+}
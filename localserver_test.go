@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// localGet 请求测试服务器，origin 不为空时带上 Origin 请求头
+func localGet(t *testing.T, rawURL string, origin string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestLocalServerRequiresToken(t *testing.T) {
+	c, local := newTestCacheService(t)
+	writeCacheFile(t, c.mp3Dir, "song_high.mp3", 10)
+	writeTestFile(t, filepath.Join(c.cacheDir, "cache", "covers", "cover.jpg"), "jpeg")
+
+	songURL := localTestURL(t, local, c.getLocalURL("song_high.mp3"))
+	if resp, _ := localGet(t, songURL, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("song with token = %d", resp.StatusCode)
+	}
+	if resp, _ := localGet(t, local.URL+"/cache/mp3/song_high.mp3", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("song without token = %d, want 401", resp.StatusCode)
+	}
+	if resp, _ := localGet(t, local.URL+"/cache/mp3/song_high.mp3?token=wrong", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("song with wrong token = %d, want 401", resp.StatusCode)
+	}
+
+	coverURL := localTestURL(t, local, c.cacheFileURL("covers", "cover.jpg"))
+	if resp, body := localGet(t, coverURL, ""); resp.StatusCode != http.StatusOK || body != "jpeg" {
+		t.Errorf("cover = %d %q", resp.StatusCode, body)
+	}
+
+	// 令牌也可以通过请求头传递
+	req, _ := http.NewRequest(http.MethodGet, local.URL+"/cache/covers/cover.jpg", nil)
+	req.Header.Set(localServerTokenHeader, c.serverToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("cover with token header = %d", resp.StatusCode)
+	}
+
+}
+
+// sseRequest 直接调用本地服务的处理器请求歌词SSE，请求的上下文已取消，连接确认后立即返回
+func sseRequest(c *CacheService, remoteAddr string, origin string) *httptest.ResponseRecorder {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/osd-lyrics/sse", nil).WithContext(ctx)
+	req.RemoteAddr = remoteAddr
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	c.newHTTPHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLocalServerLyricsSSEFromLoopback(t *testing.T) {
+	c, _ := newTestCacheService(t)
+
+	// OSD歌词程序使用默认地址连接，不带令牌
+	for _, addr := range []string{"127.0.0.1:50000", "[::1]:50000"} {
+		if rec := sseRequest(c, addr, ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "connected") {
+			t.Errorf("sse from %s without token = %d %q", addr, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := sseRequest(c, "192.168.1.20:50000", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("sse from lan without token = %d, want 401", rec.Code)
+	}
+	if rec := sseRequest(c, "127.0.0.1:50000", "wails://localhost"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("sse from page without token = %d, want 401", rec.Code)
+	}
+
+	// 其它端点仍然需要令牌
+	req := httptest.NewRequest(http.MethodGet, "/cache/covers/cover.jpg", nil)
+	req.RemoteAddr = "127.0.0.1:50000"
+	rec := httptest.NewRecorder()
+	c.newHTTPHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("cover from loopback without token = %d, want 401", rec.Code)
+	}
+}
+
+func TestLocalServerServesOnlyCacheFiles(t *testing.T) {
+	c, local := newTestCacheService(t)
+	if err := c.saveLocalMusicMap(); err != nil {
+		t.Fatal(err)
+	}
+	token := "?token=" + c.serverToken
+
+	for _, p := range []string{
+		"/cache/local_music_map.json",
+		"/cache/mp3/",
+		"/cache/mp3/..%2Flocal_music_map.json",
+		"/cache/mp3/%2E%2E%2Flocal_music_map.json",
+		"/cache/mp3/..%5Clocal_music_map.json",
+		"/cache/covers/..%2F..%2Fcache%2Flocal_music_map.json",
+		"/cache/mp3/C:%5Cwindows%5Cwin.ini",
+		"/",
+	} {
+		if resp, body := localGet(t, local.URL+p+token, ""); resp.StatusCode == http.StatusOK {
+			t.Errorf("%s should not be served: %q", p, body)
+		}
+	}
+}
+
+func TestLocalServerOriginCheck(t *testing.T) {
+	c, local := newTestCacheService(t)
+	writeCacheFile(t, c.mp3Dir, "song_high.mp3", 10)
+	songURL := localTestURL(t, local, c.getLocalURL("song_high.mp3"))
+
+	resp, _ := localGet(t, songURL, "wails://localhost")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "wails://localhost" {
+		t.Errorf("wails origin = %d, ACAO %q", resp.StatusCode, resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+	if resp, _ := localGet(t, songURL, "http://wails.localhost:34115"); resp.StatusCode != http.StatusOK {
+		t.Errorf("dev origin = %d", resp.StatusCode)
+	}
+	// 服务自己的端口可以，回环地址上的其它端口（如其它本地程序的页面）不行
+	if resp, _ := localGet(t, songURL, "http://127.0.0.1:"+c.listenPort()); resp.StatusCode != http.StatusOK {
+		t.Errorf("own port origin = %d", resp.StatusCode)
+	}
+	for _, origin := range []string{"https://evil.example", "null", "http://192.168.1.20", "http://localhost:3000", "http://127.0.0.1:8080", "wails://evil.example"} {
+		if resp, _ := localGet(t, songURL, origin); resp.StatusCode != http.StatusForbidden || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("origin %s = %d", origin, resp.StatusCode)
+		}
+	}
+}
+
+func TestLocalServerURLs(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+
+	if !strings.HasSuffix(c.getLocalURL("a b.mp3"), "/cache/mp3/a%20b.mp3?token="+c.serverToken) {
+		t.Errorf("getLocalURL = %s", c.getLocalURL("a b.mp3"))
+	}
+	if other := NewCacheService(); other.serverToken == c.serverToken || len(c.serverToken) != 32 {
+		t.Error("each run should use a new random token")
+	}
+
+	// 上次运行保存的封面URL换成本次的令牌，其它URL不变
+	stale := "http://127.0.0.1:18911/cache/covers/abc.jpg?token=old"
+	if got := refreshLocalCacheURL(stale); got != c.cacheFileURL("covers", "abc.jpg") {
+		t.Errorf("refresh cover = %s", got)
+	}
+	if got := refreshLocalCacheURL("http://127.0.0.1:18911/cache/covers/abc.jpg"); got != c.cacheFileURL("covers", "abc.jpg") {
+		t.Errorf("refresh legacy cover = %s", got)
+	}
+	online := "https://imge.kugou.com/stdmusic/cover.jpg"
+	if got := refreshLocalCacheURL(online); got != online {
+		t.Errorf("online cover changed to %s", got)
+	}
+}
+
+func TestLocalServerBindsLoopbackAndWritesInfo(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	c.serverPort = "0"
+	if err := c.StartHTTPServerWithOSDLyrics(); err != nil {
+		t.Fatal(err)
+	}
+	if host, _, _ := net.SplitHostPort(c.serverAddr); host != "127.0.0.1" {
+		t.Errorf("addr = %s, want loopback", c.serverAddr)
+	}
+
+	infoFile := filepath.Join(c.cacheDir, localServerInfoFile)
+	data, err := os.ReadFile(infoFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info LocalServerInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.Token != c.serverToken || info.URL != c.serverBaseURL() || !strings.Contains(info.SSEURL, "token="+c.serverToken) {
+		t.Errorf("server info = %s, %v", data, err)
+	}
+
+	c.StopHTTPServer()
+	if _, err := os.Stat(infoFile); !os.IsNotExist(err) {
+		t.Error("server info should be removed after stop")
+	}
+	if c.getLocalURL("song_high.mp3") != "" {
+		t.Error("no URL should be built while the server is stopped")
+	}
+
+	c.ApplyNetworkSettings(NetworkSettings{LanAccess: true})
+	if err := c.StartHTTPServerWithOSDLyrics(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.StopHTTPServer()
+	if host, _, _ := net.SplitHostPort(c.serverAddr); host == "127.0.0.1" {
+		t.Errorf("addr with LAN access = %s", c.serverAddr)
+	}
+	if !strings.HasPrefix(c.getLocalURL("song_high.mp3"), "http://127.0.0.1:") {
+		t.Errorf("URL with LAN access = %s", c.getLocalURL("song_high.mp3"))
+	}
+}
+
+func TestLocalServerFallsBackToFreePort(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	_, busyPort, _ := net.SplitHostPort(busy.Addr().String())
+
+	c.serverPort = busyPort
+	if err := c.StartHTTPServerWithOSDLyrics(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.StopHTTPServer()
+
+	_, port, _ := net.SplitHostPort(c.serverAddr)
+	if port == busyPort || port == "0" {
+		t.Fatalf("addr = %s, want a free port other than %s", c.serverAddr, busyPort)
+	}
+
+	// 所有URL都使用实际监听的端口
+	writeCacheFile(t, c.mp3Dir, "song_high.mp3", 10)
+	songURL := c.getLocalURL("song_high.mp3")
+	if !strings.HasPrefix(songURL, "http://127.0.0.1:"+port+"/") || !strings.Contains(c.osdLyricsSSEURL(), ":"+port+"/") {
+		t.Errorf("urls = %s, %s", songURL, c.osdLyricsSSEURL())
+	}
+	if resp, _ := localGet(t, songURL, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("song = %d", resp.StatusCode)
+	}
+}
+
+func TestLocalServerStartFailure(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	c.serverAddr = ""
+	// 不属于本机的地址无法监听，首选端口和空闲端口都会失败
+	c.listenHost = "203.0.113.1"
+	if err := c.StartHTTPServerWithOSDLyrics(); err == nil {
+		c.StopHTTPServer()
+		t.Fatal("start should fail synchronously")
+	}
+
+	resp := c.StreamAudioFile(testStreamHash, AudioQualityHigh, []string{"http://127.0.0.1:1/song.mp3"})
+	if resp.Success || !strings.Contains(resp.Message, "本地缓存服务未启动") {
+		t.Errorf("StreamAudioFile = %+v", resp)
+	}
+	if resp := c.GetCachedURL(testStreamHash); resp.Success || !strings.Contains(resp.Message, "本地缓存服务未启动") {
+		t.Errorf("GetCachedURL = %+v", resp)
+	}
+}
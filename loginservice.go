@@ -1,10 +1,7 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -142,91 +139,37 @@ func (l *LoginService) SendCaptcha(mobile string) CaptchaResponse {
 		}
 	}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/captcha/sent?mobile=%s", baseApi, url.QueryEscape(mobile))
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/captcha/sent",
+		Params:  url.Values{"mobile": {mobile}},
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return CaptchaResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[CaptchaData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return CaptchaResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+	// 检查API响应是否成功 - 根据error_code判断
+	if !envelope.errorCodeIs(0) {
+		return apiFailure[CaptchaData](envelope.failure("验证码发送失败"))
 	}
 
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return CaptchaResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
+	// 提取count数据
+	data, _ := decodeApiData[struct {
+		Count apiInt `json:"count"`
+	}](envelope)
 
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return CaptchaResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	return CaptchaResponse{
+		Success:   true,
+		Message:   "验证码发送成功",
+		ErrorCode: envelope.ErrorCode.Int(),
+		Data:      CaptchaData{Count: data.Count.Int()},
 	}
+}
 
-	// 检查API响应是否成功 - 根据error_code判断
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		// 提取count数据
-		var captchaData CaptchaData
-		if data, ok := apiResponse["data"].(map[string]any); ok {
-			if count, ok := data["count"].(float64); ok {
-				captchaData.Count = int(count)
-			}
-		}
-
-		return CaptchaResponse{
-			Success:   true,
-			Message:   "验证码发送成功",
-			ErrorCode: int(errorCode),
-			Data:      captchaData,
-		}
-	} else {
-		message := "验证码发送失败"
-		errorCodeInt := -1
-		statusInt := 0
-
-		// 尝试从响应中获取错误信息
-		if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-			message = msg
-		} else if status, ok := apiResponse["status"].(float64); ok {
-			message = fmt.Sprintf("验证码发送失败，状态码: %.0f", status)
-			statusInt = int(status)
-		}
-
-		if errorCode, ok := apiResponse["error_code"].(float64); ok {
-			errorCodeInt = int(errorCode)
-		}
-
-		return CaptchaResponse{
-			Success:   false,
-			Message:   message,
-			ErrorCode: errorCodeInt,
-			Status:    statusInt,
-			Data:      CaptchaData{},
-		}
-	}
+// loginTokenData 登录接口 data 字段中的凭证信息
+type loginTokenData struct {
+	Token  apiString `json:"token"`
+	UserID apiInt    `json:"userid"`
 }
 
 // LoginWithPhone 手机号登录
@@ -245,117 +188,50 @@ func (l *LoginService) LoginWithPhone(mobile, code string) LoginResponse {
 		}
 	}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/login/cellphone?mobile=%s&code=%s",
-		baseApi, url.QueryEscape(mobile), url.QueryEscape(code))
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:   "/login/cellphone",
+		Params: url.Values{"mobile": {mobile}, "code": {code}},
+	})
 	if err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[LoginData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+	// 检查API响应是否成功 - 根据error_code判断
+	if !envelope.errorCodeIs(0) {
+		return apiFailure[LoginData](envelope.failure("登录失败"))
 	}
 
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return LoginResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
+	// 提取登录数据
+	var loginData LoginData
+	if token, err := decodeApiData[loginTokenData](envelope); err == nil {
+		loginData.Token = token.Token.String()
+		loginData.UserID = int64(token.UserID)
 	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	// 保存其他用户信息
+	if userInfo, err := decodeApiData[map[string]any](envelope); err == nil {
+		loginData.UserInfo = userInfo
 	}
 
-	// 检查API响应是否成功 - 根据error_code判断
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		// 提取登录数据
-		var loginData LoginData
-		if data, ok := apiResponse["data"].(map[string]any); ok {
-			if tokenValue, ok := data["token"].(string); ok {
-				loginData.Token = tokenValue
-			}
-			if useridValue, ok := data["userid"].(float64); ok {
-				loginData.UserID = int64(useridValue)
-			}
-			// 保存其他用户信息
-			loginData.UserInfo = data
+	// 保存cookie到文件
+	if loginData.Token != "" && loginData.UserID != 0 {
+		if err := saveCookieToFile(loginData.Token, loginData.UserID); err != nil {
+			// 记录错误但不影响登录成功
+			fmt.Printf("保存cookie失败: %v\n", err)
 		}
 
-		// 保存cookie到文件
-		if loginData.Token != "" && loginData.UserID != 0 {
-			if err := saveCookieToFile(loginData.Token, loginData.UserID); err != nil {
-				// 记录错误但不影响登录成功
-				fmt.Printf("保存cookie失败: %v\n", err)
-			}
-
-			// 保存登录方式
-			if err := saveLoginMethodToFile("phone"); err != nil {
-				// 记录错误但不影响登录成功
-				fmt.Printf("保存登录方式失败: %v\n", err)
-			}
-		}
-
-		return LoginResponse{
-			Success:   true,
-			Message:   "登录成功",
-			ErrorCode: int(errorCode),
-			Data:      loginData,
-		}
-	} else {
-		message := "登录失败"
-		errorCodeInt := -1
-		statusInt := 0
-
-		// 尝试从响应中获取错误信息
-		if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-			message = msg
-		} else if status, ok := apiResponse["status"].(float64); ok {
-			message = fmt.Sprintf("登录失败，状态码: %.0f", status)
-			statusInt = int(status)
-		}
-
-		if errorCode, ok := apiResponse["error_code"].(float64); ok {
-			errorCodeInt = int(errorCode)
-		}
-
-		return LoginResponse{
-			Success:   false,
-			Message:   message,
-			ErrorCode: errorCodeInt,
-			Status:    statusInt,
-			Data:      LoginData{},
+		// 保存登录方式
+		if err := saveLoginMethodToFile("phone"); err != nil {
+			// 记录错误但不影响登录成功
+			fmt.Printf("保存登录方式失败: %v\n", err)
 		}
 	}
-}
 
-// readCookieFromFile 从全局Cookie管理器读取cookie
-func (l *LoginService) readCookieFromFile() (string, error) {
-	cookie := GlobalCookieManager.GetCookie()
-	return cookie, nil
+	return LoginResponse{
+		Success:   true,
+		Message:   "登录成功",
+		ErrorCode: envelope.ErrorCode.Int(),
+		Data:      loginData,
+	}
 }
 
 // UserDetailData 用户详情数据结构
@@ -382,17 +258,18 @@ type VipDetailData struct {
 // VipDetailResponse VIP详情响应结构
 type VipDetailResponse = ApiResponse[VipDetailData]
 
+// userDetailPayload /user/detail 接口 data 字段的结构
+type userDetailPayload struct {
+	Nickname  apiString `json:"nickname"`
+	Pic       apiString `json:"pic"`
+	LoginTime apiInt    `json:"logintime"`
+	VipType   apiInt    `json:"vip_type"`
+	VipLevel  apiInt    `json:"vip_level"`
+}
+
 // GetUserDetail 获取用户详情
 func (l *LoginService) GetUserDetail() UserDetailResponse {
-	// 读取cookie
-	cookie, err := l.readCookieFromFile()
-	if err != nil {
-		return UserDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
+	cookie := GlobalCookieManager.GetCookie()
 	if cookie == "" {
 		return UserDetailResponse{
 			Success: false,
@@ -400,229 +277,89 @@ func (l *LoginService) GetUserDetail() UserDetailResponse {
 		}
 	}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/user/detail?cookie=%s&timestamp=%d",
-		baseApi, url.QueryEscape(cookie), time.Now().UnixMilli())
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/user/detail",
+		Params:  url.Values{"timestamp": {strconv.FormatInt(time.Now().UnixMilli(), 10)}},
+		Cookie:  true,
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return UserDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return UserDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return UserDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return UserDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+		return apiFailure[UserDetailData](err)
 	}
 
 	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-			// 提取用户详情数据
-			var userDetailData UserDetailData
-			if data, ok := apiResponse["data"].(map[string]any); ok {
-				if len(strings.Split(cookie, ";")) == 2 {
-					// userid 从cookie中获取
-					if userid, err := strconv.Atoi(strings.Split(strings.Split(cookie, ";")[1], "=")[1]); err == nil {
-						userDetailData.UserID = int64(userid)
-					}
+	if !envelope.errorCodeIs(0) || !envelope.statusIs(1) {
+		return apiFailure[UserDetailData](envelope.failure("获取用户详情失败"))
+	}
+
+	// 提取用户详情数据
+	var userDetailData UserDetailData
+	if data, err := decodeApiData[userDetailPayload](envelope); err == nil {
+		if parts := strings.Split(cookie, ";"); len(parts) == 2 {
+			// userid 从cookie中获取
+			if kv := strings.SplitN(parts[1], "=", 2); len(kv) == 2 {
+				if userid, err := strconv.Atoi(kv[1]); err == nil {
+					userDetailData.UserID = int64(userid)
 				}
-
-				if nickname, ok := data["nickname"].(string); ok {
-					userDetailData.Nickname = nickname
-				}
-				if pic, ok := data["pic"].(string); ok {
-					userDetailData.Pic = pic
-				}
-				if loginTime, ok := data["logintime"].(float64); ok {
-					userDetailData.LoginTime = int64(loginTime)
-				}
-				if vipType, ok := data["vip_type"].(float64); ok {
-					userDetailData.VipType = int(vipType)
-				}
-				if vipLevel, ok := data["vip_level"].(float64); ok {
-					userDetailData.VipLevel = int(vipLevel)
-				}
-				// 判断是否为VIP
-				userDetailData.IsVip = userDetailData.VipType > 0 || userDetailData.VipLevel > 0
-			}
-
-			return UserDetailResponse{
-				Success:   true,
-				Message:   "获取用户详情成功",
-				ErrorCode: int(errorCode),
-				Status:    int(status),
-				Data:      userDetailData,
 			}
 		}
-	}
 
-	// 处理错误情况
-	message := "获取用户详情失败"
-	errorCodeInt := -1
-	statusInt := 0
-
-	if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-		message = msg
-	} else if status, ok := apiResponse["status"].(float64); ok {
-		message = fmt.Sprintf("获取用户详情失败，状态码: %.0f", status)
-		statusInt = int(status)
-	}
-
-	if errorCode, ok := apiResponse["error_code"].(float64); ok {
-		errorCodeInt = int(errorCode)
+		userDetailData.Nickname = data.Nickname.String()
+		userDetailData.Pic = data.Pic.String()
+		userDetailData.LoginTime = int64(data.LoginTime)
+		userDetailData.VipType = data.VipType.Int()
+		userDetailData.VipLevel = data.VipLevel.Int()
+		// 判断是否为VIP
+		userDetailData.IsVip = userDetailData.VipType > 0 || userDetailData.VipLevel > 0
 	}
 
 	return UserDetailResponse{
-		Success:   false,
-		Message:   message,
-		ErrorCode: errorCodeInt,
-		Status:    statusInt,
-		Data:      UserDetailData{},
+		Success:   true,
+		Message:   "获取用户详情成功",
+		ErrorCode: envelope.ErrorCode.Int(),
+		Status:    envelope.Status.Int(),
+		Data:      userDetailData,
 	}
 }
 
+// vipDetailPayload /user/vip/detail 接口 data 字段的结构
+type vipDetailPayload struct {
+	BusiVip apiList[struct {
+		IsVip       apiInt    `json:"is_vip"`
+		VipEndTime  apiString `json:"vip_end_time"`
+		ProductType apiString `json:"product_type"`
+	}] `json:"busi_vip"`
+}
+
 // GetVipDetail 获取VIP详情
 func (l *LoginService) GetVipDetail() VipDetailResponse {
-	// 读取cookie
-	cookie, err := l.readCookieFromFile()
-	if err != nil {
-		return VipDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/user/vip/detail?cookie=%s", baseApi, url.QueryEscape(cookie))
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/user/vip/detail",
+		Cookie:  true,
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return VipDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return VipDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return VipDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d", resp.StatusCode),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return VipDetailResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+		return apiFailure[VipDetailData](err)
 	}
 
 	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-		// 获取data字段
-		if dataInterface, ok := apiResponse["data"]; ok {
-			if dataMap, ok := dataInterface.(map[string]interface{}); ok {
-				// 获取busi_vip数组
-				if busiVipInterface, ok := dataMap["busi_vip"]; ok {
-					if busiVipArray, ok := busiVipInterface.([]interface{}); ok && len(busiVipArray) > 0 {
-						// 获取第一个VIP信息
-						if vipInfo, ok := busiVipArray[0].(map[string]interface{}); ok {
-							vipDetailData := VipDetailData{}
-
-							// 获取是否VIP (1是VIP，0不是VIP)
-							if isVipFloat, ok := vipInfo["is_vip"].(float64); ok {
-								vipDetailData.IsVip = int(isVipFloat)
-							} else if isVipBool, ok := vipInfo["is_vip"].(bool); ok {
-								if isVipBool {
-									vipDetailData.IsVip = 1
-								} else {
-									vipDetailData.IsVip = 0
-								}
-							}
-
-							// 获取VIP结束时间 (字符串格式)
-							if vipEndTime, ok := vipInfo["vip_end_time"].(string); ok {
-								vipDetailData.VipEndTime = vipEndTime
-							}
-
-							// 获取VIP类型
-							if productType, ok := vipInfo["product_type"].(string); ok {
-								vipDetailData.ProductType = productType
-							}
-
-							return VipDetailResponse{
-								Success: true,
-								Message: "获取VIP详情成功",
-								Data:    vipDetailData,
-							}
-						}
-					}
-				}
+	if envelope.statusIs(1) {
+		// 获取第一个VIP信息
+		if data, err := decodeApiData[vipDetailPayload](envelope); err == nil && len(data.BusiVip) > 0 {
+			vipInfo := data.BusiVip[0]
+			return VipDetailResponse{
+				Success: true,
+				Message: "获取VIP详情成功",
+				Data: VipDetailData{
+					IsVip:       vipInfo.IsVip.Int(),
+					VipEndTime:  vipInfo.VipEndTime.String(),
+					ProductType: vipInfo.ProductType.String(),
+				},
 			}
 		}
 	}
 
-	// 处理错误情况
-	message := "获取VIP详情失败"
-	if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-		message = msg
-	}
-
-	return VipDetailResponse{
-		Success: false,
-		Message: message,
-		Data:    VipDetailData{},
-	}
+	return apiFailure[VipDetailData](envelope.failure("获取VIP详情失败"))
 }
 
 // CheckLoginStatus 检查登录状态
@@ -686,170 +423,71 @@ func (l *LoginService) CheckLoginStatus() LoginResponse {
 
 // ClaimDailyVip 领取每日VIP
 func (l *LoginService) ClaimDailyVip() LoginResponse {
-	// 读取cookie文件
-	cookie, err := l.readCookieFromFile()
-	if err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: "未找到登录信息，请先登录",
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/youth/day/vip?cookie=%s", baseApi, url.QueryEscape(cookie))
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/youth/day/vip",
+		Cookie:  true,
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: "请求失败: " + err.Error(),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: "读取响应失败: " + err.Error(),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return LoginResponse{
-			Success: false,
-			Message: "解析响应失败: " + err.Error(),
-		}
+		return apiFailure[LoginData](err)
 	}
 
 	// 检查API响应状态
-	if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-		// 成功
+	if envelope.statusIs(1) {
 		message := "领取成功"
-		if msg, ok := apiResponse["msg"].(string); ok && msg != "" {
-			message = msg
+		if envelope.Msg != "" {
+			message = envelope.Msg.String()
 		}
 
 		return LoginResponse{
 			Success: true,
 			Message: message,
 		}
-	} else {
-		// 失败
-		message := "领取失败"
-		if msg, ok := apiResponse["msg"].(string); ok && msg != "" {
-			message = msg
-		}
+	}
 
-		return LoginResponse{
-			Success: false,
-			Message: message,
-		}
+	message := "领取失败"
+	if envelope.Msg != "" {
+		message = envelope.Msg.String()
+	}
+
+	return LoginResponse{
+		Success: false,
+		Message: message,
 	}
 }
 
 // GenerateQRKey 生成二维码登录Key
 func (l *LoginService) GenerateQRKey() QRKeyResponse {
-	// 构建请求URL，添加时间戳防止缓存
-	requestURL := fmt.Sprintf("%s/login/qr/key?timestamp=%d", baseApi, time.Now().UnixMilli())
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	// 添加时间戳防止缓存
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/login/qr/key",
+		Params:  url.Values{"timestamp": {strconv.FormatInt(time.Now().UnixMilli(), 10)}},
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return QRKeyResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return QRKeyResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return QRKeyResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return QRKeyResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+		return apiFailure[QRKeyData](err)
 	}
 
 	// 检查API响应是否成功 - 根据error_code和status判断
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-			// 提取二维码Key数据
-			var qrKeyData QRKeyData
-			if data, ok := apiResponse["data"].(map[string]any); ok {
-				if qrcode, ok := data["qrcode"].(string); ok {
-					qrKeyData.QRCode = qrcode
-				}
-				if qrcodeImg, ok := data["qrcode_img"].(string); ok {
-					qrKeyData.QRCodeImg = qrcodeImg
-				}
-			}
-
-			return QRKeyResponse{
-				Success:   true,
-				Message:   "二维码Key生成成功",
-				ErrorCode: int(errorCode),
-				Status:    int(status),
-				Data:      qrKeyData,
-			}
-		}
-	}
-
-	// 处理错误情况
-	message := "二维码Key生成失败"
-	errorCodeInt := -1
-	statusInt := 0
-
-	// 尝试从响应中获取错误信息
-	if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-		message = msg
-	} else if status, ok := apiResponse["status"].(float64); ok {
-		message = fmt.Sprintf("二维码Key生成失败，状态码: %.0f", status)
-		statusInt = int(status)
+	if !envelope.errorCodeIs(0) || !envelope.statusIs(1) {
+		return apiFailure[QRKeyData](envelope.failure("二维码Key生成失败"))
 	}
 
-	if errorCode, ok := apiResponse["error_code"].(float64); ok {
-		errorCodeInt = int(errorCode)
-	}
+	// 提取二维码Key数据
+	data, _ := decodeApiData[struct {
+		QRCode    apiString `json:"qrcode"`
+		QRCodeImg apiString `json:"qrcode_img"`
+	}](envelope)
 
 	return QRKeyResponse{
-		Success:   false,
-		Message:   message,
-		ErrorCode: errorCodeInt,
-		Status:    statusInt,
-		Data:      QRKeyData{},
+		Success:   true,
+		Message:   "二维码Key生成成功",
+		ErrorCode: envelope.ErrorCode.Int(),
+		Status:    envelope.Status.Int(),
+		Data: QRKeyData{
+			QRCode:    data.QRCode.String(),
+			QRCodeImg: data.QRCodeImg.String(),
+		},
 	}
 }
 
@@ -862,92 +500,65 @@ func (l *LoginService) CreateQRCode(key string) QRCodeResponse {
 		}
 	}
 
-	// 构建请求URL，添加时间戳防止缓存
-	requestURL := fmt.Sprintf("%s/login/qr/create?key=%s&qrimg=true&timestamp=%d",
-		baseApi, url.QueryEscape(key), time.Now().UnixMilli())
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	// 添加时间戳防止缓存
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/login/qr/create",
+		Params: url.Values{
+			"key":       {key},
+			"qrimg":     {"true"},
+			"timestamp": {strconv.FormatInt(time.Now().UnixMilli(), 10)},
+		},
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return QRCodeResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	})
 	if err != nil {
-		return QRCodeResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return QRCodeResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return QRCodeResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+		return apiFailure[QRCodeData](err)
 	}
 
 	// 检查API响应是否成功 - 根据code判断（这个接口返回的是code而不是error_code）
-	if code, ok := apiResponse["code"].(float64); ok && code == 200 {
-		// 提取二维码数据
-		var qrCodeData QRCodeData
-		if data, ok := apiResponse["data"].(map[string]any); ok {
-			if url, ok := data["url"].(string); ok {
-				qrCodeData.URL = url
-			}
-			if base64, ok := data["base64"].(string); ok {
-				qrCodeData.Base64 = base64
-			}
+	if !envelope.codeIs(200) {
+		message := "二维码生成失败"
+		statusInt := 0
+		if envelope.Message != "" {
+			message = envelope.Message.String()
+		} else if envelope.Code != nil {
+			statusInt = envelope.Code.Int()
+			message = fmt.Sprintf("二维码生成失败，状态码: %d", statusInt)
 		}
 
 		return QRCodeResponse{
-			Success: true,
-			Message: "二维码生成成功",
-			Status:  int(code),
-			Data:    qrCodeData,
+			Success: false,
+			Message: message,
+			Status:  statusInt,
+			Data:    QRCodeData{},
 		}
 	}
 
-	// 处理错误情况
-	message := "二维码生成失败"
-	statusInt := 0
-
-	// 尝试从响应中获取错误信息
-	if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-		message = msg
-	} else if code, ok := apiResponse["code"].(float64); ok {
-		message = fmt.Sprintf("二维码生成失败，状态码: %.0f", code)
-		statusInt = int(code)
-	}
+	// 提取二维码数据
+	data, _ := decodeApiData[struct {
+		URL    apiString `json:"url"`
+		Base64 apiString `json:"base64"`
+	}](envelope)
 
 	return QRCodeResponse{
-		Success: false,
-		Message: message,
-		Status:  statusInt,
-		Data:    QRCodeData{},
+		Success: true,
+		Message: "二维码生成成功",
+		Status:  envelope.Code.Int(),
+		Data: QRCodeData{
+			URL:    data.URL.String(),
+			Base64: data.Base64.String(),
+		},
 	}
 }
 
+// qrStatusPayload /login/qr/check 接口 data 字段的结构
+type qrStatusPayload struct {
+	Nickname apiString `json:"nickname"`
+	Pic      apiString `json:"pic"`
+	Token    apiString `json:"token"`
+	UserID   apiInt    `json:"userid"`
+	Status   apiInt    `json:"status"`
+}
+
 // CheckQRStatus 检测二维码扫码状态
 func (l *LoginService) CheckQRStatus(key string) QRStatusResponse {
 	if key == "" {
@@ -957,121 +568,54 @@ func (l *LoginService) CheckQRStatus(key string) QRStatusResponse {
 		}
 	}
 
-	// 构建请求URL，添加时间戳防止缓存
-	requestURL := fmt.Sprintf("%s/login/qr/check?key=%s&timestamp=%d",
-		baseApi, url.QueryEscape(key), time.Now().UnixMilli())
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	// 添加时间戳防止缓存
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/login/qr/check",
+		Params: url.Values{
+			"key":       {key},
+			"timestamp": {strconv.FormatInt(time.Now().UnixMilli(), 10)},
+		},
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return QRStatusResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[QRStatusData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return QRStatusResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+	// 检查API响应是否成功 - 根据error_code和status判断
+	if !envelope.errorCodeIs(0) || !envelope.statusIs(1) {
+		return apiFailure[QRStatusData](envelope.failure("二维码状态检测失败"))
 	}
 
-	// 检查HTTP状态码
-	if resp.StatusCode != http.StatusOK {
-		return QRStatusResponse{
-			Success: false,
-			Message: fmt.Sprintf("服务器返回错误状态: %d, 响应: %s", resp.StatusCode, string(body)),
-		}
+	// 提取二维码状态数据
+	data, _ := decodeApiData[qrStatusPayload](envelope)
+	qrStatusData := QRStatusData{
+		Nickname: data.Nickname.String(),
+		Pic:      data.Pic.String(),
+		Token:    data.Token.String(),
+		UserID:   int64(data.UserID),
+		Status:   data.Status.Int(),
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]any
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return QRStatusResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
+	// 如果登录成功（status=4），保存cookie到文件
+	if qrStatusData.Status == 4 && qrStatusData.Token != "" && qrStatusData.UserID != 0 {
+		if err := saveCookieToFile(qrStatusData.Token, qrStatusData.UserID); err != nil {
+			// 记录错误但不影响登录成功
+			fmt.Printf("保存cookie失败: %v\n", err)
 		}
-	}
 
-	// 检查API响应是否成功 - 根据error_code和status判断
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-			// 提取二维码状态数据
-			var qrStatusData QRStatusData
-			if data, ok := apiResponse["data"].(map[string]any); ok {
-				if nickname, ok := data["nickname"].(string); ok {
-					qrStatusData.Nickname = nickname
-				}
-				if pic, ok := data["pic"].(string); ok {
-					qrStatusData.Pic = pic
-				}
-				if token, ok := data["token"].(string); ok {
-					qrStatusData.Token = token
-				}
-				if userid, ok := data["userid"].(float64); ok {
-					qrStatusData.UserID = int64(userid)
-				}
-				if qrStatus, ok := data["status"].(float64); ok {
-					qrStatusData.Status = int(qrStatus)
-				}
-			}
-
-			// 如果登录成功（status=4），保存cookie到文件
-			if qrStatusData.Status == 4 && qrStatusData.Token != "" && qrStatusData.UserID != 0 {
-				if err := saveCookieToFile(qrStatusData.Token, qrStatusData.UserID); err != nil {
-					// 记录错误但不影响登录成功
-					fmt.Printf("保存cookie失败: %v\n", err)
-				}
-
-				// 保存登录方式
-				if err := saveLoginMethodToFile("qrcode"); err != nil {
-					// 记录错误但不影响登录成功
-					fmt.Printf("保存登录方式失败: %v\n", err)
-				}
-			}
-
-			return QRStatusResponse{
-				Success:   true,
-				Message:   getQRStatusMessage(qrStatusData.Status),
-				ErrorCode: int(errorCode),
-				Status:    int(status),
-				Data:      qrStatusData,
-			}
+		// 保存登录方式
+		if err := saveLoginMethodToFile("qrcode"); err != nil {
+			// 记录错误但不影响登录成功
+			fmt.Printf("保存登录方式失败: %v\n", err)
 		}
 	}
 
-	// 处理错误情况
-	message := "二维码状态检测失败"
-	errorCodeInt := -1
-	statusInt := 0
-
-	// 尝试从响应中获取错误信息
-	if msg, ok := apiResponse["message"].(string); ok && msg != "" {
-		message = msg
-	} else if status, ok := apiResponse["status"].(float64); ok {
-		message = fmt.Sprintf("二维码状态检测失败，状态码: %.0f", status)
-		statusInt = int(status)
-	}
-
-	if errorCode, ok := apiResponse["error_code"].(float64); ok {
-		errorCodeInt = int(errorCode)
-	}
-
 	return QRStatusResponse{
-		Success:   false,
-		Message:   message,
-		ErrorCode: errorCodeInt,
-		Status:    statusInt,
-		Data:      QRStatusData{},
+		Success:   true,
+		Message:   getQRStatusMessage(qrStatusData.Status),
+		ErrorCode: envelope.ErrorCode.Int(),
+		Status:    envelope.Status.Int(),
+		Data:      qrStatusData,
 	}
 }
 
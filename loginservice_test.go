@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestQRLoginFlow(t *testing.T) {
+	srv := newMockAPI(t)
+	l := &LoginService{}
+
+	key := l.GenerateQRKey()
+	if !key.Success || key.Data.QRCode != "0123456789abcdef0123456789abcdef" {
+		t.Fatalf("GenerateQRKey = %+v", key)
+	}
+
+	code := l.CreateQRCode(key.Data.QRCode)
+	if !code.Success || code.Status != 200 || code.Data.Base64 == "" {
+		t.Fatalf("CreateQRCode = %+v", code)
+	}
+
+	status := l.CheckQRStatus(key.Data.QRCode)
+	if !status.Success || status.Data.Status != 4 || status.Data.UserID != 123456789 || status.Data.Token != "mock-token-0123456789" {
+		t.Fatalf("CheckQRStatus = %+v", status)
+	}
+
+	// 登录相关接口不附带旧的cookie
+	assertCookieSent(t, srv, "/login/qr/key", false)
+	assertCookieSent(t, srv, "/login/qr/check", false)
+}
+
+func TestCreateQRCodeFailure(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/login/qr/create", `{"code":502}`)
+
+	resp := (&LoginService{}).CreateQRCode("key")
+	if resp.Success || resp.Status != 502 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestSendCaptchaAndLoginWithPhone(t *testing.T) {
+	srv := newMockAPI(t)
+	l := &LoginService{}
+
+	captcha := l.SendCaptcha("13800000000")
+	if !captcha.Success || captcha.Data.Count != 1 {
+		t.Fatalf("SendCaptcha = %+v", captcha)
+	}
+	req, _ := srv.LastRequest("/captcha/sent")
+	if req.Query.Get("mobile") != "13800000000" {
+		t.Errorf("mobile = %q", req.Query.Get("mobile"))
+	}
+
+	login := l.LoginWithPhone("13800000000", "1234")
+	if !login.Success || login.Data.Token != "mock-token-0123456789" || login.Data.UserID != 123456789 {
+		t.Fatalf("LoginWithPhone = %+v", login)
+	}
+}
+
+func TestGetUserDetail(t *testing.T) {
+	srv := newMockAPI(t)
+
+	resp := (&LoginService{}).GetUserDetail()
+	if !resp.Success {
+		t.Fatalf("GetUserDetail failed: %s", resp.Message)
+	}
+	// userid 取自cookie
+	if resp.Data.UserID != 123456789 || resp.Data.Nickname != "测试用户" || !resp.Data.IsVip {
+		t.Errorf("data = %+v", resp.Data)
+	}
+	assertCookieSent(t, srv, "/user/detail", true)
+}
+
+func TestGetVipDetail(t *testing.T) {
+	newMockAPI(t)
+
+	resp := (&LoginService{}).GetVipDetail()
+	if !resp.Success || resp.Data.IsVip != 1 || resp.Data.ProductType != "svip" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestClaimDailyVip(t *testing.T) {
+	srv := newMockAPI(t)
+
+	if resp := (&LoginService{}).ClaimDailyVip(); !resp.Success || resp.Message != "领取成功" {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	srv.HandleJSON("/youth/day/vip", `{"status":0,"msg":"今日已领取"}`)
+	if resp := (&LoginService{}).ClaimDailyVip(); resp.Success || resp.Message != "今日已领取" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// lyricsCacheTTL 歌词缓存的有效期，过期后在后台重新获取
+const lyricsCacheTTL = 30 * 24 * time.Hour
+
+// lyricsMissTTL 没有歌词的歌曲的记录有效期，避免每次播放都重新搜索
+const lyricsMissTTL = 24 * time.Hour
+
+// lyricsWaitTimeout 没有缓存时获取播放地址最多等待歌词的时间，超时后歌词在后台继续获取
+const lyricsWaitTimeout = 3 * time.Second
+
+// lyricsFetchTimeout 前端单独请求歌词时最多等待的时间
+const lyricsFetchTimeout = 30 * time.Second
+
+// cachedLyrics 磁盘上缓存的一首歌的歌词
+type cachedLyrics struct {
+	Hash      string `json:"hash"`
+	KRC       string `json:"krc"`
+	LRC       string `json:"lrc"`
+	NotFound  bool   `json:"not_found"` // 歌词接口没有这首歌的歌词
+	FetchedAt int64  `json:"fetched_at"`
+	// 歌词对应的候选歌词ID，用于在歌词选择中标记当前使用的歌词
+	CandidateID string `json:"candidate_id,omitempty"`
+}
+
+// content 返回歌词内容，优先使用带逐字时间戳的KRC格式
+func (l *cachedLyrics) content() string {
+	if l == nil {
+		return ""
+	}
+	if l.KRC != "" {
+		return l.KRC
+	}
+	return l.LRC
+}
+
+// fresh 缓存是否仍在有效期内
+func (l *cachedLyrics) fresh() bool {
+	ttl := lyricsCacheTTL
+	if l.NotFound {
+		ttl = lyricsMissTTL
+	}
+	return time.Since(time.Unix(l.FetchedAt, 0)) < ttl
+}
+
+// lyricsFetch 正在进行的歌词获取
+type lyricsFetch struct {
+	done   chan struct{}
+	result *cachedLyrics
+}
+
+// wait 等待歌词获取完成，超时返回空字符串
+func (f *lyricsFetch) wait(timeout time.Duration) string {
+	select {
+	case <-f.done:
+		return f.result.content()
+	case <-time.After(timeout):
+		return ""
+	}
+}
+
+// LyricsCache 按歌曲hash在磁盘上缓存歌词
+type LyricsCache struct {
+	dir     string
+	fetches map[string]*lyricsFetch
+	mutex   sync.Mutex
+}
+
+// NewLyricsCache 创建歌词缓存
+func NewLyricsCache(dir string) *LyricsCache {
+	return &LyricsCache{
+		dir:     dir,
+		fetches: make(map[string]*lyricsFetch),
+	}
+}
+
+// path 歌词缓存文件路径
+func (l *LyricsCache) path(hash string) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%x.json", md5.Sum([]byte(hash))))
+}
+
+// Get 读取缓存的歌词，不存在时返回nil
+func (l *LyricsCache) Get(hash string) *cachedLyrics {
+	data, err := os.ReadFile(l.path(hash))
+	if err != nil {
+		return nil
+	}
+
+	var lyrics cachedLyrics
+	if err := json.Unmarshal(data, &lyrics); err != nil || lyrics.Hash != hash {
+		return nil
+	}
+	return &lyrics
+}
+
+// Put 保存歌词
+func (l *LyricsCache) Put(lyrics *cachedLyrics) error {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("创建歌词缓存目录失败: %v", err)
+	}
+	data, err := json.Marshal(lyrics)
+	if err != nil {
+		return fmt.Errorf("序列化歌词失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	path := l.path(lyrics.Hash)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("写入歌词缓存失败: %v", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Fetch 在后台获取歌词并写入缓存，同一首歌同时只获取一次
+// fetch 返回错误时不写入缓存，下次播放时重试
+func (l *LyricsCache) Fetch(hash string, fetch func(hash string) (*cachedLyrics, error)) *lyricsFetch {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if task, ok := l.fetches[hash]; ok {
+		return task
+	}
+	task := &lyricsFetch{done: make(chan struct{})}
+	l.fetches[hash] = task
+
+	go func() {
+		lyrics, err := fetch(hash)
+		if err != nil {
+			fmt.Printf("⚠️ 获取歌词失败: %s, %v\n", hash, err)
+		} else {
+			lyrics.Hash = hash
+			lyrics.FetchedAt = time.Now().Unix()
+			if err := l.Put(lyrics); err != nil {
+				fmt.Printf("⚠️ 保存歌词缓存失败: %s, %v\n", hash, err)
+			}
+			task.result = lyrics
+		}
+
+		l.mutex.Lock()
+		delete(l.fetches, hash)
+		l.mutex.Unlock()
+		close(task.done)
+	}()
+	return task
+}
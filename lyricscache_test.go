@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wmplayer/internal/mockapi"
+)
+
+const testLyricsHash = "A1B2C3D4E5F60718293A4B5C6D7E8F90"
+
+func TestSongLyricsServedFromCache(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	first := h.GetSongLyrics(testLyricsHash)
+	if !first.Success || !strings.Contains(first.Data, "[29350,3220]") {
+		t.Fatalf("first = %+v, want KRC content", first)
+	}
+	cached := state.lyricsCache.Get(testLyricsHash)
+	if cached == nil || cached.KRC == "" || cached.LRC == "" {
+		t.Fatalf("cached = %+v, want both KRC and LRC", cached)
+	}
+
+	// 再次获取时不再请求歌词接口
+	srv.Reset()
+	if second := h.GetSongLyrics(testLyricsHash); second.Data != first.Data {
+		t.Errorf("second = %+v", second)
+	}
+	if _, ok := srv.LastRequest("/search/lyric"); ok {
+		t.Error("cached lyrics should not be searched again")
+	}
+}
+
+func TestSongLyricsRemembersMissing(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	srv.HandleJSON("/search/lyric", `{"status":200,"candidates":[]}`)
+	if resp := h.GetSongLyrics(testLyricsHash); resp.Success {
+		t.Fatalf("resp = %+v, want no lyrics", resp)
+	}
+
+	srv.Reset()
+	if resp := h.GetSongLyrics(testLyricsHash); resp.Success {
+		t.Fatalf("resp = %+v, want no lyrics", resp)
+	}
+	if _, ok := srv.LastRequest("/search/lyric"); ok {
+		t.Error("missing lyrics should be remembered")
+	}
+}
+
+func TestSongLyricsRefreshesStaleCache(t *testing.T) {
+	newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	state.lyricsCache.Put(&cachedLyrics{
+		Hash:      testLyricsHash,
+		LRC:       "[00:00.00]旧歌词",
+		FetchedAt: time.Now().Add(-2 * lyricsCacheTTL).Unix(),
+	})
+
+	// 先返回过期的歌词，同时在后台刷新
+	if lyrics := h.songLyrics(testLyricsHash, lyricsWaitTimeout); lyrics != "[00:00.00]旧歌词" {
+		t.Errorf("lyrics = %q, want stale lyrics", lyrics)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cached := state.lyricsCache.Get(testLyricsHash); cached != nil && cached.fresh() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("stale lyrics were not refreshed")
+}
+
+func TestSongLyricsNetworkErrorNotCached(t *testing.T) {
+	newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+
+	dead := mockapi.New()
+	dead.Close()
+	GlobalBackendManager.Configure([]string{dead.URL}, "test")
+
+	if resp := NewHomepageService(c, state).GetSongLyrics(testLyricsHash); resp.Success {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if cached := state.lyricsCache.Get(testLyricsHash); cached != nil {
+		t.Errorf("cached = %+v, network errors should not be cached", cached)
+	}
+}
+
+func TestGetLyricsTimeline(t *testing.T) {
+	newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	resp := h.GetLyricsTimeline(testLyricsHash)
+	if !resp.Success || resp.Data.Format != "krc" || len(resp.Data.Lines) == 0 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if line := resp.Data.Lines[0]; line.Start != 29350 || line.Duration != 3220 || len(line.Words) == 0 {
+		t.Errorf("first line = %+v", line)
+	}
+}
+
+func TestUpdateCurrentLyricsSendsParsedLine(t *testing.T) {
+	c := &CacheService{}
+	req := httptest.NewRequest(http.MethodGet, "/api/osd-lyrics/sse", nil)
+	msgChan := make(chan LyricsMessage, 1)
+	c.addOSDClient(req, msgChan)
+	defer c.removeOSDClient(req)
+
+	c.UpdateCurrentLyrics("[1000,800]<0,300,0>拦<300,500,0>路", "富士山下", "陈奕迅")
+	message := <-msgChan
+	if message.Format != "krc" || message.Line == nil || message.Line.Text != "拦路" || len(message.Line.Words) != 2 {
+		t.Fatalf("message = %+v", message)
+	}
+	if word := message.Line.Words[1]; word.Start != 1300 || word.Duration != 500 {
+		t.Errorf("word = %+v", word)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LyricsChoice 用户为一首歌选择的候选歌词
+type LyricsChoice struct {
+	ID        string `json:"id"`
+	AccessKey string `json:"accesskey"`
+}
+
+// LyricsCandidates 歌曲的候选歌词列表
+type LyricsCandidates struct {
+	SelectedID string             `json:"selected_id"` // 当前使用的候选歌词ID，没有时为空
+	Chosen     bool               `json:"chosen"`      // 当前歌词是否为用户选择的
+	Candidates []LyricsSearchData `json:"candidates"`
+}
+
+// LyricsChoiceStore 按歌曲hash保存用户选择的歌词，歌词缓存过期刷新时仍使用选择的歌词
+type LyricsChoiceStore struct {
+	file    string
+	choices map[string]LyricsChoice
+	mutex   sync.Mutex
+}
+
+// NewLyricsChoiceStore 创建歌词选择存储并加载已保存的选择
+func NewLyricsChoiceStore(file string) *LyricsChoiceStore {
+	store := &LyricsChoiceStore{
+		file:    file,
+		choices: make(map[string]LyricsChoice),
+	}
+	if data, err := os.ReadFile(file); err == nil {
+		if err := json.Unmarshal(data, &store.choices); err != nil {
+			fmt.Printf("⚠️ 歌词选择文件格式错误，已忽略: %v\n", err)
+			store.choices = make(map[string]LyricsChoice)
+		}
+	}
+	return store
+}
+
+// Get 获取歌曲选择的歌词
+func (s *LyricsChoiceStore) Get(hash string) (LyricsChoice, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	choice, ok := s.choices[hash]
+	return choice, ok
+}
+
+// Set 保存歌曲选择的歌词
+func (s *LyricsChoiceStore) Set(hash string, choice LyricsChoice) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.choices[hash] = choice
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("创建歌词选择目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(s.choices, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化歌词选择失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	if err := os.WriteFile(s.file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("写入歌词选择失败: %v", err)
+	}
+	return os.Rename(s.file+".tmp", s.file)
+}
+
+// lyricsChoice 获取歌曲选择的歌词，没有应用数据时没有选择
+func (h *HomepageService) lyricsChoice(hash string) (LyricsChoice, bool) {
+	if h.appState == nil {
+		return LyricsChoice{}, false
+	}
+	return h.appState.lyricsChoices.Get(hash)
+}
+
+// fetchChosenLyrics 下载用户选择的歌词，没有选择或选择的歌词已失效时返回 false
+func (h *HomepageService) fetchChosenLyrics(hash string) (*cachedLyrics, bool) {
+	choice, ok := h.lyricsChoice(hash)
+	if !ok {
+		return nil, false
+	}
+	lyrics, err := h.downloadLyrics(&LyricsSearchData{ID: choice.ID, AccessKey: choice.AccessKey})
+	if err != nil || lyrics.NotFound {
+		fmt.Printf("⚠️ 选择的歌词已失效，重新搜索: %s\n", hash)
+		return nil, false
+	}
+	return lyrics, true
+}
+
+// GetLyricsCandidates 获取歌曲的全部候选歌词
+// keyword 为空时按歌曲hash搜索；本地音乐或hash搜索不到时可按“歌手 - 歌名”搜索
+func (h *HomepageService) GetLyricsCandidates(hash string, keyword string) ApiResponse[LyricsCandidates] {
+	keyword = strings.TrimSpace(keyword)
+	if hash == "" {
+		return ApiResponse[LyricsCandidates]{
+			Success: false,
+			Message: "歌曲hash不能为空",
+		}
+	}
+
+	params := url.Values{"man": {"yes"}} // 返回全部候选歌词
+	if keyword != "" {
+		params.Set("keywords", keyword)
+	} else if strings.HasPrefix(hash, "local-") {
+		return ApiResponse[LyricsCandidates]{
+			Success: false,
+			Message: "本地音乐需要输入关键词搜索歌词",
+		}
+	} else {
+		params.Set("hash", hash)
+	}
+
+	candidates, err := searchLyricCandidates(params)
+	if err != nil {
+		if _, ok := err.(*ApiError); ok {
+			return apiFailure[LyricsCandidates](err)
+		}
+		return ApiResponse[LyricsCandidates]{
+			Success: true,
+			Message: "没有找到候选歌词",
+			Data:    LyricsCandidates{Candidates: []LyricsSearchData{}},
+		}
+	}
+
+	result := LyricsCandidates{Candidates: candidates}
+	if choice, ok := h.lyricsChoice(hash); ok {
+		result.SelectedID = choice.ID
+		result.Chosen = true
+	} else if h.appState != nil {
+		if cached := h.appState.lyricsCache.Get(hash); cached != nil {
+			result.SelectedID = cached.CandidateID
+		}
+	}
+
+	return ApiResponse[LyricsCandidates]{
+		Success: true,
+		Message: fmt.Sprintf("找到 %d 个候选歌词", len(candidates)),
+		Data:    result,
+	}
+}
+
+// PreviewLyricsCandidate 获取候选歌词的内容用于预览，不修改歌曲使用的歌词
+func (h *HomepageService) PreviewLyricsCandidate(id string, accessKey string) ApiResponse[string] {
+	if id == "" || accessKey == "" {
+		return ApiResponse[string]{
+			Success: false,
+			Message: "候选歌词ID和accesskey不能为空",
+		}
+	}
+
+	lyrics, err := h.downloadLyrics(&LyricsSearchData{ID: id, AccessKey: accessKey})
+	if err != nil {
+		return apiFailure[string](err)
+	}
+	if lyrics.NotFound {
+		return ApiResponse[string]{
+			Success: false,
+			Message: "候选歌词没有内容",
+		}
+	}
+	return ApiResponse[string]{
+		Success: true,
+		Message: "获取候选歌词成功",
+		Data:    lyrics.content(),
+	}
+}
+
+// SelectLyricsCandidate 为歌曲选择候选歌词并记住选择，返回选择的歌词内容
+// 选择的歌词立即写入歌词缓存，之后缓存过期刷新时也使用选择的歌词
+func (h *HomepageService) SelectLyricsCandidate(hash string, id string, accessKey string) ApiResponse[string] {
+	if hash == "" || id == "" || accessKey == "" {
+		return ApiResponse[string]{
+			Success: false,
+			Message: "歌曲hash和候选歌词不能为空",
+		}
+	}
+	if h.appState == nil {
+		return ApiResponse[string]{
+			Success: false,
+			Message: errAppStateUnavailable.Error(),
+		}
+	}
+
+	lyrics, err := h.downloadLyrics(&LyricsSearchData{ID: id, AccessKey: accessKey})
+	if err != nil {
+		return apiFailure[string](err)
+	}
+	if lyrics.NotFound {
+		return ApiResponse[string]{
+			Success: false,
+			Message: "候选歌词没有内容",
+		}
+	}
+
+	if err := h.appState.lyricsChoices.Set(hash, LyricsChoice{ID: id, AccessKey: accessKey}); err != nil {
+		return ApiResponse[string]{
+			Success: false,
+			Message: fmt.Sprintf("保存歌词选择失败: %v", err),
+		}
+	}
+	lyrics.Hash = hash
+	lyrics.FetchedAt = time.Now().Unix()
+	if err := h.appState.lyricsCache.Put(lyrics); err != nil {
+		fmt.Printf("⚠️ 保存歌词缓存失败: %s, %v\n", hash, err)
+	}
+
+	fmt.Printf("🎵 歌曲 %s 使用候选歌词 %s\n", hash, id)
+	return ApiResponse[string]{
+		Success: true,
+		Message: "已使用选择的歌词",
+		Data:    lyrics.content(),
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetLyricsCandidates(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	// 自动获取的歌词标记为当前使用的候选
+	h.GetSongLyrics(testLyricsHash)
+	resp := h.GetLyricsCandidates(testLyricsHash, "")
+	if !resp.Success || len(resp.Data.Candidates) != 2 || resp.Data.SelectedID != "12345678" || resp.Data.Chosen {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if second := resp.Data.Candidates[1]; second.Source != "用户上传" || second.Duration != 301000 || second.Score != 40 {
+		t.Errorf("second candidate = %+v", second)
+	}
+	request, _ := srv.LastRequest("/search/lyric")
+	if request.Query.Get("man") != "yes" || request.Query.Get("hash") != testLyricsHash {
+		t.Errorf("search request = %+v", request.Query)
+	}
+
+	// 本地音乐只能按关键词搜索
+	if resp := h.GetLyricsCandidates("local-abc", ""); resp.Success {
+		t.Error("local song without keyword should fail")
+	}
+	h.GetLyricsCandidates("local-abc", " 周杰伦 - 晴天 ")
+	if request, _ := srv.LastRequest("/search/lyric"); request.Query.Get("keywords") != "周杰伦 - 晴天" || request.Query.Has("hash") {
+		t.Errorf("keyword search request = %+v", request.Query)
+	}
+}
+
+func TestSelectLyricsCandidate(t *testing.T) {
+	srv := newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+	h := NewHomepageService(c, state)
+
+	// 预览不改变歌曲使用的歌词
+	if preview := h.PreviewLyricsCandidate("87654321", "0123456789ABCDEF0123456789ABCDEF"); !preview.Success || preview.Data == "" {
+		t.Fatalf("preview = %+v", preview)
+	}
+	if _, ok := state.lyricsChoices.Get(testLyricsHash); ok || state.lyricsCache.Get(testLyricsHash) != nil {
+		t.Error("preview should not change the song lyrics")
+	}
+
+	resp := h.SelectLyricsCandidate(testLyricsHash, "87654321", "0123456789ABCDEF0123456789ABCDEF")
+	if !resp.Success || resp.Data == "" {
+		t.Fatalf("select = %+v", resp)
+	}
+	if cached := state.lyricsCache.Get(testLyricsHash); cached == nil || cached.CandidateID != "87654321" || !cached.fresh() {
+		t.Errorf("cached = %+v", cached)
+	}
+	reloaded := NewLyricsChoiceStore(filepath.Join(c.cacheDir, "cache", "lyrics_choices.json"))
+	if choice, ok := reloaded.Get(testLyricsHash); !ok || choice.ID != "87654321" {
+		t.Errorf("choice not persisted: %+v", choice)
+	}
+
+	// 缓存过期后按选择的歌词刷新，不再搜索
+	state.lyricsCache.Put(&cachedLyrics{Hash: testLyricsHash, LRC: "[00:00.00]旧歌词", FetchedAt: time.Now().Add(-2 * lyricsCacheTTL).Unix()})
+	srv.Reset()
+	lyrics, err := h.fetchLyrics(testLyricsHash)
+	if err != nil || lyrics.CandidateID != "87654321" {
+		t.Fatalf("refreshed = %+v, %v", lyrics, err)
+	}
+	if _, ok := srv.LastRequest("/search/lyric"); ok {
+		t.Error("chosen lyrics should not be searched again")
+	}
+	if request, _ := srv.LastRequest("/lyric"); request.Query.Get("id") != "87654321" {
+		t.Errorf("lyric request = %+v", request.Query)
+	}
+
+	if candidates := h.GetLyricsCandidates(testLyricsHash, ""); candidates.Data.SelectedID != "87654321" || !candidates.Data.Chosen {
+		t.Errorf("candidates = %+v", candidates.Data)
+	}
+}
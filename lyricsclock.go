@@ -0,0 +1,263 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"wmplayer/internal/lyrics"
+)
+
+// lyricsClockTolerance 前端上报的位置与后端时钟相差不超过此值时不重新校准，避免歌词抖动
+const lyricsClockTolerance = 300 * time.Millisecond
+
+// LyricsClock 后端的歌词播放时钟
+// 前端只需上报歌词、播放状态和位置，时钟在正确的时间通过SSE推送行和逐字事件，
+// 不受窗口隐藏时webview定时器降频的影响
+type LyricsClock struct {
+	timeline  lyrics.Timeline
+	base      lyrics.Timeline // 未应用用户偏移的时间轴
+	key       string          // 当前歌曲的偏移key
+	songName  string
+	artist    string
+	playing   bool
+	position  int64     // 校准时的播放位置，毫秒
+	anchor    time.Time // 校准的时间
+	lineIndex int
+	wordIndex int
+	// 推送的附加层
+	translation  bool
+	romanization bool
+	timer        *time.Timer
+	emit         func(LyricsMessage)
+	onLine       func(text string)
+	mutex        sync.Mutex
+}
+
+// NewLyricsClock 创建歌词时钟，emit 用于发送SSE消息
+func NewLyricsClock(emit func(LyricsMessage)) *LyricsClock {
+	return &LyricsClock{
+		lineIndex: -1,
+		wordIndex: -1,
+		emit:      emit,
+	}
+}
+
+// SetOnLine 设置当前行变化时的回调，用于同步MPRIS元数据
+func (c *LyricsClock) SetOnLine(onLine func(text string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onLine = onLine
+}
+
+// SetLayers 设置推送的翻译和音译层，并立即重新推送当前行
+func (c *LyricsClock) SetLayers(translation bool, romanization bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.translation == translation && c.romanization == romanization {
+		return
+	}
+	c.translation = translation
+	c.romanization = romanization
+	if c.lineIndex >= 0 {
+		c.emitLocked(c.lineMessageLocked(c.positionLocked()))
+	}
+}
+
+// Load 切换到新歌的歌词，播放位置从0开始
+// key 和 offset 是该歌曲保存的偏移，之后可以通过 SetOffset 调整
+func (c *LyricsClock) Load(key string, timeline lyrics.Timeline, offset int64, songName string, artist string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.key = key
+	c.base = timeline
+	c.timeline = timeline.WithOffset(offset)
+	c.songName = songName
+	c.artist = artist
+	c.position = 0
+	c.anchor = time.Now()
+	c.lineIndex = -1
+	c.wordIndex = -1
+
+	// 先发送不含歌词行的消息，让OSD清除上一首歌的歌词并显示歌曲信息
+	c.emitLocked(c.lineMessageLocked(0))
+	if c.onLine != nil {
+		c.onLine("")
+	}
+	c.updateLocked()
+}
+
+// SetOffset 调整当前歌曲的歌词偏移，key 与当前歌曲不同时忽略
+func (c *LyricsClock) SetOffset(key string, offset int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if key == "" || key != c.key {
+		return
+	}
+	c.timeline = c.base.WithOffset(offset)
+	// 重新推送当前行，让OSD按新的时间显示逐字进度
+	c.lineIndex = -1
+	c.wordIndex = -1
+	c.updateLocked()
+}
+
+// SetPlaying 更新播放状态，暂停时停止推送
+func (c *LyricsClock) SetPlaying(playing bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.playing == playing {
+		return
+	}
+	c.position = c.positionLocked()
+	c.anchor = time.Now()
+	c.playing = playing
+	c.emitLocked(LyricsMessage{Type: "playback_status", Playing: playing, Position: c.position})
+	c.updateLocked()
+}
+
+// SetPosition 校准播放位置（毫秒）
+// 播放中与时钟的误差在容差内时忽略，误差较大时视为跳转
+func (c *LyricsClock) SetPosition(ms int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	drift := time.Duration(ms-c.positionLocked()) * time.Millisecond
+	if c.playing && drift > -lyricsClockTolerance && drift < lyricsClockTolerance {
+		return
+	}
+	c.position = ms
+	c.anchor = time.Now()
+	if c.playing {
+		c.emitLocked(LyricsMessage{Type: "playback_status", Playing: true, Position: ms})
+	}
+	c.updateLocked()
+}
+
+// Position 返回当前播放位置（毫秒）
+func (c *LyricsClock) Position() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.positionLocked()
+}
+
+// CurrentMessage 返回当前行的消息，用于新连接的OSD客户端立即显示歌词
+func (c *LyricsClock) CurrentMessage() LyricsMessage {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lineMessageLocked(c.positionLocked())
+}
+
+// lineMessageLocked 生成当前行的 lyrics_line 消息
+func (c *LyricsClock) lineMessageLocked(pos int64) LyricsMessage {
+	message := LyricsMessage{
+		Type:      "lyrics_line",
+		SongName:  c.songName,
+		Artist:    c.artist,
+		Format:    c.timeline.Format,
+		Index:     c.lineIndex,
+		WordIndex: c.wordIndex,
+		Position:  pos,
+		Playing:   c.playing,
+	}
+	if c.lineIndex >= 0 {
+		line := c.timeline.Lines[c.lineIndex].WithLayers(c.translation, c.romanization)
+		message.Line = &line
+		message.Text = line.Text
+		message.Translation = line.Translation
+		message.Romanization = line.Romanization
+	}
+	return message
+}
+
+// positionLocked 根据校准点推算当前位置
+func (c *LyricsClock) positionLocked() int64 {
+	if !c.playing {
+		return c.position
+	}
+	return c.position + time.Since(c.anchor).Milliseconds()
+}
+
+// updateLocked 推送行和字的变化，并在播放中预约下一次变化
+func (c *LyricsClock) updateLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	pos := c.positionLocked()
+	lineIndex := c.timeline.LineAt(pos)
+	wordIndex := -1
+	if lineIndex >= 0 {
+		wordIndex = c.timeline.Lines[lineIndex].WordAt(pos)
+	}
+
+	if lineIndex != c.lineIndex {
+		c.lineIndex = lineIndex
+		c.wordIndex = -1
+		message := c.lineMessageLocked(pos)
+		c.emitLocked(message)
+		if c.onLine != nil {
+			c.onLine(message.Text)
+		}
+	}
+	if wordIndex != c.wordIndex {
+		c.wordIndex = wordIndex
+		if wordIndex >= 0 {
+			c.emitLocked(LyricsMessage{
+				Type:      "lyrics_word",
+				Index:     lineIndex,
+				WordIndex: wordIndex,
+				Position:  pos,
+				Playing:   c.playing,
+			})
+		}
+	}
+
+	if !c.playing {
+		return
+	}
+	if next, ok := c.nextChangeLocked(pos); ok {
+		c.timer = time.AfterFunc(time.Duration(next-pos)*time.Millisecond, c.tick)
+	}
+}
+
+// nextChangeLocked 返回下一个字或下一行开始的时间
+func (c *LyricsClock) nextChangeLocked(pos int64) (int64, bool) {
+	if !c.timeline.Synced {
+		return 0, false
+	}
+	next := int64(-1)
+	if c.lineIndex+1 < len(c.timeline.Lines) {
+		next = c.timeline.Lines[c.lineIndex+1].Start
+	}
+	if c.lineIndex >= 0 {
+		words := c.timeline.Lines[c.lineIndex].Words
+		if c.wordIndex+1 < len(words) && (next < 0 || words[c.wordIndex+1].Start < next) {
+			next = words[c.wordIndex+1].Start
+		}
+	}
+	if next < 0 {
+		return 0, false
+	}
+	if next < pos {
+		next = pos
+	}
+	return next, true
+}
+
+// tick 定时器到期时推送变化
+func (c *LyricsClock) tick() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.updateLocked()
+}
+
+// emitLocked 发送SSE消息
+func (c *LyricsClock) emitLocked(message LyricsMessage) {
+	if c.emit != nil {
+		c.emit(message)
+	}
+}
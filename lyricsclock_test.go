@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"wmplayer/internal/lyrics"
+)
+
+// newTestLyricsClock 创建把消息写入通道的歌词时钟
+func newTestLyricsClock(t *testing.T) (*LyricsClock, chan LyricsMessage) {
+	t.Helper()
+	messages := make(chan LyricsMessage, 100)
+	clock := NewLyricsClock(func(message LyricsMessage) { messages <- message })
+	t.Cleanup(func() { clock.SetPlaying(false) })
+	return clock, messages
+}
+
+// nextLyricsMessage 等待下一条指定类型的消息
+func nextLyricsMessage(t *testing.T, messages chan LyricsMessage, messageType string) LyricsMessage {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case message := <-messages:
+			if message.Type == messageType {
+				return message
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", messageType)
+		}
+	}
+}
+
+func TestLyricsClockEmitsLinesAndWords(t *testing.T) {
+	clock, messages := newTestLyricsClock(t)
+	var lines []string
+	clock.SetOnLine(func(text string) { lines = append(lines, text) })
+
+	clock.Load("", lyrics.Parse("[20,60]<0,30,0>拦<30,30,0>路\n[100,50]<0,50,0>人\n"), 0, "富士山下", "陈奕迅")
+	if message := nextLyricsMessage(t, messages, "lyrics_line"); message.Index != -1 || message.SongName != "富士山下" {
+		t.Fatalf("load message = %+v", message)
+	}
+	clock.SetPlaying(true)
+
+	first := nextLyricsMessage(t, messages, "lyrics_line")
+	if first.Index != 0 || first.Line == nil || first.Line.Text != "拦路" {
+		t.Fatalf("first line = %+v", first)
+	}
+	if word := nextLyricsMessage(t, messages, "lyrics_word"); word.Index != 0 || word.WordIndex != 0 {
+		t.Errorf("first word = %+v", word)
+	}
+	if word := nextLyricsMessage(t, messages, "lyrics_word"); word.Index != 0 || word.WordIndex != 1 || word.Position < 50 {
+		t.Errorf("second word = %+v", word)
+	}
+	if second := nextLyricsMessage(t, messages, "lyrics_line"); second.Index != 1 || second.Position < 100 {
+		t.Errorf("second line = %+v", second)
+	}
+
+	clock.SetPlaying(false)
+	if len(lines) != 3 || lines[0] != "" || lines[1] != "拦路" || lines[2] != "人" {
+		t.Errorf("MPRIS lines = %q", lines)
+	}
+}
+
+func TestLyricsClockPauseAndSeek(t *testing.T) {
+	clock, messages := newTestLyricsClock(t)
+	clock.Load("", lyrics.Parse("[0,1000]第一行\n[5000,1000]第二行\n[60000,1000]第三行\n"), 0, "", "")
+	nextLyricsMessage(t, messages, "lyrics_line")
+	if first := nextLyricsMessage(t, messages, "lyrics_line"); first.Index != 0 {
+		t.Fatalf("line at 0ms = %+v", first)
+	}
+	clock.SetPlaying(true)
+	nextLyricsMessage(t, messages, "playback_status")
+
+	// 暂停后位置不再前进
+	clock.SetPlaying(false)
+	nextLyricsMessage(t, messages, "playback_status")
+	paused := clock.Position()
+	time.Sleep(20 * time.Millisecond)
+	if clock.Position() != paused {
+		t.Error("position should not advance while paused")
+	}
+
+	// 暂停时跳转立即推送新的行
+	clock.SetPosition(6000)
+	if line := nextLyricsMessage(t, messages, "lyrics_line"); line.Index != 1 || line.Playing {
+		t.Errorf("seek while paused = %+v", line)
+	}
+
+	// 播放中小误差的校准被忽略，较大的误差视为跳转
+	clock.SetPlaying(true)
+	nextLyricsMessage(t, messages, "playback_status")
+	clock.SetPosition(clock.Position() + 100)
+	clock.SetPosition(61000)
+	status := nextLyricsMessage(t, messages, "playback_status")
+	if status.Position != 61000 {
+		t.Errorf("small drift should not re-anchor the clock: %+v", status)
+	}
+	if line := nextLyricsMessage(t, messages, "lyrics_line"); line.Index != 2 || line.Text != "第三行" {
+		t.Errorf("seek while playing = %+v", line)
+	}
+}
+
+func TestLyricsClockLayers(t *testing.T) {
+	clock, messages := newTestLyricsClock(t)
+	language := base64.StdEncoding.EncodeToString([]byte(
+		`{"content":[{"type":1,"lyricContent":[["sa ","ku ","ra"]]},{"type":0,"lyricContent":[["樱花"]]}]}`))
+	clock.Load("", lyrics.Parse("[language:"+language+"]\n[0,900]<0,300,0>さ<300,300,0>く<600,300,0>ら\n"), 0, "", "")
+	nextLyricsMessage(t, messages, "lyrics_line")
+
+	// 默认不推送附加层
+	line := nextLyricsMessage(t, messages, "lyrics_line")
+	if line.Translation != "" || line.Romanization != "" || line.Line.Translation != "" || line.Line.Words[0].Romanization != "" {
+		t.Errorf("layers should be hidden by default: %+v", line.Line)
+	}
+
+	// 开启后立即重新推送当前行
+	state := &AppState{lyricsClock: clock}
+	state.ApplyInterfaceSettings(InterfaceSettings{OSDLyricsTranslation: true, OSDLyricsRomanization: true})
+	line = nextLyricsMessage(t, messages, "lyrics_line")
+	if line.Translation != "樱花" || line.Romanization != "sa ku ra" || line.Line.Words[1].Romanization != "ku" {
+		t.Errorf("line with layers = %+v", line)
+	}
+}
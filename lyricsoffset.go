@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"wmplayer/internal/lyrics"
+)
+
+// lyricsOffsetStep 单次微调歌词偏移的最大幅度，避免误操作
+const lyricsOffsetStep = 10000
+
+// lrcOffsetPattern LRC文件中的 [offset:] 标签行
+var lrcOffsetPattern = regexp.MustCompile(`(?mi)^[ \t]*\[offset:[ \t]*([+-]?\d+)[ \t]*\][ \t]*\r?\n?`)
+
+// LyricsOffsetStore 保存用户为每首歌调整的歌词偏移（毫秒），在线歌曲按hash，本地音乐按文件路径
+// 偏移的含义与LRC的 [offset:] 相同，正值让歌词提前显示
+type LyricsOffsetStore struct {
+	file    string
+	offsets map[string]int64
+	mutex   sync.Mutex
+}
+
+// NewLyricsOffsetStore 创建歌词偏移存储并加载已保存的偏移
+func NewLyricsOffsetStore(file string) *LyricsOffsetStore {
+	store := &LyricsOffsetStore{
+		file:    file,
+		offsets: make(map[string]int64),
+	}
+	if data, err := os.ReadFile(file); err == nil {
+		if err := json.Unmarshal(data, &store.offsets); err != nil {
+			fmt.Printf("⚠️ 歌词偏移文件格式错误，已忽略: %v\n", err)
+			store.offsets = make(map[string]int64)
+		}
+	}
+	return store
+}
+
+// lyricsOffsetKey 生成偏移的key，有文件路径时按路径，否则按hash
+func lyricsOffsetKey(hash string, filePath string) string {
+	if filePath != "" {
+		return "path:" + filepath.Clean(filePath)
+	}
+	if hash == "" {
+		return ""
+	}
+	return "hash:" + strings.ToUpper(hash)
+}
+
+// Get 获取偏移，没有调整过时返回0
+func (s *LyricsOffsetStore) Get(key string) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.offsets[key]
+}
+
+// Set 设置偏移并立即保存，偏移为0时删除记录
+func (s *LyricsOffsetStore) Set(key string, offset int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if offset == 0 {
+		delete(s.offsets, key)
+	} else {
+		s.offsets[key] = offset
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("创建歌词偏移目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(s.offsets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化歌词偏移失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	if err := os.WriteFile(s.file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("写入歌词偏移失败: %v", err)
+	}
+	return os.Rename(s.file+".tmp", s.file)
+}
+
+// resolveLyricsFilePath 本地音乐只传hash时，从本地音乐映射中查找文件路径
+func (c *CacheService) resolveLyricsFilePath(hash string, filePath string) string {
+	if filePath == "" && strings.HasPrefix(hash, "local-") {
+		filePath, _ = c.localMusicPath(hash)
+	}
+	return filePath
+}
+
+// lyricsOffset 获取歌曲的歌词偏移，应用数据尚未创建时没有偏移
+func (c *CacheService) lyricsOffset(hash string, filePath string) int64 {
+	state := GetAppState()
+	if state == nil {
+		return 0
+	}
+	return state.lyricsOffsets.Get(lyricsOffsetKey(hash, c.resolveLyricsFilePath(hash, filePath)))
+}
+
+// GetLyricsOffset 获取歌曲的歌词偏移（毫秒），本地音乐传入文件路径
+func (c *CacheService) GetLyricsOffset(hash string, filePath string) ApiResponse[int64] {
+	return ApiResponse[int64]{
+		Success: true,
+		Message: "获取歌词偏移成功",
+		Data:    c.lyricsOffset(hash, filePath),
+	}
+}
+
+// SetLyricsOffset 设置歌曲的歌词偏移（毫秒），正值让歌词提前显示
+// 如果是当前播放的歌曲，歌词时钟立即按新的偏移推送
+func (c *CacheService) SetLyricsOffset(hash string, filePath string, offset int64) ApiResponse[int64] {
+	key := lyricsOffsetKey(hash, c.resolveLyricsFilePath(hash, filePath))
+	if key == "" {
+		return ApiResponse[int64]{
+			Success: false,
+			Message: "歌曲hash和文件路径不能都为空",
+		}
+	}
+	state, err := currentAppState()
+	if err != nil {
+		return ApiResponse[int64]{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if err := state.lyricsOffsets.Set(key, offset); err != nil {
+		return ApiResponse[int64]{
+			Success: false,
+			Message: fmt.Sprintf("保存歌词偏移失败: %v", err),
+		}
+	}
+	state.lyricsClock.SetOffset(key, offset)
+
+	fmt.Printf("🎵 歌词偏移已设置: %s -> %dms\n", key, offset)
+	return ApiResponse[int64]{
+		Success: true,
+		Message: "歌词偏移已保存",
+		Data:    offset,
+	}
+}
+
+// AdjustLyricsOffset 在当前偏移的基础上微调歌词偏移，返回调整后的偏移
+func (c *CacheService) AdjustLyricsOffset(hash string, filePath string, delta int64) ApiResponse[int64] {
+	if delta > lyricsOffsetStep || delta < -lyricsOffsetStep {
+		return ApiResponse[int64]{
+			Success: false,
+			Message: fmt.Sprintf("单次调整不能超过 %d 毫秒", lyricsOffsetStep),
+		}
+	}
+	return c.SetLyricsOffset(hash, filePath, c.lyricsOffset(hash, filePath)+delta)
+}
+
+// WriteLyricsOffsetToFile 把本地音乐调整的偏移写回所用LRC歌词文件的 [offset:] 标签
+// 写回后文件中的偏移已包含调整量，保存的偏移清零，避免重复应用
+func (c *CacheService) WriteLyricsOffsetToFile(filePath string) CacheResponse {
+	if filePath == "" {
+		return CacheResponse{
+			Success: false,
+			Message: "文件路径不能为空",
+		}
+	}
+	state, err := currentAppState()
+	if err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	key := lyricsOffsetKey("", filePath)
+	offset := state.lyricsOffsets.Get(key)
+	if offset == 0 {
+		return CacheResponse{
+			Success: true,
+			Message: "歌词没有调整偏移，无需写回",
+		}
+	}
+
+	title, artist := readLyricsTags(filePath)
+	lrcPath := findLyricsFile(filePath, c.localMusicConfig().LyricsFolder, title, artist)
+	if lrcPath == "" || !strings.EqualFold(filepath.Ext(lrcPath), ".lrc") {
+		return CacheResponse{
+			Success: false,
+			Message: "没有可写入的LRC歌词文件",
+		}
+	}
+	data, err := os.ReadFile(lrcPath)
+	if err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: fmt.Sprintf("读取LRC歌词文件失败: %v", err),
+		}
+	}
+
+	content := string(data)
+	total := lyrics.Parse(content).Offset + offset
+	content = setLRCOffsetTag(content, total)
+
+	info, _ := os.Stat(lrcPath)
+	mode := os.FileMode(0644)
+	if info != nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(lrcPath+".tmp", []byte(content), mode); err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: fmt.Sprintf("写入LRC歌词文件失败: %v", err),
+		}
+	}
+	if err := os.Rename(lrcPath+".tmp", lrcPath); err != nil {
+		return CacheResponse{
+			Success: false,
+			Message: fmt.Sprintf("写入LRC歌词文件失败: %v", err),
+		}
+	}
+
+	if err := state.lyricsOffsets.Set(key, 0); err != nil {
+		fmt.Printf("⚠️ 清除歌词偏移失败: %v\n", err)
+	}
+	fmt.Printf("💾 歌词偏移已写回: %s [offset:%d]\n", lrcPath, total)
+	return CacheResponse{
+		Success: true,
+		Message: "歌词偏移已写入LRC文件",
+		Data:    lrcPath,
+	}
+}
+
+// setLRCOffsetTag 替换或插入LRC的 [offset:] 标签，插入时放在文件开头的元数据标签之后
+func setLRCOffsetTag(content string, offset int64) string {
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	tag := fmt.Sprintf("[offset:%d]", offset)
+
+	if loc := lrcOffsetPattern.FindStringIndex(content); loc != nil {
+		return content[:loc[0]] + tag + newline + content[loc[1]:]
+	}
+
+	// 跳过 BOM 和开头的 [ti:]、[ar:] 等标签行
+	insertAt := 0
+	if strings.HasPrefix(content, "\ufeff") {
+		insertAt = len("\ufeff")
+	}
+	for insertAt < len(content) {
+		end := strings.Index(content[insertAt:], "\n")
+		if end < 0 {
+			break
+		}
+		line := strings.TrimSpace(content[insertAt : insertAt+end])
+		if lyrics.DetectFormat(line) != lyrics.FormatPlain || !strings.HasPrefix(line, "[") {
+			break
+		}
+		insertAt += end + 1
+	}
+	return content[:insertAt] + tag + newline + content[insertAt:]
+}
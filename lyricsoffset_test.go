@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wmplayer/internal/lyrics"
+)
+
+func TestLyricsOffsetStorePersists(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lyrics_offsets.json")
+	store := NewLyricsOffsetStore(file)
+	if err := store.Set("hash:ABC", 300); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("path:/music/a.mp3", -200); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewLyricsOffsetStore(file)
+	if reloaded.Get("hash:ABC") != 300 || reloaded.Get("path:/music/a.mp3") != -200 {
+		t.Errorf("offsets not persisted: %v", reloaded.offsets)
+	}
+
+	// 偏移归零时删除记录
+	if err := reloaded.Set("hash:ABC", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := NewLyricsOffsetStore(file).offsets["hash:ABC"]; ok {
+		t.Error("zero offset should be removed")
+	}
+}
+
+func TestAdjustLyricsOffset(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+
+	if resp := c.AdjustLyricsOffset("abc", "", 200); !resp.Success || resp.Data != 200 {
+		t.Fatalf("adjust = %+v", resp)
+	}
+	if resp := c.AdjustLyricsOffset("ABC", "", -500); resp.Data != -300 {
+		t.Errorf("hash should be case-insensitive: %+v", resp)
+	}
+	if resp := c.AdjustLyricsOffset("ABC", "", 60000); resp.Success {
+		t.Error("too large step should be rejected")
+	}
+	if resp := c.SetLyricsOffset("", "", 100); resp.Success {
+		t.Error("empty hash and path should be rejected")
+	}
+
+	// 本地音乐只传hash时按文件路径保存
+	c.RegisterLocalMusic("local-1", "/music/a.mp3")
+	c.SetLyricsOffset("local-1", "", 400)
+	if resp := c.GetLyricsOffset("", "/music/a.mp3"); resp.Data != 400 {
+		t.Errorf("local offset = %+v", resp)
+	}
+
+	// 解析歌词时应用保存的偏移
+	h := NewHomepageService(c, state)
+	timeline := h.ParseLyrics("[00:01.00]第一行\n", "abc", "").Data
+	if timeline.Offset != -300 || timeline.Lines[0].Start != 1300 {
+		t.Errorf("timeline = %+v", timeline)
+	}
+}
+
+func TestLyricsClockSetOffset(t *testing.T) {
+	clock, messages := newTestLyricsClock(t)
+	clock.Load("hash:ABC", lyrics.Parse("[1000,500]第一行\n[3000,500]第二行\n"), 0, "", "")
+	nextLyricsMessage(t, messages, "lyrics_line")
+
+	// 其它歌曲的偏移不影响当前歌词
+	clock.SetOffset("hash:DEF", 2000)
+	clock.SetPosition(1500)
+	if line := nextLyricsMessage(t, messages, "lyrics_line"); line.Index != 0 {
+		t.Fatalf("line at 1500ms = %+v", line)
+	}
+
+	// 歌词提前2秒后，1500ms 处已经是第二行
+	clock.SetOffset("hash:ABC", 2000)
+	if line := nextLyricsMessage(t, messages, "lyrics_line"); line.Index != 1 || line.Line.Start != 1000 {
+		t.Errorf("line after offset = %+v", line)
+	}
+}
+
+func TestWriteLyricsOffsetToFile(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	newTestAppState(t, c)
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "song.mp3")
+	lrc := filepath.Join(dir, "song.lrc")
+	if err := os.WriteFile(lrc, []byte("[ti:歌名]\r\n[ar:歌手]\r\n[00:01.00]第一行\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetLyricsOffset("", audio, 250)
+	if resp := c.WriteLyricsOffsetToFile(audio); !resp.Success || resp.Data != lrc {
+		t.Fatalf("write = %+v", resp)
+	}
+	data, _ := os.ReadFile(lrc)
+	if string(data) != "[ti:歌名]\r\n[ar:歌手]\r\n[offset:250]\r\n[00:01.00]第一行\r\n" {
+		t.Errorf("lrc = %q", data)
+	}
+	if c.GetLyricsOffset("", audio).Data != 0 {
+		t.Error("stored offset should be cleared after writing back")
+	}
+
+	// 已有 [offset:] 标签时累加
+	c.SetLyricsOffset("", audio, -100)
+	c.WriteLyricsOffsetToFile(audio)
+	data, _ = os.ReadFile(lrc)
+	if !strings.Contains(string(data), "[offset:150]\r\n") || strings.Count(string(data), "[offset:") != 1 {
+		t.Errorf("lrc = %q", data)
+	}
+
+	c.SetLyricsOffset("", filepath.Join(dir, "missing.mp3"), 100)
+	if resp := c.WriteLyricsOffsetToFile(filepath.Join(dir, "missing.mp3")); resp.Success {
+		t.Error("missing lrc file should fail")
+	}
+}
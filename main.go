@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"embed"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -15,6 +16,9 @@ import (
 // 全局缓存服务实例
 var globalCacheService *CacheService
 
+// 全局应用数据：播放队列、歌单、歌词和歌曲元数据
+var globalAppState *AppState
+
 // Wails uses Go's `embed` package to embed the frontend files into the binary.
 // Any files in the frontend/dist folder will be embedded into the binary and
 // made available to the frontend.
@@ -32,11 +36,31 @@ var customIcon []byte
 // and starts a goroutine that emits a time-based event every second. It subsequently runs the application and
 // logs any error that might occur.
 func main() {
+	// 解析命令行参数
+	cmdOptions := parseCommandLine(os.Args[1:])
+
+	// 控制运行中实例的参数（--play-pause、--status 等）只发送命令并输出结果，不启动界面
+	if len(remoteRequests(cmdOptions, "")) > 0 {
+		os.Exit(runRemoteControl(instanceSocketPath(), cmdOptions, os.Stdout, os.Stderr))
+	}
+
 	// 打印环境信息用于调试
 	log.Printf("DISPLAY: %s", os.Getenv("DISPLAY"))
 	log.Printf("XDG_CURRENT_DESKTOP: %s", os.Getenv("XDG_CURRENT_DESKTOP"))
 	log.Printf("WAYLAND_DISPLAY: %s", os.Getenv("WAYLAND_DISPLAY"))
 
+	// 单实例：已有实例运行时把参数转发给它并退出，避免重复的托盘、端口和MPRIS名称
+	instanceLock, err := acquireInstanceLock(instanceSocketPath())
+	if errors.Is(err, errInstanceRunning) {
+		log.Printf("🔁 wmplayer 已在运行，转发启动参数")
+		if err := forwardToRunningInstance(instanceSocketPath(), os.Args[1:]); err != nil {
+			log.Fatalf("❌ 转发到运行中的实例失败: %v", err)
+		}
+		return
+	} else if err != nil {
+		log.Printf("⚠️ 获取单实例锁失败，继续启动: %v", err)
+	}
+
 	// 在程序启动时初始化Cookie管理器
 	log.Printf("🍪 初始化Cookie管理器...")
 	if err := InitializeCookieManager(); err != nil {
@@ -54,6 +78,11 @@ func main() {
 	log.Printf("🔧 程序启动，开始加载设置文件...")
 	settingsService := NewSettingsService()
 	response, err := settingsService.LoadSettings()
+	var networkSettings NetworkSettings
+	cacheSettings := CacheSettings{MaxSizeMB: defaultCacheMaxSizeMB}
+	qualitySettings := QualitySettings{StreamingQuality: defaultStreamingQuality}
+	interfaceSettings := settingsService.getDefaultSettings().Interface
+	localMusicSettings := settingsService.getDefaultSettings().LocalMusic
 	if err != nil {
 		log.Printf("❌ 加载设置文件失败: %v", err)
 	} else {
@@ -65,9 +94,19 @@ func main() {
 			log.Printf("   关闭行为: %s", response.Data.Behavior.CloseAction)
 			log.Printf("   启动最小化: %v", response.Data.Behavior.StartMinimized)
 			log.Printf("   自动启动: %v", response.Data.Behavior.AutoStart)
+			networkSettings = response.Data.Network
+			cacheSettings = response.Data.Cache
+			qualitySettings = response.Data.Quality
+			interfaceSettings = response.Data.Interface
+			localMusicSettings = response.Data.LocalMusic
 		}
 	}
 
+	// 确定后端地址：命令行 > 环境变量 > 设置文件
+	endpoints, endpointSource := resolveBackendEndpoints(networkSettings, cmdOptions)
+	GlobalBackendManager.Configure(endpoints, endpointSource)
+	GlobalBackendManager.SetOffline(networkSettings.OfflineMode)
+
 	// Create a new Wails application by providing the necessary options.
 	// Variables 'Name' and 'Description' are for application metadata.
 	// 'Assets' configures the asset server with the 'FS' variable pointing to the frontend files.
@@ -77,12 +116,23 @@ func main() {
 	// 创建缓存服务实例
 	cacheService := NewCacheService()
 	globalCacheService = cacheService // 设置全局实例
+	cacheService.ApplyCacheSettings(cacheSettings)
+	cacheService.ApplyNetworkSettings(networkSettings)
+	cacheService.ApplyQualitySettings(qualitySettings)
+	cacheService.ApplyLocalMusicSettings(localMusicSettings)
 
-	// 创建首页服务实例，传入缓存服务
-	homepageService := NewHomepageService(cacheService)
+	// 加载播放队列、歌单、歌词和歌曲元数据，歌词时钟通过缓存服务的SSE推送给OSD
+	appState := NewAppState(cacheService.cacheDir, cacheService.broadcastLyricsMessage)
+	globalAppState = appState // 设置全局实例
+	appState.ApplyInterfaceSettings(interfaceSettings)
+
+	// 创建首页服务实例，传入缓存服务和应用数据
+	homepageService := NewHomepageService(cacheService, appState)
 
 	// 创建媒体键服务实例
 	mediaKeyService := NewMediaKeyService()
+	// 歌词时钟的当前行同步到MPRIS元数据
+	appState.lyricsClock.SetOnLine(mediaKeyService.UpdateMPRISLyrics)
 
 	// 创建播放器服务实例（如果需要的话）
 	// playerService := NewPlayerService()
@@ -106,10 +156,14 @@ func main() {
 			application.NewService(&PlayHistoryService{}),
 			application.NewService(&FavoritesService{}),
 			application.NewService(&PlaylistService{}),
+			application.NewService(&LocalPlaylistService{}),
+			application.NewService(&PlaylistFileService{}),
+			application.NewService(&SmartPlaylistService{}),
 			application.NewService(cacheService),
 			application.NewService(NewSettingsService()),
 			application.NewService(NewDownloadService()),
 			application.NewService(mediaKeyService),
+			application.NewService(NewBackendService()),
 		},
 		Assets: application.AssetOptions{
 			Handler: application.AssetFileServerFS(assets),
@@ -128,7 +182,7 @@ func main() {
 	// 'BackgroundColour' is the background colour of the window.
 	// 'URL' is the URL that will be loaded into the webview.
 
-	app.Window.NewWithOptions(application.WebviewWindowOptions{
+	mainWindow := app.Window.NewWithOptions(application.WebviewWindowOptions{
 		Title:     "wmplayer",
 		Height:    900,
 		Width:     1600,
@@ -142,6 +196,25 @@ func main() {
 		URL:              "/",
 	})
 
+	// 处理再次启动时转发的请求：显示主窗口并执行传入的参数
+	instanceController := NewInstanceController(func() {
+		mainWindow.Show()
+		mainWindow.UnMinimise()
+		mainWindow.Focus()
+	}, func(name string, data any) {
+		app.Event.Emit(name, data)
+	}, mediaKeyService)
+	if instanceLock != nil {
+		instanceLock.Serve(instanceController.Handle)
+	}
+
+	// 第一次启动时传入的文件和 --toggle 等前端注册好事件监听后，按转发的请求同样处理
+	workDir, _ := os.Getwd()
+	instanceController.SetStartupArgs(os.Args[1:], workDir)
+	app.Event.On(instanceReadyEvent, func(*application.CustomEvent) {
+		instanceController.FrontendReady()
+	})
+
 	// 创建系统托盘图标
 	systemTray := app.SystemTray.New()
 	systemTray.SetLabel("wmplayer")
@@ -239,8 +312,15 @@ func main() {
 
 	// 设置媒体键服务的应用实例
 	mediaKeyService.SetApp(app)
+	mediaKeyService.setEventEmitter(func(name string, data any) {
+		app.Event.Emit(name, data)
+	})
 	mediaKeyService.SetContext(context.Background())
 
+	// 探测后端服务，不可达时定期重试，状态变化时通知前端
+	GlobalBackendManager.SetApp(app)
+	go GlobalBackendManager.Monitor()
+
 	// 注册媒体键（在应用启动后）
 	go func() {
 		// 等待应用完全启动
@@ -260,6 +340,12 @@ func main() {
 		log.Printf("🔴 收到退出信号，清理OSD歌词进程...")
 		if cacheService != nil {
 			cacheService.stopOSDLyricsProcess()
+			// 缓存索引、播放列表和本地歌单延迟写盘，退出前保存
+			cacheService.flushStores()
+		}
+		appState.flush()
+		if instanceLock != nil {
+			instanceLock.Close()
 		}
 
 		// 退出程序
@@ -288,6 +374,13 @@ func main() {
 		} else {
 			log.Printf("✅ HTTP缓存服务器已停止")
 		}
+		cacheService.flushStores()
+	}
+	appState.flush()
+
+	// 释放单实例锁
+	if instanceLock != nil {
+		instanceLock.Close()
 	}
 
 	// If an error occurred while running the application, log it and exit.
@@ -300,3 +393,8 @@ func main() {
 func GetCacheService() *CacheService {
 	return globalCacheService
 }
+
+// GetAppState 获取全局应用数据
+func GetAppState() *AppState {
+	return globalAppState
+}
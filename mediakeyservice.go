@@ -3,10 +3,36 @@ package main
 import (
 	"context"
 	"log"
+	"math"
 	"runtime"
 	"sync"
+	"time"
 )
 
+// 媒体键转发给前端的事件
+const (
+	togglePlayPauseEvent = "systray:toggle-play-pause"
+	nextSongEvent        = "systray:next-song"
+	previousSongEvent    = "systray:previous-song"
+	setVolumeEvent       = "mediakey:set-volume"
+)
+
+// volumeStep 媒体键每次调整的音量
+const volumeStep = 10
+
+// PlayerStatus 前端上报的播放器状态，供命令行和状态栏查询
+type PlayerStatus struct {
+	Status   string  `json:"status"` // Playing、Paused 或 Stopped
+	Title    string  `json:"title"`
+	Artist   string  `json:"artist"`
+	Album    string  `json:"album"`
+	ArtURL   string  `json:"art_url"`
+	Duration float64 `json:"duration"`        // 时长（秒）
+	Position float64 `json:"position"`        // 播放位置（秒）
+	Volume   int     `json:"volume"`          // 音量 0-100
+	Lyric    string  `json:"lyric,omitempty"` // 当前歌词行
+}
+
 // MediaKeyService 媒体键服务
 type MediaKeyService struct {
 	ctx          context.Context
@@ -14,11 +40,21 @@ type MediaKeyService struct {
 	isRegistered bool
 	mu           sync.RWMutex
 	mprisService *MPRISService // MPRIS服务实例
+
+	// 发送事件到前端，媒体键、MPRIS和命令行的控制都通过它交给前端播放器执行
+	emit func(name string, data any)
+
+	// 前端上报的播放状态
+	stateMutex     sync.Mutex
+	state          PlayerStatus
+	positionAnchor time.Time // 上报播放位置的时间，播放中据此推算当前位置
 }
 
 // NewMediaKeyService 创建媒体键服务
 func NewMediaKeyService() *MediaKeyService {
-	return &MediaKeyService{}
+	return &MediaKeyService{
+		state: PlayerStatus{Status: "Stopped", Volume: -1},
+	}
 }
 
 // SetContext 设置上下文
@@ -32,6 +68,26 @@ func (m *MediaKeyService) SetApp(app any) {
 	log.Println("🎵 媒体键服务：应用实例已设置")
 }
 
+// setEventEmitter 设置发送事件到前端的方法
+func (m *MediaKeyService) setEventEmitter(emit func(name string, data any)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.emit = emit
+}
+
+// emitEvent 发送事件到前端，前端未连接时返回 false
+func (m *MediaKeyService) emitEvent(name string, data any) bool {
+	m.mu.RLock()
+	emit := m.emit
+	m.mu.RUnlock()
+	if emit == nil {
+		log.Printf("⚠️ 媒体键服务：前端未连接，忽略事件 %s", name)
+		return false
+	}
+	emit(name, data)
+	return true
+}
+
 // SetPlayerService 设置播放器服务（预留接口）
 func (m *MediaKeyService) SetPlayerService(playerService any) {
 	// 预留接口，当前版本主要依赖前端实现
@@ -229,13 +285,24 @@ func (m *MediaKeyService) IsRegistered() bool {
 	return m.isRegistered
 }
 
-// HandleMediaKeyEvent 处理媒体键事件（供前端调用）
+// HandleMediaKeyEvent 处理媒体键事件，MPRIS和命令行的控制也通过这里交给前端播放器
 func (m *MediaKeyService) HandleMediaKeyEvent(action string) map[string]any {
-	log.Printf("🎵 收到前端媒体键事件: %s", action)
+	log.Printf("🎵 收到媒体键事件: %s", action)
 
 	switch action {
 	case "play_pause":
 		return m.handlePlayPause()
+	case "play":
+		// 已经在播放时不再切换
+		if m.GetPlayerStatus().Status == "Playing" {
+			return mediaKeyResult(true, "已在播放", action)
+		}
+		return m.handlePlayPause()
+	case "pause", "stop":
+		if m.GetPlayerStatus().Status != "Playing" {
+			return mediaKeyResult(true, "已暂停", action)
+		}
+		return m.handlePlayPause()
 	case "next_track":
 		return m.handleNextTrack()
 	case "previous_track":
@@ -252,62 +319,112 @@ func (m *MediaKeyService) HandleMediaKeyEvent(action string) map[string]any {
 	}
 }
 
+// mediaKeyResult 生成媒体键动作的处理结果
+func mediaKeyResult(success bool, message string, action string) map[string]any {
+	return map[string]any{
+		"success": success,
+		"message": message,
+		"action":  action,
+	}
+}
+
 // handlePlayPause 处理播放/暂停
 func (m *MediaKeyService) handlePlayPause() map[string]any {
 	log.Println("🎵 媒体键触发：播放/暂停")
-
-	// 这里可以添加具体的播放/暂停逻辑
-	// 由于当前架构主要依赖前端，这里主要做日志记录
-
-	return map[string]any{
-		"success": true,
-		"message": "播放/暂停命令已处理",
-		"action":  "play_pause",
+	if !m.emitEvent(togglePlayPauseEvent, nil) {
+		return mediaKeyResult(false, "播放器界面未就绪", "play_pause")
 	}
+	return mediaKeyResult(true, "播放/暂停命令已处理", "play_pause")
 }
 
 // handleNextTrack 处理下一首
 func (m *MediaKeyService) handleNextTrack() map[string]any {
 	log.Println("🎵 媒体键触发：下一首")
-
-	return map[string]any{
-		"success": true,
-		"message": "下一首命令已处理",
-		"action":  "next_track",
+	if !m.emitEvent(nextSongEvent, nil) {
+		return mediaKeyResult(false, "播放器界面未就绪", "next_track")
 	}
+	return mediaKeyResult(true, "下一首命令已处理", "next_track")
 }
 
 // handlePreviousTrack 处理上一首
 func (m *MediaKeyService) handlePreviousTrack() map[string]any {
 	log.Println("🎵 媒体键触发：上一首")
-
-	return map[string]any{
-		"success": true,
-		"message": "上一首命令已处理",
-		"action":  "previous_track",
+	if !m.emitEvent(previousSongEvent, nil) {
+		return mediaKeyResult(false, "播放器界面未就绪", "previous_track")
 	}
+	return mediaKeyResult(true, "上一首命令已处理", "previous_track")
 }
 
 // handleVolumeUp 处理音量增加
 func (m *MediaKeyService) handleVolumeUp() map[string]any {
 	log.Println("🎵 媒体键触发：音量+")
-
-	return map[string]any{
-		"success": true,
-		"message": "音量增加命令已处理",
-		"action":  "volume_up",
-	}
+	return m.adjustVolume(volumeStep, "volume_up")
 }
 
 // handleVolumeDown 处理音量减少
 func (m *MediaKeyService) handleVolumeDown() map[string]any {
 	log.Println("🎵 媒体键触发：音量-")
+	return m.adjustVolume(-volumeStep, "volume_down")
+}
 
-	return map[string]any{
-		"success": true,
-		"message": "音量减少命令已处理",
-		"action":  "volume_down",
+// adjustVolume 在前端上报的音量基础上调整音量
+func (m *MediaKeyService) adjustVolume(delta int, action string) map[string]any {
+	volume := m.GetPlayerStatus().Volume
+	if volume < 0 {
+		return mediaKeyResult(false, "播放器尚未上报音量", action)
 	}
+	result := m.setVolume(volume + delta)
+	result["action"] = action
+	return result
+}
+
+// setVolume 设置播放器音量（0-100），超出范围时取边界值
+func (m *MediaKeyService) setVolume(volume int) map[string]any {
+	volume = max(0, min(100, volume))
+	if !m.emitEvent(setVolumeEvent, volume) {
+		return mediaKeyResult(false, "播放器界面未就绪", "set_volume")
+	}
+	// 先记录目标音量，连续调整时不必等待前端上报
+	m.stateMutex.Lock()
+	m.state.Volume = volume
+	m.stateMutex.Unlock()
+
+	result := mediaKeyResult(true, "音量已设置", "set_volume")
+	result["volume"] = volume
+	return result
+}
+
+// GetPlayerStatus 获取前端上报的播放器状态，播放中的位置按上报后经过的时间推算
+func (m *MediaKeyService) GetPlayerStatus() PlayerStatus {
+	m.stateMutex.Lock()
+	status := m.state
+	if status.Status == "Playing" && !m.positionAnchor.IsZero() {
+		status.Position += time.Since(m.positionAnchor).Seconds()
+		if status.Duration > 0 && status.Position > status.Duration {
+			status.Position = status.Duration
+		}
+	}
+	m.stateMutex.Unlock()
+
+	if appState := GetAppState(); appState != nil {
+		status.Lyric = appState.lyricsClock.CurrentMessage().Text
+	}
+	return status
+}
+
+// updateState 在锁内修改播放器状态
+func (m *MediaKeyService) updateState(update func(state *PlayerStatus)) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	update(&m.state)
+}
+
+// setStatePosition 记录播放位置（微秒）和上报时间
+func (m *MediaKeyService) setStatePosition(position int64) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	m.state.Position = float64(position) / 1e6
+	m.positionAnchor = time.Now()
 }
 
 // ==================== MPRIS状态更新方法 ====================
@@ -340,6 +457,13 @@ func (m *MediaKeyService) UpdateMPRISPosition(position int64) {
 	}
 }
 
+// UpdateMPRISLyrics 更新MPRIS元数据中的当前歌词行
+func (m *MediaKeyService) UpdateMPRISLyrics(text string) {
+	if m.mprisService != nil && m.mprisService.IsActive() {
+		m.mprisService.SetLyricsText(text)
+	}
+}
+
 // EmitMPRISSeeked 发射MPRIS Seeked信号
 func (m *MediaKeyService) EmitMPRISSeeked(position int64) {
 	if m.mprisService != nil && m.mprisService.IsActive() {
@@ -354,6 +478,17 @@ func (m *MediaKeyService) UpdatePlaybackStatus(status string) map[string]any {
 	log.Printf("🎵 前端请求更新播放状态: %s", status)
 
 	m.UpdateMPRISPlaybackStatus(status)
+	m.stateMutex.Lock()
+	if m.state.Status == "Playing" && !m.positionAnchor.IsZero() {
+		// 暂停时把推算的位置固定下来
+		m.state.Position += time.Since(m.positionAnchor).Seconds()
+	}
+	m.state.Status = status
+	m.positionAnchor = time.Now()
+	m.stateMutex.Unlock()
+	if appState := GetAppState(); appState != nil {
+		appState.lyricsClock.SetPlaying(status == "Playing")
+	}
 
 	return map[string]any{
 		"success": true,
@@ -367,6 +502,15 @@ func (m *MediaKeyService) UpdateSongMetadata(title, artist, album, artUrl string
 	log.Printf("🎵 前端请求更新歌曲元数据: %s - %s", title, artist)
 
 	m.UpdateMPRISMetadata(title, artist, album, artUrl, duration)
+	m.updateState(func(state *PlayerStatus) {
+		state.Title = title
+		state.Artist = artist
+		state.Album = album
+		state.ArtURL = artUrl
+		state.Duration = float64(duration) / 1e6
+	})
+	// 切换歌曲后位置从头开始，等待前端上报
+	m.setStatePosition(0)
 
 	return map[string]any{
 		"success": true,
@@ -382,6 +526,9 @@ func (m *MediaKeyService) UpdatePlayerVolume(volume float64) map[string]any {
 	log.Printf("🎵 前端请求更新音量: %.2f", volume)
 
 	m.UpdateMPRISVolume(volume)
+	m.updateState(func(state *PlayerStatus) {
+		state.Volume = int(math.Round(volume * 100))
+	})
 
 	return map[string]any{
 		"success": true,
@@ -396,6 +543,10 @@ func (m *MediaKeyService) UpdatePlayerPosition(position int64) map[string]any {
 	// log.Printf("🎵 前端请求更新播放位置: %d微秒", position)
 
 	m.UpdateMPRISPosition(position)
+	m.setStatePosition(position)
+	if appState := GetAppState(); appState != nil {
+		appState.lyricsClock.SetPosition(position / 1000)
+	}
 
 	return map[string]any{
 		"success":  true,
@@ -409,6 +560,10 @@ func (m *MediaKeyService) NotifySeek(position int64) map[string]any {
 	log.Printf("🎵 前端通知跳转事件: %d微秒", position)
 
 	m.EmitMPRISSeeked(position)
+	m.setStatePosition(position)
+	if appState := GetAppState(); appState != nil {
+		appState.lyricsClock.SetPosition(position / 1000)
+	}
 
 	return map[string]any{
 		"success":  true,
@@ -485,6 +485,27 @@ func (m *MPRISService) SetMetadata(title, artist, album, artUrl string, duration
 	log.Printf("🎵 MPRIS: 元数据更新 - 标题: %s, 艺术家: %s, 专辑: %s", title, artist, album)
 }
 
+// SetLyricsText 把当前歌词行写入元数据的 xesam:asText
+func (m *MPRISService) SetLyricsText(text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metadata := make(map[string]dbus.Variant, len(m.metadata)+1)
+	for key, value := range m.metadata {
+		metadata[key] = value
+	}
+	if text != "" {
+		metadata["xesam:asText"] = dbus.MakeVariant(text)
+	} else {
+		delete(metadata, "xesam:asText")
+	}
+
+	m.metadata = metadata
+	if m.props != nil {
+		m.props.SetMust(mprisPlayerInterface, "Metadata", metadata)
+	}
+}
+
 // SetVolume 设置音量
 func (m *MPRISService) SetVolume(volume float64) {
 	m.mu.Lock()
@@ -559,8 +580,9 @@ func (m *MPRISService) callFrontendSetVolume(volume float64) {
 	volumePercent := int(volume * 100)
 	log.Printf("🎵 MPRIS: 设置音量 %d%%", volumePercent)
 
-	// TODO: 实现具体的音量设置逻辑
-	// 这里需要与前端播放器集成
+	if m.mediaKeyService != nil {
+		m.mediaKeyService.setVolume(volumePercent)
+	}
 }
 
 // ==================== 公共API方法 ====================
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// offlineSongs 收集离线时可以播放的歌曲
+// 已缓存的在线歌曲的信息取自缓存元数据，缺失时参考播放历史、我喜欢的本地副本和下载记录，本地音乐取自扫描结果
+func offlineSongs() []SearchSongData {
+	var songs []SearchSongData
+	seen := make(map[string]bool)
+	add := func(song SearchSongData) {
+		key := strings.ToUpper(song.Hash)
+		if song.Hash == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		songs = append(songs, song)
+	}
+
+	cacheService := GetCacheService()
+	addCached := func(song SearchSongData) {
+		if cacheService == nil {
+			return
+		}
+		if _, ok := cacheService.lookupCachedFile(song.Hash, AudioQualityHigh, true); ok {
+			add(song)
+		}
+	}
+
+	if cacheService != nil {
+		for _, song := range cacheService.cachedSongs() {
+			if song.SongName != "" || song.FileName != "" {
+				add(song.searchData())
+			}
+		}
+	}
+
+	if history, err := (&PlayHistoryService{}).loadPlayHistory(); err == nil {
+		for _, record := range history.Records {
+			addCached(SearchSongData{
+				Hash:       record.Hash,
+				SongName:   record.SongName,
+				FileName:   record.Filename,
+				TimeLength: record.Duration,
+				AlbumName:  record.AlbumName,
+				AlbumID:    record.AlbumID,
+				AuthorName: record.ArtistName,
+				UnionCover: record.UnionCover,
+			})
+		}
+	}
+
+	favoritesService := &FavoritesService{}
+	localFavoritesMutex.Lock()
+	favorites, err := favoritesService.loadLocalFavorites()
+	localFavoritesMutex.Unlock()
+	if err == nil {
+		for _, song := range favorites.Songs {
+			addCached(SearchSongData{
+				Hash:       song.Hash,
+				SongName:   song.SongName,
+				FileName:   song.FileName,
+				TimeLength: song.TimeLength,
+				AlbumName:  song.AlbumName,
+				AlbumID:    song.AlbumID,
+				AuthorName: song.AuthorName,
+				UnionCover: song.UnionCover,
+			})
+		}
+	}
+
+	if downloads, err := NewDownloadService().loadDownloadRecords(); err == nil {
+		for _, record := range downloads.Records {
+			addCached(SearchSongData{
+				Hash:       record.Hash,
+				SongName:   record.SongName,
+				FileName:   record.Filename,
+				AuthorName: record.ArtistName,
+			})
+		}
+	}
+
+	// 本地音乐使用与本地音乐页面相同的 local- 前缀hash
+	if local := (&LocalMusicService{}).GetCachedMusicFiles(); local.Success {
+		for _, file := range local.Data {
+			songName := file.Title
+			if songName == "" {
+				songName = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+			}
+			add(SearchSongData{
+				Hash:       "local-" + file.Hash,
+				SongName:   songName,
+				FileName:   file.Filename,
+				TimeLength: file.Duration,
+				AlbumName:  file.Album,
+				AuthorName: file.Artist,
+				UnionCover: file.UnionCover,
+			})
+		}
+	}
+
+	return songs
+}
+
+// matchOfflineSong 歌名、歌手、专辑和文件名中包含所有关键词时匹配，不区分大小写
+func matchOfflineSong(song SearchSongData, keywords []string) bool {
+	text := strings.ToLower(strings.Join([]string{song.SongName, song.AuthorName, song.AlbumName, song.FileName}, " "))
+	for _, keyword := range keywords {
+		if !strings.Contains(text, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// searchOffline 在已缓存的歌曲和本地音乐中搜索歌曲
+func searchOffline(keyword string, page int, pageSize int) SearchResponse {
+	page, pageSize = normalizePaging(page, pageSize)
+	keywords := strings.Fields(strings.ToLower(keyword))
+
+	var matches []SearchSongData
+	for _, song := range offlineSongs() {
+		if matchOfflineSong(song, keywords) {
+			matches = append(matches, song)
+		}
+	}
+
+	results := SearchResults{}
+	results.Songs.Total = len(matches)
+	start := (page - 1) * pageSize
+	if start < len(matches) {
+		end := start + pageSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+		results.Songs.List = matches[start:end]
+	}
+
+	fmt.Printf("✈️ 离线搜索: %s, 找到 %d 首\n", keyword, len(matches))
+	return SearchResponse{
+		Success: true,
+		Message: "离线模式：仅搜索已缓存和本地音乐",
+		Data:    results,
+	}
+}
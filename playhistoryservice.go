@@ -26,6 +26,28 @@ type PlayHistoryRecord struct {
 	PlayTime     time.Time `json:"play_time"`      // 播放时间
 	PlayCount    int       `json:"play_count"`     // 播放次数
 	LastPlayTime time.Time `json:"last_play_time"` // 最后播放时间
+	// PlayTimes 最近的播放时间，用于统计一段时间内的播放次数，旧版本的记录没有
+	PlayTimes []time.Time `json:"play_times,omitempty"`
+}
+
+// maxPlayTimes 每首歌保留的播放时间数量
+const maxPlayTimes = 50
+
+// PlaysSince 统计 since 之后的播放次数，没有播放时间的旧记录只按最后播放时间计算
+func (r PlayHistoryRecord) PlaysSince(since time.Time) int {
+	if len(r.PlayTimes) == 0 {
+		if r.LastPlayTime.After(since) {
+			return 1
+		}
+		return 0
+	}
+	count := 0
+	for _, playTime := range r.PlayTimes {
+		if playTime.After(since) {
+			count++
+		}
+	}
+	return count
 }
 
 // PlayHistoryData 播放历史数据结构
@@ -158,6 +180,17 @@ func (p *PlayHistoryService) AddPlayHistory(request AddPlayHistoryRequest) PlayH
 
 	now := time.Now()
 
+	recordSongMetadata(SongMetadata{
+		Hash:       request.Hash,
+		SongName:   request.SongName,
+		FileName:   request.Filename,
+		AuthorName: request.ArtistName,
+		AlbumName:  request.AlbumName,
+		AlbumID:    request.AlbumID,
+		TimeLength: request.Duration,
+		UnionCover: request.UnionCover,
+	})
+
 	// 查找是否已存在该歌曲的记录
 	var existingRecord *PlayHistoryRecord
 	for i := range historyData.Records {
@@ -173,6 +206,10 @@ func (p *PlayHistoryService) AddPlayHistory(request AddPlayHistoryRequest) PlayH
 		existingRecord.PlayCount++
 		existingRecord.LastPlayTime = now
 		existingRecord.PlayTime = now // 更新为最新播放时间，用于排序
+		existingRecord.PlayTimes = append(existingRecord.PlayTimes, now)
+		if len(existingRecord.PlayTimes) > maxPlayTimes {
+			existingRecord.PlayTimes = existingRecord.PlayTimes[len(existingRecord.PlayTimes)-maxPlayTimes:]
+		}
 
 		// 更新歌曲信息（可能有变化）
 		existingRecord.SongName = request.SongName
@@ -198,6 +235,7 @@ func (p *PlayHistoryService) AddPlayHistory(request AddPlayHistoryRequest) PlayH
 			PlayTime:     now,
 			PlayCount:    1,
 			LastPlayTime: now,
+			PlayTimes:    []time.Time{now},
 		}
 		historyData.Records = append(historyData.Records, newRecord)
 	}
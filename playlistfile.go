@@ -0,0 +1,824 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 播放列表文件格式
+const (
+	PlaylistFormatM3U  = "m3u8"
+	PlaylistFormatPLS  = "pls"
+	PlaylistFormatXSPF = "xspf"
+)
+
+// 导出的播放列表来源
+const (
+	PlaylistSourceQueue  = "queue"  // 当前播放列表
+	PlaylistSourceLocal  = "local"  // 本地歌单
+	PlaylistSourceOnline = "online" // 在线歌单
+)
+
+// playlistSongScheme 在线歌曲在播放列表文件中的位置前缀，导入时按hash还原
+const playlistSongScheme = "wmplayer:song/"
+
+// playlistDurationTolerance 按歌名匹配在线歌曲时允许的时长误差（秒）
+const playlistDurationTolerance = 10
+
+// playlistDuplicateReason 导入时重复出现的歌曲在结果中的原因
+const playlistDuplicateReason = "重复"
+
+// 播放列表文件的错误
+var (
+	errPlaylistFormat  = errors.New("不支持的播放列表格式，请使用 m3u8、pls 或 xspf")
+	errPlaylistEmpty   = errors.New("播放列表文件中没有歌曲")
+	errPlaylistNoMatch = errors.New("播放列表中的歌曲都没有找到")
+	errPlaylistPath    = errors.New("文件路径不能为空")
+	errPlaylistSource  = errors.New("未知的播放列表来源")
+)
+
+// playlistFileEntry 播放列表文件中的一首歌曲
+type playlistFileEntry struct {
+	Location string // 文件路径或URL
+	Title    string
+	Artist   string
+	Album    string
+	Duration int // 时长（秒），未知时为 0
+}
+
+// playlistFile 解析后的播放列表文件
+type playlistFile struct {
+	Title   string
+	Entries []playlistFileEntry
+}
+
+// PlaylistFileIssue 导入时没有找到或重复、导出时没有本地文件的歌曲
+type PlaylistFileIssue struct {
+	Index    int    `json:"index"` // 在播放列表中的位置，从 0 开始
+	Location string `json:"location"`
+	Title    string `json:"title"`
+	Artist   string `json:"artist"`
+	Reason   string `json:"reason"`
+}
+
+// PlaylistFileImportResult 导入播放列表文件的结果
+type PlaylistFileImportResult struct {
+	Playlist  LocalPlaylist       `json:"playlist"` // 导入后创建的本地歌单
+	Format    string              `json:"format"`
+	Total     int                 `json:"total"`
+	Matched   int                 `json:"matched"`
+	Unmatched []PlaylistFileIssue `json:"unmatched"` // 没有找到的歌曲和重复的条目，重复的原因为 playlistDuplicateReason
+}
+
+// ExportPlaylistFileRequest 导出播放列表文件请求
+type ExportPlaylistFileRequest struct {
+	Source        string `json:"source"`         // queue、local 或 online
+	ID            string `json:"id"`             // 本地歌单ID或在线歌单的 global_collection_id
+	Path          string `json:"path"`           // 导出的文件路径
+	Format        string `json:"format"`         // 为空时按扩展名判断
+	RelativePaths bool   `json:"relative_paths"` // 使用相对于播放列表文件的路径
+}
+
+// PlaylistFileExportResult 导出播放列表文件的结果
+type PlaylistFileExportResult struct {
+	Path    string              `json:"path"`
+	Format  string              `json:"format"`
+	Total   int                 `json:"total"`
+	Missing []PlaylistFileIssue `json:"missing"` // 没有本地文件的歌曲，其他播放器无法播放
+}
+
+// PlaylistFileImportResponse 导入播放列表文件响应结构
+type PlaylistFileImportResponse = ApiResponse[PlaylistFileImportResult]
+
+// PlaylistFileExportResponse 导出播放列表文件响应结构
+type PlaylistFileExportResponse = ApiResponse[PlaylistFileExportResult]
+
+// detectPlaylistFormat 按扩展名判断播放列表格式，扩展名未知时检查文件内容
+func detectPlaylistFormat(path string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u", ".m3u8":
+		return PlaylistFormatM3U, nil
+	case ".pls":
+		return PlaylistFormatPLS, nil
+	case ".xspf":
+		return PlaylistFormatXSPF, nil
+	}
+
+	head := strings.ToLower(strings.TrimSpace(string(trimBOM(data))))
+	switch {
+	case strings.HasPrefix(head, "#extm3u"):
+		return PlaylistFormatM3U, nil
+	case strings.HasPrefix(head, "[playlist]"):
+		return PlaylistFormatPLS, nil
+	case strings.HasPrefix(head, "<") && strings.Contains(head, "<playlist"):
+		return PlaylistFormatXSPF, nil
+	}
+	return "", errPlaylistFormat
+}
+
+// normalizePlaylistFormat 把用户指定的格式转换为格式常量
+func normalizePlaylistFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), ".")) {
+	case "m3u", "m3u8":
+		return PlaylistFormatM3U, nil
+	case "pls":
+		return PlaylistFormatPLS, nil
+	case "xspf":
+		return PlaylistFormatXSPF, nil
+	}
+	return "", errPlaylistFormat
+}
+
+// trimBOM 去掉 UTF-8 BOM
+func trimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+}
+
+// parsePlaylistFile 按格式解析播放列表文件
+func parsePlaylistFile(format string, data []byte) (playlistFile, error) {
+	data = trimBOM(data)
+	switch format {
+	case PlaylistFormatM3U:
+		return parseM3U(data), nil
+	case PlaylistFormatPLS:
+		return parsePLS(data), nil
+	case PlaylistFormatXSPF:
+		return parseXSPF(data)
+	}
+	return playlistFile{}, errPlaylistFormat
+}
+
+// splitDisplayTitle 拆分 "歌手 - 歌名" 形式的显示名称
+func splitDisplayTitle(display string) (artist string, title string) {
+	display = strings.TrimSpace(display)
+	if artist, title, ok := strings.Cut(display, " - "); ok {
+		return strings.TrimSpace(artist), strings.TrimSpace(title)
+	}
+	return "", display
+}
+
+// parsePlaylistDuration 解析秒数，-1 等未知时长返回 0
+func parsePlaylistDuration(value string) int {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return int(seconds + 0.5)
+}
+
+// parseM3U 解析 M3U/M3U8，支持 #EXTINF、#EXTALB、#EXTART 和 #PLAYLIST 扩展
+func parseM3U(data []byte) playlistFile {
+	var playlist playlistFile
+	var pending playlistFileEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			duration, display, _ := strings.Cut(info, ",")
+			// 时长后面可能跟着 key="value" 形式的属性
+			if fields := strings.Fields(duration); len(fields) > 0 {
+				pending.Duration = parsePlaylistDuration(fields[0])
+			}
+			artist, title := splitDisplayTitle(display)
+			if artist != "" {
+				pending.Artist = artist
+			}
+			pending.Title = title
+		case strings.HasPrefix(line, "#EXTALB:"):
+			pending.Album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+		case strings.HasPrefix(line, "#EXTART:"):
+			pending.Artist = strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			playlist.Title = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#"):
+		default:
+			pending.Location = line
+			playlist.Entries = append(playlist.Entries, pending)
+			pending = playlistFileEntry{}
+		}
+	}
+	return playlist
+}
+
+// parsePLS 解析 PLS，条目按 FileN 的序号排列
+func parsePLS(data []byte) playlistFile {
+	entries := map[int]*playlistFileEntry{}
+	entry := func(index int) *playlistFileEntry {
+		if entries[index] == nil {
+			entries[index] = &playlistFileEntry{}
+		}
+		return entries[index]
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		for _, field := range []string{"file", "title", "length"} {
+			index, err := strconv.Atoi(strings.TrimPrefix(key, field))
+			if !strings.HasPrefix(key, field) || err != nil {
+				continue
+			}
+			switch field {
+			case "file":
+				entry(index).Location = value
+			case "title":
+				entry(index).Artist, entry(index).Title = splitDisplayTitle(value)
+			case "length":
+				entry(index).Duration = parsePlaylistDuration(value)
+			}
+		}
+	}
+
+	indices := make([]int, 0, len(entries))
+	for index, e := range entries {
+		if e.Location != "" {
+			indices = append(indices, index)
+		}
+	}
+	sort.Ints(indices)
+
+	var playlist playlistFile
+	for _, index := range indices {
+		playlist.Entries = append(playlist.Entries, *entries[index])
+	}
+	return playlist
+}
+
+// xspfPlaylist XSPF 文件结构，读取时不限制命名空间
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Version string      `xml:"version,attr,omitempty"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack XSPF 中的歌曲，时长单位为毫秒
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+// parseXSPF 解析 XSPF，位置是 URI，相对路径会被解码
+func parseXSPF(data []byte) (playlistFile, error) {
+	var doc xspfPlaylist
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return playlistFile{}, fmt.Errorf("解析 XSPF 失败: %v", err)
+	}
+
+	playlist := playlistFile{Title: strings.TrimSpace(doc.Title)}
+	for _, track := range doc.Tracks {
+		location := strings.TrimSpace(track.Location)
+		if !strings.Contains(location, ":") {
+			if decoded, err := url.PathUnescape(location); err == nil {
+				location = decoded
+			}
+		}
+		playlist.Entries = append(playlist.Entries, playlistFileEntry{
+			Location: location,
+			Title:    strings.TrimSpace(track.Title),
+			Artist:   strings.TrimSpace(track.Creator),
+			Album:    strings.TrimSpace(track.Album),
+			Duration: (track.Duration + 500) / 1000,
+		})
+	}
+	return playlist, nil
+}
+
+// writePlaylistFile 按格式生成播放列表文件的内容
+func writePlaylistFile(format string, playlist playlistFile) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case PlaylistFormatM3U:
+		buf.WriteString("#EXTM3U\n")
+		if playlist.Title != "" {
+			fmt.Fprintf(&buf, "#PLAYLIST:%s\n", playlist.Title)
+		}
+		for _, entry := range playlist.Entries {
+			duration := entry.Duration
+			if duration <= 0 {
+				duration = -1
+			}
+			fmt.Fprintf(&buf, "#EXTINF:%d,%s\n", duration, displayTitle(entry))
+			if entry.Album != "" {
+				fmt.Fprintf(&buf, "#EXTALB:%s\n", entry.Album)
+			}
+			buf.WriteString(entry.Location + "\n")
+		}
+	case PlaylistFormatPLS:
+		buf.WriteString("[playlist]\n")
+		for i, entry := range playlist.Entries {
+			duration := entry.Duration
+			if duration <= 0 {
+				duration = -1
+			}
+			fmt.Fprintf(&buf, "File%d=%s\nTitle%d=%s\nLength%d=%d\n", i+1, entry.Location, i+1, displayTitle(entry), i+1, duration)
+		}
+		fmt.Fprintf(&buf, "NumberOfEntries=%d\nVersion=2\n", len(playlist.Entries))
+	case PlaylistFormatXSPF:
+		doc := xspfPlaylist{Xmlns: "http://xspf.org/ns/0/", Version: "1", Title: playlist.Title}
+		for _, entry := range playlist.Entries {
+			doc.Tracks = append(doc.Tracks, xspfTrack{
+				Location: xspfLocation(entry.Location),
+				Title:    entry.Title,
+				Creator:  entry.Artist,
+				Album:    entry.Album,
+				Duration: entry.Duration * 1000,
+			})
+		}
+		data, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(xml.Header)
+		buf.Write(data)
+		buf.WriteString("\n")
+	default:
+		return nil, errPlaylistFormat
+	}
+	return buf.Bytes(), nil
+}
+
+// displayTitle M3U 和 PLS 中的显示名称
+func displayTitle(entry playlistFileEntry) string {
+	if entry.Artist == "" {
+		return entry.Title
+	}
+	return entry.Artist + " - " + entry.Title
+}
+
+// xspfLocation 把文件路径转换为 XSPF 使用的 URI
+func xspfLocation(location string) string {
+	if strings.HasPrefix(location, playlistSongScheme) {
+		return location
+	}
+	if filepath.IsAbs(location) {
+		path := filepath.ToSlash(location)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path // Windows 盘符
+		}
+		return (&url.URL{Scheme: "file", Path: path}).String()
+	}
+	return (&url.URL{Path: filepath.ToSlash(location)}).String()
+}
+
+// localPlaylistPath 把播放列表中的位置转换为本地文件路径，相对路径相对于播放列表所在目录
+// 不是本地文件的位置（例如网络地址）返回 false
+func localPlaylistPath(location string, dir string) (string, bool) {
+	if strings.HasPrefix(strings.ToLower(location), "file:") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", false
+		}
+		path := u.Path
+		// file:///C:/Music/a.mp3
+		if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+		return filepath.Clean(filepath.FromSlash(path)), true
+	}
+	// 排除 http:// 等网络地址，保留 Windows 盘符
+	if scheme, _, ok := strings.Cut(location, "://"); ok && len(scheme) > 1 {
+		return "", false
+	}
+	if strings.HasPrefix(location, playlistSongScheme) {
+		return "", false
+	}
+
+	// 其他系统上导出的相对路径可能使用反斜杠
+	path := location
+	if runtime.GOOS != "windows" {
+		path = strings.ReplaceAll(path, `\`, "/")
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path), true
+}
+
+// normalizeSongText 比较歌名和歌手时忽略大小写、空白和标点
+func normalizeSongText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// matchArtist 歌手相同或互相包含时匹配，兼容 "歌手A、歌手B" 形式的多位歌手
+func matchArtist(a string, b string) bool {
+	a, b = normalizeSongText(a), normalizeSongText(b)
+	if a == "" || b == "" {
+		return true
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+// searchSongToPlaylist 把搜索结果转换为播放列表中的歌曲
+func searchSongToPlaylist(song SearchSongData) PlayerPlaylistSong {
+	return PlayerPlaylistSong{
+		Hash:       song.Hash,
+		SongName:   song.SongName,
+		Filename:   song.FileName,
+		ArtistName: song.AuthorName,
+		AlbumName:  song.AlbumName,
+		AlbumID:    song.AlbumID,
+		Duration:   song.TimeLength,
+		UnionCover: song.UnionCover,
+	}
+}
+
+// playlistResolver 把播放列表文件中的条目匹配到本地音乐或在线歌曲
+type playlistResolver struct {
+	dir      string // 播放列表文件所在目录
+	local    *LocalMusicService
+	library  []LocalMusicFile
+	byPath   map[string]int
+	byName   map[string]int // 小写的文件名
+	bySong   map[string]int // 歌名和歌手
+	search   *SearchService
+	metadata *SongMetadataStore
+}
+
+// newPlaylistResolver 加载本地音乐库的缓存
+func newPlaylistResolver(dir string) *playlistResolver {
+	r := &playlistResolver{
+		dir:    dir,
+		local:  &LocalMusicService{},
+		search: &SearchService{},
+		byPath: map[string]int{},
+		byName: map[string]int{},
+		bySong: map[string]int{},
+	}
+	if appState := GetAppState(); appState != nil {
+		r.metadata = appState.metadata
+	}
+	if library := r.local.GetCachedMusicFiles(); library.Success {
+		r.library = library.Data
+	}
+	for i, file := range r.library {
+		r.byPath[filepath.Clean(file.FilePath)] = i
+		r.byName[strings.ToLower(filepath.Base(file.FilePath))] = i
+		if file.Title != "" {
+			r.bySong[normalizeSongText(file.Title)+"\x00"+normalizeSongText(file.Artist)] = i
+		}
+	}
+	return r
+}
+
+// libraryFile 使用本地音乐库中的文件并注册映射，文件已经不存在时返回 false
+func (r *playlistResolver) libraryFile(index int) (PlayerPlaylistSong, bool) {
+	file := r.library[index]
+	if _, err := os.Stat(file.FilePath); err != nil {
+		return PlayerPlaylistSong{}, false
+	}
+	if err := r.local.generateLocalMusicMappings([]LocalMusicFile{file}); err != nil {
+		fmt.Printf("生成本地音乐映射失败: %v\n", err)
+	}
+	return file.playlistSong(), true
+}
+
+// resolve 依次按hash、本地文件、音乐库和在线搜索查找歌曲，找不到时返回原因
+func (r *playlistResolver) resolve(entry playlistFileEntry) (PlayerPlaylistSong, string) {
+	// 从 wmplayer 导出的在线歌曲
+	if hash, ok := strings.CutPrefix(entry.Location, playlistSongScheme); ok && hash != "" {
+		if r.metadata != nil {
+			if meta, ok := r.metadata.Get(hash); ok {
+				return searchSongToPlaylist(meta.searchData()), ""
+			}
+		}
+		return PlayerPlaylistSong{
+			Hash:       hash,
+			SongName:   entry.Title,
+			ArtistName: entry.Artist,
+			AlbumName:  entry.Album,
+			Duration:   entry.Duration,
+		}, ""
+	}
+
+	path, isLocal := localPlaylistPath(entry.Location, r.dir)
+	if isLocal {
+		if index, ok := r.byPath[path]; ok {
+			if song, ok := r.libraryFile(index); ok {
+				return song, ""
+			}
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			opened := r.local.OpenMusicFiles([]string{path})
+			if !opened.Success {
+				return PlayerPlaylistSong{}, "不支持的音频文件"
+			}
+			return opened.Data[0].playlistSong(), ""
+		}
+		// 文件被移动过时按文件名在音乐库中查找
+		if index, ok := r.byName[strings.ToLower(filepath.Base(path))]; ok {
+			if song, ok := r.libraryFile(index); ok {
+				return song, ""
+			}
+		}
+	}
+
+	title, artist := entry.Title, entry.Artist
+	if title == "" && isLocal {
+		artist, title = splitDisplayTitle(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	}
+	if title == "" {
+		return PlayerPlaylistSong{}, "缺少歌曲信息"
+	}
+
+	if index, ok := r.bySong[normalizeSongText(title)+"\x00"+normalizeSongText(artist)]; ok {
+		if song, ok := r.libraryFile(index); ok {
+			return song, ""
+		}
+	}
+
+	keyword := strings.TrimSpace(artist + " " + title)
+	resp := r.search.SearchSongs(keyword, 1, 10)
+	if !resp.Success {
+		return PlayerPlaylistSong{}, "搜索失败: " + resp.Message
+	}
+	for _, song := range resp.Data.Songs.List {
+		if normalizeSongText(song.SongName) != normalizeSongText(title) || !matchArtist(song.AuthorName, artist) {
+			continue
+		}
+		if entry.Duration > 0 && song.TimeLength > 0 && max(entry.Duration-song.TimeLength, song.TimeLength-entry.Duration) > playlistDurationTolerance {
+			continue
+		}
+		return searchSongToPlaylist(song), ""
+	}
+	if isLocal {
+		return PlayerPlaylistSong{}, "找不到文件，在线也没有匹配的歌曲"
+	}
+	return PlayerPlaylistSong{}, "没有找到匹配的歌曲"
+}
+
+// PlaylistFileService 播放列表文件的导入和导出服务
+type PlaylistFileService struct{}
+
+// ImportPlaylistFile 导入 M3U/M3U8、PLS 或 XSPF 文件为本地歌单，name 为空时使用文件中的标题或文件名
+// 没有找到的歌曲和重复的条目不会加入歌单，在结果中列出
+func (p *PlaylistFileService) ImportPlaylistFile(path string, name string) PlaylistFileImportResponse {
+	result, err := p.importPlaylistFile(path, name)
+	if err != nil {
+		return PlaylistFileImportResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    result,
+		}
+	}
+	message := fmt.Sprintf("已导入 %d 首歌曲", result.Matched)
+	duplicates := 0
+	for _, issue := range result.Unmatched {
+		if issue.Reason == playlistDuplicateReason {
+			duplicates++
+		}
+	}
+	if missing := len(result.Unmatched) - duplicates; missing > 0 {
+		message += fmt.Sprintf("，%d 首没有找到", missing)
+	}
+	if duplicates > 0 {
+		message += fmt.Sprintf("，%d 首重复", duplicates)
+	}
+	return PlaylistFileImportResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	}
+}
+
+// importPlaylistFile 解析播放列表文件，匹配歌曲后创建本地歌单
+func (p *PlaylistFileService) importPlaylistFile(path string, name string) (PlaylistFileImportResult, error) {
+	result := PlaylistFileImportResult{Unmatched: []PlaylistFileIssue{}}
+	if strings.TrimSpace(path) == "" {
+		return result, errPlaylistPath
+	}
+	state, err := currentAppState()
+	if err != nil {
+		return result, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("读取播放列表文件失败: %v", err)
+	}
+	result.Format, err = detectPlaylistFormat(path, data)
+	if err != nil {
+		return result, err
+	}
+	file, err := parsePlaylistFile(result.Format, data)
+	if err != nil {
+		return result, err
+	}
+	result.Total = len(file.Entries)
+	if result.Total == 0 {
+		return result, errPlaylistEmpty
+	}
+
+	resolver := newPlaylistResolver(filepath.Dir(path))
+	var songs []PlayerPlaylistSong
+	seen := make(map[string]bool)
+	for i, entry := range file.Entries {
+		song, reason := resolver.resolve(entry)
+		if reason == "" && seen[song.Hash] {
+			// 歌单中的歌曲不能重复，和创建歌单时一样只保留第一次出现的条目
+			reason = playlistDuplicateReason
+		}
+		if reason != "" {
+			result.Unmatched = append(result.Unmatched, PlaylistFileIssue{
+				Index:    i,
+				Location: entry.Location,
+				Title:    entry.Title,
+				Artist:   entry.Artist,
+				Reason:   reason,
+			})
+			continue
+		}
+		seen[song.Hash] = true
+		recordSongMetadata(song.metadata())
+		songs = append(songs, song)
+	}
+	if len(songs) == 0 {
+		return result, errPlaylistNoMatch
+	}
+
+	if strings.TrimSpace(name) == "" {
+		name = file.Title
+	}
+	if strings.TrimSpace(name) == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	result.Playlist, err = state.playlists.Create(name, songs)
+	if err != nil {
+		return result, err
+	}
+	result.Matched = len(result.Playlist.Songs)
+	return result, nil
+}
+
+// ExportPlaylistFile 把当前播放列表、本地歌单或在线歌单导出为 M3U8、PLS 或 XSPF 文件
+// 本地音乐写入文件路径，在线歌曲（包括已缓存的）写入 wmplayer:song/<hash> 并在结果中列出
+func (p *PlaylistFileService) ExportPlaylistFile(request ExportPlaylistFileRequest) PlaylistFileExportResponse {
+	result, err := p.exportPlaylistFile(request)
+	if err != nil {
+		return PlaylistFileExportResponse{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	message := fmt.Sprintf("已导出 %d 首歌曲", result.Total)
+	if len(result.Missing) > 0 {
+		message += fmt.Sprintf("，%d 首没有本地文件", len(result.Missing))
+	}
+	return PlaylistFileExportResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	}
+}
+
+// exportSongs 获取要导出的歌曲和播放列表名称
+func (p *PlaylistFileService) exportSongs(state *AppState, source string, id string) (string, []PlayerPlaylistSong, error) {
+	switch source {
+	case PlaylistSourceQueue, "":
+		data := state.queue.Snapshot()
+		return data.Name, data.Songs, nil
+	case PlaylistSourceLocal:
+		playlist, err := state.playlists.Get(id)
+		return playlist.Name, playlist.Songs, err
+	case PlaylistSourceOnline:
+		resp := (&FavoritesService{}).GetPlaylistSongs(id)
+		if !resp.Success {
+			return "", nil, errors.New(resp.Message)
+		}
+		songs := make([]PlayerPlaylistSong, len(resp.Data))
+		for i, song := range resp.Data {
+			songs[i] = PlayerPlaylistSong{
+				Hash:       song.Hash,
+				SongName:   song.SongName,
+				Filename:   song.FileName,
+				ArtistName: song.AuthorName,
+				AlbumName:  song.AlbumName,
+				AlbumID:    song.AlbumID,
+				Duration:   song.TimeLength,
+				UnionCover: song.UnionCover,
+			}
+		}
+		return "", songs, nil
+	}
+	return "", nil, errPlaylistSource
+}
+
+// songFilePath 歌曲在本机的文件路径，只有本地音乐有，没有时返回原因
+// 在线歌曲的缓存文件随时可能被清理或按音质替换，不写入播放列表文件
+func songFilePath(cacheService *CacheService, hash string) (string, string) {
+	if !strings.HasPrefix(hash, "local-") {
+		return "", "在线歌曲没有本地文件"
+	}
+	if path, ok := cacheService.localMusicPath(hash); ok {
+		return path, ""
+	}
+	return "", "本地音乐文件不存在"
+}
+
+// exportPlaylistFile 生成播放列表文件并写入磁盘
+func (p *PlaylistFileService) exportPlaylistFile(request ExportPlaylistFileRequest) (PlaylistFileExportResult, error) {
+	result := PlaylistFileExportResult{Missing: []PlaylistFileIssue{}}
+	if strings.TrimSpace(request.Path) == "" {
+		return result, errPlaylistPath
+	}
+	cacheService := GetCacheService()
+	if cacheService == nil {
+		return result, fmt.Errorf("缓存服务未初始化")
+	}
+	state, err := currentAppState()
+	if err != nil {
+		return result, err
+	}
+
+	path, err := filepath.Abs(request.Path)
+	if err != nil {
+		return result, err
+	}
+	if request.Format != "" {
+		result.Format, err = normalizePlaylistFormat(request.Format)
+	} else {
+		result.Format, err = normalizePlaylistFormat(filepath.Ext(path))
+	}
+	if err != nil {
+		return result, err
+	}
+
+	name, songs, err := p.exportSongs(state, request.Source, request.ID)
+	if err != nil {
+		return result, err
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	file := playlistFile{Title: name}
+	dir := filepath.Dir(path)
+	for i, song := range songs {
+		entry := playlistFileEntry{
+			Title:    song.SongName,
+			Artist:   song.ArtistName,
+			Album:    song.AlbumName,
+			Duration: song.Duration,
+		}
+		location, reason := songFilePath(cacheService, song.Hash)
+		if reason != "" {
+			location = playlistSongScheme + song.Hash
+			result.Missing = append(result.Missing, PlaylistFileIssue{
+				Index:    i,
+				Location: location,
+				Title:    song.SongName,
+				Artist:   song.ArtistName,
+				Reason:   reason,
+			})
+		} else if request.RelativePaths {
+			if relative, err := filepath.Rel(dir, location); err == nil {
+				location = relative
+			}
+		}
+		entry.Location = location
+		file.Entries = append(file.Entries, entry)
+	}
+
+	data, err := writePlaylistFile(result.Format, file)
+	if err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return result, fmt.Errorf("写入播放列表文件失败: %v", err)
+	}
+	result.Path = path
+	result.Total = len(songs)
+	return result, nil
+}
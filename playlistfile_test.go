@@ -0,0 +1,291 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectPlaylistFormat(t *testing.T) {
+	tests := []struct {
+		path string
+		data string
+		want string
+	}{
+		{"a.M3U", "", PlaylistFormatM3U},
+		{"a.m3u8", "", PlaylistFormatM3U},
+		{"a.pls", "", PlaylistFormatPLS},
+		{"a.xspf", "", PlaylistFormatXSPF},
+		{"a.txt", "\xef\xbb\xbf#EXTM3U\n", PlaylistFormatM3U},
+		{"a.txt", "\n[Playlist]\nFile1=a.mp3", PlaylistFormatPLS},
+		{"a.xml", `<?xml version="1.0"?><playlist version="1">`, PlaylistFormatXSPF},
+		{"a.txt", "a.mp3\n", ""},
+	}
+	for _, tt := range tests {
+		got, err := detectPlaylistFormat(tt.path, []byte(tt.data))
+		if got != tt.want || (tt.want == "") != (err == errPlaylistFormat) {
+			t.Errorf("detect(%s, %q) = %q, %v", tt.path, tt.data, got, err)
+		}
+	}
+}
+
+func TestParsePlaylistFiles(t *testing.T) {
+	m3u := "\xef\xbb\xbf#EXTM3U\r\n#PLAYLIST:夜跑\r\n" +
+		"#EXTINF:269 tvg-id=\"x\",周杰伦 - 晴天\r\n#EXTALB:叶惠美\r\nsongs/晴天.mp3\r\n" +
+		"# 注释\r\n\r\nhttp://example.com/stream\r\n" +
+		"#EXTINF:-1,七里香\r\n#EXTART:周杰伦\r\nC:\\Music\\七里香.flac\r\n"
+	file, err := parsePlaylistFile(PlaylistFormatM3U, []byte(m3u))
+	want := playlistFile{Title: "夜跑", Entries: []playlistFileEntry{
+		{Location: "songs/晴天.mp3", Title: "晴天", Artist: "周杰伦", Album: "叶惠美", Duration: 269},
+		{Location: "http://example.com/stream"},
+		{Location: `C:\Music\七里香.flac`, Title: "七里香", Artist: "周杰伦"},
+	}}
+	if err != nil || !reflect.DeepEqual(file, want) {
+		t.Errorf("m3u = %+v, %v", file, err)
+	}
+
+	// PLS 按序号排列，忽略没有文件的条目
+	pls := "[playlist]\nTitle2=七里香\nFile2=b.mp3\nLength2=299.4\nfile1=a.mp3\nTitle3=孤立的标题\nNumberOfEntries=2\n"
+	file, _ = parsePlaylistFile(PlaylistFormatPLS, []byte(pls))
+	if got := file.Entries; len(got) != 2 || got[0].Location != "a.mp3" || got[1] != (playlistFileEntry{Location: "b.mp3", Title: "七里香", Duration: 299}) {
+		t.Errorf("pls = %+v", got)
+	}
+
+	xspf := `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <title>收藏</title>
+  <trackList>
+    <track><location>my%20songs/a.mp3</location><title>晴天</title><creator>周杰伦</creator><duration>269400</duration></track>
+    <track><location>file:///music/b.mp3</location></track>
+  </trackList>
+</playlist>`
+	file, err = parsePlaylistFile(PlaylistFormatXSPF, []byte(xspf))
+	if err != nil || file.Title != "收藏" || len(file.Entries) != 2 ||
+		file.Entries[0] != (playlistFileEntry{Location: "my songs/a.mp3", Title: "晴天", Artist: "周杰伦", Duration: 269}) ||
+		file.Entries[1].Location != "file:///music/b.mp3" {
+		t.Errorf("xspf = %+v, %v", file, err)
+	}
+	if _, err := parsePlaylistFile(PlaylistFormatXSPF, []byte("<playlist>")); err == nil {
+		t.Error("broken xspf should fail")
+	}
+}
+
+func TestWritePlaylistFileRoundTrip(t *testing.T) {
+	original := playlistFile{Title: "夜跑", Entries: []playlistFileEntry{
+		{Location: "my songs/晴天.mp3", Title: "晴天", Artist: "周杰伦", Album: "叶惠美", Duration: 269},
+		{Location: playlistSongScheme + "ABC", Title: "七里香"},
+	}}
+	for _, format := range []string{PlaylistFormatM3U, PlaylistFormatPLS, PlaylistFormatXSPF} {
+		data, err := writePlaylistFile(format, original)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		parsed, err := parsePlaylistFile(format, data)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		want := original
+		if format == PlaylistFormatPLS {
+			// PLS 没有专辑和播放列表标题
+			want = playlistFile{Entries: []playlistFileEntry{original.Entries[0], original.Entries[1]}}
+			want.Entries[0].Album = ""
+		}
+		if !reflect.DeepEqual(parsed, want) {
+			t.Errorf("%s round trip = %+v\n%s", format, parsed, data)
+		}
+	}
+	if _, err := writePlaylistFile("wpl", original); err != errPlaylistFormat {
+		t.Errorf("unknown format = %v", err)
+	}
+}
+
+func TestLocalPlaylistPath(t *testing.T) {
+	dir := filepath.FromSlash("/playlists")
+	tests := []struct {
+		location string
+		want     string
+		ok       bool
+	}{
+		{"a.mp3", filepath.Join(dir, "a.mp3"), true},
+		{`sub\a.mp3`, filepath.Join(dir, "sub", "a.mp3"), true},
+		{"http://example.com/a.mp3", "", false},
+		{playlistSongScheme + "ABC", "", false},
+	}
+	if filepath.Separator == '/' {
+		tests = append(tests, struct {
+			location string
+			want     string
+			ok       bool
+		}{"file:///music/my%20songs/a.mp3", "/music/my songs/a.mp3", true})
+	}
+	for _, tt := range tests {
+		got, ok := localPlaylistPath(tt.location, dir)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("localPlaylistPath(%q) = %q, %v", tt.location, got, ok)
+		}
+	}
+}
+
+// newTestPlaylistFileService 使用模拟接口、临时缓存目录和应用数据
+func newTestPlaylistFileService(t *testing.T) (*PlaylistFileService, *CacheService, *AppState) {
+	t.Helper()
+	newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+	return &PlaylistFileService{}, c, newTestAppState(t, c)
+}
+
+func TestImportPlaylistFile(t *testing.T) {
+	service, c, _ := newTestPlaylistFileService(t)
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "songs", "晴天.mp3"), "not really audio")
+
+	// 音乐库中的文件被移动过，播放列表里还是旧路径
+	moved := writeTestFile(t, filepath.Join(dir, "library", "七里香.mp3"), "not really audio")
+	if err := (&LocalMusicService{}).cacheMusicFiles([]LocalMusicFile{
+		{FilePath: moved, Hash: "lib1", Filename: "七里香.mp3", Title: "七里香", Artist: "周杰伦"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	playlist := writeTestFile(t, filepath.Join(dir, "list.m3u8"), strings.Join([]string{
+		"#EXTM3U",
+		"#PLAYLIST:导入的歌单",
+		"songs/晴天.mp3",
+		"#EXTINF:299,周杰伦 - 七里香",
+		"/old/place/七里香.mp3",
+		"#EXTINF:270,周杰伦 - 晴天",
+		"http://example.com/stream",
+		"#EXTINF:100,周杰伦 - 晴天",
+		"http://example.com/short",
+		"wmplayer:song/ABCDEF",
+		"#EXTINF:200,不存在 - 不存在的歌",
+		"missing.mp3",
+	}, "\n"))
+
+	resp := service.ImportPlaylistFile(playlist, "")
+	if !resp.Success {
+		t.Fatalf("import = %+v", resp)
+	}
+	result := resp.Data
+	if result.Format != PlaylistFormatM3U || result.Total != 6 || result.Matched != 4 || result.Playlist.Name != "导入的歌单" {
+		t.Errorf("result = %+v", result)
+	}
+	songs := result.Playlist.Songs
+	if len(songs) != 4 || !strings.HasPrefix(songs[0].Hash, "local-") || songs[1].Hash != "local-lib1" ||
+		songs[2].Hash != "A1B2C3D4E5F60718293A4B5C6D7E8F90" || songs[3].Hash != "ABCDEF" {
+		t.Fatalf("songs = %v", songHashes(songs))
+	}
+	// 匹配到的本地文件可以直接按hash播放
+	if got, _ := c.localMusicPath(songs[0].Hash); got != filepath.Join(dir, "songs", "晴天.mp3") {
+		t.Errorf("opened file mapping = %q", got)
+	}
+	if got, _ := c.localMusicPath("local-lib1"); got != moved {
+		t.Errorf("library mapping = %q", got)
+	}
+
+	// 时长不符的在线歌曲和找不到的文件都会被列出
+	if len(result.Unmatched) != 2 || result.Unmatched[0].Index != 3 || result.Unmatched[1].Index != 5 ||
+		result.Unmatched[1].Title != "不存在的歌" || result.Unmatched[1].Reason == "" {
+		t.Errorf("unmatched = %+v", result.Unmatched)
+	}
+	if !strings.Contains(resp.Message, "2 首没有找到") {
+		t.Errorf("message = %s", resp.Message)
+	}
+
+	empty := writeTestFile(t, filepath.Join(dir, "empty.pls"), "[playlist]\nNumberOfEntries=0\n")
+	if resp := service.ImportPlaylistFile(empty, ""); resp.Success || resp.Message != errPlaylistEmpty.Error() {
+		t.Errorf("empty = %+v", resp)
+	}
+	none := writeTestFile(t, filepath.Join(dir, "none.m3u"), "gone.mp3\n")
+	if resp := service.ImportPlaylistFile(none, ""); resp.Success || len(resp.Data.Unmatched) != 1 {
+		t.Errorf("nothing matched = %+v", resp)
+	}
+
+	// 重复的条目只导入一次，其余的作为重复列出
+	dup := writeTestFile(t, filepath.Join(dir, "dup.m3u8"), "songs/晴天.mp3\nwmplayer:song/ABCDEF\nsongs/晴天.mp3\n")
+	resp = service.ImportPlaylistFile(dup, "重复")
+	if !resp.Success || resp.Data.Matched != 2 || len(resp.Data.Playlist.Songs) != 2 {
+		t.Errorf("duplicates = %+v", resp)
+	}
+	if len(resp.Data.Unmatched) != 1 || resp.Data.Unmatched[0].Index != 2 || resp.Data.Unmatched[0].Reason != playlistDuplicateReason {
+		t.Errorf("duplicate issues = %+v", resp.Data.Unmatched)
+	}
+	if resp.Message != "已导入 2 首歌曲，1 首重复" {
+		t.Errorf("duplicate message = %s", resp.Message)
+	}
+	if resp := service.ImportPlaylistFile(filepath.Join(dir, "missing.xspf"), ""); resp.Success {
+		t.Errorf("missing file = %+v", resp)
+	}
+}
+
+func TestExportPlaylistFile(t *testing.T) {
+	service, c, state := newTestPlaylistFileService(t)
+	dir := t.TempDir()
+	localFile := writeTestFile(t, filepath.Join(dir, "music", "晴天.mp3"), "not really audio")
+	c.RegisterLocalMusic("local-1", localFile)
+	cachedFile := c.getCacheKey("CACHED", AudioQualityHigh) + ".mp3"
+	writeCacheFile(t, c.mp3Dir, cachedFile, 10)
+	c.audioCache.Record(cachedFile, "CACHED", AudioQualityHigh)
+	recordSongMetadata(SongMetadata{Hash: "REMOTE", SongName: "七里香", AuthorName: "周杰伦", TimeLength: 299})
+
+	state.queue.Replace("夜跑", []PlayerPlaylistSong{
+		{Hash: "local-1", SongName: "晴天", ArtistName: "周杰伦", Duration: 269},
+		{Hash: "CACHED", SongName: "稻香", ArtistName: "周杰伦"},
+		{Hash: "REMOTE", SongName: "七里香", ArtistName: "周杰伦", Duration: 299},
+	}, 0)
+
+	path := filepath.Join(dir, "夜跑.m3u8")
+	resp := service.ExportPlaylistFile(ExportPlaylistFileRequest{Source: PlaylistSourceQueue, Path: path, RelativePaths: true})
+	if !resp.Success || resp.Data.Total != 3 || resp.Data.Format != PlaylistFormatM3U {
+		t.Fatalf("export = %+v", resp)
+	}
+	// 已缓存的在线歌曲也按hash写入，缓存文件随时可能被清理
+	if missing := resp.Data.Missing; len(missing) != 2 || missing[0].Index != 1 || missing[0].Location != playlistSongScheme+"CACHED" ||
+		missing[1].Index != 2 || missing[1].Location != playlistSongScheme+"REMOTE" {
+		t.Errorf("missing = %+v", missing)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), cachedFile) {
+		t.Errorf("m3u8 should not reference cache files:\n%s", data)
+	}
+	for _, line := range []string{"#PLAYLIST:夜跑", "#EXTINF:269,周杰伦 - 晴天", filepath.Join("music", "晴天.mp3"), playlistSongScheme + "CACHED"} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("m3u8 missing %q:\n%s", line, data)
+		}
+	}
+
+	// 导出的文件可以再导入，没有本地文件的在线歌曲按hash还原
+	xspf := filepath.Join(dir, "out", "夜跑.xml")
+	os.MkdirAll(filepath.Dir(xspf), 0755)
+	resp = service.ExportPlaylistFile(ExportPlaylistFileRequest{Path: xspf, Format: "xspf"})
+	if !resp.Success {
+		t.Fatalf("export xspf = %+v", resp)
+	}
+	imported := service.ImportPlaylistFile(xspf, "")
+	if !imported.Success || len(imported.Data.Unmatched) != 0 || imported.Data.Playlist.Name != "夜跑" {
+		t.Fatalf("import exported = %+v", imported)
+	}
+	if songs := imported.Data.Playlist.Songs; len(songs) != 3 || songs[2].Hash != "REMOTE" || songs[2].SongName != "七里香" {
+		t.Errorf("reimported = %+v", songs)
+	}
+
+	playlist, _ := state.playlists.Create("歌单", []PlayerPlaylistSong{{Hash: "local-1", SongName: "晴天"}})
+	resp = service.ExportPlaylistFile(ExportPlaylistFileRequest{Source: PlaylistSourceLocal, ID: playlist.ID, Path: filepath.Join(dir, "歌单.pls")})
+	data, _ = os.ReadFile(filepath.Join(dir, "歌单.pls"))
+	if !resp.Success || !strings.Contains(string(data), "File1="+localFile+"\n") {
+		t.Errorf("export local = %+v\n%s", resp, data)
+	}
+
+	if resp := service.ExportPlaylistFile(ExportPlaylistFileRequest{Source: PlaylistSourceLocal, ID: "missing", Path: path}); resp.Success {
+		t.Errorf("missing playlist = %+v", resp)
+	}
+	if resp := service.ExportPlaylistFile(ExportPlaylistFileRequest{Source: "radio", Path: path}); resp.Success {
+		t.Errorf("unknown source = %+v", resp)
+	}
+	if resp := service.ExportPlaylistFile(ExportPlaylistFileRequest{Path: filepath.Join(dir, "a.wpl")}); resp.Message != errPlaylistFormat.Error() {
+		t.Errorf("unknown format = %+v", resp)
+	}
+}
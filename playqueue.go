@@ -0,0 +1,881 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 循环模式
+const (
+	RepeatOff = "off" // 播放完列表后停止
+	RepeatOne = "one" // 单曲循环，只影响播放结束后的自动续播，手动切歌仍按列表前进
+	RepeatAll = "all" // 列表循环
+)
+
+// defaultQueueName 临时播放列表的名称
+const defaultQueueName = "播放列表"
+
+// playQueueSaveDelay 播放队列变更后延迟写盘的时间，连续切歌只写一次文件
+const playQueueSaveDelay = time.Second
+
+// maxShuffleHistory 随机播放保留的已播放记录数，超过后丢弃最早的记录
+const maxShuffleHistory = 1000
+
+// maxQueueUndo 可以撤销的播放列表修改次数
+const maxQueueUndo = 20
+
+// 播放列表的排序方式
+const (
+	QueueSortName     = "name"     // 歌曲名称
+	QueueSortArtist   = "artist"   // 歌手
+	QueueSortAlbum    = "album"    // 专辑
+	QueueSortDuration = "duration" // 时长
+	QueueSortReverse  = "reverse"  // 倒序
+)
+
+// 播放队列的错误
+var (
+	errQueueEmpty     = errors.New("播放列表为空")
+	errQueueEnd       = errors.New("已播放完所有歌曲")
+	errQueueStart     = errors.New("已是第一首歌曲")
+	errQueueIndex     = errors.New("索引超出范围")
+	errQueueDuplicate = errors.New("歌曲已存在于播放列表中")
+	errQueueNotFound  = errors.New("歌曲不存在于播放列表中")
+	errQueueNoUndo    = errors.New("没有可以撤销的操作")
+	errQueueNoChange  = errors.New("播放列表没有变化")
+)
+
+// PlayQueue 播放队列引擎，在内存中维护当前播放列表、播放位置和播放模式，变更后异步写盘
+//
+// 随机播放时 order 记录播放顺序（songs 的下标），position 之前是实际播放过的歌曲，
+// 之后是本轮还没播放的歌曲，因此“上一首”会回到真正的上一首；本轮播完后在末尾追加新一轮的顺序，
+// 新一轮的第一首不会与刚播放的歌曲相同
+//
+// 每首歌曲有唯一的 EntryID，同一首歌多次出现时可以分别操作；修改歌曲列表前保存状态，用于撤销
+type PlayQueue struct {
+	file      string
+	saveTimer *time.Timer
+	saveMutex sync.Mutex // 串行写盘，先取得的快照先写入
+	mutex     sync.Mutex
+
+	playlistID string
+	name       string
+	songs      []PlayerPlaylistSong
+	current    int
+	shuffle    bool
+	repeat     string
+	order      []int
+	position   int
+	rng        *rand.Rand
+	updated    time.Time
+	undo       []queueState
+}
+
+// queueState 修改歌曲列表之前的状态，用于撤销
+type queueState struct {
+	name       string
+	playlistID string
+	songs      []PlayerPlaylistSong
+	current    int
+	order      []int
+	position   int
+}
+
+// NewPlayQueue 创建播放队列并加载已保存的播放列表
+func NewPlayQueue(file string) *PlayQueue {
+	q := &PlayQueue{
+		file:     file,
+		name:     defaultQueueName,
+		current:  -1,
+		repeat:   RepeatOff,
+		position: -1,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		updated:  time.Now(),
+	}
+	q.load()
+	return q
+}
+
+// parsePlayMode 把旧的 play_mode 转换为随机和循环模式，无法识别时返回 false
+func parsePlayMode(mode string) (shuffle bool, repeat string, ok bool) {
+	switch mode {
+	case "normal", "list":
+		return false, RepeatOff, true
+	case "shuffle":
+		return true, RepeatAll, true
+	case "repeat_one":
+		return false, RepeatOne, true
+	case "repeat_all":
+		return false, RepeatAll, true
+	}
+	return false, "", false
+}
+
+// validRepeatMode 检查循环模式是否有效
+func validRepeatMode(repeat string) bool {
+	return repeat == RepeatOff || repeat == RepeatOne || repeat == RepeatAll
+}
+
+// load 读取播放列表文件，兼容旧版本只有 play_mode 或只记录剩余随机顺序的数据
+func (q *PlayQueue) load() {
+	data, err := os.ReadFile(q.file)
+	if err != nil {
+		return
+	}
+	var saved PlayerPlaylistData
+	if err := json.Unmarshal(data, &saved); err != nil {
+		fmt.Printf("⚠️ 解析播放列表数据失败，使用空列表: %v\n", err)
+		return
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.playlistID = saved.PlaylistID
+	if saved.Name != "" {
+		q.name = saved.Name
+	}
+	if saved.Songs != nil {
+		q.songs = saved.Songs
+	}
+	assignEntryIDs(q.songs)
+	q.current = saved.CurrentIndex
+	if q.current < -1 || q.current >= len(q.songs) {
+		q.current = -1
+	}
+	if len(q.songs) > 0 && q.current == -1 {
+		q.current = 0
+	}
+
+	// 界面按钮显示的是 shuffle_mode 和 repeat_mode，以它们为准
+	if validRepeatMode(saved.RepeatMode) {
+		q.shuffle = saved.ShuffleMode
+		q.repeat = saved.RepeatMode
+	} else if shuffle, repeat, ok := parsePlayMode(saved.PlayMode); ok {
+		q.shuffle = shuffle
+		q.repeat = repeat
+	}
+
+	if q.shuffle {
+		q.order = saved.ShuffleOrder
+		q.position = saved.ShufflePosition
+		if !q.orderValidLocked() {
+			q.newShuffleRoundLocked()
+		}
+	}
+}
+
+// orderValidLocked 检查随机顺序是否与当前歌曲一致
+func (q *PlayQueue) orderValidLocked() bool {
+	for _, index := range q.order {
+		if index < 0 || index >= len(q.songs) {
+			return false
+		}
+	}
+	if q.current == -1 {
+		return q.position == -1
+	}
+	return q.position >= 0 && q.position < len(q.order) && q.order[q.position] == q.current
+}
+
+// newShuffleRoundLocked 从当前歌曲开始生成新的随机顺序，丢弃之前的记录
+func (q *PlayQueue) newShuffleRoundLocked() {
+	q.order = make([]int, 0, len(q.songs))
+	q.position = -1
+	if q.current >= 0 {
+		q.order = append(q.order, q.current)
+		q.position = 0
+	}
+	start := len(q.order)
+	for i := range q.songs {
+		if i != q.current {
+			q.order = append(q.order, i)
+		}
+	}
+	q.shuffleLocked(q.order[start:])
+}
+
+// shuffleLocked 随机打乱下标
+func (q *PlayQueue) shuffleLocked(indices []int) {
+	q.rng.Shuffle(len(indices), func(i, j int) {
+		indices[i], indices[j] = indices[j], indices[i]
+	})
+}
+
+// appendShuffleRoundLocked 本轮播完后追加新一轮的随机顺序，第一首不与刚播放的歌曲相同
+func (q *PlayQueue) appendShuffleRoundLocked() {
+	round := make([]int, len(q.songs))
+	for i := range round {
+		round[i] = i
+	}
+	q.shuffleLocked(round)
+	if len(round) > 1 && round[0] == q.current {
+		swap := 1 + q.rng.Intn(len(round)-1)
+		round[0], round[swap] = round[swap], round[0]
+	}
+	q.order = append(q.order, round...)
+
+	// 丢弃过早的播放记录
+	if q.position > maxShuffleHistory {
+		drop := q.position - maxShuffleHistory
+		q.order = q.order[drop:]
+		q.position -= drop
+	}
+}
+
+// snapshotLocked 生成返回给前端和写入文件的播放列表数据
+func (q *PlayQueue) snapshotLocked() PlayerPlaylistData {
+	playMode := "normal"
+	switch {
+	case q.shuffle:
+		playMode = "shuffle"
+	case q.repeat == RepeatOne:
+		playMode = "repeat_one"
+	case q.repeat == RepeatAll:
+		playMode = "repeat_all"
+	}
+
+	order := []int{}
+	position := 0
+	if q.shuffle {
+		order = slices.Clone(q.order)
+		position = q.position
+	}
+
+	return PlayerPlaylistData{
+		Songs:           slices.Clone(q.songs),
+		CurrentIndex:    q.current,
+		PlayMode:        playMode,
+		ShuffleMode:     q.shuffle,
+		RepeatMode:      q.repeat,
+		Name:            q.name,
+		UpdateTime:      q.updated,
+		ShuffleOrder:    order,
+		ShufflePosition: position,
+		HasNext:         q.hasNextLocked(),
+		PlaylistID:      q.playlistID,
+		UndoCount:       len(q.undo),
+	}
+}
+
+// hasNextLocked 是否还有下一首可以播放
+func (q *PlayQueue) hasNextLocked() bool {
+	if len(q.songs) == 0 {
+		return false
+	}
+	if q.repeat != RepeatOff {
+		return true
+	}
+	if q.shuffle {
+		return q.position < len(q.order)-1
+	}
+	return q.current < len(q.songs)-1
+}
+
+// changedLocked 记录变更并安排写盘，返回变更后的数据
+func (q *PlayQueue) changedLocked() PlayerPlaylistData {
+	q.updated = time.Now()
+	q.scheduleSaveLocked()
+	return q.snapshotLocked()
+}
+
+// Snapshot 获取当前播放列表
+func (q *PlayQueue) Snapshot() PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.snapshotLocked()
+}
+
+// Replace 替换整个播放列表，从 current 开始播放
+func (q *PlayQueue) Replace(name string, songs []PlayerPlaylistSong, current int) PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pushUndoLocked()
+	q.name = name
+	q.songs = slices.Clone(songs)
+	assignEntryIDs(q.songs)
+	q.current = current
+	if q.current < -1 || q.current >= len(q.songs) {
+		q.current = -1
+	}
+	if len(q.songs) > 0 && q.current == -1 {
+		q.current = 0
+	}
+	if q.shuffle {
+		q.newShuffleRoundLocked()
+	}
+	return q.changedLocked()
+}
+
+// Append 把歌曲追加到末尾，current 为追加的歌曲中要播放的位置，-1 时保持当前歌曲
+func (q *PlayQueue) Append(name string, songs []PlayerPlaylistSong, current int) PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pushUndoLocked()
+	start := len(q.songs)
+	q.name = name
+	for _, song := range songs {
+		q.insertLocked(len(q.songs), song)
+	}
+	if current >= 0 && current < len(songs) {
+		q.jumpLocked(start + current)
+	} else if q.current == -1 && len(q.songs) > 0 {
+		q.jumpLocked(0)
+	}
+	return q.changedLocked()
+}
+
+// Add 添加一首歌曲，playNext 为 true 时作为下一首播放（随机播放时也是），否则添加到末尾
+// 下一首播放的歌曲已在列表中时移动到当前歌曲后面
+func (q *PlayQueue) Add(song PlayerPlaylistSong, playNext bool) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	existing := slices.IndexFunc(q.songs, func(s PlayerPlaylistSong) bool { return s.Hash == song.Hash })
+	if existing >= 0 && (!playNext || existing == q.current) {
+		return PlayerPlaylistData{}, errQueueDuplicate
+	}
+	q.pushUndoLocked()
+	if existing >= 0 {
+		song.EntryID = q.songs[existing].EntryID
+		q.removeLocked(existing)
+	}
+
+	if playNext && q.current >= 0 {
+		q.insertLocked(q.current+1, song)
+		if q.shuffle {
+			q.queueNextLocked(q.current + 1)
+		}
+	} else {
+		q.insertLocked(len(q.songs), song)
+	}
+	if q.current == -1 {
+		q.jumpLocked(0)
+	}
+	return q.changedLocked(), nil
+}
+
+// insertLocked 在 index 处插入歌曲，随机播放时新歌曲随机放入本轮剩余的顺序中
+func (q *PlayQueue) insertLocked(index int, song PlayerPlaylistSong) {
+	if song.EntryID == "" || q.entryIndexLocked(song.EntryID) >= 0 {
+		song.EntryID = newRandomID()
+	}
+	q.songs = slices.Insert(q.songs, index, song)
+	if q.current >= index {
+		q.current++
+	}
+	if !q.shuffle {
+		return
+	}
+	for i, songIndex := range q.order {
+		if songIndex >= index {
+			q.order[i]++
+		}
+	}
+	at := q.position + 1 + q.rng.Intn(len(q.order)-q.position)
+	q.order = slices.Insert(q.order, at, index)
+}
+
+// Remove 移除 EntryID 对应的歌曲，同一首歌多次出现时只移除这一首
+func (q *PlayQueue) Remove(entryID string) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	index := q.entryIndexLocked(entryID)
+	if index < 0 {
+		return PlayerPlaylistData{}, errQueueNotFound
+	}
+	q.pushUndoLocked()
+	q.removeLocked(index)
+	return q.changedLocked(), nil
+}
+
+// removeLocked 移除 index 处的歌曲，移除的是当前歌曲时由下一首接替
+func (q *PlayQueue) removeLocked(index int) {
+	keep := make([]int, 0, len(q.songs)-1)
+	for i := range q.songs {
+		if i != index {
+			keep = append(keep, i)
+		}
+	}
+	q.rebuildLocked(keep)
+}
+
+// rebuildLocked 按 keep 中的原下标重新排列歌曲，不在 keep 中的歌曲被移除
+// 当前歌曲和随机顺序跟着歌曲移动；当前歌曲被移除时由下一首接替，随机播放时是播放顺序中的下一首
+func (q *PlayQueue) rebuildLocked(keep []int) {
+	newIndex := make([]int, len(q.songs))
+	for i := range newIndex {
+		newIndex[i] = -1
+	}
+	songs := make([]PlayerPlaylistSong, 0, len(keep))
+	for i, old := range keep {
+		newIndex[old] = i
+		songs = append(songs, q.songs[old])
+	}
+	oldCurrent := q.current
+	q.songs = songs
+
+	if q.shuffle {
+		// 当前位置之前保留下来的记录数就是新的位置，移除的是当前歌曲时它指向本轮的下一首
+		order := make([]int, 0, len(q.order))
+		position := 0
+		for i, old := range q.order {
+			if newIndex[old] < 0 {
+				continue
+			}
+			if i < q.position {
+				position++
+			}
+			order = append(order, newIndex[old])
+		}
+		q.order = order
+		switch {
+		case q.current == -1:
+			return
+		case len(q.order) == 0:
+			q.position, q.current = -1, -1
+		case position >= len(q.order):
+			q.position = len(q.order) - 1
+			q.current = q.order[q.position]
+		default:
+			q.position = position
+			q.current = q.order[position]
+		}
+		return
+	}
+
+	if oldCurrent < 0 {
+		return
+	}
+	if newIndex[oldCurrent] >= 0 {
+		q.current = newIndex[oldCurrent]
+		return
+	}
+	// 当前歌曲被移除，由原来在它之后的第一首保留下来的歌曲接替，没有时播放最后一首
+	q.current = len(q.songs) - 1
+	for old := oldCurrent + 1; old < len(newIndex); old++ {
+		if newIndex[old] >= 0 {
+			q.current = newIndex[old]
+			break
+		}
+	}
+}
+
+// Jump 切换到 index 处的歌曲，随机播放时这首歌记入播放记录，之后继续本轮的顺序
+func (q *PlayQueue) Jump(index int) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if index < -1 || index >= len(q.songs) {
+		return PlayerPlaylistData{}, errQueueIndex
+	}
+	q.jumpLocked(index)
+	return q.changedLocked(), nil
+}
+
+// jumpLocked 切换当前歌曲
+func (q *PlayQueue) jumpLocked(index int) {
+	if index == q.current {
+		return
+	}
+	q.current = index
+	if !q.shuffle {
+		return
+	}
+	if index == -1 {
+		q.newShuffleRoundLocked()
+		return
+	}
+	q.queueNextLocked(index)
+	q.position++
+}
+
+// queueNextLocked 从本轮剩余的随机顺序中取出这首歌，放到当前位置之后
+func (q *PlayQueue) queueNextLocked(index int) {
+	rest := slices.DeleteFunc(slices.Clone(q.order[q.position+1:]), func(i int) bool { return i == index })
+	q.order = append(append(q.order[:q.position+1], index), rest...)
+}
+
+// PlaylistID 正在播放的本地歌单ID，为空时是临时播放列表
+func (q *PlayQueue) PlaylistID() string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.playlistID
+}
+
+// Restore 切换到另一个播放列表并恢复它的播放位置，播放模式保持不变
+// 随机播放时保存的顺序仍然有效就继续使用，否则从当前歌曲开始生成新的顺序
+func (q *PlayQueue) Restore(playlist LocalPlaylist) PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.playlistID = playlist.ID
+	q.name = playlist.Name
+	q.songs = slices.Clone(playlist.Songs)
+	assignEntryIDs(q.songs)
+	// 撤销记录属于之前的播放列表
+	q.undo = nil
+	q.current = playlist.CurrentIndex
+	if q.current < -1 || q.current >= len(q.songs) {
+		q.current = -1
+	}
+	if len(q.songs) > 0 && q.current == -1 {
+		q.current = 0
+	}
+	q.order = nil
+	q.position = -1
+	if q.shuffle {
+		q.order = slices.Clone(playlist.ShuffleOrder)
+		q.position = playlist.ShufflePosition
+		if len(q.order) == 0 || !q.orderValidLocked() {
+			q.newShuffleRoundLocked()
+		}
+	}
+	return q.changedLocked()
+}
+
+// Rename 修改播放列表名称
+func (q *PlayQueue) Rename(name string) PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.name = name
+	return q.changedLocked()
+}
+
+// Move 把 EntryID 对应的歌曲移动到 to，当前歌曲和随机顺序跟着歌曲移动
+// 按 EntryID 查找歌曲，播放列表同时被其他地方修改时不会移动错歌曲
+func (q *PlayQueue) Move(entryID string, to int) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	from := q.entryIndexLocked(entryID)
+	if from < 0 {
+		return PlayerPlaylistData{}, errQueueNotFound
+	}
+	if to < 0 || to >= len(q.songs) {
+		return PlayerPlaylistData{}, errQueueIndex
+	}
+	if from == to {
+		return q.snapshotLocked(), nil
+	}
+	q.pushUndoLocked()
+	keep := make([]int, 0, len(q.songs))
+	for i := range q.songs {
+		if i != from {
+			keep = append(keep, i)
+		}
+	}
+	q.rebuildLocked(slices.Insert(keep, to, from))
+	return q.changedLocked(), nil
+}
+
+// RemoveEntries 移除多个 EntryID 对应的歌曲，重复的ID只算一次
+// 有歌曲已经不在播放列表中时不做任何修改，避免按过期的选择移除
+func (q *PlayQueue) RemoveEntries(entryIDs []string) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(entryIDs) == 0 {
+		return PlayerPlaylistData{}, errQueueNoChange
+	}
+	remove := make(map[int]bool, len(entryIDs))
+	for _, entryID := range entryIDs {
+		index := q.entryIndexLocked(entryID)
+		if index < 0 {
+			return PlayerPlaylistData{}, errQueueNotFound
+		}
+		remove[index] = true
+	}
+
+	q.pushUndoLocked()
+	keep := make([]int, 0, len(q.songs))
+	for i := range q.songs {
+		if !remove[i] {
+			keep = append(keep, i)
+		}
+	}
+	q.rebuildLocked(keep)
+	return q.changedLocked(), nil
+}
+
+// Deduplicate 移除 hash 重复的歌曲，保留第一次出现的位置；正在播放的是重复的歌曲时保留正在播放的这首
+// 返回移除的歌曲数量
+func (q *PlayQueue) Deduplicate() (PlayerPlaylistData, int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// 每个hash保留的下标
+	kept := make(map[string]int, len(q.songs))
+	for i, song := range q.songs {
+		if _, ok := kept[song.Hash]; !ok || i == q.current {
+			kept[song.Hash] = i
+		}
+	}
+	keep := make([]int, 0, len(kept))
+	for i, song := range q.songs {
+		if kept[song.Hash] == i {
+			keep = append(keep, i)
+		}
+	}
+	removed := len(q.songs) - len(keep)
+	if removed == 0 {
+		return PlayerPlaylistData{}, 0, errQueueNoChange
+	}
+
+	q.pushUndoLocked()
+	q.rebuildLocked(keep)
+	return q.changedLocked(), removed, nil
+}
+
+// Sort 按指定方式排序，相同的歌曲保持原来的先后顺序，当前歌曲跟着歌曲移动
+func (q *PlayQueue) Sort(by string) (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	keep := make([]int, len(q.songs))
+	for i := range keep {
+		keep[i] = i
+	}
+	compareText := func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	switch by {
+	case QueueSortName:
+		slices.SortStableFunc(keep, func(a, b int) int { return compareText(q.songs[a].SongName, q.songs[b].SongName) })
+	case QueueSortArtist:
+		slices.SortStableFunc(keep, func(a, b int) int { return compareText(q.songs[a].ArtistName, q.songs[b].ArtistName) })
+	case QueueSortAlbum:
+		slices.SortStableFunc(keep, func(a, b int) int { return compareText(q.songs[a].AlbumName, q.songs[b].AlbumName) })
+	case QueueSortDuration:
+		slices.SortStableFunc(keep, func(a, b int) int { return q.songs[a].Duration - q.songs[b].Duration })
+	case QueueSortReverse:
+		slices.Reverse(keep)
+	default:
+		return PlayerPlaylistData{}, fmt.Errorf("无效的排序方式: %s", by)
+	}
+	if slices.IsSorted(keep) {
+		return PlayerPlaylistData{}, errQueueNoChange
+	}
+
+	q.pushUndoLocked()
+	q.rebuildLocked(keep)
+	return q.changedLocked(), nil
+}
+
+// pushUndoLocked 在修改歌曲列表之前保存当前状态，只保留最近 maxQueueUndo 次
+func (q *PlayQueue) pushUndoLocked() {
+	q.undo = append(q.undo, queueState{
+		name:       q.name,
+		playlistID: q.playlistID,
+		songs:      slices.Clone(q.songs),
+		current:    q.current,
+		order:      slices.Clone(q.order),
+		position:   q.position,
+	})
+	if len(q.undo) > maxQueueUndo {
+		q.undo = slices.Delete(q.undo, 0, len(q.undo)-maxQueueUndo)
+	}
+}
+
+// Undo 撤销最近一次歌曲列表的修改，正在播放的歌曲还在恢复后的列表中时继续作为当前歌曲
+func (q *PlayQueue) Undo() (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.undo) == 0 {
+		return PlayerPlaylistData{}, errQueueNoUndo
+	}
+	state := q.undo[len(q.undo)-1]
+	q.undo = q.undo[:len(q.undo)-1]
+
+	playing := ""
+	if q.current >= 0 {
+		playing = q.songs[q.current].EntryID
+	}
+	q.name = state.name
+	q.playlistID = state.playlistID
+	q.songs = state.songs
+	q.current = state.current
+	q.order = state.order
+	q.position = state.position
+
+	// 保存状态之后可能切换过播放模式
+	if !q.shuffle {
+		q.order = nil
+		q.position = -1
+	} else if len(q.order) == 0 || !q.orderValidLocked() {
+		q.newShuffleRoundLocked()
+	}
+	if index := q.entryIndexLocked(playing); index >= 0 {
+		q.jumpLocked(index)
+	}
+	return q.changedLocked(), nil
+}
+
+// entryIndexLocked 查找 EntryID 对应的下标，找不到时返回 -1
+func (q *PlayQueue) entryIndexLocked(entryID string) int {
+	if entryID == "" {
+		return -1
+	}
+	return slices.IndexFunc(q.songs, func(s PlayerPlaylistSong) bool { return s.EntryID == entryID })
+}
+
+// assignEntryIDs 为没有 EntryID 或 EntryID 重复的歌曲生成新的ID
+func assignEntryIDs(songs []PlayerPlaylistSong) {
+	seen := make(map[string]bool, len(songs))
+	for i := range songs {
+		if songs[i].EntryID == "" || seen[songs[i].EntryID] {
+			songs[i].EntryID = newRandomID()
+		}
+		seen[songs[i].EntryID] = true
+	}
+}
+
+// SetMode 设置随机和循环模式，开启随机播放时从当前歌曲开始生成新的顺序
+func (q *PlayQueue) SetMode(shuffle bool, repeat string) (PlayerPlaylistData, error) {
+	if !validRepeatMode(repeat) {
+		return PlayerPlaylistData{}, fmt.Errorf("无效的循环模式: %s", repeat)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if shuffle && !q.shuffle {
+		q.shuffle = true
+		q.newShuffleRoundLocked()
+	} else if !shuffle {
+		q.shuffle = false
+		q.order = nil
+		q.position = -1
+	}
+	q.repeat = repeat
+	return q.changedLocked(), nil
+}
+
+// Next 切换到下一首
+func (q *PlayQueue) Next() (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.songs) == 0 {
+		return PlayerPlaylistData{}, errQueueEmpty
+	}
+
+	if q.shuffle {
+		if q.position >= len(q.order)-1 {
+			if q.repeat == RepeatOff {
+				return PlayerPlaylistData{}, errQueueEnd
+			}
+			q.appendShuffleRoundLocked()
+		}
+		q.position++
+		q.current = q.order[q.position]
+		return q.changedLocked(), nil
+	}
+
+	next := q.current + 1
+	if next >= len(q.songs) {
+		if q.repeat == RepeatOff {
+			return PlayerPlaylistData{}, errQueueEnd
+		}
+		next = 0
+	}
+	q.current = next
+	return q.changedLocked(), nil
+}
+
+// Previous 切换到上一首，随机播放时回到实际播放过的上一首
+func (q *PlayQueue) Previous() (PlayerPlaylistData, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.songs) == 0 {
+		return PlayerPlaylistData{}, errQueueEmpty
+	}
+
+	if q.shuffle {
+		if q.position <= 0 {
+			return PlayerPlaylistData{}, errQueueStart
+		}
+		q.position--
+		q.current = q.order[q.position]
+		return q.changedLocked(), nil
+	}
+
+	prev := q.current - 1
+	if prev < 0 {
+		if q.repeat == RepeatOff {
+			return PlayerPlaylistData{}, errQueueStart
+		}
+		prev = len(q.songs) - 1
+	}
+	q.current = prev
+	return q.changedLocked(), nil
+}
+
+// Clear 清空播放列表，保留播放模式
+func (q *PlayQueue) Clear() PlayerPlaylistData {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pushUndoLocked()
+	q.name = defaultQueueName
+	q.songs = nil
+	q.current = -1
+	q.order = nil
+	q.position = -1
+	return q.changedLocked()
+}
+
+// scheduleSaveLocked 延迟保存播放列表，调用方需持有锁
+func (q *PlayQueue) scheduleSaveLocked() {
+	if q.saveTimer != nil {
+		return
+	}
+	q.saveTimer = time.AfterFunc(playQueueSaveDelay, func() {
+		if err := q.Flush(); err != nil {
+			fmt.Printf("⚠️ 保存播放列表失败: %v\n", err)
+		}
+	})
+}
+
+// Flush 立即将播放列表写入文件
+func (q *PlayQueue) Flush() error {
+	// 定时保存和退出时的保存不会交错写入临时文件，旧的快照也不会覆盖新的
+	q.saveMutex.Lock()
+	defer q.saveMutex.Unlock()
+
+	q.mutex.Lock()
+	if q.saveTimer != nil {
+		q.saveTimer.Stop()
+		q.saveTimer = nil
+	}
+	snapshot := q.snapshotLocked()
+	q.mutex.Unlock()
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化播放列表数据失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.file), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	tempFile := q.file + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入播放列表文件失败: %v", err)
+	}
+	return os.Rename(tempFile, q.file)
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// testQueueSongs 生成 n 首测试歌曲，hash 为 s0、s1……
+func testQueueSongs(n int) []PlayerPlaylistSong {
+	songs := make([]PlayerPlaylistSong, n)
+	for i := range songs {
+		songs[i] = PlayerPlaylistSong{Hash: fmt.Sprintf("s%d", i), SongName: fmt.Sprintf("歌曲%d", i)}
+	}
+	return songs
+}
+
+// newTestPlayQueue 在临时目录创建播放队列，使用固定的随机种子
+func newTestPlayQueue(t *testing.T, n int) *PlayQueue {
+	t.Helper()
+	q := NewPlayQueue(filepath.Join(t.TempDir(), "playlist.json"))
+	q.rng = rand.New(rand.NewSource(1))
+	q.Replace("测试", testQueueSongs(n), 0)
+	t.Cleanup(func() { q.Flush() })
+	return q
+}
+
+// currentHash 当前歌曲的hash
+func currentHash(data PlayerPlaylistData) string {
+	if data.CurrentIndex < 0 {
+		return ""
+	}
+	return data.Songs[data.CurrentIndex].Hash
+}
+
+func TestPlayQueueSequential(t *testing.T) {
+	q := newTestPlayQueue(t, 3)
+
+	if _, err := q.Previous(); err != errQueueStart {
+		t.Errorf("previous at start = %v", err)
+	}
+	q.Next()
+	data, _ := q.Next()
+	if data.CurrentIndex != 2 || data.HasNext {
+		t.Fatalf("data = %+v", data)
+	}
+	if _, err := q.Next(); err != errQueueEnd {
+		t.Errorf("next at end = %v", err)
+	}
+
+	// 列表循环时首尾相接；单曲循环时手动切歌也按列表前进
+	for _, repeat := range []string{RepeatAll, RepeatOne} {
+		q.SetMode(false, repeat)
+		if data, err := q.Next(); err != nil || data.CurrentIndex != 0 || !data.HasNext {
+			t.Errorf("%s next = %+v, %v", repeat, data, err)
+		}
+		if data, err := q.Previous(); err != nil || data.CurrentIndex != 2 {
+			t.Errorf("%s previous = %+v, %v", repeat, data, err)
+		}
+	}
+	if data := q.Snapshot(); data.PlayMode != "repeat_one" || data.ShuffleMode {
+		t.Errorf("play mode = %s", data.PlayMode)
+	}
+	if _, err := q.SetMode(false, "sometimes"); err == nil {
+		t.Error("invalid repeat mode should fail")
+	}
+}
+
+func TestPlayQueueShuffleRound(t *testing.T) {
+	q := newTestPlayQueue(t, 6)
+	q.Jump(2)
+	data, _ := q.SetMode(true, RepeatOff)
+	if data.PlayMode != "shuffle" || currentHash(data) != "s2" {
+		t.Fatalf("data = %+v", data)
+	}
+
+	// 一轮中每首歌只播放一次
+	played := []string{"s2"}
+	for {
+		data, err := q.Next()
+		if err == errQueueEnd {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		played = append(played, currentHash(data))
+	}
+	sorted := slices.Clone(played)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, []string{"s0", "s1", "s2", "s3", "s4", "s5"}) {
+		t.Fatalf("played = %v", played)
+	}
+	if q.Snapshot().HasNext {
+		t.Error("no next song after a full round without repeat")
+	}
+
+	// 上一首沿实际播放的顺序返回
+	for i := len(played) - 2; i >= 0; i-- {
+		data, err := q.Previous()
+		if err != nil || currentHash(data) != played[i] {
+			t.Fatalf("previous = %s, %v, want %s", currentHash(data), err, played[i])
+		}
+	}
+	if _, err := q.Previous(); err != errQueueStart {
+		t.Errorf("previous before the first played song = %v", err)
+	}
+}
+
+func TestPlayQueueShuffleReshufflesOnWrap(t *testing.T) {
+	q := newTestPlayQueue(t, 4)
+	q.SetMode(true, RepeatAll)
+
+	var played []string
+	for i := 0; i < 40; i++ {
+		data, err := q.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := currentHash(data)
+		if len(played) > 0 && played[len(played)-1] == hash {
+			t.Fatalf("song %s repeated immediately at %d: %v", hash, i, played)
+		}
+		played = append(played, hash)
+	}
+
+	// 跨轮次后上一首仍回到实际播放过的歌曲
+	data, _ := q.Previous()
+	if currentHash(data) != played[len(played)-2] {
+		t.Errorf("previous = %s, want %s", currentHash(data), played[len(played)-2])
+	}
+}
+
+func TestPlayQueueShuffleJumpAndPlayNext(t *testing.T) {
+	q := newTestPlayQueue(t, 5)
+	q.SetMode(true, RepeatOff)
+
+	// 手动选择的歌曲之后不会在本轮再次播放
+	q.Jump(3)
+	seen := map[string]int{"s0": 1, "s3": 1}
+	for {
+		data, err := q.Next()
+		if err != nil {
+			break
+		}
+		seen[currentHash(data)]++
+	}
+	for hash, count := range seen {
+		if count != 1 {
+			t.Errorf("%s played %d times", hash, count)
+		}
+	}
+	if data, _ := q.Previous(); len(seen) != 5 || currentHash(data) == "" {
+		t.Errorf("seen = %v", seen)
+	}
+
+	// 下一首播放在随机模式下也是下一首
+	q.Replace("测试", testQueueSongs(5), 0)
+	data, err := q.Add(PlayerPlaylistSong{Hash: "next"}, true)
+	if err != nil || data.Songs[1].Hash != "next" {
+		t.Fatalf("add next = %+v, %v", data.Songs, err)
+	}
+	if data, _ := q.Next(); currentHash(data) != "next" {
+		t.Errorf("next after play next = %s", currentHash(data))
+	}
+
+	// 已在列表中的歌曲下一首播放时移动过来，添加到末尾时拒绝
+	if _, err := q.Add(PlayerPlaylistSong{Hash: "s4"}, false); err != errQueueDuplicate {
+		t.Errorf("duplicate add = %v", err)
+	}
+	data, err = q.Add(PlayerPlaylistSong{Hash: "s4"}, true)
+	if err != nil || len(data.Songs) != 6 {
+		t.Fatalf("move to next = %+v, %v", data.Songs, err)
+	}
+	if data, _ := q.Next(); currentHash(data) != "s4" {
+		t.Errorf("next after moving s4 = %s", currentHash(data))
+	}
+}
+
+func TestPlayQueueRemove(t *testing.T) {
+	q := newTestPlayQueue(t, 4)
+	q.Jump(1)
+	data, _ := q.Remove(entryIDs(q, 1)[0])
+	if currentHash(data) != "s2" {
+		t.Errorf("current after removing current = %s", currentHash(data))
+	}
+	removed := entryIDs(q, 0)[0]
+	data, _ = q.Remove(removed)
+	if currentHash(data) != "s2" || data.CurrentIndex != 0 {
+		t.Errorf("current after removing earlier song = %+v", data)
+	}
+	if _, err := q.Remove(removed); err != errQueueNotFound {
+		t.Errorf("remove missing = %v", err)
+	}
+
+	// 随机模式下移除后顺序中的下标保持有效
+	q.Replace("测试", testQueueSongs(6), 0)
+	q.SetMode(true, RepeatOff)
+	q.Next()
+	q.Next()
+	before := q.Snapshot()
+	data, _ = q.Remove(before.Songs[before.CurrentIndex].EntryID)
+	if len(data.ShuffleOrder) != 5 || data.ShuffleOrder[data.ShufflePosition] != data.CurrentIndex {
+		t.Fatalf("order = %v at %d, current %d", data.ShuffleOrder, data.ShufflePosition, data.CurrentIndex)
+	}
+	for _, index := range data.ShuffleOrder {
+		if index < 0 || index >= len(data.Songs) {
+			t.Fatalf("invalid index in order %v", data.ShuffleOrder)
+		}
+	}
+	data, _ = q.Previous()
+	if currentHash(data) != currentHash(PlayerPlaylistData{Songs: before.Songs, CurrentIndex: before.ShuffleOrder[before.ShufflePosition-1]}) {
+		t.Errorf("previous after remove = %s", currentHash(data))
+	}
+
+	for _, song := range q.Snapshot().Songs {
+		q.Remove(song.EntryID)
+	}
+	if data := q.Snapshot(); data.CurrentIndex != -1 || data.HasNext {
+		t.Errorf("empty queue = %+v", data)
+	}
+}
+
+func TestPlayQueuePersistence(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "playlist.json")
+	q := NewPlayQueue(file)
+	q.Replace("我喜欢", testQueueSongs(5), 2)
+	q.SetMode(true, RepeatAll)
+	q.Next()
+	saved := q.Snapshot()
+
+	// 变更异步写盘，Flush 后立即写入
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewPlayQueue(file).Snapshot()
+	if loaded.Name != "我喜欢" || loaded.CurrentIndex != saved.CurrentIndex || !loaded.ShuffleMode || loaded.RepeatMode != RepeatAll ||
+		!slices.Equal(loaded.ShuffleOrder, saved.ShuffleOrder) || loaded.ShufflePosition != saved.ShufflePosition {
+		t.Errorf("loaded = %+v, saved = %+v", loaded, saved)
+	}
+}
+
+func TestPlayQueueLoadsLegacyData(t *testing.T) {
+	dir := t.TempDir()
+
+	// 旧版本 SetPlaylist 只写 play_mode
+	legacy := filepath.Join(dir, "legacy.json")
+	writeTestFile(t, legacy, `{"songs":[{"hash":"a"},{"hash":"b"}],"current_index":1,"play_mode":"repeat_all","shuffle_order":[]}`)
+	if data := NewPlayQueue(legacy).Snapshot(); data.RepeatMode != RepeatAll || data.ShuffleMode || data.CurrentIndex != 1 {
+		t.Errorf("legacy play mode = %+v", data)
+	}
+
+	// 旧版本的随机顺序只包含剩余的歌曲，重新生成
+	shuffled := filepath.Join(dir, "shuffled.json")
+	writeTestFile(t, shuffled, `{"songs":[{"hash":"a"},{"hash":"b"},{"hash":"c"}],"current_index":1,"play_mode":"shuffle","shuffle_mode":true,"repeat_mode":"off","shuffle_order":[2,0]}`)
+	data := NewPlayQueue(shuffled).Snapshot()
+	if !data.ShuffleMode || data.RepeatMode != RepeatOff || len(data.ShuffleOrder) != 3 || data.ShuffleOrder[data.ShufflePosition] != 1 {
+		t.Errorf("legacy shuffle = %+v", data)
+	}
+
+	// 损坏的文件使用空列表
+	broken := filepath.Join(dir, "broken.json")
+	os.WriteFile(broken, []byte("{"), 0644)
+	if data := NewPlayQueue(broken).Snapshot(); len(data.Songs) != 0 || data.CurrentIndex != -1 {
+		t.Errorf("broken = %+v", data)
+	}
+}
+
+func TestPlaylistServiceKeepsUserMode(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	newTestAppState(t, c)
+
+	p := &PlaylistService{}
+	p.UpdatePlayMode(UpdatePlayModeRequest{ShuffleMode: true, RepeatMode: RepeatAll})
+
+	// 没有指定播放模式时保持用户选择的模式
+	resp := p.SetPlaylist(SetPlaylistRequest{Songs: testQueueSongs(3), CurrentIndex: 1, ClearFirst: true})
+	if !resp.Success || !resp.Data.ShuffleMode || resp.Data.RepeatMode != RepeatAll || resp.Data.CurrentIndex != 1 || resp.Data.Name != "播放列表" {
+		t.Fatalf("set playlist = %+v", resp)
+	}
+	resp = p.SetPlaylist(SetPlaylistRequest{Songs: testQueueSongs(3), ClearFirst: true, PlayMode: "list"})
+	if resp.Data.ShuffleMode || resp.Data.RepeatMode != RepeatOff || resp.Data.PlayMode != "normal" {
+		t.Errorf("explicit mode = %+v", resp.Data)
+	}
+	if resp := p.SetPlaylist(SetPlaylistRequest{PlayMode: "bogus"}); resp.Success {
+		t.Error("unknown play mode should fail")
+	}
+
+	if resp := p.GetNextSong(); !resp.Success || resp.Data.CurrentIndex != 1 {
+		t.Errorf("next = %+v", resp)
+	}
+	if resp := p.RemoveFromPlaylist(""); resp.Success {
+		t.Error("empty hash should fail")
+	}
+	if resp := p.ClearPlaylist(); !resp.Success || len(resp.Data.Songs) != 0 || resp.Data.ShuffleMode {
+		t.Errorf("clear = %+v", resp)
+	}
+	if resp := p.GetNextSong(); resp.Success || resp.Message != "播放列表为空" {
+		t.Errorf("next on empty = %+v", resp)
+	}
+}
+
+// queueHashes 播放队列中歌曲的hash
+func queueHashes(q *PlayQueue) []string {
+	return songHashes(q.Snapshot().Songs)
+}
+
+// entryIDs 播放队列中指定位置的歌曲的 EntryID
+func entryIDs(q *PlayQueue, indices ...int) []string {
+	songs := q.Snapshot().Songs
+	ids := make([]string, len(indices))
+	for i, index := range indices {
+		ids[i] = songs[index].EntryID
+	}
+	return ids
+}
+
+func TestPlayQueueEntryIDs(t *testing.T) {
+	q := newTestPlayQueue(t, 0)
+	songs := testQueueSongs(2)
+	q.Replace("测试", append(songs, songs[0]), 2)
+
+	data := q.Snapshot()
+	ids := map[string]bool{}
+	for _, song := range data.Songs {
+		if song.EntryID == "" || ids[song.EntryID] {
+			t.Fatalf("entry ids = %+v", data.Songs)
+		}
+		ids[song.EntryID] = true
+	}
+
+	// 同一首歌出现两次时只移除选中的那一首，正在播放的那首保持不动
+	removed := entryIDs(q, 0)[0]
+	data, err := q.RemoveEntries([]string{removed, removed})
+	if err != nil || !slices.Equal(songHashes(data.Songs), []string{"s1", "s0"}) || data.CurrentIndex != 1 {
+		t.Fatalf("remove = %v at %d, %v", songHashes(data.Songs), data.CurrentIndex, err)
+	}
+	if data.Songs[1].EntryID != q.Snapshot().Songs[1].EntryID {
+		t.Error("entry id should be stable")
+	}
+	// 选择中有已经移除的条目时整批都不生效
+	if _, err := q.RemoveEntries([]string{data.Songs[0].EntryID, removed}); err != errQueueNotFound || len(q.Snapshot().Songs) != 2 {
+		t.Errorf("remove stale entries = %v", err)
+	}
+
+	// 重新加载后ID不变
+	q.Flush()
+	if loaded := NewPlayQueue(q.file).Snapshot(); loaded.Songs[1].EntryID != data.Songs[1].EntryID {
+		t.Errorf("reloaded entry id = %s", loaded.Songs[1].EntryID)
+	}
+}
+
+func TestPlayQueueMoveAndSort(t *testing.T) {
+	q := newTestPlayQueue(t, 4)
+	q.Jump(1)
+
+	data, err := q.Move(entryIDs(q, 1)[0], 3)
+	if err != nil || !slices.Equal(songHashes(data.Songs), []string{"s0", "s2", "s3", "s1"}) || currentHash(data) != "s1" {
+		t.Fatalf("move = %v, current %s, %v", songHashes(data.Songs), currentHash(data), err)
+	}
+	if _, err := q.Move(entryIDs(q, 0)[0], 4); err != errQueueIndex {
+		t.Errorf("move out of range = %v", err)
+	}
+	if _, err := q.Move("missing", 0); err != errQueueNotFound {
+		t.Errorf("move missing entry = %v", err)
+	}
+
+	data, _ = q.Sort(QueueSortReverse)
+	if !slices.Equal(songHashes(data.Songs), []string{"s1", "s3", "s2", "s0"}) || currentHash(data) != "s1" {
+		t.Errorf("reverse = %v", songHashes(data.Songs))
+	}
+
+	q.Replace("测试", []PlayerPlaylistSong{
+		{Hash: "a", SongName: "晴天", ArtistName: "周杰伦", Duration: 269},
+		{Hash: "b", SongName: "Apple", ArtistName: "Beyond", Duration: 120},
+		{Hash: "c", SongName: "banana", ArtistName: "周杰伦", Duration: 200},
+	}, 0)
+	if data, _ := q.Sort(QueueSortName); !slices.Equal(songHashes(data.Songs), []string{"b", "c", "a"}) || currentHash(data) != "a" {
+		t.Errorf("by name = %v", songHashes(data.Songs))
+	}
+	if _, err := q.Sort(QueueSortDuration); err != errQueueNoChange {
+		t.Errorf("sort sorted = %v", err)
+	}
+	q.Sort(QueueSortReverse)
+	if data, _ := q.Sort(QueueSortArtist); !slices.Equal(songHashes(data.Songs), []string{"b", "a", "c"}) {
+		t.Errorf("by artist keeps ties in place = %v", songHashes(data.Songs))
+	}
+	if data, _ := q.Sort(QueueSortDuration); !slices.Equal(songHashes(data.Songs), []string{"b", "c", "a"}) {
+		t.Errorf("by duration = %v", songHashes(data.Songs))
+	}
+	if _, err := q.Sort("color"); err == nil {
+		t.Error("unknown sort should fail")
+	}
+}
+
+func TestPlayQueueShuffleMoveKeepsHistory(t *testing.T) {
+	q := newTestPlayQueue(t, 6)
+	q.SetMode(true, RepeatOff)
+	q.Next()
+	q.Next()
+	before := q.Snapshot()
+	previous := before.Songs[before.ShuffleOrder[before.ShufflePosition-1]].Hash
+
+	data, _ := q.Sort(QueueSortReverse)
+	if currentHash(data) != currentHash(before) || data.ShufflePosition != before.ShufflePosition {
+		t.Fatalf("after sort = %+v", data)
+	}
+	if data, _ := q.Previous(); currentHash(data) != previous {
+		t.Errorf("previous after sort = %s, want %s", currentHash(data), previous)
+	}
+}
+
+func TestPlayQueueDeduplicate(t *testing.T) {
+	q := newTestPlayQueue(t, 0)
+	songs := testQueueSongs(3)
+	q.Replace("测试", []PlayerPlaylistSong{songs[0], songs[1], songs[0], songs[2], songs[1]}, 2)
+
+	data, removed, err := q.Deduplicate()
+	if err != nil || removed != 2 || !slices.Equal(songHashes(data.Songs), []string{"s1", "s0", "s2"}) || data.CurrentIndex != 1 {
+		t.Fatalf("dedupe = %v at %d, removed %d, %v", songHashes(data.Songs), data.CurrentIndex, removed, err)
+	}
+	if _, _, err := q.Deduplicate(); err != errQueueNoChange {
+		t.Errorf("second dedupe = %v", err)
+	}
+}
+
+func TestPlayQueueUndo(t *testing.T) {
+	q := newTestPlayQueue(t, 4)
+	q.Undo() // 撤销初始的 Replace
+	if _, err := q.Undo(); err != errQueueNoUndo {
+		t.Fatalf("undo on empty stack = %v", err)
+	}
+
+	q.Replace("测试", testQueueSongs(4), 0)
+	q.Move(entryIDs(q, 0)[0], 3)
+	q.RemoveEntries(entryIDs(q, 0, 1))
+	q.Clear()
+	if data := q.Snapshot(); data.UndoCount != 4 || len(data.Songs) != 0 {
+		t.Fatalf("before undo = %+v", data)
+	}
+
+	data, _ := q.Undo()
+	if !slices.Equal(songHashes(data.Songs), []string{"s3", "s0"}) || data.Name != "测试" {
+		t.Fatalf("undo clear = %v", songHashes(data.Songs))
+	}
+
+	// 撤销后正在播放的歌曲保持不变
+	q.Jump(0)
+	data, _ = q.Undo()
+	if !slices.Equal(songHashes(data.Songs), []string{"s1", "s2", "s3", "s0"}) || currentHash(data) != "s3" {
+		t.Errorf("undo remove = %v, current %s", songHashes(data.Songs), currentHash(data))
+	}
+	data, _ = q.Undo()
+	if !slices.Equal(songHashes(data.Songs), []string{"s0", "s1", "s2", "s3"}) || currentHash(data) != "s3" || data.UndoCount != 1 {
+		t.Errorf("undo move = %v, current %s", songHashes(data.Songs), currentHash(data))
+	}
+
+	// 只保留最近的修改
+	for i := 0; i < maxQueueUndo+5; i++ {
+		q.Move(entryIDs(q, 0)[0], 1)
+	}
+	if data := q.Snapshot(); data.UndoCount != maxQueueUndo {
+		t.Errorf("undo count = %d", data.UndoCount)
+	}
+
+	// 切换播放列表后不能撤销到之前的列表
+	q.Restore(LocalPlaylist{ID: "x", Name: "歌单", Songs: testQueueSongs(1)})
+	if _, err := q.Undo(); err != errQueueNoUndo {
+		t.Errorf("undo after restore = %v", err)
+	}
+
+	// 撤销时恢复修改前绑定的歌单
+	q.Append("歌单", testQueueSongs(2)[1:], -1)
+	q.playlistID = ""
+	if data, _ := q.Undo(); data.PlaylistID != "x" || len(data.Songs) != 1 {
+		t.Errorf("undo playlist id = %q, %v", data.PlaylistID, songHashes(data.Songs))
+	}
+}
+
+func TestPlaylistServiceQueueEditing(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	newTestAppState(t, c)
+
+	p := &PlaylistService{}
+	songs := testQueueSongs(3)
+	p.SetPlaylist(SetPlaylistRequest{Songs: append(songs, songs[1]), ClearFirst: true})
+
+	if resp := p.DeduplicateQueue(); !resp.Success || len(resp.Data.Songs) != 3 || resp.Message != "已移除 1 首重复的歌曲" {
+		t.Errorf("dedupe = %+v", resp)
+	}
+	data := p.GetPlaylist().Data
+	if resp := p.MoveSong(data.Songs[2].EntryID, 0); !resp.Success || resp.Data.Songs[0].Hash != "s2" {
+		t.Errorf("move = %+v", resp)
+	}
+	if resp := p.SortQueue(QueueSortName); !resp.Success || resp.Data.Songs[0].Hash != "s0" {
+		t.Errorf("sort = %+v", resp)
+	}
+	if resp := p.RemoveSongs(nil); resp.Success {
+		t.Error("removing nothing should fail")
+	}
+	data = p.GetPlaylist().Data
+	if resp := p.RemoveSongs([]string{data.Songs[0].EntryID, data.Songs[2].EntryID}); !resp.Success || len(resp.Data.Songs) != 1 {
+		t.Errorf("remove = %+v", resp)
+	}
+
+	if resp := p.UndoQueue(); !resp.Success || len(resp.Data.Songs) != 3 {
+		t.Errorf("undo = %+v", resp)
+	}
+
+	// 同一首歌出现两次时按条目ID只移除指定的那一首
+	data = p.SetPlaylist(SetPlaylistRequest{Songs: []PlayerPlaylistSong{songs[0], songs[0]}, ClearFirst: true}).Data
+	if resp := p.RemoveFromPlaylist(data.Songs[1].EntryID); !resp.Success || len(resp.Data.Songs) != 1 || resp.Data.Songs[0].EntryID != data.Songs[0].EntryID {
+		t.Errorf("remove duplicate = %+v", resp)
+	}
+}
+
+func TestPlayQueueConcurrentFlush(t *testing.T) {
+	q := newTestPlayQueue(t, 20)
+
+	// 多个保存同时进行时不会互相删除临时文件，最后写入的是最新的状态
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			q.Next()
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- q.Flush()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	}
+
+	if err := q.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, saved := NewPlayQueue(q.file).Snapshot(), q.Snapshot(); loaded.CurrentIndex != saved.CurrentIndex {
+		t.Errorf("loaded index = %d, want %d", loaded.CurrentIndex, saved.CurrentIndex)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// remoteRequests 命令行中控制运行中实例的请求，按添加文件、音量、播放控制、查询状态的顺序执行
+// 没有控制参数时返回空，此时正常启动或转发给运行中的实例
+func remoteRequests(options CommandLineOptions, workDir string) []InstanceRequest {
+	var requests []InstanceRequest
+	if len(options.Enqueue) > 0 {
+		requests = append(requests, InstanceRequest{Command: instanceCommandEnqueue, Args: options.Enqueue, WorkDir: workDir})
+	}
+	if options.Volume >= 0 {
+		requests = append(requests, InstanceRequest{Command: instanceCommandVolume, Args: []string{strconv.Itoa(options.Volume)}})
+	}
+	if options.PlayPause {
+		requests = append(requests, InstanceRequest{Command: instanceCommandPlayPause})
+	}
+	if options.Prev {
+		requests = append(requests, InstanceRequest{Command: instanceCommandPrevious})
+	}
+	if options.Next {
+		requests = append(requests, InstanceRequest{Command: instanceCommandNext})
+	}
+	if options.Status || options.JSON {
+		requests = append(requests, InstanceRequest{Command: instanceCommandStatus})
+	}
+	return requests
+}
+
+// runRemoteControl 把控制命令发给运行中的实例并输出结果，返回进程退出码
+// 查询状态的结果写到 stdout 供状态栏读取，其它提示写到 stderr
+func runRemoteControl(path string, options CommandLineOptions, stdout io.Writer, stderr io.Writer) int {
+	workDir, _ := os.Getwd()
+	exitCode := 0
+	for _, request := range remoteRequests(options, workDir) {
+		response, err := sendInstanceRequest(path, request)
+		if err != nil {
+			fmt.Fprintf(stderr, "wmplayer 未在运行: %v\n", err)
+			return 1
+		}
+		if !response.Success {
+			fmt.Fprintf(stderr, "%s\n", response.Message)
+			exitCode = 1
+			continue
+		}
+		if request.Command != instanceCommandStatus {
+			fmt.Fprintf(stderr, "%s\n", response.Message)
+			continue
+		}
+
+		status, err := decodePlayerStatus(response.Data)
+		if err != nil {
+			fmt.Fprintf(stderr, "播放状态格式错误: %v\n", err)
+			return 1
+		}
+		if options.JSON {
+			data, _ := json.Marshal(status)
+			fmt.Fprintf(stdout, "%s\n", data)
+		} else {
+			fmt.Fprintf(stdout, "%s\n", formatPlayerStatus(status))
+		}
+	}
+	return exitCode
+}
+
+// decodePlayerStatus 把响应中的状态数据解析为 PlayerStatus
+func decodePlayerStatus(data any) (PlayerStatus, error) {
+	var status PlayerStatus
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return status, err
+	}
+	err = json.Unmarshal(raw, &status)
+	return status, err
+}
+
+// formatPlayerStatus 生成一行文字的播放状态，如 "▶ 周杰伦 - 晴天 [1:05/4:29]"
+func formatPlayerStatus(status PlayerStatus) string {
+	if status.Title == "" {
+		return "⏹ 未在播放"
+	}
+
+	icon := "⏹"
+	switch status.Status {
+	case "Playing":
+		icon = "▶"
+	case "Paused":
+		icon = "⏸"
+	}
+	song := status.Title
+	if status.Artist != "" {
+		song = status.Artist + " - " + status.Title
+	}
+	return fmt.Sprintf("%s %s [%s/%s]", icon, song, formatSeconds(status.Position), formatSeconds(status.Duration))
+}
+
+// formatSeconds 把秒数格式化为 m:ss
+func formatSeconds(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestRemoteInstance 启动处理控制命令的实例，返回socket路径和记录的前端事件
+func newTestRemoteInstance(t *testing.T) (string, *MediaKeyService, func() []emittedEvent) {
+	t.Helper()
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+	newTestAppState(t, c)
+
+	var mutex sync.Mutex
+	var events []emittedEvent
+	emit := func(name string, data any) {
+		mutex.Lock()
+		events = append(events, emittedEvent{name, data})
+		mutex.Unlock()
+	}
+	mediaKeys := NewMediaKeyService()
+	mediaKeys.setEventEmitter(emit)
+
+	lock, path := newTestInstanceLock(t)
+	lock.Serve(NewInstanceController(func() { t.Error("control commands should not show the window") }, emit, mediaKeys).Handle)
+	return path, mediaKeys, func() []emittedEvent {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]emittedEvent(nil), events...)
+	}
+}
+
+func TestParseCommandLineRemoteControl(t *testing.T) {
+	options := parseCommandLine([]string{"--volume", "40", "--enqueue", "a.flac", "--next", "--enqueue", "b.mp3", "--status", "--json"})
+	if options.Volume != 40 || !options.Next || !options.Status || !options.JSON || !reflect.DeepEqual(options.Enqueue, []string{"a.flac", "b.mp3"}) || len(options.Files) != 0 {
+		t.Errorf("options = %+v", options)
+	}
+
+	requests := remoteRequests(options, "/work")
+	var commands []string
+	for _, request := range requests {
+		commands = append(commands, request.Command)
+	}
+	if !reflect.DeepEqual(commands, []string{"enqueue", "volume", "next", "status"}) || requests[0].WorkDir != "/work" {
+		t.Errorf("requests = %+v", requests)
+	}
+
+	// 没有控制参数时正常启动
+	if requests := remoteRequests(parseCommandLine([]string{"--toggle", "a.flac"}), ""); len(requests) != 0 {
+		t.Errorf("plain launch requests = %+v", requests)
+	}
+}
+
+func TestRemoteControlPlaybackAndStatus(t *testing.T) {
+	path, mediaKeys, events := newTestRemoteInstance(t)
+	mediaKeys.UpdateSongMetadata("晴天", "周杰伦", "叶惠美", "", 269_000_000)
+	mediaKeys.UpdatePlayerVolume(0.8)
+	mediaKeys.UpdatePlayerPosition(65_000_000)
+	mediaKeys.UpdatePlaybackStatus("Paused")
+
+	var stdout, stderr bytes.Buffer
+	options := parseCommandLine([]string{"--volume", "40", "--play-pause", "--next", "--prev", "--status", "--json"})
+	if code := runRemoteControl(path, options, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr %s", code, stderr.String())
+	}
+
+	var names []string
+	for _, event := range events() {
+		names = append(names, event.name)
+	}
+	want := []string{setVolumeEvent, togglePlayPauseEvent, previousSongEvent, nextSongEvent}
+	if !reflect.DeepEqual(names, want) || events()[0].data != 40 {
+		t.Errorf("events = %+v", events())
+	}
+
+	var status PlayerStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("status output %q: %v", stdout.String(), err)
+	}
+	if status.Title != "晴天" || status.Artist != "周杰伦" || status.Status != "Paused" || status.Duration != 269 || status.Position != 65 || status.Volume != 40 {
+		t.Errorf("status = %+v", status)
+	}
+
+	stdout.Reset()
+	runRemoteControl(path, parseCommandLine([]string{"--status"}), &stdout, &stderr)
+	if got := strings.TrimSpace(stdout.String()); got != "⏸ 周杰伦 - 晴天 [1:05/4:29]" {
+		t.Errorf("text status = %q", got)
+	}
+}
+
+func TestRemoteControlRejectsBadVolume(t *testing.T) {
+	path, _, events := newTestRemoteInstance(t)
+	var stdout, stderr bytes.Buffer
+	if code := runRemoteControl(path, CommandLineOptions{Volume: 140}, &stdout, &stderr); code == 0 || !strings.Contains(stderr.String(), "0-100") {
+		t.Errorf("exit code = %d, stderr %q", code, stderr.String())
+	}
+	if len(events()) != 0 {
+		t.Errorf("events = %+v", events())
+	}
+}
+
+func TestRemoteControlEnqueue(t *testing.T) {
+	path, _, events := newTestRemoteInstance(t)
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "晴天.mp3"), "not really audio")
+	writeTestFile(t, filepath.Join(dir, "七里香.flac"), "not really audio either")
+
+	var stdout, stderr bytes.Buffer
+	options := CommandLineOptions{Volume: -1, Enqueue: []string{filepath.Join(dir, "晴天.mp3"), filepath.Join(dir, "七里香.flac")}}
+	if code := runRemoteControl(path, options, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit code = %d, stderr %s", code, stderr.String())
+	}
+	playlist := (&PlaylistService{}).GetPlaylist()
+	if !playlist.Success || len(playlist.Data.Songs) != 2 || playlist.Data.Songs[0].SongName != "晴天" || !strings.HasPrefix(playlist.Data.Songs[0].Hash, "local-") {
+		t.Fatalf("playlist = %+v", playlist)
+	}
+	if got := events(); len(got) != 1 || got[0].name != instancePlaylistChangedEvent {
+		t.Errorf("events = %+v", got)
+	}
+
+	// 已在播放列表中的文件不会重复添加
+	if code := runRemoteControl(path, options, &stdout, &stderr); code == 0 {
+		t.Error("enqueue of existing songs should fail")
+	}
+	if playlist := (&PlaylistService{}).GetPlaylist(); len(playlist.Data.Songs) != 2 {
+		t.Errorf("songs after second enqueue = %d", len(playlist.Data.Songs))
+	}
+}
+
+func TestRemoteControlWithoutInstance(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runRemoteControl(filepath.Join(t.TempDir(), "missing.sock"), CommandLineOptions{Volume: -1, Status: true}, &stdout, &stderr)
+	if code != 1 || stdout.Len() != 0 || !strings.Contains(stderr.String(), "未在运行") {
+		t.Errorf("exit code = %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestMediaKeyEventsReachFrontend(t *testing.T) {
+	m := NewMediaKeyService()
+	if result := m.HandleMediaKeyEvent("play_pause"); result["success"] != false {
+		t.Errorf("without frontend = %v", result)
+	}
+
+	var events []emittedEvent
+	m.setEventEmitter(func(name string, data any) { events = append(events, emittedEvent{name, data}) })
+	if result := m.HandleMediaKeyEvent("volume_up"); result["success"] != false {
+		t.Errorf("volume_up before volume is known = %v", result)
+	}
+	m.UpdatePlayerVolume(0.95)
+	m.HandleMediaKeyEvent("volume_up")
+	m.HandleMediaKeyEvent("volume_down")
+
+	// play 和 pause 只在需要时切换
+	m.HandleMediaKeyEvent("pause")
+	m.HandleMediaKeyEvent("play")
+	m.UpdatePlaybackStatus("Playing")
+	m.HandleMediaKeyEvent("play")
+
+	want := []emittedEvent{{setVolumeEvent, 100}, {setVolumeEvent, 90}, {togglePlayPauseEvent, nil}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v", events)
+	}
+	if status := m.GetPlayerStatus(); status.Volume != 90 || status.Status != "Playing" {
+		t.Errorf("status = %+v", status)
+	}
+}
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"time"
 )
@@ -133,386 +130,353 @@ type SearchSuggestData struct {
 // SearchSuggestResponse 搜索建议响应结构
 type SearchSuggestResponse = ApiResponse[[]SearchSuggestData]
 
-// readCookieFromFile 从全局Cookie管理器读取cookie
-func (s *SearchService) readCookieFromFile() (string, error) {
-	cookie := GlobalCookieManager.GetCookie()
-	return cookie, nil
+// searchComplexData /search/complex 接口 data 字段的结构
+type searchComplexData struct {
+	Song    searchComplexSection[searchComplexSong]    `json:"song"`
+	Author  searchComplexSection[searchComplexAuthor]  `json:"author"`
+	Special searchComplexSection[searchComplexSpecial] `json:"special"`
+	Album   searchComplexSection[searchComplexAlbum]   `json:"album"`
+	MV      searchComplexSection[searchComplexMV]      `json:"mv"`
 }
 
-// Search 综合搜索
-func (s *SearchService) Search(keyword string, page int, pageSize int) SearchResponse {
-	if keyword == "" {
-		return SearchResponse{
-			Success: false,
-			Message: "搜索关键词不能为空",
-		}
-	}
+// searchComplexSection 综合搜索中单个分类的结构
+type searchComplexSection[T any] struct {
+	List apiList[T] `json:"list"`
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (s *searchComplexSection[T]) UnmarshalJSON(b []byte) error {
+	type plain searchComplexSection[T]
+	return decodeObject(b, (*plain)(s))
+}
+
+// searchComplexSong 综合搜索中的歌曲条目
+type searchComplexSong struct {
+	Hash       apiString `json:"hash"`
+	SongName   apiString `json:"songname"`
+	FileName   apiString `json:"filename"`
+	TimeLength apiInt    `json:"timelength"`
+	AlbumName  apiString `json:"album_name"`
+	AlbumID    apiString `json:"album_id"`
+	AuthorName apiString `json:"author_name"`
+	UnionCover apiString `json:"union_cover"`
+}
+
+// searchComplexAuthor 综合搜索中的艺人条目
+type searchComplexAuthor struct {
+	AuthorID   apiString `json:"author_id"`
+	AuthorName apiString `json:"author_name"`
+	Avatar     apiString `json:"avatar"`
+	SongCount  apiInt    `json:"song_count"`
+}
+
+// searchComplexSpecial 综合搜索中的歌单条目
+type searchComplexSpecial struct {
+	GID         apiString `json:"gid"`
+	SpecialName apiString `json:"special_name"`
+	ImgURL      apiString `json:"img_url"`
+	PlayCount   apiInt    `json:"play_count"`
+	SongCount   apiInt    `json:"song_count"`
+	AuthorName  apiString `json:"author_name"`
+}
+
+// searchComplexAlbum 综合搜索中的专辑条目
+type searchComplexAlbum struct {
+	AlbumID    apiString `json:"album_id"`
+	AlbumName  apiString `json:"album_name"`
+	ImgURL     apiString `json:"img_url"`
+	AuthorName apiString `json:"author_name"`
+	SongCount  apiInt    `json:"song_count"`
+}
+
+// searchComplexMV 综合搜索中的MV条目
+type searchComplexMV struct {
+	Hash       apiString `json:"hash"`
+	MVName     apiString `json:"mv_name"`
+	ImgURL     apiString `json:"img_url"`
+	AuthorName apiString `json:"author_name"`
+	Duration   apiInt    `json:"duration"`
+}
+
+// searchListData /search 接口 data 字段的结构
+type searchListData[T any] struct {
+	Total apiInt     `json:"total"`
+	Lists apiList[T] `json:"lists"`
+}
+
+// searchSongItem 按歌曲搜索的条目
+type searchSongItem struct {
+	FileHash    apiString `json:"FileHash"`
+	OriSongName apiString `json:"OriSongName"`
+	FileName    apiString `json:"FileName"`
+	ExtName     apiString `json:"ExtName"`
+	Duration    apiInt    `json:"Duration"`
+	AlbumName   apiString `json:"AlbumName"`
+	AlbumID     apiString `json:"AlbumID"`
+	SingerName  apiString `json:"SingerName"`
+	Image       apiString `json:"Image"`
+}
+
+// searchArtistItem 按艺人搜索的条目
+type searchArtistItem struct {
+	SingerID   apiString `json:"SingerID"`
+	AuthorName apiString `json:"AuthorName"`
+	Avatar     apiString `json:"Avatar"`
+	AudioCount apiInt    `json:"AudioCount"`
+}
+
+// searchPlaylistItem 按歌单搜索的条目
+type searchPlaylistItem struct {
+	GID         apiString `json:"gid"`
+	SpecialName apiString `json:"specialname"`
+	Img         apiString `json:"img"`
+	PlayCount   apiInt    `json:"play_count"`
+	SongCount   apiInt    `json:"song_count"`
+	Nickname    apiString `json:"nickname"`
+}
+
+// searchAlbumItem 按专辑搜索的条目
+type searchAlbumItem struct {
+	AlbumID   apiString `json:"albumid"`
+	AlbumName apiString `json:"albumname"`
+	Img       apiString `json:"img"`
+	Singer    apiString `json:"singer"`
+	SongCount apiInt    `json:"songcount"`
+}
+
+// searchMVItem 按MV搜索的条目
+type searchMVItem struct {
+	MvHash     apiString `json:"MvHash"`
+	MvName     apiString `json:"MvName"`
+	ThumbGif   apiString `json:"ThumbGif"`
+	SingerName apiString `json:"SingerName"`
+	Duration   apiInt    `json:"Duration"`
+}
+
+// hotSearchData /search/hot 接口 data 字段的结构
+type hotSearchData struct {
+	Timestamp apiInt                     `json:"timestamp"`
+	List      apiList[hotSearchCategory] `json:"list"`
+}
+
+// hotSearchCategory 热搜分类条目
+type hotSearchCategory struct {
+	Name     apiString                 `json:"name"`
+	Keywords apiList[hotSearchKeyword] `json:"keywords"`
+}
+
+// hotSearchKeyword 热搜关键词条目
+type hotSearchKeyword struct {
+	Reason      apiString `json:"reason"`
+	JsonURL     apiString `json:"json_url"`
+	JumpURL     apiString `json:"jumpurl"`
+	Keyword     apiString `json:"keyword"`
+	IsCoverWord apiInt    `json:"is_cover_word"`
+	Type        apiInt    `json:"type"`
+	Icon        apiInt    `json:"icon"`
+}
 
+// searchSuggestData /search/suggest 接口 data 字段的结构
+type searchSuggestData struct {
+	MusicTip apiList[string] `json:"music_tip"`
+	AlbumTip apiList[string] `json:"album_tip"`
+	MVTip    apiList[string] `json:"mv_tip"`
+}
+
+// normalizePaging 补全分页参数的默认值
+func normalizePaging(page int, pageSize int) (int, int) {
 	if page <= 0 {
 		page = 1
 	}
 	if pageSize <= 0 {
 		pageSize = 30
 	}
+	return page, pageSize
+}
 
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
+// searchByType 调用 /search 接口按类型搜索，返回解析后的 data 字段
+func searchByType[T any](keyword string, searchType string, page int, pageSize int, fallback string) (*searchListData[T], error) {
+	page, pageSize = normalizePaging(page, pageSize)
+
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/search",
+		Params: url.Values{
+			"keywords": {keyword},
+			"type":     {searchType},
+			"page":     {fmt.Sprintf("%d", page)},
+			"pagesize": {fmt.Sprintf("%d", pageSize)},
+		},
+		Cookie: true,
+	})
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
+		return nil, err
 	}
 
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search/complex", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
+	if !envelope.errorCodeIs(0) {
+		return nil, envelope.failure(fallback)
 	}
 
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	data, err := decodeApiData[searchListData[T]](envelope)
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return &data, nil
+}
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+// Search 综合搜索
+// 离线时只在已缓存和本地音乐中搜索歌曲
+func (s *SearchService) Search(keyword string, page int, pageSize int) SearchResponse {
+	if keyword == "" {
 		return SearchResponse{
 			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
+			Message: "搜索关键词不能为空",
 		}
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	page, pageSize = normalizePaging(page, pageSize)
+	if GlobalBackendManager.IsOffline() {
+		return searchOffline(keyword, page, pageSize)
 	}
 
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 解析歌曲数据
-			if songList, ok := data["song"].(map[string]interface{}); ok {
-				if songs, ok := songList["list"].([]interface{}); ok {
-					for _, songItem := range songs {
-						if song, ok := songItem.(map[string]interface{}); ok {
-							songData := SearchSongData{}
-							if hash, ok := song["hash"].(string); ok {
-								songData.Hash = hash
-							}
-							if songname, ok := song["songname"].(string); ok {
-								songData.SongName = songname
-							}
-							if filename, ok := song["filename"].(string); ok {
-								songData.FileName = filename
-							}
-							if timelength, ok := song["timelength"].(float64); ok {
-								songData.TimeLength = int(timelength)
-							}
-							if albumname, ok := song["album_name"].(string); ok {
-								songData.AlbumName = albumname
-							}
-							if albumID, ok := song["album_id"].(string); ok {
-								songData.AlbumID = albumID
-							}
-							if authorName, ok := song["author_name"].(string); ok {
-								songData.AuthorName = authorName
-							}
-							if unionCover, ok := song["union_cover"].(string); ok {
-								songData.UnionCover = unionCover
-							}
-							results.Songs.List = append(results.Songs.List, songData)
-						}
-					}
-				}
-			}
-
-			// 解析艺人数据
-			if authorList, ok := data["author"].(map[string]interface{}); ok {
-				if authors, ok := authorList["list"].([]interface{}); ok {
-					for _, authorItem := range authors {
-						if author, ok := authorItem.(map[string]interface{}); ok {
-							artistData := SearchArtistData{}
-							if authorID, ok := author["author_id"].(string); ok {
-								artistData.AuthorID = authorID
-							}
-							if authorName, ok := author["author_name"].(string); ok {
-								artistData.AuthorName = authorName
-							}
-							if avatar, ok := author["avatar"].(string); ok {
-								artistData.Avatar = avatar
-							}
-							if songCount, ok := author["song_count"].(float64); ok {
-								artistData.SongCount = int(songCount)
-							}
-							results.Artists.List = append(results.Artists.List, artistData)
-						}
-					}
-				}
-			}
-
-			// 解析歌单数据
-			if specialList, ok := data["special"].(map[string]interface{}); ok {
-				if specials, ok := specialList["list"].([]interface{}); ok {
-					for _, specialItem := range specials {
-						if special, ok := specialItem.(map[string]interface{}); ok {
-							playlistData := SearchPlaylistData{}
-							if specialID, ok := special["gid"].(string); ok {
-								playlistData.SpecialID = specialID
-							}
-							if specialName, ok := special["special_name"].(string); ok {
-								playlistData.SpecialName = specialName
-							}
-							if imgURL, ok := special["img_url"].(string); ok {
-								playlistData.ImgURL = imgURL
-							}
-							if playCount, ok := special["play_count"].(float64); ok {
-								playlistData.PlayCount = int(playCount)
-							}
-							if songCount, ok := special["song_count"].(float64); ok {
-								playlistData.SongCount = int(songCount)
-							}
-							if authorName, ok := special["author_name"].(string); ok {
-								playlistData.AuthorName = authorName
-							}
-							results.Playlists.List = append(results.Playlists.List, playlistData)
-						}
-					}
-				}
-			}
-
-			// 解析专辑数据
-			if albumList, ok := data["album"].(map[string]interface{}); ok {
-				if albums, ok := albumList["list"].([]interface{}); ok {
-					for _, albumItem := range albums {
-						if album, ok := albumItem.(map[string]interface{}); ok {
-							albumData := SearchAlbumData{}
-							if albumID, ok := album["album_id"].(float64); ok {
-								albumData.AlbumID = fmt.Sprintf("%.0f", albumID)
-							} else if albumIDStr, ok := album["album_id"].(string); ok {
-								albumData.AlbumID = albumIDStr
-							}
-							if albumName, ok := album["album_name"].(string); ok {
-								albumData.AlbumName = albumName
-							}
-							if imgURL, ok := album["img_url"].(string); ok {
-								albumData.ImgURL = imgURL
-							}
-							if authorName, ok := album["author_name"].(string); ok {
-								albumData.AuthorName = authorName
-							}
-							if songCount, ok := album["song_count"].(float64); ok {
-								albumData.SongCount = int(songCount)
-							}
-							results.Albums.List = append(results.Albums.List, albumData)
-						}
-					}
-				}
-			}
-
-			// 解析MV数据
-			if mvList, ok := data["mv"].(map[string]interface{}); ok {
-				if mvs, ok := mvList["list"].([]interface{}); ok {
-					for _, mvItem := range mvs {
-						if mv, ok := mvItem.(map[string]interface{}); ok {
-							mvData := SearchMVData{}
-							if hash, ok := mv["hash"].(string); ok {
-								mvData.Hash = hash
-							}
-							if mvName, ok := mv["mv_name"].(string); ok {
-								mvData.MVName = mvName
-							}
-							if imgURL, ok := mv["img_url"].(string); ok {
-								mvData.ImgURL = imgURL
-							}
-							if authorName, ok := mv["author_name"].(string); ok {
-								mvData.AuthorName = authorName
-							}
-							if duration, ok := mv["duration"].(float64); ok {
-								mvData.Duration = int(duration)
-							}
-							results.MVs.List = append(results.MVs.List, mvData)
-						}
-					}
-				}
-			}
-		}
-
-		return SearchResponse{
-			Success: true,
-			Message: "搜索成功",
-			Data:    results,
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path: "/search/complex",
+		Params: url.Values{
+			"keywords": {keyword},
+			"page":     {fmt.Sprintf("%d", page)},
+			"pagesize": {fmt.Sprintf("%d", pageSize)},
+		},
+		Cookie: true,
+	})
+	if err != nil {
+		if isOfflineError(err) {
+			return searchOffline(keyword, page, pageSize)
 		}
+		return apiFailure[SearchResults](err)
 	}
 
-	// 处理API错误
-	errorMsg := "搜索失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	// 检查API响应是否成功
+	if !envelope.errorCodeIs(0) {
+		return apiFailure[SearchResults](envelope.failure("搜索失败"))
+	}
+
+	results := SearchResults{}
+
+	// data 缺失或格式不符时返回空结果，与之前的行为保持一致
+	data, _ := decodeApiData[searchComplexData](envelope)
+
+	// 解析歌曲数据
+	for _, song := range data.Song.List {
+		results.Songs.List = append(results.Songs.List, SearchSongData{
+			Hash:       song.Hash.String(),
+			SongName:   song.SongName.String(),
+			FileName:   song.FileName.String(),
+			TimeLength: song.TimeLength.Int(),
+			AlbumName:  song.AlbumName.String(),
+			AlbumID:    song.AlbumID.String(),
+			AuthorName: song.AuthorName.String(),
+			UnionCover: song.UnionCover.String(),
+		})
+	}
+
+	// 解析艺人数据
+	for _, author := range data.Author.List {
+		results.Artists.List = append(results.Artists.List, SearchArtistData{
+			AuthorID:   author.AuthorID.String(),
+			AuthorName: author.AuthorName.String(),
+			Avatar:     author.Avatar.String(),
+			SongCount:  author.SongCount.Int(),
+		})
+	}
+
+	// 解析歌单数据
+	for _, special := range data.Special.List {
+		results.Playlists.List = append(results.Playlists.List, SearchPlaylistData{
+			SpecialID:   special.GID.String(),
+			SpecialName: special.SpecialName.String(),
+			ImgURL:      special.ImgURL.String(),
+			PlayCount:   special.PlayCount.Int(),
+			SongCount:   special.SongCount.Int(),
+			AuthorName:  special.AuthorName.String(),
+		})
+	}
+
+	// 解析专辑数据
+	for _, album := range data.Album.List {
+		results.Albums.List = append(results.Albums.List, SearchAlbumData{
+			AlbumID:    album.AlbumID.String(),
+			AlbumName:  album.AlbumName.String(),
+			ImgURL:     album.ImgURL.String(),
+			AuthorName: album.AuthorName.String(),
+			SongCount:  album.SongCount.Int(),
+		})
+	}
+
+	// 解析MV数据
+	for _, mv := range data.MV.List {
+		results.MVs.List = append(results.MVs.List, SearchMVData{
+			Hash:       mv.Hash.String(),
+			MVName:     mv.MVName.String(),
+			ImgURL:     mv.ImgURL.String(),
+			AuthorName: mv.AuthorName.String(),
+			Duration:   mv.Duration.Int(),
+		})
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索成功",
+		Data:    results,
 	}
 }
 
 // GetHotSearch 获取热搜列表
 func (s *SearchService) GetHotSearch() HotSearchResponse {
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return HotSearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search/hot", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/search/hot",
+		Cookie:  true,
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return HotSearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[HotSearchData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return HotSearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+	// 检查API响应是否成功
+	if !envelope.statusIs(1) {
+		return apiFailure[HotSearchData](envelope.failure("获取热搜失败"))
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return HotSearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	data, _ := decodeApiData[hotSearchData](envelope)
+
+	result := HotSearchData{
+		Timestamp: int64(data.Timestamp),
 	}
 
-	// 检查API响应是否成功
-	if status, ok := apiResponse["status"].(float64); ok && status == 1 {
-		hotSearchData := HotSearchData{}
-
-		// 解析热搜数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 解析时间戳
-			if timestamp, ok := data["timestamp"].(float64); ok {
-				hotSearchData.Timestamp = int64(timestamp)
-			}
-
-			// 解析分类列表
-			if list, ok := data["list"].([]interface{}); ok {
-				for _, categoryItem := range list {
-					if categoryMap, ok := categoryItem.(map[string]interface{}); ok {
-						category := HotSearchCategory{}
-
-						// 解析分类名称
-						if name, ok := categoryMap["name"].(string); ok {
-							category.Name = name
-						}
-
-						// 解析关键词列表
-						if keywords, ok := categoryMap["keywords"].([]interface{}); ok {
-							for _, keywordItem := range keywords {
-								if keywordMap, ok := keywordItem.(map[string]interface{}); ok {
-									keyword := HotSearchKeyword{}
-
-									if reason, ok := keywordMap["reason"].(string); ok {
-										keyword.Reason = reason
-									}
-									if jsonURL, ok := keywordMap["json_url"].(string); ok {
-										keyword.JsonURL = jsonURL
-									}
-									if jumpURL, ok := keywordMap["jumpurl"].(string); ok {
-										keyword.JumpURL = jumpURL
-									}
-									if keywordText, ok := keywordMap["keyword"].(string); ok {
-										keyword.Keyword = keywordText
-									}
-									if isCoverWord, ok := keywordMap["is_cover_word"].(float64); ok {
-										keyword.IsCoverWord = int(isCoverWord)
-									}
-									if keywordType, ok := keywordMap["type"].(float64); ok {
-										keyword.Type = int(keywordType)
-									}
-									if icon, ok := keywordMap["icon"].(float64); ok {
-										keyword.Icon = int(icon)
-									}
-
-									category.Keywords = append(category.Keywords, keyword)
-								}
-							}
-						}
-
-						hotSearchData.List = append(hotSearchData.List, category)
-					}
-				}
-			}
+	// 解析分类列表
+	for _, item := range data.List {
+		category := HotSearchCategory{
+			Name: item.Name.String(),
 		}
 
-		return HotSearchResponse{
-			Success: true,
-			Message: "获取热搜成功",
-			Data:    hotSearchData,
+		// 解析关键词列表
+		for _, keyword := range item.Keywords {
+			category.Keywords = append(category.Keywords, HotSearchKeyword{
+				Reason:      keyword.Reason.String(),
+				JsonURL:     keyword.JsonURL.String(),
+				JumpURL:     keyword.JumpURL.String(),
+				Keyword:     keyword.Keyword.String(),
+				IsCoverWord: keyword.IsCoverWord.Int(),
+				Type:        keyword.Type.Int(),
+				Icon:        keyword.Icon.Int(),
+			})
 		}
-	}
 
-	// 处理API错误
-	errorMsg := "获取热搜失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+		result.List = append(result.List, category)
 	}
 
 	return HotSearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "获取热搜成功",
+		Data:    result,
 	}
 }
 
@@ -525,122 +489,43 @@ func (s *SearchService) GetSearchSuggest(keyword string) SearchSuggestResponse {
 		}
 	}
 
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchSuggestResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search/suggest", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
+	envelope, err := apiGet[apiEnvelope](apiRequest{
+		Path:    "/search/suggest",
+		Params:  url.Values{"keywords": {keyword}},
+		Cookie:  true,
 		Timeout: 10 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	})
 	if err != nil {
-		return SearchSuggestResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[[]SearchSuggestData](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return SearchSuggestResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchSuggestResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
+	// 检查API响应是否成功
+	if !envelope.errorCodeIs(0) {
+		return apiFailure[[]SearchSuggestData](envelope.failure("获取搜索建议失败"))
 	}
 
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		var suggestList []SearchSuggestData
-
-		// 解析建议数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 解析音乐建议
-			if musicTips, ok := data["music_tip"].([]interface{}); ok {
-				for _, tip := range musicTips {
-					if tipStr, ok := tip.(string); ok {
-						suggestList = append(suggestList, SearchSuggestData{
-							Keyword: tipStr,
-							Type:    "song",
-						})
-					}
-				}
-			}
-
-			// 解析专辑建议
-			if albumTips, ok := data["album_tip"].([]interface{}); ok {
-				for _, tip := range albumTips {
-					if tipStr, ok := tip.(string); ok {
-						suggestList = append(suggestList, SearchSuggestData{
-							Keyword: tipStr,
-							Type:    "album",
-						})
-					}
-				}
-			}
-
-			// 解析MV建议
-			if mvTips, ok := data["mv_tip"].([]interface{}); ok {
-				for _, tip := range mvTips {
-					if tipStr, ok := tip.(string); ok {
-						suggestList = append(suggestList, SearchSuggestData{
-							Keyword: tipStr,
-							Type:    "mv",
-						})
-					}
-				}
-			}
-		}
+	data, _ := decodeApiData[searchSuggestData](envelope)
 
-		return SearchSuggestResponse{
-			Success: true,
-			Message: "获取搜索建议成功",
-			Data:    suggestList,
-		}
+	var suggestList []SearchSuggestData
+	for _, tip := range data.MusicTip {
+		suggestList = append(suggestList, SearchSuggestData{Keyword: tip, Type: "song"})
 	}
-
-	// 处理API错误
-	errorMsg := "获取搜索建议失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	for _, tip := range data.AlbumTip {
+		suggestList = append(suggestList, SearchSuggestData{Keyword: tip, Type: "album"})
+	}
+	for _, tip := range data.MVTip {
+		suggestList = append(suggestList, SearchSuggestData{Keyword: tip, Type: "mv"})
 	}
 
 	return SearchSuggestResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "获取搜索建议成功",
+		Data:    suggestList,
 	}
 }
 
 // SearchSongs 搜索歌曲
+// 离线时只在已缓存和本地音乐中搜索
 func (s *SearchService) SearchSongs(keyword string, page int, pageSize int) SearchResponse {
 	if keyword == "" {
 		return SearchResponse{
@@ -649,132 +534,40 @@ func (s *SearchService) SearchSongs(keyword string, page int, pageSize int) Sear
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("type", "song")
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+	if GlobalBackendManager.IsOffline() {
+		return searchOffline(keyword, page, pageSize)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := searchByType[searchSongItem](keyword, "song", page, pageSize, "搜索歌曲失败")
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
+		if isOfflineError(err) {
+			return searchOffline(keyword, page, pageSize)
 		}
+		return apiFailure[SearchResults](err)
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
+	results := SearchResults{}
+	results.Songs.Total = data.Total.Int()
+	for _, song := range data.Lists {
+		songData := SearchSongData{
+			Hash:       song.FileHash.String(),
+			SongName:   song.OriSongName.String(),
+			TimeLength: song.Duration.Int(),
+			AlbumName:  song.AlbumName.String(),
+			AlbumID:    song.AlbumID.String(),
+			AuthorName: song.SingerName.String(),
+			UnionCover: song.Image.String(),
 		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析歌曲数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 提取总数
-			if total, ok := data["total"].(float64); ok {
-				results.Songs.Total = int(total)
-			}
-
-			if lists, ok := data["lists"].([]interface{}); ok {
-				for _, listItem := range lists {
-					if song, ok := listItem.(map[string]interface{}); ok {
-						songData := SearchSongData{}
-						if hash, ok := song["FileHash"].(string); ok {
-							songData.Hash = hash
-						}
-						if songname, ok := song["OriSongName"].(string); ok {
-							songData.SongName = songname
-						}
-						if filename, ok := song["FileName"].(string); ok {
-							if extName, ok := song["ExtName"].(string); ok {
-								songData.FileName = filename + extName
-							}
-						}
-						if timelength, ok := song["Duration"].(float64); ok {
-							songData.TimeLength = int(timelength)
-						}
-						if albumname, ok := song["AlbumName"].(string); ok {
-							songData.AlbumName = albumname
-						}
-						if albumID, ok := song["AlbumID"].(string); ok {
-							songData.AlbumID = albumID
-						}
-						if authorName, ok := song["SingerName"].(string); ok {
-							songData.AuthorName = authorName
-						}
-						if unionCover, ok := song["Image"].(string); ok {
-							songData.UnionCover = unionCover
-						}
-						results.Songs.List = append(results.Songs.List, songData)
-					}
-				}
-			}
-		}
-		fmt.Println("曲：", results.Songs)
-		return SearchResponse{
-			Success: true,
-			Message: "搜索歌曲成功",
-			Data:    results,
+		if song.FileName != "" {
+			songData.FileName = song.FileName.String() + song.ExtName.String()
 		}
-	}
-
-	// 处理API错误
-	errorMsg := "搜索歌曲失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+		results.Songs.List = append(results.Songs.List, songData)
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索歌曲成功",
+		Data:    results,
 	}
 }
 
@@ -787,118 +580,26 @@ func (s *SearchService) SearchArtists(keyword string, page int, pageSize int) Se
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("type", "author")
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
+	data, err := searchByType[searchArtistItem](keyword, "author", page, pageSize, "搜索艺人失败")
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
+		return apiFailure[SearchResults](err)
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析艺人数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 提取总数
-			if total, ok := data["total"].(float64); ok {
-				results.Artists.Total = int(total)
-			}
-
-			if lists, ok := data["lists"].([]interface{}); ok {
-				for _, listItem := range lists {
-					if artist, ok := listItem.(map[string]interface{}); ok {
-						artistData := SearchArtistData{}
-						if authorID, ok := artist["SingerID"].(string); ok {
-							artistData.AuthorID = authorID
-						}
-						if authorName, ok := artist["AuthorName"].(string); ok {
-							artistData.AuthorName = authorName
-						}
-						if avatar, ok := artist["Avatar"].(string); ok {
-							artistData.Avatar = avatar
-						}
-						if songCount, ok := artist["AudioCount"].(float64); ok {
-							artistData.SongCount = int(songCount)
-						}
-						results.Artists.List = append(results.Artists.List, artistData)
-					}
-				}
-			}
-		}
-
-		return SearchResponse{
-			Success: true,
-			Message: "搜索艺人成功",
-			Data:    results,
-		}
-	}
-
-	// 处理API错误
-	errorMsg := "搜索艺人失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	results := SearchResults{}
+	results.Artists.Total = data.Total.Int()
+	for _, artist := range data.Lists {
+		results.Artists.List = append(results.Artists.List, SearchArtistData{
+			AuthorID:   artist.SingerID.String(),
+			AuthorName: artist.AuthorName.String(),
+			Avatar:     artist.Avatar.String(),
+			SongCount:  artist.AudioCount.Int(),
+		})
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索艺人成功",
+		Data:    results,
 	}
 }
 
@@ -911,124 +612,28 @@ func (s *SearchService) SearchPlaylists(keyword string, page int, pageSize int)
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("type", "special")
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := searchByType[searchPlaylistItem](keyword, "special", page, pageSize, "搜索歌单失败")
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+		return apiFailure[SearchResults](err)
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析歌单数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 提取总数
-			if total, ok := data["total"].(float64); ok {
-				results.Playlists.Total = int(total)
-			}
-
-			if lists, ok := data["lists"].([]interface{}); ok {
-				for _, listItem := range lists {
-					if playlist, ok := listItem.(map[string]interface{}); ok {
-						playlistData := SearchPlaylistData{}
-						if specialID, ok := playlist["gid"].(string); ok {
-							playlistData.SpecialID = specialID
-						}
-						if specialName, ok := playlist["specialname"].(string); ok {
-							playlistData.SpecialName = specialName
-						}
-						if imgURL, ok := playlist["img"].(string); ok {
-							playlistData.ImgURL = imgURL
-						}
-						if playCount, ok := playlist["play_count"].(float64); ok {
-							playlistData.PlayCount = int(playCount)
-						}
-						if songCount, ok := playlist["song_count"].(float64); ok {
-							playlistData.SongCount = int(songCount)
-						}
-						if authorName, ok := playlist["nickname"].(string); ok {
-							playlistData.AuthorName = authorName
-						}
-						results.Playlists.List = append(results.Playlists.List, playlistData)
-					}
-				}
-			}
-		}
-
-		return SearchResponse{
-			Success: true,
-			Message: "搜索歌单成功",
-			Data:    results,
-		}
-	}
-
-	// 处理API错误
-	errorMsg := "搜索歌单失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	results := SearchResults{}
+	results.Playlists.Total = data.Total.Int()
+	for _, playlist := range data.Lists {
+		results.Playlists.List = append(results.Playlists.List, SearchPlaylistData{
+			SpecialID:   playlist.GID.String(),
+			SpecialName: playlist.SpecialName.String(),
+			ImgURL:      playlist.Img.String(),
+			PlayCount:   playlist.PlayCount.Int(),
+			SongCount:   playlist.SongCount.Int(),
+			AuthorName:  playlist.Nickname.String(),
+		})
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索歌单成功",
+		Data:    results,
 	}
 }
 
@@ -1041,123 +646,27 @@ func (s *SearchService) SearchAlbums(keyword string, page int, pageSize int) Sea
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("type", "album")
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := searchByType[searchAlbumItem](keyword, "album", page, pageSize, "搜索专辑失败")
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
+		return apiFailure[SearchResults](err)
 	}
 
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析专辑数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 提取总数
-			if total, ok := data["total"].(float64); ok {
-				results.Albums.Total = int(total)
-			}
-
-			if lists, ok := data["lists"].([]interface{}); ok {
-				for _, listItem := range lists {
-					if album, ok := listItem.(map[string]interface{}); ok {
-						albumData := SearchAlbumData{}
-						if albumID, ok := album["albumid"].(float64); ok {
-							albumData.AlbumID = fmt.Sprintf("%.0f", albumID)
-						} else if albumIDStr, ok := album["albumid"].(string); ok {
-							albumData.AlbumID = albumIDStr
-						}
-						if albumName, ok := album["albumname"].(string); ok {
-							albumData.AlbumName = albumName
-						}
-						if imgURL, ok := album["img"].(string); ok {
-							albumData.ImgURL = imgURL
-						}
-						if authorName, ok := album["singer"].(string); ok {
-							albumData.AuthorName = authorName
-						}
-						if songCount, ok := album["songcount"].(float64); ok {
-							albumData.SongCount = int(songCount)
-						}
-						results.Albums.List = append(results.Albums.List, albumData)
-					}
-				}
-			}
-		}
-
-		return SearchResponse{
-			Success: true,
-			Message: "搜索专辑成功",
-			Data:    results,
-		}
-	}
-
-	// 处理API错误
-	errorMsg := "搜索专辑失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	results := SearchResults{}
+	results.Albums.Total = data.Total.Int()
+	for _, album := range data.Lists {
+		results.Albums.List = append(results.Albums.List, SearchAlbumData{
+			AlbumID:    album.AlbumID.String(),
+			AlbumName:  album.AlbumName.String(),
+			ImgURL:     album.Img.String(),
+			AuthorName: album.Singer.String(),
+			SongCount:  album.SongCount.Int(),
+		})
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索专辑成功",
+		Data:    results,
 	}
 }
 
@@ -1170,120 +679,26 @@ func (s *SearchService) SearchMVs(keyword string, page int, pageSize int) Search
 		}
 	}
 
-	if page <= 0 {
-		page = 1
-	}
-	if pageSize <= 0 {
-		pageSize = 30
-	}
-
-	// 读取cookie
-	cookie, err := s.readCookieFromFile()
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取cookie失败: %v", err),
-		}
-	}
-
-	// 构建请求URL
-	requestURL := fmt.Sprintf("%s/search", baseApi)
-
-	// 构建查询参数
-	queryParams := url.Values{}
-	queryParams.Add("keywords", keyword)
-	queryParams.Add("type", "mv")
-	queryParams.Add("page", fmt.Sprintf("%d", page))
-	queryParams.Add("pagesize", fmt.Sprintf("%d", pageSize))
-	queryParams.Add("cookie", cookie)
-
-	// 添加查询参数到URL
-	requestURL += "?" + queryParams.Encode()
-
-	// 创建HTTP客户端，设置超时
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-
-	// 发送GET请求
-	resp, err := client.Get(requestURL)
-	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("网络请求失败: %v", err),
-		}
-	}
-	defer resp.Body.Close()
-
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	data, err := searchByType[searchMVItem](keyword, "mv", page, pageSize, "搜索MV失败")
 	if err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("读取响应失败: %v", err),
-		}
-	}
-
-	// 解析JSON响应
-	var apiResponse map[string]interface{}
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return SearchResponse{
-			Success: false,
-			Message: fmt.Sprintf("解析响应失败: %v", err),
-		}
-	}
-
-	// 检查API响应是否成功
-	if errorCode, ok := apiResponse["error_code"].(float64); ok && errorCode == 0 {
-		results := SearchResults{}
-
-		// 解析MV数据
-		if data, ok := apiResponse["data"].(map[string]interface{}); ok {
-			// 提取总数
-			if total, ok := data["total"].(float64); ok {
-				results.MVs.Total = int(total)
-			}
-
-			if lists, ok := data["lists"].([]interface{}); ok {
-				for _, listItem := range lists {
-					if mv, ok := listItem.(map[string]interface{}); ok {
-						mvData := SearchMVData{}
-						if hash, ok := mv["MvHash"].(string); ok {
-							mvData.Hash = hash
-						}
-						if mvName, ok := mv["MvName"].(string); ok {
-							mvData.MVName = mvName
-						}
-						if imgURL, ok := mv["ThumbGif"].(string); ok {
-							mvData.ImgURL = imgURL
-						}
-						if authorName, ok := mv["SingerName"].(string); ok {
-							mvData.AuthorName = authorName
-						}
-						if duration, ok := mv["Duration"].(float64); ok {
-							mvData.Duration = int(duration)
-						}
-						results.MVs.List = append(results.MVs.List, mvData)
-					}
-				}
-			}
-		}
-
-		return SearchResponse{
-			Success: true,
-			Message: "搜索MV成功",
-			Data:    results,
-		}
+		return apiFailure[SearchResults](err)
 	}
 
-	// 处理API错误
-	errorMsg := "搜索MV失败"
-	if msg, ok := apiResponse["error_msg"].(string); ok {
-		errorMsg = msg
+	results := SearchResults{}
+	results.MVs.Total = data.Total.Int()
+	for _, mv := range data.Lists {
+		results.MVs.List = append(results.MVs.List, SearchMVData{
+			Hash:       mv.MvHash.String(),
+			MVName:     mv.MvName.String(),
+			ImgURL:     mv.ThumbGif.String(),
+			AuthorName: mv.SingerName.String(),
+			Duration:   mv.Duration.Int(),
+		})
 	}
 
 	return SearchResponse{
-		Success: false,
-		Message: errorMsg,
+		Success: true,
+		Message: "搜索MV成功",
+		Data:    results,
 	}
 }
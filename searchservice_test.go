@@ -0,0 +1,186 @@
+package main
+
+import (
+	"testing"
+
+	"wmplayer/internal/mockapi"
+)
+
+func TestSearchComplex(t *testing.T) {
+	srv := newMockAPI(t)
+	s := NewSearchService()
+
+	resp := s.Search("晴天", 0, 0)
+	if !resp.Success {
+		t.Fatalf("Search failed: %s", resp.Message)
+	}
+	if len(resp.Data.Songs.List) != 1 || resp.Data.Songs.List[0].Hash != "A1B2C3D4E5F60718293A4B5C6D7E8F90" {
+		t.Errorf("songs = %+v", resp.Data.Songs.List)
+	}
+	if got := resp.Data.Albums.List; len(got) != 1 || got[0].AlbumID != "960399" || got[0].SongCount != 11 {
+		t.Errorf("albums = %+v", got)
+	}
+	if got := resp.Data.Artists.List; len(got) != 1 || got[0].AuthorID != "3520" {
+		t.Errorf("artists = %+v", got)
+	}
+	if got := resp.Data.Playlists.List; len(got) != 1 || got[0].SpecialID != "collection_3_1234567_8_0" {
+		t.Errorf("playlists = %+v", got)
+	}
+	if len(resp.Data.MVs.List) != 0 {
+		t.Errorf("mvs = %+v, want empty", resp.Data.MVs.List)
+	}
+
+	req, _ := srv.LastRequest("/search/complex")
+	if req.Query.Get("page") != "1" || req.Query.Get("pagesize") != "30" {
+		t.Errorf("paging = %v, want defaults 1/30", req.Query)
+	}
+	assertCookieSent(t, srv, "/search/complex", true)
+}
+
+func TestSearchSongs(t *testing.T) {
+	srv := newMockAPI(t)
+	s := NewSearchService()
+
+	resp := s.SearchSongs("周杰伦", 2, 10)
+	if !resp.Success {
+		t.Fatalf("SearchSongs failed: %s", resp.Message)
+	}
+	if resp.Data.Songs.Total != 2 || len(resp.Data.Songs.List) != 2 {
+		t.Fatalf("songs = %+v", resp.Data.Songs)
+	}
+
+	first, second := resp.Data.Songs.List[0], resp.Data.Songs.List[1]
+	if first.SongName != "晴天" || first.FileName != "周杰伦 - 晴天.mp3" || first.TimeLength != 269 {
+		t.Errorf("first = %+v", first)
+	}
+	// Duration 与 AlbumID 的类型与第一首不同，应同样解析
+	if second.TimeLength != 299 || second.AlbumID != "979856" {
+		t.Errorf("second = %+v", second)
+	}
+
+	req, _ := srv.LastRequest("/search")
+	if req.Query.Get("type") != "song" || req.Query.Get("page") != "2" || req.Query.Get("pagesize") != "10" {
+		t.Errorf("query = %v", req.Query)
+	}
+}
+
+func TestSearchByOtherTypes(t *testing.T) {
+	newMockAPI(t)
+	s := NewSearchService()
+
+	if resp := s.SearchArtists("周杰伦", 1, 30); !resp.Success || len(resp.Data.Artists.List) != 1 ||
+		resp.Data.Artists.List[0].AuthorName != "周杰伦" || resp.Data.Artists.List[0].SongCount != 812 {
+		t.Errorf("SearchArtists = %+v", resp)
+	}
+	if resp := s.SearchPlaylists("周杰伦", 1, 30); !resp.Success || len(resp.Data.Playlists.List) != 1 ||
+		resp.Data.Playlists.List[0].PlayCount != 1520000 {
+		t.Errorf("SearchPlaylists = %+v", resp)
+	}
+	if resp := s.SearchAlbums("周杰伦", 1, 30); !resp.Success || len(resp.Data.Albums.List) != 1 ||
+		resp.Data.Albums.List[0].AlbumID != "960399" {
+		t.Errorf("SearchAlbums = %+v", resp)
+	}
+	if resp := s.SearchMVs("周杰伦", 1, 30); !resp.Success || len(resp.Data.MVs.List) != 1 ||
+		resp.Data.MVs.List[0].Hash != "9F8E7D6C5B4A39281706F5E4D3C2B1A0" {
+		t.Errorf("SearchMVs = %+v", resp)
+	}
+}
+
+func TestSearchEmptyKeyword(t *testing.T) {
+	srv := newMockAPI(t)
+
+	if resp := NewSearchService().SearchSongs("", 1, 30); resp.Success {
+		t.Error("empty keyword should fail")
+	}
+	if len(srv.Requests()) != 0 {
+		t.Error("empty keyword should not reach the API")
+	}
+}
+
+func TestSearchUpstreamError(t *testing.T) {
+	srv := newMockAPI(t)
+	srv.HandleJSON("/search", `{"status":0,"error_code":20028,"error_msg":"搜索服务繁忙"}`)
+
+	resp := NewSearchService().SearchSongs("晴天", 1, 30)
+	if resp.Success || resp.ErrorCode != 20028 || resp.Message != "搜索服务繁忙" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestGetHotSearch(t *testing.T) {
+	newMockAPI(t)
+
+	resp := NewSearchService().GetHotSearch()
+	if !resp.Success {
+		t.Fatalf("GetHotSearch failed: %s", resp.Message)
+	}
+	if resp.Data.Timestamp != 1718000000 || len(resp.Data.List) != 1 {
+		t.Fatalf("data = %+v", resp.Data)
+	}
+	keywords := resp.Data.List[0].Keywords
+	if len(keywords) != 2 || keywords[0].Keyword != "晴天" || keywords[1].IsCoverWord != 1 {
+		t.Errorf("keywords = %+v", keywords)
+	}
+}
+
+func TestGetSearchSuggest(t *testing.T) {
+	newMockAPI(t)
+
+	resp := NewSearchService().GetSearchSuggest("晴")
+	if !resp.Success {
+		t.Fatalf("GetSearchSuggest failed: %s", resp.Message)
+	}
+	want := []SearchSuggestData{
+		{Keyword: "晴天", Type: "song"},
+		{Keyword: "晴天 周杰伦", Type: "song"},
+		{Keyword: "叶惠美", Type: "album"},
+	}
+	if len(resp.Data) != len(want) {
+		t.Fatalf("suggestions = %+v", resp.Data)
+	}
+	for i := range want {
+		if resp.Data[i] != want[i] {
+			t.Errorf("suggestion %d = %+v, want %+v", i, resp.Data[i], want[i])
+		}
+	}
+}
+
+func TestSearchSongsOfflineFallback(t *testing.T) {
+	newMockAPI(t)
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+
+	// 播放历史中只有已缓存的歌曲可以离线播放
+	history := &PlayHistoryService{}
+	history.AddPlayHistory(AddPlayHistoryRequest{Hash: "CACHED", SongName: "晴天", ArtistName: "周杰伦"})
+	history.AddPlayHistory(AddPlayHistoryRequest{Hash: "MISSING", SongName: "晴天 (Live)", ArtistName: "周杰伦"})
+	fileName := c.getCacheKey("CACHED", AudioQualityHigh) + ".mp3"
+	writeCacheFile(t, c.mp3Dir, fileName, 10)
+	c.audioCache.Record(fileName, "CACHED", AudioQualityHigh)
+
+	local := &LocalMusicService{}
+	if err := local.cacheMusicFiles([]LocalMusicFile{
+		{Hash: "abc", Filename: "周杰伦 - 晴天.flac", Title: "晴天", Artist: "周杰伦"},
+		{Hash: "def", Filename: "七里香.mp3", Title: "七里香", Artist: "周杰伦"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 后端不可达时自动退回离线搜索
+	dead := mockapi.New()
+	dead.Close()
+	GlobalBackendManager.Configure([]string{dead.URL}, "test")
+
+	resp := NewSearchService().SearchSongs("周杰伦 晴天", 1, 30)
+	if !resp.Success || resp.Data.Songs.Total != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if got := resp.Data.Songs.List; got[0].Hash != "CACHED" || got[1].Hash != "local-abc" {
+		t.Errorf("songs = %+v", got)
+	}
+
+	// 之后的搜索不再请求后端
+	if resp := NewSearchService().Search("七里香", 1, 30); !resp.Success || len(resp.Data.Songs.List) != 1 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
@@ -31,6 +31,12 @@ type Settings struct {
 	Privacy PrivacySettings `json:"privacy"`
 	// 应用行为设置
 	Behavior BehaviorSettings `json:"behavior"`
+	// 网络设置
+	Network NetworkSettings `json:"network"`
+	// 缓存设置
+	Cache CacheSettings `json:"cache"`
+	// 本地音乐设置
+	LocalMusic LocalMusicSettings `json:"localMusic"`
 }
 
 // PlaybackSettings 播放设置
@@ -57,6 +63,11 @@ type InterfaceSettings struct {
 	ShowLyrics   bool   `json:"showLyrics"`
 	ShowSpectrum bool   `json:"showSpectrum"`
 	MiniPlayer   bool   `json:"miniPlayer"`
+	// 歌词翻译和音译（罗马音）层，分别控制主界面和桌面歌词
+	LyricsTranslation     bool `json:"lyricsTranslation"`
+	LyricsRomanization    bool `json:"lyricsRomanization"`
+	OSDLyricsTranslation  bool `json:"osdLyricsTranslation"`
+	OSDLyricsRomanization bool `json:"osdLyricsRomanization"`
 }
 
 // DownloadSettings 下载设置
@@ -91,6 +102,25 @@ type BehaviorSettings struct {
 	AutoStart      bool   `json:"autoStart"`
 }
 
+// NetworkSettings 网络设置
+type NetworkSettings struct {
+	ApiBaseURL      string   `json:"apiBaseUrl"`      // 后端服务地址
+	FallbackApiURLs []string `json:"fallbackApiUrls"` // 备用后端地址，按顺序尝试
+	OfflineMode     bool     `json:"offlineMode"`     // 离线模式：只播放已缓存和本地音乐
+	LanAccess       bool     `json:"lanAccess"`       // 允许局域网设备访问本地缓存服务（仍需令牌），重启后生效
+}
+
+// CacheSettings 缓存设置
+type CacheSettings struct {
+	MaxSizeMB int `json:"maxSizeMB"` // 音频缓存上限（MB），0表示不限制
+}
+
+// LocalMusicSettings 本地音乐设置
+type LocalMusicSettings struct {
+	LyricsFolder string `json:"lyricsFolder"` // 集中存放歌词文件的文件夹，按音频文件名或“歌手 - 歌名”查找
+	OnlineLyrics bool   `json:"onlineLyrics"` // 没有歌词文件时按歌名、歌手和时长在线搜索
+}
+
 // getSettingsPath 获取设置文件路径
 func (s *SettingsService) getSettingsPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -130,6 +160,11 @@ func (s *SettingsService) getDefaultSettings() Settings {
 			ShowLyrics:   true,
 			ShowSpectrum: false,
 			MiniPlayer:   false,
+			// 默认显示翻译，音译按需开启
+			LyricsTranslation:     true,
+			LyricsRomanization:    false,
+			OSDLyricsTranslation:  true,
+			OSDLyricsRomanization: false,
 		},
 		Download: DownloadSettings{
 			DownloadPath:   "",
@@ -155,6 +190,16 @@ func (s *SettingsService) getDefaultSettings() Settings {
 			StartMinimized: false,
 			AutoStart:      false,
 		},
+		Network: NetworkSettings{
+			ApiBaseURL:      defaultBaseApi,
+			FallbackApiURLs: []string{},
+		},
+		Cache: CacheSettings{
+			MaxSizeMB: defaultCacheMaxSizeMB,
+		},
+		LocalMusic: LocalMusicSettings{
+			OnlineLyrics: true,
+		},
 	}
 }
 
@@ -187,7 +232,8 @@ func (s *SettingsService) LoadSettings() (*ApiResponse[Settings], error) {
 		}, err
 	}
 	
-	var settings Settings
+	// 以默认设置为基础，旧版本设置文件中缺少的分组保持默认值
+	settings := s.getDefaultSettings()
 	if err := json.Unmarshal(data, &settings); err != nil {
 		// 如果解析失败，返回默认设置
 		defaultSettings := s.getDefaultSettings()
@@ -231,6 +277,18 @@ func (s *SettingsService) SaveSettings(settings Settings) (*ApiResponse[bool], e
 			Message: fmt.Sprintf("保存设置文件失败: %v", err),
 		}, err
 	}
+
+	// 后端地址可能已修改，立即生效
+	GlobalBackendManager.ApplySettings(settings.Network)
+	if cacheService := GetCacheService(); cacheService != nil {
+		cacheService.ApplyCacheSettings(settings.Cache)
+		cacheService.ApplyNetworkSettings(settings.Network)
+		cacheService.ApplyQualitySettings(settings.Quality)
+		cacheService.ApplyLocalMusicSettings(settings.LocalMusic)
+	}
+	if appState := GetAppState(); appState != nil {
+		appState.ApplyInterfaceSettings(settings.Interface)
+	}
 	
 	return &ApiResponse[bool]{
 		Success: true,
@@ -0,0 +1,757 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 智能歌单条件的字段
+const (
+	SmartFieldSource     = "source"      // 歌曲来源：library、favorite 或 history
+	SmartFieldTitle      = "title"       // 歌名
+	SmartFieldArtist     = "artist"      // 歌手
+	SmartFieldAlbum      = "album"       // 专辑
+	SmartFieldGenre      = "genre"       // 流派，只有本地音乐有
+	SmartFieldFormat     = "format"      // 文件格式，例如 flac，只有本地音乐有
+	SmartFieldYear       = "year"        // 年份，只有本地音乐有
+	SmartFieldDuration   = "duration"    // 时长（秒）
+	SmartFieldPlayCount  = "play_count"  // 播放次数，设置 days 时只统计最近几天
+	SmartFieldLastPlayed = "last_played" // 上次播放时间
+	SmartFieldAdded      = "added"       // 加入音乐库的时间
+)
+
+// 智能歌单的歌曲来源
+const (
+	SmartSourceLibrary  = "library"  // 本地音乐库
+	SmartSourceFavorite = "favorite" // 我喜欢的歌曲
+	SmartSourceHistory  = "history"  // 播放历史
+)
+
+// 智能歌单条件的比较方式
+const (
+	SmartOpIs          = "is"
+	SmartOpIsNot       = "is_not"
+	SmartOpContains    = "contains"
+	SmartOpNotContains = "not_contains"
+	SmartOpEqual       = "eq"
+	SmartOpNotEqual    = "ne"
+	SmartOpGreater     = "gt"
+	SmartOpGreaterOrEq = "gte"
+	SmartOpLess        = "lt"
+	SmartOpLessOrEq    = "lte"
+	SmartOpInLast      = "in_last"     // 最近 N 天内
+	SmartOpNotInLast   = "not_in_last" // 最近 N 天内没有，从未发生也算
+)
+
+// 多个条件的组合方式
+const (
+	SmartMatchAll = "all"
+	SmartMatchAny = "any"
+)
+
+// 智能歌单的排序方式，为空时按音乐库、我喜欢、播放历史的顺序
+const (
+	SmartSortPlayCount  = "play_count"  // 播放次数从多到少
+	SmartSortLastPlayed = "last_played" // 最近播放的在前
+	SmartSortAdded      = "added"       // 最近加入的在前
+	SmartSortTitle      = "title"
+	SmartSortArtist     = "artist"
+)
+
+// 字段的类型决定可以使用的比较方式
+var (
+	smartTextOps   = []string{SmartOpIs, SmartOpIsNot, SmartOpContains, SmartOpNotContains}
+	smartNumberOps = []string{SmartOpEqual, SmartOpNotEqual, SmartOpGreater, SmartOpGreaterOrEq, SmartOpLess, SmartOpLessOrEq}
+	smartTimeOps   = []string{SmartOpInLast, SmartOpNotInLast}
+
+	smartFieldOps = map[string][]string{
+		SmartFieldSource:     {SmartOpIs, SmartOpIsNot},
+		SmartFieldTitle:      smartTextOps,
+		SmartFieldArtist:     smartTextOps,
+		SmartFieldAlbum:      smartTextOps,
+		SmartFieldGenre:      smartTextOps,
+		SmartFieldFormat:     smartTextOps,
+		SmartFieldYear:       smartNumberOps,
+		SmartFieldDuration:   smartNumberOps,
+		SmartFieldPlayCount:  smartNumberOps,
+		SmartFieldLastPlayed: smartTimeOps,
+		SmartFieldAdded:      smartTimeOps,
+	}
+
+	smartSorts = []string{"", SmartSortPlayCount, SmartSortLastPlayed, SmartSortAdded, SmartSortTitle, SmartSortArtist}
+)
+
+// 智能歌单的错误
+var (
+	errSmartPlaylistNotFound = errors.New("智能歌单不存在")
+	errSmartPlaylistRules    = errors.New("智能歌单至少需要一个条件")
+)
+
+// SmartPlaylistRule 智能歌单的一个条件
+type SmartPlaylistRule struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`          // 时间条件的值为天数
+	Days     int    `json:"days,omitempty"` // 播放次数只统计最近几天，0 为全部
+}
+
+// SmartPlaylistRequest 创建或修改智能歌单请求
+type SmartPlaylistRequest struct {
+	Name  string              `json:"name"`
+	Match string              `json:"match"` // all 或 any，为空时为 all
+	Rules []SmartPlaylistRule `json:"rules"`
+	Sort  string              `json:"sort"`
+	Limit int                 `json:"limit"` // 最多歌曲数量，0 为不限制
+}
+
+// SmartPlaylist 按条件从播放历史、我喜欢的歌曲和本地音乐库中生成的歌单
+// 只保存条件，歌曲在刷新时重新计算
+type SmartPlaylist struct {
+	ID string `json:"id"`
+	SmartPlaylistRequest
+	Count       int       `json:"count"`        // 上次刷新时的歌曲数量
+	RefreshTime time.Time `json:"refresh_time"` // 上次刷新的时间，没有刷新过时为零值
+	CreateTime  time.Time `json:"create_time"`
+	UpdateTime  time.Time `json:"update_time"`
+}
+
+// SmartPlaylistSongs 刷新智能歌单的结果
+type SmartPlaylistSongs struct {
+	Playlist SmartPlaylist        `json:"playlist"`
+	Songs    []PlayerPlaylistSong `json:"songs"`
+}
+
+// SmartPlaylistsResponse 智能歌单列表响应结构
+type SmartPlaylistsResponse = ApiResponse[[]SmartPlaylist]
+
+// SmartPlaylistResponse 智能歌单响应结构
+type SmartPlaylistResponse = ApiResponse[SmartPlaylist]
+
+// SmartPlaylistSongsResponse 智能歌单歌曲响应结构
+type SmartPlaylistSongsResponse = ApiResponse[SmartPlaylistSongs]
+
+// normalize 去掉多余的空白并检查条件，返回整理后的请求
+func (r SmartPlaylistRequest) normalize() (SmartPlaylistRequest, error) {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return r, errLocalPlaylistName
+	}
+	if r.Match == "" {
+		r.Match = SmartMatchAll
+	}
+	if r.Match != SmartMatchAll && r.Match != SmartMatchAny {
+		return r, fmt.Errorf("无效的条件组合方式: %s", r.Match)
+	}
+	if !slices.Contains(smartSorts, r.Sort) {
+		return r, fmt.Errorf("无效的排序方式: %s", r.Sort)
+	}
+	if r.Limit < 0 {
+		r.Limit = 0
+	}
+	if len(r.Rules) == 0 {
+		return r, errSmartPlaylistRules
+	}
+
+	rules := make([]SmartPlaylistRule, len(r.Rules))
+	for i, rule := range r.Rules {
+		rule.Value = strings.TrimSpace(rule.Value)
+		ops, ok := smartFieldOps[rule.Field]
+		if !ok {
+			return r, fmt.Errorf("无效的条件字段: %s", rule.Field)
+		}
+		if !slices.Contains(ops, rule.Operator) {
+			return r, fmt.Errorf("条件 %s 不支持比较方式 %s", rule.Field, rule.Operator)
+		}
+		switch {
+		case rule.Field == SmartFieldSource:
+			if rule.Value != SmartSourceLibrary && rule.Value != SmartSourceFavorite && rule.Value != SmartSourceHistory {
+				return r, fmt.Errorf("无效的歌曲来源: %s", rule.Value)
+			}
+		case slices.Equal(ops, smartTextOps):
+			if rule.Value == "" {
+				return r, fmt.Errorf("条件 %s 的值不能为空", rule.Field)
+			}
+		default:
+			if n, err := strconv.Atoi(rule.Value); err != nil || n < 0 {
+				return r, fmt.Errorf("条件 %s 的值必须是非负整数: %s", rule.Field, rule.Value)
+			}
+		}
+		if rule.Field != SmartFieldPlayCount || rule.Days < 0 {
+			rule.Days = 0
+		}
+		rules[i] = rule
+	}
+	r.Rules = rules
+	return r, nil
+}
+
+// smartSong 参与计算的歌曲，同一个hash在多个来源中出现时合并
+type smartSong struct {
+	song     PlayerPlaylistSong
+	file     *LocalMusicFile    // 本地音乐库中的文件
+	favorite bool               // 在我喜欢的歌曲中
+	record   *PlayHistoryRecord // 播放历史
+}
+
+// addedTime 加入音乐库的时间，不是本地音乐时为零值
+func (s *smartSong) addedTime() time.Time {
+	if s.file == nil {
+		return time.Time{}
+	}
+	added := s.file.AddedAt
+	if added == 0 {
+		added = s.file.LastModified
+	}
+	return time.Unix(added, 0)
+}
+
+// lastPlayed 上次播放的时间，没有播放过时为零值
+func (s *smartSong) lastPlayed() time.Time {
+	if s.record == nil {
+		return time.Time{}
+	}
+	return s.record.LastPlayTime
+}
+
+// playCount 播放次数，days 大于 0 时只统计最近几天
+func (s *smartSong) playCount(days int, now time.Time) int {
+	if s.record == nil {
+		return 0
+	}
+	if days > 0 {
+		return s.record.PlaysSince(now.AddDate(0, 0, -days))
+	}
+	return s.record.PlayCount
+}
+
+// text 文本字段的值
+func (s *smartSong) text(field string) string {
+	switch field {
+	case SmartFieldTitle:
+		return s.song.SongName
+	case SmartFieldArtist:
+		return s.song.ArtistName
+	case SmartFieldAlbum:
+		return s.song.AlbumName
+	}
+	if s.file == nil {
+		return ""
+	}
+	switch field {
+	case SmartFieldGenre:
+		return s.file.Genre
+	case SmartFieldFormat:
+		return strings.TrimPrefix(s.file.Format, ".")
+	}
+	return ""
+}
+
+// matchRule 判断歌曲是否满足条件，条件已经检查过
+func (s *smartSong) matchRule(rule SmartPlaylistRule, now time.Time) bool {
+	switch rule.Field {
+	case SmartFieldSource:
+		var in bool
+		switch rule.Value {
+		case SmartSourceLibrary:
+			in = s.file != nil
+		case SmartSourceFavorite:
+			in = s.favorite
+		case SmartSourceHistory:
+			in = s.record != nil
+		}
+		return in == (rule.Operator == SmartOpIs)
+
+	case SmartFieldTitle, SmartFieldArtist, SmartFieldAlbum, SmartFieldGenre, SmartFieldFormat:
+		text := strings.ToLower(s.text(rule.Field))
+		value := strings.ToLower(rule.Value)
+		if rule.Field == SmartFieldFormat {
+			value = strings.TrimPrefix(value, ".")
+		}
+		switch rule.Operator {
+		case SmartOpIs:
+			return text == value
+		case SmartOpIsNot:
+			return text != value
+		case SmartOpContains:
+			return strings.Contains(text, value)
+		case SmartOpNotContains:
+			return !strings.Contains(text, value)
+		}
+
+	case SmartFieldLastPlayed, SmartFieldAdded:
+		at := s.lastPlayed()
+		if rule.Field == SmartFieldAdded {
+			at = s.addedTime()
+		}
+		days, _ := strconv.Atoi(rule.Value)
+		recent := !at.IsZero() && at.After(now.AddDate(0, 0, -days))
+		return recent == (rule.Operator == SmartOpInLast)
+
+	default:
+		var n int
+		switch rule.Field {
+		case SmartFieldYear:
+			if s.file != nil {
+				n = s.file.Year
+			}
+		case SmartFieldDuration:
+			n = s.song.Duration
+		case SmartFieldPlayCount:
+			n = s.playCount(rule.Days, now)
+		}
+		// 年份和时长未知的歌曲不满足任何比较
+		if n == 0 && rule.Field != SmartFieldPlayCount {
+			return false
+		}
+		value, _ := strconv.Atoi(rule.Value)
+		switch rule.Operator {
+		case SmartOpEqual:
+			return n == value
+		case SmartOpNotEqual:
+			return n != value
+		case SmartOpGreater:
+			return n > value
+		case SmartOpGreaterOrEq:
+			return n >= value
+		case SmartOpLess:
+			return n < value
+		case SmartOpLessOrEq:
+			return n <= value
+		}
+	}
+	return false
+}
+
+// match 按组合方式判断歌曲是否满足所有或任一条件
+func (s *smartSong) match(request SmartPlaylistRequest, now time.Time) bool {
+	for _, rule := range request.Rules {
+		matched := s.matchRule(rule, now)
+		if request.Match == SmartMatchAny && matched {
+			return true
+		}
+		if request.Match != SmartMatchAny && !matched {
+			return false
+		}
+	}
+	return request.Match != SmartMatchAny
+}
+
+// smartLibrary 智能歌单计算时使用的歌曲，按本地音乐库、我喜欢、播放历史的顺序
+type smartLibrary struct {
+	songs  []*smartSong
+	byHash map[string]*smartSong
+}
+
+// add 加入歌曲，已经存在时返回已有的歌曲
+func (l *smartLibrary) add(song PlayerPlaylistSong) *smartSong {
+	if existing, ok := l.byHash[song.Hash]; ok {
+		return existing
+	}
+	s := &smartSong{song: song}
+	l.byHash[song.Hash] = s
+	l.songs = append(l.songs, s)
+	return s
+}
+
+// newSmartLibrary 合并本地音乐库、我喜欢的歌曲和播放历史
+func newSmartLibrary(files []LocalMusicFile, favorites []FavoritesSongData, history []PlayHistoryRecord) *smartLibrary {
+	library := &smartLibrary{byHash: make(map[string]*smartSong)}
+	for i := range files {
+		library.add(files[i].playlistSong()).file = &files[i]
+	}
+	for _, song := range favorites {
+		library.add(PlayerPlaylistSong{
+			Hash:       song.Hash,
+			SongName:   song.SongName,
+			Filename:   song.FileName,
+			ArtistName: song.AuthorName,
+			AlbumName:  song.AlbumName,
+			AlbumID:    song.AlbumID,
+			Duration:   song.TimeLength,
+			UnionCover: song.UnionCover,
+		}).favorite = true
+	}
+	for i := range history {
+		record := &history[i]
+		library.add(PlayerPlaylistSong{
+			Hash:       record.Hash,
+			SongName:   record.SongName,
+			Filename:   record.Filename,
+			ArtistName: record.ArtistName,
+			AlbumName:  record.AlbumName,
+			AlbumID:    record.AlbumID,
+			Duration:   record.Duration,
+			UnionCover: record.UnionCover,
+		}).record = record
+	}
+	return library
+}
+
+// loadSmartLibrary 读取本地音乐库缓存、我喜欢的歌曲的本地副本和播放历史
+func loadSmartLibrary() *smartLibrary {
+	var files []LocalMusicFile
+	if local := (&LocalMusicService{}).GetCachedMusicFiles(); local.Success {
+		files = local.Data
+	}
+
+	var favoriteSongs []FavoritesSongData
+	localFavoritesMutex.Lock()
+	favorites, err := (&FavoritesService{}).loadLocalFavorites()
+	localFavoritesMutex.Unlock()
+	if err == nil {
+		favoriteSongs = favorites.Songs
+	}
+
+	var records []PlayHistoryRecord
+	if history, err := (&PlayHistoryService{}).loadPlayHistory(); err == nil {
+		records = history.Records
+	}
+	return newSmartLibrary(files, favoriteSongs, records)
+}
+
+// evaluate 计算满足条件的歌曲，排序后按数量限制截取
+func (l *smartLibrary) evaluate(request SmartPlaylistRequest, now time.Time) []*smartSong {
+	matched := []*smartSong{}
+	for _, song := range l.songs {
+		if song.match(request, now) {
+			matched = append(matched, song)
+		}
+	}
+
+	days := playCountSortDays(request.Rules)
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		switch request.Sort {
+		case SmartSortPlayCount:
+			return a.playCount(days, now) > b.playCount(days, now)
+		case SmartSortLastPlayed:
+			return a.lastPlayed().After(b.lastPlayed())
+		case SmartSortAdded:
+			return a.addedTime().After(b.addedTime())
+		case SmartSortTitle:
+			return strings.ToLower(a.song.SongName) < strings.ToLower(b.song.SongName)
+		case SmartSortArtist:
+			return strings.ToLower(a.song.ArtistName) < strings.ToLower(b.song.ArtistName)
+		}
+		return false
+	})
+
+	if request.Limit > 0 && len(matched) > request.Limit {
+		matched = matched[:request.Limit]
+	}
+	return matched
+}
+
+// playCountSortDays 按播放次数排序时统计的天数，和第一个播放次数条件的统计范围一致，没有时统计全部
+func playCountSortDays(rules []SmartPlaylistRule) int {
+	for _, rule := range rules {
+		if rule.Field == SmartFieldPlayCount {
+			return rule.Days
+		}
+	}
+	return 0
+}
+
+// smartSongs 转换为播放列表中的歌曲
+func smartSongs(matched []*smartSong) []PlayerPlaylistSong {
+	songs := make([]PlayerPlaylistSong, len(matched))
+	for i, song := range matched {
+		songs[i] = song.song
+	}
+	return songs
+}
+
+// SmartPlaylistStore 保存智能歌单的条件，修改后立即写盘
+type SmartPlaylistStore struct {
+	file      string
+	mutex     sync.Mutex
+	playlists []*SmartPlaylist
+}
+
+// NewSmartPlaylistStore 创建智能歌单存储并加载已保存的条件
+func NewSmartPlaylistStore(file string) *SmartPlaylistStore {
+	store := &SmartPlaylistStore{file: file}
+	if data, err := os.ReadFile(file); err == nil {
+		if err := json.Unmarshal(data, &store.playlists); err != nil {
+			fmt.Printf("⚠️ 智能歌单文件格式错误，已忽略: %v\n", err)
+			store.playlists = nil
+		}
+	}
+	return store
+}
+
+// findLocked 按ID查找智能歌单，调用方需持有锁
+func (s *SmartPlaylistStore) findLocked(id string) *SmartPlaylist {
+	for _, playlist := range s.playlists {
+		if playlist.ID == id {
+			return playlist
+		}
+	}
+	return nil
+}
+
+// saveLocked 保存所有智能歌单，调用方需持有锁
+func (s *SmartPlaylistStore) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("创建智能歌单目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(s.playlists, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化智能歌单失败: %v", err)
+	}
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	if err := os.WriteFile(s.file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("写入智能歌单失败: %v", err)
+	}
+	return os.Rename(s.file+".tmp", s.file)
+}
+
+// List 获取所有智能歌单，按创建顺序排列
+func (s *SmartPlaylistStore) List() []SmartPlaylist {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlists := make([]SmartPlaylist, len(s.playlists))
+	for i, playlist := range s.playlists {
+		playlists[i] = *playlist
+	}
+	return playlists
+}
+
+// Get 获取智能歌单
+func (s *SmartPlaylistStore) Get(id string) (SmartPlaylist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist := s.findLocked(id)
+	if playlist == nil {
+		return SmartPlaylist{}, errSmartPlaylistNotFound
+	}
+	return *playlist, nil
+}
+
+// Create 检查条件后创建智能歌单
+func (s *SmartPlaylistStore) Create(request SmartPlaylistRequest) (SmartPlaylist, error) {
+	request, err := request.normalize()
+	if err != nil {
+		return SmartPlaylist{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	playlist := &SmartPlaylist{
+		ID:                   newRandomID(),
+		SmartPlaylistRequest: request,
+		CreateTime:           now,
+		UpdateTime:           now,
+	}
+	s.playlists = append(s.playlists, playlist)
+	return *playlist, s.saveLocked()
+}
+
+// Update 修改智能歌单的名称和条件，上次刷新的结果随之失效
+func (s *SmartPlaylistStore) Update(id string, request SmartPlaylistRequest) (SmartPlaylist, error) {
+	request, err := request.normalize()
+	if err != nil {
+		return SmartPlaylist{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist := s.findLocked(id)
+	if playlist == nil {
+		return SmartPlaylist{}, errSmartPlaylistNotFound
+	}
+	playlist.SmartPlaylistRequest = request
+	playlist.Count = 0
+	playlist.RefreshTime = time.Time{}
+	playlist.UpdateTime = time.Now()
+	return *playlist, s.saveLocked()
+}
+
+// Delete 删除智能歌单
+func (s *SmartPlaylistStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, playlist := range s.playlists {
+		if playlist.ID == id {
+			s.playlists = slices.Delete(s.playlists, i, i+1)
+			return s.saveLocked()
+		}
+	}
+	return errSmartPlaylistNotFound
+}
+
+// Refresh 重新计算智能歌单的歌曲并记录数量和刷新时间
+func (s *SmartPlaylistStore) Refresh(id string, library *smartLibrary, now time.Time) (SmartPlaylistSongs, []*smartSong, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	playlist := s.findLocked(id)
+	if playlist == nil {
+		return SmartPlaylistSongs{}, nil, errSmartPlaylistNotFound
+	}
+	matched := library.evaluate(playlist.SmartPlaylistRequest, now)
+	playlist.Count = len(matched)
+	playlist.RefreshTime = now
+	if err := s.saveLocked(); err != nil {
+		fmt.Printf("⚠️ 保存智能歌单失败: %v\n", err)
+	}
+	return SmartPlaylistSongs{Playlist: *playlist, Songs: smartSongs(matched)}, matched, nil
+}
+
+// SmartPlaylistService 智能歌单服务
+type SmartPlaylistService struct{}
+
+// smartPlaylists 获取智能歌单存储，智能歌单由应用数据持有
+func (s *SmartPlaylistService) smartPlaylists() (*SmartPlaylistStore, error) {
+	state, err := currentAppState()
+	if err != nil {
+		return nil, err
+	}
+	return state.smartPlaylists, nil
+}
+
+// smartPlaylistResult 把智能歌单的操作结果转换为响应
+func smartPlaylistResult(playlist SmartPlaylist, err error, message string) SmartPlaylistResponse {
+	if err != nil {
+		return SmartPlaylistResponse{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	return SmartPlaylistResponse{
+		Success: true,
+		Message: message,
+		Data:    playlist,
+	}
+}
+
+// GetSmartPlaylists 获取智能歌单列表
+func (s *SmartPlaylistService) GetSmartPlaylists() SmartPlaylistsResponse {
+	store, err := s.smartPlaylists()
+	if err != nil {
+		return SmartPlaylistsResponse{Success: false, Message: err.Error()}
+	}
+	return SmartPlaylistsResponse{
+		Success: true,
+		Message: "获取智能歌单成功",
+		Data:    store.List(),
+	}
+}
+
+// CreateSmartPlaylist 创建智能歌单
+func (s *SmartPlaylistService) CreateSmartPlaylist(request SmartPlaylistRequest) SmartPlaylistResponse {
+	store, err := s.smartPlaylists()
+	if err != nil {
+		return smartPlaylistResult(SmartPlaylist{}, err, "")
+	}
+	playlist, err := store.Create(request)
+	return smartPlaylistResult(playlist, err, "创建智能歌单成功")
+}
+
+// UpdateSmartPlaylist 修改智能歌单的名称和条件
+func (s *SmartPlaylistService) UpdateSmartPlaylist(id string, request SmartPlaylistRequest) SmartPlaylistResponse {
+	store, err := s.smartPlaylists()
+	if err != nil {
+		return smartPlaylistResult(SmartPlaylist{}, err, "")
+	}
+	playlist, err := store.Update(id, request)
+	return smartPlaylistResult(playlist, err, "修改智能歌单成功")
+}
+
+// DeleteSmartPlaylist 删除智能歌单
+func (s *SmartPlaylistService) DeleteSmartPlaylist(id string) SmartPlaylistsResponse {
+	store, err := s.smartPlaylists()
+	if err == nil {
+		err = store.Delete(id)
+	}
+	if err != nil {
+		return SmartPlaylistsResponse{Success: false, Message: err.Error()}
+	}
+	return SmartPlaylistsResponse{
+		Success: true,
+		Message: "删除智能歌单成功",
+		Data:    store.List(),
+	}
+}
+
+// PreviewSmartPlaylist 不保存条件，直接计算满足条件的歌曲，用于编辑时预览
+func (s *SmartPlaylistService) PreviewSmartPlaylist(request SmartPlaylistRequest) SmartPlaylistSongsResponse {
+	request, err := request.normalize()
+	if err != nil {
+		return SmartPlaylistSongsResponse{Success: false, Message: err.Error()}
+	}
+	songs := smartSongs(loadSmartLibrary().evaluate(request, time.Now()))
+	return SmartPlaylistSongsResponse{
+		Success: true,
+		Message: fmt.Sprintf("找到 %d 首歌曲", len(songs)),
+		Data: SmartPlaylistSongs{
+			Playlist: SmartPlaylist{SmartPlaylistRequest: request, Count: len(songs)},
+			Songs:    songs,
+		},
+	}
+}
+
+// RefreshSmartPlaylist 按最新的播放历史、我喜欢的歌曲和本地音乐重新计算智能歌单
+func (s *SmartPlaylistService) RefreshSmartPlaylist(id string) SmartPlaylistSongsResponse {
+	store, err := s.smartPlaylists()
+	if err != nil {
+		return SmartPlaylistSongsResponse{Success: false, Message: err.Error()}
+	}
+	result, _, err := store.Refresh(id, loadSmartLibrary(), time.Now())
+	if err != nil {
+		return SmartPlaylistSongsResponse{Success: false, Message: err.Error()}
+	}
+	return SmartPlaylistSongsResponse{
+		Success: true,
+		Message: fmt.Sprintf("找到 %d 首歌曲", len(result.Songs)),
+		Data:    result,
+	}
+}
+
+// PlaySmartPlaylist 刷新智能歌单并替换当前播放列表，本地音乐会注册映射以便按hash播放
+func (s *SmartPlaylistService) PlaySmartPlaylist(id string) PlayerPlaylistResponse {
+	store, err := s.smartPlaylists()
+	if err != nil {
+		return playlistResult(PlayerPlaylistData{}, err, "")
+	}
+	result, matched, err := store.Refresh(id, loadSmartLibrary(), time.Now())
+	if err != nil {
+		return playlistResult(PlayerPlaylistData{}, err, "")
+	}
+	if len(result.Songs) == 0 {
+		return playlistResult(PlayerPlaylistData{}, fmt.Errorf("智能歌单「%s」没有满足条件的歌曲", result.Playlist.Name), "")
+	}
+
+	var files []LocalMusicFile
+	for _, song := range matched {
+		if song.file != nil {
+			files = append(files, *song.file)
+		}
+	}
+	if len(files) > 0 {
+		if err := (&LocalMusicService{}).generateLocalMusicMappings(files); err != nil {
+			fmt.Printf("生成本地音乐映射失败: %v\n", err)
+		}
+	}
+
+	return (&PlaylistService{}).SetPlaylist(SetPlaylistRequest{
+		Songs:      result.Songs,
+		Name:       result.Playlist.Name,
+		ClearFirst: true,
+	})
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSmartPlaylistRequestValidation(t *testing.T) {
+	valid := SmartPlaylistRule{Field: SmartFieldPlayCount, Operator: SmartOpGreater, Value: "5"}
+	invalid := []SmartPlaylistRequest{
+		{Name: " ", Rules: []SmartPlaylistRule{valid}},
+		{Name: "a"},
+		{Name: "a", Match: "some", Rules: []SmartPlaylistRule{valid}},
+		{Name: "a", Sort: "random", Rules: []SmartPlaylistRule{valid}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: "rating", Operator: SmartOpIs, Value: "5"}}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: SmartFieldTitle, Operator: SmartOpGreater, Value: "5"}}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: SmartFieldSource, Operator: SmartOpIs, Value: "radio"}}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: SmartFieldArtist, Operator: SmartOpContains, Value: " "}}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: SmartFieldAdded, Operator: SmartOpInLast, Value: "一周"}}},
+		{Name: "a", Rules: []SmartPlaylistRule{{Field: SmartFieldDuration, Operator: SmartOpLess, Value: "-1"}}},
+	}
+	for _, request := range invalid {
+		if _, err := request.normalize(); err == nil {
+			t.Errorf("normalize(%+v) should fail", request)
+		}
+	}
+
+	request, err := SmartPlaylistRequest{
+		Name:  " 常听 ",
+		Limit: -1,
+		Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpGreater, Value: " 5 ", Days: 30},
+			{Field: SmartFieldTitle, Operator: SmartOpContains, Value: "晴天", Days: 7},
+		},
+	}.normalize()
+	want := SmartPlaylistRequest{
+		Name:  "常听",
+		Match: SmartMatchAll,
+		Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpGreater, Value: "5", Days: 30},
+			{Field: SmartFieldTitle, Operator: SmartOpContains, Value: "晴天"},
+		},
+	}
+	if err != nil || !reflect.DeepEqual(request, want) {
+		t.Errorf("normalize = %+v, %v", request, err)
+	}
+}
+
+// playTimesAgo 距离 now 指定天数的播放时间
+func playTimesAgo(now time.Time, days ...int) []time.Time {
+	times := make([]time.Time, len(days))
+	for i, d := range days {
+		times[i] = now.AddDate(0, 0, -d)
+	}
+	return times
+}
+
+func TestSmartPlaylistEvaluate(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	daysAgo := func(days int) int64 { return now.AddDate(0, 0, -days).Unix() }
+
+	library := newSmartLibrary(
+		[]LocalMusicFile{
+			{Hash: "a", Title: "晴天", Artist: "周杰伦", Format: "flac", Year: 2003, Duration: 269, AddedAt: daysAgo(2)},
+			{Hash: "b", Title: "七里香", Artist: "周杰伦", Format: "mp3", Year: 2004, Duration: 299, AddedAt: daysAgo(20)},
+			// 旧版本缓存没有加入时间，使用文件修改时间
+			{Hash: "c", Title: "江南", Artist: "林俊杰", Format: "FLAC", Duration: 267, LastModified: daysAgo(1)},
+		},
+		[]FavoritesSongData{
+			{Hash: "F1", SongName: "稻香", AuthorName: "周杰伦"},
+			{Hash: "F2", SongName: "夜曲", AuthorName: "周杰伦"},
+		},
+		[]PlayHistoryRecord{
+			{Hash: "F2", SongName: "夜曲", PlayCount: 6, LastPlayTime: now.AddDate(0, 0, -1), PlayTimes: playTimesAgo(now, 29, 20, 10, 5, 2, 1)},
+			{Hash: "H1", SongName: "不能说的秘密", PlayCount: 10, LastPlayTime: now.AddDate(0, 0, -3), PlayTimes: playTimesAgo(now, 90, 60, 40, 3)},
+			// 旧版本的记录只有最后播放时间
+			{Hash: "H2", SongName: "告白气球", PlayCount: 8, LastPlayTime: now.AddDate(0, 0, -10)},
+			{Hash: "local-a", SongName: "晴天", PlayCount: 1, LastPlayTime: now.AddDate(0, 0, -40), PlayTimes: playTimesAgo(now, 40)},
+		},
+	)
+
+	tests := []struct {
+		name    string
+		request SmartPlaylistRequest
+		want    []string
+	}{
+		{"played more than 5 times in 30 days", SmartPlaylistRequest{Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpGreater, Value: "5", Days: 30},
+		}}, []string{"F2"}},
+		{"most played", SmartPlaylistRequest{Sort: SmartSortPlayCount, Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpGreaterOrEq, Value: "6"},
+		}}, []string{"H1", "H2", "F2"}},
+		{"most played in 30 days sorts by the same window", SmartPlaylistRequest{Sort: SmartSortPlayCount, Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpGreaterOrEq, Value: "1", Days: 30},
+		}}, []string{"F2", "H1", "H2"}},
+		{"legacy record counts its last play", SmartPlaylistRequest{Rules: []SmartPlaylistRule{
+			{Field: SmartFieldPlayCount, Operator: SmartOpEqual, Value: "1", Days: 30},
+		}}, []string{"H1", "H2"}},
+		{"local flac by artist", SmartPlaylistRequest{Rules: []SmartPlaylistRule{
+			{Field: SmartFieldSource, Operator: SmartOpIs, Value: SmartSourceLibrary},
+			{Field: SmartFieldFormat, Operator: SmartOpIs, Value: ".flac"},
+			{Field: SmartFieldArtist, Operator: SmartOpContains, Value: "周杰伦"},
+		}}, []string{"local-a"}},
+		{"favorites never played", SmartPlaylistRequest{Rules: []SmartPlaylistRule{
+			{Field: SmartFieldSource, Operator: SmartOpIs, Value: SmartSourceFavorite},
+			{Field: SmartFieldPlayCount, Operator: SmartOpEqual, Value: "0"},
+		}}, []string{"F1"}},
+		{"added this week", SmartPlaylistRequest{Sort: SmartSortAdded, Rules: []SmartPlaylistRule{
+			{Field: SmartFieldAdded, Operator: SmartOpInLast, Value: "7"},
+		}}, []string{"local-c", "local-a"}},
+		{"not played recently", SmartPlaylistRequest{Rules: []SmartPlaylistRule{
+			{Field: SmartFieldSource, Operator: SmartOpIsNot, Value: SmartSourceFavorite},
+			{Field: SmartFieldLastPlayed, Operator: SmartOpNotInLast, Value: "7"},
+		}}, []string{"local-a", "local-b", "local-c", "H2"}},
+		{"any with limit", SmartPlaylistRequest{Match: SmartMatchAny, Sort: SmartSortTitle, Limit: 2, Rules: []SmartPlaylistRule{
+			{Field: SmartFieldArtist, Operator: SmartOpIs, Value: "林俊杰"},
+			{Field: SmartFieldYear, Operator: SmartOpLess, Value: "2004"},
+			{Field: SmartFieldDuration, Operator: SmartOpGreater, Value: "290"},
+		}}, []string{"local-b", "local-a"}},
+	}
+	for _, tt := range tests {
+		tt.request.Name = tt.name
+		request, err := tt.request.normalize()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := songHashes(smartSongs(library.evaluate(request, now))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCacheMusicFilesKeepsAddedTime(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	local := &LocalMusicService{}
+	dir := t.TempDir()
+	pathA := writeTestFile(t, filepath.Join(dir, "music", "a.mp3"), "a")
+	pathB := writeTestFile(t, filepath.Join(dir, "other", "b.mp3"), "b")
+
+	// 还没有加入时间的记录时，已有的文件用修改时间补齐
+	files := []LocalMusicFile{{FilePath: pathA, Hash: "a", LastModified: 100}}
+	if err := local.cacheMusicFiles(files); err != nil {
+		t.Fatal(err)
+	}
+	if files[0].AddedAt != 100 {
+		t.Errorf("backfilled added = %d", files[0].AddedAt)
+	}
+
+	// 之后新扫描到的文件使用扫描的时间，即使修改时间保留得很早
+	before := time.Now().Unix()
+	files = []LocalMusicFile{{FilePath: pathB, Hash: "b", LastModified: 300}}
+	local.cacheMusicFiles(files)
+	if files[0].AddedAt < before {
+		t.Errorf("new file added = %d, want >= %d", files[0].AddedAt, before)
+	}
+
+	// 只扫描其他文件夹后重新扫描，修改过的文件仍然使用第一次的时间
+	local.cacheMusicFiles([]LocalMusicFile{{FilePath: pathA, Hash: "a2", LastModified: 200}})
+	cached := local.GetCachedMusicFiles()
+	if !cached.Success || len(cached.Data) != 1 || cached.Data[0].AddedAt != 100 {
+		t.Errorf("cached = %+v", cached)
+	}
+
+	// 已经删除的文件不再保留记录
+	os.Remove(pathB)
+	local.cacheMusicFiles([]LocalMusicFile{{FilePath: pathA, Hash: "a", LastModified: 100}})
+	cacheDir, _ := local.getCacheDir()
+	addedTimes, _ := local.loadAddedTimes(filepath.Join(cacheDir, "music_cache.json"))
+	if _, ok := addedTimes[pathB]; ok || addedTimes[pathA] != 100 {
+		t.Errorf("added times = %v", addedTimes)
+	}
+}
+
+func TestSmartPlaylistService(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	withCacheService(t, c)
+	newTestAppState(t, c)
+
+	dir := t.TempDir()
+	path := writeTestFile(t, filepath.Join(dir, "晴天.flac"), "not really audio")
+	if err := (&LocalMusicService{}).cacheMusicFiles([]LocalMusicFile{
+		{FilePath: path, Hash: "a", Title: "晴天", Artist: "周杰伦", Format: "flac"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	history := &PlayHistoryService{}
+	for i := 0; i < 3; i++ {
+		history.AddPlayHistory(AddPlayHistoryRequest{Hash: "H1", SongName: "七里香", ArtistName: "周杰伦"})
+	}
+	if data, _ := history.loadPlayHistory(); len(data.Records[0].PlayTimes) != 3 {
+		t.Errorf("play times = %v", data.Records[0].PlayTimes)
+	}
+
+	service := &SmartPlaylistService{}
+	if resp := service.CreateSmartPlaylist(SmartPlaylistRequest{Name: "空"}); resp.Success {
+		t.Errorf("create without rules = %+v", resp)
+	}
+	created := service.CreateSmartPlaylist(SmartPlaylistRequest{
+		Name:  "最近常听",
+		Rules: []SmartPlaylistRule{{Field: SmartFieldPlayCount, Operator: SmartOpGreaterOrEq, Value: "3", Days: 30}},
+	})
+	if !created.Success || created.Data.ID == "" || created.Data.Match != SmartMatchAll {
+		t.Fatalf("create = %+v", created)
+	}
+	id := created.Data.ID
+
+	preview := service.PreviewSmartPlaylist(SmartPlaylistRequest{
+		Name:  "预览",
+		Rules: []SmartPlaylistRule{{Field: SmartFieldSource, Operator: SmartOpIs, Value: SmartSourceLibrary}},
+	})
+	if !preview.Success || len(preview.Data.Songs) != 1 || preview.Data.Songs[0].Hash != "local-a" {
+		t.Errorf("preview = %+v", preview)
+	}
+
+	refreshed := service.RefreshSmartPlaylist(id)
+	if !refreshed.Success || len(refreshed.Data.Songs) != 1 || refreshed.Data.Playlist.Count != 1 || refreshed.Data.Playlist.RefreshTime.IsZero() {
+		t.Errorf("refresh = %+v", refreshed)
+	}
+
+	// 修改条件后包含本地音乐，播放时注册映射并替换播放列表
+	updated := service.UpdateSmartPlaylist(id, SmartPlaylistRequest{
+		Name:  "周杰伦",
+		Match: SmartMatchAny,
+		Sort:  SmartSortTitle,
+		Rules: []SmartPlaylistRule{{Field: SmartFieldArtist, Operator: SmartOpIs, Value: "周杰伦"}},
+	})
+	if !updated.Success || updated.Data.Count != 0 || !updated.Data.RefreshTime.IsZero() {
+		t.Errorf("update = %+v", updated)
+	}
+	played := service.PlaySmartPlaylist(id)
+	if !played.Success || played.Data.Name != "周杰伦" || !reflect.DeepEqual(songHashes(played.Data.Songs), []string{"H1", "local-a"}) {
+		t.Fatalf("play = %+v", played)
+	}
+	if got, _ := c.localMusicPath("local-a"); got != path {
+		t.Errorf("local music path = %q", got)
+	}
+
+	// 条件立即保存，重新加载后仍然存在
+	store := NewSmartPlaylistStore(filepath.Join(c.cacheDir, "smart_playlists.json"))
+	if playlists := store.List(); len(playlists) != 1 || playlists[0].Name != "周杰伦" || playlists[0].Count != 2 {
+		t.Errorf("reloaded = %+v", playlists)
+	}
+
+	if resp := service.DeleteSmartPlaylist(id); !resp.Success || len(resp.Data) != 0 {
+		t.Errorf("delete = %+v", resp)
+	}
+	if resp := service.PlaySmartPlaylist(id); resp.Success {
+		t.Errorf("play deleted = %+v", resp)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SongMetadata 缓存歌曲的元数据，来自播放地址、播放列表和播放历史等接口，歌词单独保存在 LyricsCache
+type SongMetadata struct {
+	Hash       string `json:"hash"`
+	SongName   string `json:"songname"`
+	FileName   string `json:"filename"`
+	AuthorName string `json:"author_name"`
+	AlbumName  string `json:"album_name"`
+	AlbumID    string `json:"album_id"`
+	TimeLength int    `json:"time_length"` // 歌曲时长（秒）
+	UnionCover string `json:"union_cover"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// CachedSongInfo 已缓存的歌曲，包含元数据与缓存文件信息
+type CachedSongInfo struct {
+	SongMetadata
+	File       string `json:"file"`
+	Quality    string `json:"quality"`
+	Size       int64  `json:"size"`
+	LastAccess int64  `json:"last_access"`
+	Pinned     bool   `json:"pinned"`
+}
+
+// CachedSongsResponse 已缓存歌曲列表响应结构
+type CachedSongsResponse = ApiResponse[[]CachedSongInfo]
+
+// CacheExportResult 导出缓存歌曲的结果
+type CacheExportResult struct {
+	Directory     string   `json:"directory"`
+	ExportedCount int      `json:"exported_count"`
+	Files         []string `json:"files"`
+	Failed        []string `json:"failed"` // 导出失败的歌曲hash
+}
+
+// songMetadataSaveDelay 元数据变更后延迟写盘的时间，播放列表等接口会批量更新元数据
+const songMetadataSaveDelay = 5 * time.Second
+
+// SongMetadataStore 持久化保存歌曲元数据，以歌曲hash为键
+type SongMetadataStore struct {
+	file      string
+	songs     map[string]*SongMetadata
+	saveTimer *time.Timer
+	saveMutex sync.Mutex // 串行写盘，先取得的快照先写入
+	mutex     sync.Mutex
+}
+
+// NewSongMetadataStore 创建元数据存储并加载已保存的数据
+func NewSongMetadataStore(file string) *SongMetadataStore {
+	store := &SongMetadataStore{
+		file:  file,
+		songs: make(map[string]*SongMetadata),
+	}
+	store.load()
+	return store
+}
+
+// load 读取元数据文件
+func (s *SongMetadataStore) load() {
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		return
+	}
+
+	var songs []*SongMetadata
+	if err := json.Unmarshal(data, &songs); err != nil {
+		fmt.Printf("⚠️ 解析歌曲元数据失败: %v\n", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, song := range songs {
+		if song != nil && song.Hash != "" {
+			s.songs[song.Hash] = song
+		}
+	}
+}
+
+// Update 合并歌曲元数据，只覆盖非空的字段
+func (s *SongMetadataStore) Update(meta SongMetadata) {
+	if meta.Hash == "" {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	song, ok := s.songs[meta.Hash]
+	if !ok {
+		song = &SongMetadata{Hash: meta.Hash}
+		s.songs[meta.Hash] = song
+	}
+	before := *song
+
+	mergeString := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	mergeString(&song.SongName, meta.SongName)
+	mergeString(&song.FileName, meta.FileName)
+	mergeString(&song.AuthorName, meta.AuthorName)
+	mergeString(&song.AlbumName, meta.AlbumName)
+	mergeString(&song.AlbumID, meta.AlbumID)
+	mergeString(&song.UnionCover, meta.UnionCover)
+	if meta.TimeLength > 0 {
+		song.TimeLength = meta.TimeLength
+	}
+
+	if !ok || *song != before {
+		song.UpdatedAt = time.Now().Unix()
+		s.scheduleSaveLocked()
+	}
+}
+
+// Get 获取歌曲元数据
+func (s *SongMetadataStore) Get(hash string) (SongMetadata, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	song, ok := s.songs[hash]
+	if !ok {
+		return SongMetadata{}, false
+	}
+	return *song, true
+}
+
+// scheduleSaveLocked 延迟保存元数据，调用方需持有锁
+func (s *SongMetadataStore) scheduleSaveLocked() {
+	if s.saveTimer != nil {
+		return
+	}
+	s.saveTimer = time.AfterFunc(songMetadataSaveDelay, func() {
+		if err := s.Flush(); err != nil {
+			fmt.Printf("⚠️ 保存歌曲元数据失败: %v\n", err)
+		}
+	})
+}
+
+// Flush 立即将元数据写入文件
+func (s *SongMetadataStore) Flush() error {
+	// 定时保存和退出时的保存不会交错写入临时文件，旧的快照也不会覆盖新的
+	s.saveMutex.Lock()
+	defer s.saveMutex.Unlock()
+
+	s.mutex.Lock()
+	if s.saveTimer != nil {
+		s.saveTimer.Stop()
+		s.saveTimer = nil
+	}
+	songs := make([]SongMetadata, 0, len(s.songs))
+	for _, song := range s.songs {
+		songs = append(songs, *song)
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(songs, func(i, j int) bool {
+		return songs[i].Hash < songs[j].Hash
+	})
+
+	data, err := json.MarshalIndent(songs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化歌曲元数据失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+		return fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免写入中断导致文件损坏
+	tempFile := s.file + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("写入歌曲元数据失败: %v", err)
+	}
+	return os.Rename(tempFile, s.file)
+}
+
+// recordSongMetadata 记录歌曲元数据，应用数据尚未创建时忽略
+func recordSongMetadata(meta SongMetadata) {
+	if appState := GetAppState(); appState != nil {
+		appState.metadata.Update(meta)
+	}
+}
+
+// cachedSongs 列出已缓存的歌曲，同一首歌只保留最高音质，按最近播放时间排序
+// 应用数据尚未创建时没有歌名等元数据
+func (c *CacheService) cachedSongs() []CachedSongInfo {
+	var metadata *SongMetadataStore
+	if appState := GetAppState(); appState != nil {
+		metadata = appState.metadata
+	}
+
+	best := make(map[string]audioCacheEntry)
+	for _, entry := range c.audioCache.Entries() {
+		// 没有hash的旧版本缓存无法对应到歌曲
+		if entry.Hash == "" {
+			continue
+		}
+		if current, ok := best[entry.Hash]; !ok || audioQualityRank(entry.Quality) > audioQualityRank(current.Quality) {
+			best[entry.Hash] = entry
+		}
+	}
+
+	songs := make([]CachedSongInfo, 0, len(best))
+	for hash, entry := range best {
+		var meta SongMetadata
+		if metadata != nil {
+			meta, _ = metadata.Get(hash)
+		}
+		meta.Hash = hash
+		songs = append(songs, CachedSongInfo{
+			SongMetadata: meta,
+			File:         entry.File,
+			Quality:      entry.Quality,
+			Size:         entry.Size,
+			LastAccess:   entry.LastAccess,
+			Pinned:       c.audioCache.IsPinned(hash),
+		})
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		if songs[i].LastAccess != songs[j].LastAccess {
+			return songs[i].LastAccess > songs[j].LastAccess
+		}
+		return songs[i].Hash < songs[j].Hash
+	})
+	return songs
+}
+
+// ListCachedSongs 列出已缓存的歌曲，keyword 不为空时按歌名、歌手、专辑和文件名搜索
+func (c *CacheService) ListCachedSongs(keyword string) CachedSongsResponse {
+	keywords := strings.Fields(strings.ToLower(keyword))
+
+	songs := []CachedSongInfo{}
+	for _, song := range c.cachedSongs() {
+		if matchOfflineSong(song.searchData(), keywords) {
+			songs = append(songs, song)
+		}
+	}
+
+	return CachedSongsResponse{
+		Success: true,
+		Message: fmt.Sprintf("共 %d 首已缓存的歌曲", len(songs)),
+		Data:    songs,
+	}
+}
+
+// searchData 转换为搜索结果的歌曲结构
+func (s SongMetadata) searchData() SearchSongData {
+	return SearchSongData{
+		Hash:       s.Hash,
+		SongName:   s.SongName,
+		FileName:   s.FileName,
+		TimeLength: s.TimeLength,
+		AlbumName:  s.AlbumName,
+		AlbumID:    s.AlbumID,
+		AuthorName: s.AuthorName,
+		UnionCover: s.UnionCover,
+	}
+}
+
+// displayName 生成便于识别的文件名：歌手 - 歌名，缺少信息时使用文件名或hash
+func (s SongMetadata) displayName() string {
+	name := s.SongName
+	switch {
+	case name != "" && s.AuthorName != "":
+		name = s.AuthorName + " - " + name
+	case name == "" && s.FileName != "":
+		name = strings.TrimSuffix(s.FileName, filepath.Ext(s.FileName))
+	case name == "":
+		name = s.Hash
+	}
+
+	// 替换文件系统不允许的字符
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+// defaultExportDir 默认的导出目录：设置中的下载目录，未设置时为 ~/Music/wmplayer
+func defaultExportDir() string {
+	if response, err := NewSettingsService().LoadSettings(); err == nil && response.Success && response.Data.Download.DownloadPath != "" {
+		return response.Data.Download.DownloadPath
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, "Music", "wmplayer")
+}
+
+// ExportCachedSongs 将已缓存的歌曲以"歌手 - 歌名"命名复制到指定目录
+// hashes 为空时导出全部已缓存的歌曲，destDir 为空时使用默认导出目录
+func (c *CacheService) ExportCachedSongs(hashes []string, destDir string) ApiResponse[CacheExportResult] {
+	if destDir == "" {
+		destDir = defaultExportDir()
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return ApiResponse[CacheExportResult]{
+			Success: false,
+			Message: fmt.Sprintf("创建导出目录失败: %v", err),
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+
+	result := CacheExportResult{
+		Directory: destDir,
+		Files:     []string{},
+		Failed:    []string{},
+	}
+	for _, song := range c.cachedSongs() {
+		if len(wanted) > 0 && !wanted[song.Hash] {
+			continue
+		}
+		fileName, err := c.exportCachedSong(song, destDir)
+		if err != nil {
+			fmt.Printf("❌ 导出缓存歌曲失败: %s, %v\n", song.Hash, err)
+			result.Failed = append(result.Failed, song.Hash)
+			continue
+		}
+		result.Files = append(result.Files, fileName)
+	}
+	result.ExportedCount = len(result.Files)
+
+	fmt.Printf("📤 已导出 %d 首缓存歌曲到: %s\n", result.ExportedCount, destDir)
+	return ApiResponse[CacheExportResult]{
+		Success: len(result.Failed) == 0,
+		Message: fmt.Sprintf("已导出 %d 首歌曲，失败 %d 首", result.ExportedCount, len(result.Failed)),
+		Data:    result,
+	}
+}
+
+// exportCachedSong 复制单个缓存文件，重名时在文件名后追加序号
+func (c *CacheService) exportCachedSong(song CachedSongInfo, destDir string) (string, error) {
+	if !c.audioCache.Validate(song.File) {
+		return "", fmt.Errorf("缓存文件已损坏或不存在")
+	}
+
+	src, err := os.Open(filepath.Join(c.mp3Dir, song.File))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	base := song.displayName()
+	ext := filepath.Ext(song.File)
+	var dst *os.File
+	var fileName string
+	for i := 1; ; i++ {
+		fileName = base + ext
+		if i > 1 {
+			fileName = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		dst, err = os.OpenFile(filepath.Join(destDir, fileName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !os.IsExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return fileName, dst.Close()
+}
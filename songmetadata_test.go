@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSongMetadataStoreMerge(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "song_metadata.json")
+	store := NewSongMetadataStore(file)
+
+	store.Update(SongMetadata{Hash: "A", SongName: "晴天", AuthorName: "周杰伦"})
+	// 只有部分字段的更新不应清除已有信息
+	store.Update(SongMetadata{Hash: "A", AlbumName: "叶惠美", TimeLength: 269})
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	song, ok := NewSongMetadataStore(file).Get("A")
+	if !ok || song.SongName != "晴天" || song.AuthorName != "周杰伦" || song.TimeLength != 269 || song.AlbumName != "叶惠美" {
+		t.Errorf("song = %+v", song)
+	}
+}
+
+func TestListAndExportCachedSongs(t *testing.T) {
+	c, _ := newTestCacheService(t)
+	state := newTestAppState(t, c)
+
+	for _, song := range []SongMetadata{
+		{Hash: "A", SongName: "晴天", AuthorName: "周杰伦", AlbumName: "叶惠美"},
+		{Hash: "B", SongName: "后来", AuthorName: "刘若英"},
+	} {
+		fileName := c.getCacheKey(song.Hash, AudioQualityHigh) + ".flac"
+		writeCacheFile(t, c.mp3Dir, fileName, 10)
+		c.audioCache.Record(fileName, song.Hash, AudioQualityHigh)
+		state.metadata.Update(song)
+	}
+
+	if resp := c.ListCachedSongs(""); len(resp.Data) != 2 {
+		t.Fatalf("all = %+v", resp.Data)
+	}
+	resp := c.ListCachedSongs("叶惠美")
+	if len(resp.Data) != 1 || resp.Data[0].Hash != "A" || resp.Data[0].Quality != AudioQualityHigh {
+		t.Fatalf("search = %+v", resp.Data)
+	}
+
+	dest := t.TempDir()
+	for _, want := range []string{"周杰伦 - 晴天.flac", "周杰伦 - 晴天 (2).flac"} {
+		export := c.ExportCachedSongs([]string{"A"}, dest)
+		if !export.Success || len(export.Data.Files) != 1 || export.Data.Files[0] != want {
+			t.Fatalf("export = %+v, want %s", export, want)
+		}
+		if info, err := os.Stat(filepath.Join(dest, want)); err != nil || info.Size() != 10 {
+			t.Errorf("exported file: %v", err)
+		}
+	}
+}
+
+func TestSongMetadataDisplayName(t *testing.T) {
+	tests := []struct {
+		meta SongMetadata
+		want string
+	}{
+		{SongMetadata{Hash: "A", SongName: "晴天", AuthorName: "周杰伦"}, "周杰伦 - 晴天"},
+		{SongMetadata{Hash: "A", FileName: "周杰伦 - 晴天.mp3"}, "周杰伦 - 晴天"},
+		{SongMetadata{Hash: "A", SongName: "AC/DC: Live?"}, "AC_DC_ Live_"},
+		{SongMetadata{Hash: "A"}, "A"},
+	}
+	for _, tt := range tests {
+		if got := tt.meta.displayName(); got != tt.want {
+			t.Errorf("displayName(%+v) = %q, want %q", tt.meta, got, tt.want)
+		}
+	}
+}